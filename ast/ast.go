@@ -4,6 +4,8 @@ import (
 	"bhasa/token"
 	"bytes"
 	"fmt"
+	"math/big"
+	"sort"
 	"strings"
 )
 
@@ -71,6 +73,32 @@ func (ls *LetStatement) String() string {
 	return out.String()
 }
 
+// DestructuringLetStatement declares several variables from the elements
+// of one tuple or array: ধরি x, y = f();
+type DestructuringLetStatement struct {
+	Token token.Token // the ধরি token
+	Names []*Identifier
+	Value Expression
+}
+
+func (ds *DestructuringLetStatement) statementNode()       {}
+func (ds *DestructuringLetStatement) TokenLiteral() string { return ds.Token.Literal }
+func (ds *DestructuringLetStatement) String() string {
+	names := []string{}
+	for _, name := range ds.Names {
+		names = append(names, name.String())
+	}
+	var out bytes.Buffer
+	out.WriteString(ds.TokenLiteral() + " ")
+	out.WriteString(strings.Join(names, ", "))
+	out.WriteString(" = ")
+	if ds.Value != nil {
+		out.WriteString(ds.Value.String())
+	}
+	out.WriteString(";")
+	return out.String()
+}
+
 // ReturnStatement represents a return statement (ফেরত)
 type ReturnStatement struct {
 	Token       token.Token // the ফেরত token
@@ -186,6 +214,18 @@ func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
 func (i *Identifier) String() string       { return i.Value }
 
+// BuiltinReference names a builtin function directly, whatever the
+// program binds to the same name. Only desugaring creates it, so the code
+// it generates keeps working in programs that shadow the builtins it uses.
+type BuiltinReference struct {
+	Token token.Token
+	Name  string
+}
+
+func (br *BuiltinReference) expressionNode()      {}
+func (br *BuiltinReference) TokenLiteral() string { return br.Token.Literal }
+func (br *BuiltinReference) String() string       { return br.Name }
+
 // IntegerLiteral represents an integer literal
 type IntegerLiteral struct {
 	Token token.Token
@@ -196,6 +236,63 @@ func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+// BigIntegerLiteral represents an integer literal too large for 64 bits
+type BigIntegerLiteral struct {
+	Token token.Token
+	Value *big.Int
+}
+
+func (bl *BigIntegerLiteral) expressionNode()      {}
+func (bl *BigIntegerLiteral) TokenLiteral() string { return bl.Token.Literal }
+func (bl *BigIntegerLiteral) String() string       { return bl.Token.Literal }
+
+// FloatLiteral represents a floating-point literal such as 1.5 or 1e9
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
+// DecimalLiteral represents an exact decimal literal such as ১৯.৯৯দ
+type DecimalLiteral struct {
+	Token token.Token
+	Value *big.Rat
+}
+
+func (dl *DecimalLiteral) expressionNode()      {}
+func (dl *DecimalLiteral) TokenLiteral() string { return dl.Token.Literal }
+func (dl *DecimalLiteral) String() string       { return dl.Token.Literal + "d" }
+
+// SliceExpression represents a slice such as তালিকা[1:3]; Start and End
+// are nil when omitted
+type SliceExpression struct {
+	Token token.Token // The [ token
+	Left  Expression
+	Start Expression
+	End   Expression
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	out.WriteString("])")
+	return out.String()
+}
+
 // StringLiteral represents a string literal
 type StringLiteral struct {
 	Token token.Token
@@ -206,6 +303,29 @@ func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 
+// CharLiteral represents a character literal such as 'ক'
+type CharLiteral struct {
+	Token token.Token
+	Value rune
+}
+
+func (cl *CharLiteral) expressionNode()      {}
+func (cl *CharLiteral) TokenLiteral() string { return cl.Token.Literal }
+func (cl *CharLiteral) String() string       { return "'" + string(cl.Value) + "'" }
+
+// InterpolatedString is a string literal with ${expression} parts, such as
+// "মোট ${ক + খ}". Parts alternates between the text around the holes, as
+// StringLiterals, and the expressions inside them. The desugar pass turns
+// it into a concatenation before it is compiled.
+type InterpolatedString struct {
+	Token token.Token // the whole string
+	Parts []Expression
+}
+
+func (is *InterpolatedString) expressionNode()      {}
+func (is *InterpolatedString) TokenLiteral() string { return is.Token.Literal }
+func (is *InterpolatedString) String() string       { return is.Token.Literal }
+
 // Boolean represents a boolean value
 type Boolean struct {
 	Token token.Token
@@ -282,25 +402,66 @@ type FunctionLiteral struct {
 	Token          token.Token       // The ফাংশন token
 	Parameters     []*Identifier     // Parameter names (for backward compatibility)
 	ParameterTypes []*TypeAnnotation // Optional parameter type annotations (parallel to Parameters)
+	Defaults       []Expression      // Default values, nil for required parameters (parallel to Parameters)
+	Rest           bool              // The last parameter collects any extra arguments (...name)
 	ReturnType     *TypeAnnotation   // Optional return type annotation
 	Body           *BlockStatement
+	Doc            string // Text of the /// comment above the function
+	Arrow          bool   // Written as (x) => ..., with Token the => token
+}
+
+// ArrowExpression returns the expression an arrow function was written
+// with, as in (x) => x * ২, or nil when it has a block body. Its body is
+// then a single return whose token is the arrow.
+func (fl *FunctionLiteral) ArrowExpression() Expression {
+	if !fl.Arrow || fl.Body == nil || len(fl.Body.Statements) != 1 {
+		return nil
+	}
+	ret, ok := fl.Body.Statements[0].(*ReturnStatement)
+	if !ok || ret.Token.Type != token.ARROW {
+		return nil
+	}
+	return ret.ReturnValue
+}
+
+// FormatParameters writes a parameter list with its type annotations and
+// default values
+func FormatParameters(params []*Identifier, types []*TypeAnnotation, defaults []Expression, rest bool) string {
+	out := []string{}
+	for i, p := range params {
+		paramStr := p.String()
+		if rest && i == len(params)-1 {
+			paramStr = "..." + paramStr
+		}
+		if i < len(types) && types[i] != nil {
+			paramStr += ": " + types[i].String()
+		}
+		if i < len(defaults) && defaults[i] != nil {
+			paramStr += " = " + defaults[i].String()
+		}
+		out = append(out, paramStr)
+	}
+	return strings.Join(out, ", ")
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
-	params := []string{}
-	for i, p := range fl.Parameters {
-		paramStr := p.String()
-		if fl.ParameterTypes != nil && i < len(fl.ParameterTypes) && fl.ParameterTypes[i] != nil {
-			paramStr += ": " + fl.ParameterTypes[i].String()
+	if fl.Arrow {
+		out.WriteString("(")
+		out.WriteString(FormatParameters(fl.Parameters, fl.ParameterTypes, fl.Defaults, fl.Rest))
+		out.WriteString(") => ")
+		if expr := fl.ArrowExpression(); expr != nil {
+			out.WriteString(expr.String())
+		} else {
+			out.WriteString(fl.Body.String())
 		}
-		params = append(params, paramStr)
+		return out.String()
 	}
 	out.WriteString(fl.TokenLiteral())
 	out.WriteString("(")
-	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(FormatParameters(fl.Parameters, fl.ParameterTypes, fl.Defaults, fl.Rest))
 	out.WriteString(")")
 	if fl.ReturnType != nil {
 		out.WriteString(": ")
@@ -372,6 +533,25 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+// TupleLiteral builds a tuple, as in (a, b) or ফেরত a, b
+type TupleLiteral struct {
+	Token    token.Token // the ( token, or the first element's after ফেরত
+	Elements []Expression
+}
+
+func (tl *TupleLiteral) expressionNode()      {}
+func (tl *TupleLiteral) TokenLiteral() string { return tl.Token.Literal }
+func (tl *TupleLiteral) String() string {
+	elements := []string{}
+	for _, el := range tl.Elements {
+		elements = append(elements, el.String())
+	}
+	if len(elements) == 1 {
+		return "(" + elements[0] + ",)"
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
 // HashLiteral represents a hash map literal
 type HashLiteral struct {
 	Token token.Token // the { token
@@ -383,8 +563,8 @@ func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, key := range hl.SortedKeys() {
+		pairs = append(pairs, key.String()+":"+hl.Pairs[key].String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
@@ -392,6 +572,26 @@ func (hl *HashLiteral) String() string {
 	return out.String()
 }
 
+// SortedKeys returns the keys in source order, falling back to their text
+// for keys at the same position
+func (hl *HashLiteral) SortedKeys() []Expression {
+	keys := make([]Expression, 0, len(hl.Pairs))
+	for key := range hl.Pairs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := NodeToken(keys[i]), NodeToken(keys[j])
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
 // ForStatement represents a for loop (পর্যন্ত)
 type ForStatement struct {
 	Token       token.Token // the পর্যন্ত token
@@ -422,6 +622,30 @@ func (fs *ForStatement) String() string {
 	return out.String()
 }
 
+// ForEachStatement represents a loop over the elements of an array or the
+// characters of a string: প্রতিটি (x মধ্যে তালিকা) { ... }. The desugar
+// pass turns it into a ForStatement before it is compiled.
+type ForEachStatement struct {
+	Token    token.Token // the প্রতিটি token
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fe *ForEachStatement) statementNode()       {}
+func (fe *ForEachStatement) TokenLiteral() string { return fe.Token.Literal }
+func (fe *ForEachStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(fe.TokenLiteral())
+	out.WriteString(" (")
+	out.WriteString(fe.Variable.String())
+	out.WriteString(" মধ্যে ")
+	out.WriteString(fe.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(fe.Body.String())
+	return out.String()
+}
+
 // BreakStatement represents a break statement (বিরতি)
 type BreakStatement struct {
 	Token token.Token // the বিরতি token
@@ -559,9 +783,10 @@ func (sl *StructLiteral) String() string {
 // MemberAccessExpression represents accessing a struct field
 // Example: person.নাম
 type MemberAccessExpression struct {
-	Token  token.Token // the . token
-	Object Expression  // the struct instance
-	Member *Identifier // the field name
+	Token    token.Token // the . or ?. token
+	Object   Expression  // the struct instance
+	Member   *Identifier // the field name
+	Optional bool        // written ?., giving null when Object is null
 }
 
 func (mae *MemberAccessExpression) expressionNode()      {}
@@ -570,7 +795,11 @@ func (mae *MemberAccessExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
 	out.WriteString(mae.Object.String())
-	out.WriteString(".")
+	if mae.Optional {
+		out.WriteString("?.")
+	} else {
+		out.WriteString(".")
+	}
 	out.WriteString(mae.Member.String())
 	out.WriteString(")")
 	return out.String()
@@ -600,8 +829,58 @@ func (mas *MemberAssignmentStatement) String() string {
 	return out.String()
 }
 
+// IndexAssignmentStatement represents assigning to an element of a mutable
+// array or byte array
+// Example: সংখ্যা[0] = 10
+type IndexAssignmentStatement struct {
+	Token token.Token // the [ token
+	Left  Expression  // the collection
+	Index Expression
+	Value Expression // the new value
+}
+
+func (ias *IndexAssignmentStatement) statementNode()       {}
+func (ias *IndexAssignmentStatement) TokenLiteral() string { return ias.Token.Literal }
+func (ias *IndexAssignmentStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(ias.Left.String())
+	out.WriteString("[")
+	out.WriteString(ias.Index.String())
+	out.WriteString("] = ")
+	if ias.Value != nil {
+		out.WriteString(ias.Value.String())
+	}
+	out.WriteString(";")
+	return out.String()
+}
+
+// CompoundAssignmentStatement represents x += value and its -=, *=, /= and
+// %= forms. Target is an Identifier, IndexExpression or
+// MemberAccessExpression. The desugar pass turns it into a plain
+// assignment before it is compiled.
+type CompoundAssignmentStatement struct {
+	Token    token.Token // the operator token, e.g. +=
+	Target   Expression
+	Operator string // the arithmetic operator, e.g. +
+	Value    Expression
+}
+
+func (cas *CompoundAssignmentStatement) statementNode()       {}
+func (cas *CompoundAssignmentStatement) TokenLiteral() string { return cas.Token.Literal }
+func (cas *CompoundAssignmentStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(cas.Target.String())
+	out.WriteString(" " + cas.Operator + "= ")
+	if cas.Value != nil {
+		out.WriteString(cas.Value.String())
+	}
+	out.WriteString(";")
+	return out.String()
+}
+
 // EnumVariant represents a single variant in an enum definition
 type EnumVariant struct {
+	Token token.Token // the variant name token
 	Name  string
 	Value *int // optional explicit value
 }
@@ -665,11 +944,13 @@ const (
 
 // ClassField represents a field in a class definition
 type ClassField struct {
-	Name       string
-	TypeAnnot  *TypeAnnotation
-	Access     AccessModifier
-	IsStatic   bool // স্থির (static)
-	IsFinal    bool // চূড়ান্ত (final)
+	Token     token.Token // the field name token
+	Name      string
+	TypeAnnot *TypeAnnotation
+	Access    AccessModifier
+	IsStatic  bool       // স্থির (static)
+	IsFinal   bool       // চূড়ান্ত (final)
+	Value     Expression // initial value of a static field, or nil
 }
 
 // MethodDefinition represents a method in a class
@@ -684,8 +965,11 @@ type MethodDefinition struct {
 	IsOverride     bool              // পুনর্সংজ্ঞা (overrides parent method)
 	Parameters     []*Identifier     // parameter names
 	ParameterTypes []*TypeAnnotation // parameter types
+	Defaults       []Expression      // default values, nil for required parameters
+	Rest           bool              // the last parameter collects any extra arguments
 	ReturnType     *TypeAnnotation   // return type
 	Body           *BlockStatement   // method body (nil for abstract)
+	Doc            string            // text of the /// comment above the method
 }
 
 func (md *MethodDefinition) statementNode()       {}
@@ -713,15 +997,7 @@ func (md *MethodDefinition) String() string {
 	out.WriteString(md.Name.String())
 	out.WriteString("(")
 
-	params := []string{}
-	for i, p := range md.Parameters {
-		paramStr := p.String()
-		if md.ParameterTypes != nil && i < len(md.ParameterTypes) && md.ParameterTypes[i] != nil {
-			paramStr += ": " + md.ParameterTypes[i].String()
-		}
-		params = append(params, paramStr)
-	}
-	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(FormatParameters(md.Parameters, md.ParameterTypes, md.Defaults, md.Rest))
 	out.WriteString(")")
 
 	if md.ReturnType != nil {
@@ -743,7 +1019,10 @@ type ConstructorDefinition struct {
 	Access         AccessModifier    // access level
 	Parameters     []*Identifier     // parameter names
 	ParameterTypes []*TypeAnnotation // parameter types
+	Defaults       []Expression      // default values, nil for required parameters
+	Rest           bool              // the last parameter collects any extra arguments
 	Body           *BlockStatement   // constructor body
+	Doc            string            // text of the /// comment above the constructor
 }
 
 func (cd *ConstructorDefinition) statementNode()       {}
@@ -757,15 +1036,7 @@ func (cd *ConstructorDefinition) String() string {
 
 	out.WriteString("নির্মাতা(")
 
-	params := []string{}
-	for i, p := range cd.Parameters {
-		paramStr := p.String()
-		if cd.ParameterTypes != nil && i < len(cd.ParameterTypes) && cd.ParameterTypes[i] != nil {
-			paramStr += ": " + cd.ParameterTypes[i].String()
-		}
-		params = append(params, paramStr)
-	}
-	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(FormatParameters(cd.Parameters, cd.ParameterTypes, cd.Defaults, cd.Rest))
 	out.WriteString(") ")
 
 	if cd.Body != nil {
@@ -787,6 +1058,7 @@ type ClassDefinition struct {
 	Fields       []*ClassField           // class fields
 	Constructors []*ConstructorDefinition // constructors
 	Methods      []*MethodDefinition     // methods
+	Doc          string                   // text of the /// comment above the class
 }
 
 func (cd *ClassDefinition) statementNode()       {}
@@ -0,0 +1,31 @@
+package ast
+
+import (
+	"bhasa/token"
+	"reflect"
+)
+
+// NodeToken returns the token stored in a node's Token field. Almost every
+// node keeps the token it starts at (or its operator token) there; nodes
+// without one yield the zero token.
+func NodeToken(node Node) token.Token {
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return token.Token{}
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return token.Token{}
+	}
+	f := v.FieldByName("Token")
+	if !f.IsValid() {
+		return token.Token{}
+	}
+	tok, _ := f.Interface().(token.Token)
+	return tok
+}
+
+// Line returns the source line of a node, or 0 when unknown
+func Line(node Node) int {
+	return NodeToken(node).Line
+}
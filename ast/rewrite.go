@@ -0,0 +1,309 @@
+package ast
+
+import "fmt"
+
+// Rewrite transforms a tree bottom-up: the children of each node are
+// rewritten first, then f is called with the node and its result takes the
+// node's place. f returns its argument to keep a node as it is. Nodes are
+// changed in place, so rewrite a tree only when nothing else still needs
+// its original form.
+//
+// A replacement has to fit where the node was - a statement for a
+// statement, an expression for an expression, a *BlockStatement for a
+// function body - and Rewrite panics when it does not. Children are
+// visited in the same order as Walk.
+func Rewrite(node Node, f func(Node) Node) Node {
+	return rewriter(f).node(node)
+}
+
+// rewriter applies a Rewrite function to one node after its children
+type rewriter func(Node) Node
+
+func (r rewriter) node(node Node) Node {
+	if isNilNode(node) {
+		return node
+	}
+
+	switch n := node.(type) {
+	// Statements
+	case *Program:
+		r.statements(n.Statements)
+	case *LetStatement:
+		n.Name = r.ident(n.Name)
+		n.TypeAnnot = r.typ(n.TypeAnnot)
+		n.Value = r.expr(n.Value)
+	case *DestructuringLetStatement:
+		for i, name := range n.Names {
+			n.Names[i] = r.ident(name)
+		}
+		n.Value = r.expr(n.Value)
+	case *ReturnStatement:
+		n.ReturnValue = r.expr(n.ReturnValue)
+	case *ExpressionStatement:
+		n.Expression = r.expr(n.Expression)
+	case *AssignmentStatement:
+		n.Name = r.ident(n.Name)
+		n.Value = r.expr(n.Value)
+	case *ImportStatement:
+		n.Path = r.expr(n.Path)
+	case *BlockStatement:
+		r.statements(n.Statements)
+	case *WhileStatement:
+		n.Condition = r.expr(n.Condition)
+		n.Body = r.block(n.Body)
+	case *ForStatement:
+		n.Init = r.stmt(n.Init)
+		n.Condition = r.expr(n.Condition)
+		n.Increment = r.stmt(n.Increment)
+		n.Body = r.block(n.Body)
+	case *BreakStatement, *ContinueStatement:
+		// no children
+	case *MemberAssignmentStatement:
+		n.Object = r.expr(n.Object)
+		n.Member = r.ident(n.Member)
+		n.Value = r.expr(n.Value)
+	case *IndexAssignmentStatement:
+		n.Left = r.expr(n.Left)
+		n.Index = r.expr(n.Index)
+		n.Value = r.expr(n.Value)
+	case *CompoundAssignmentStatement:
+		n.Target = r.expr(n.Target)
+		n.Value = r.expr(n.Value)
+	case *ForEachStatement:
+		n.Variable = r.ident(n.Variable)
+		n.Iterable = r.expr(n.Iterable)
+		n.Body = r.block(n.Body)
+
+	// Literals and leaves
+	case *Identifier, *BuiltinReference, *IntegerLiteral, *BigIntegerLiteral, *FloatLiteral,
+		*DecimalLiteral, *StringLiteral, *CharLiteral, *Boolean, *ThisExpression, *SuperExpression:
+		// no children
+	case *InterpolatedString:
+		r.expressions(n.Parts)
+	case *ArrayLiteral:
+		r.expressions(n.Elements)
+	case *TupleLiteral:
+		r.expressions(n.Elements)
+	case *HashLiteral:
+		pairs := make(map[Expression]Expression, len(n.Pairs))
+		for _, key := range n.SortedKeys() {
+			value := n.Pairs[key]
+			pairs[r.expr(key)] = r.expr(value)
+		}
+		n.Pairs = pairs
+	case *FunctionLiteral:
+		r.parameters(n.Parameters, n.ParameterTypes, n.Defaults)
+		n.ReturnType = r.typ(n.ReturnType)
+		n.Body = r.block(n.Body)
+
+	// Expressions
+	case *PrefixExpression:
+		n.Right = r.expr(n.Right)
+	case *InfixExpression:
+		n.Left = r.expr(n.Left)
+		n.Right = r.expr(n.Right)
+	case *IfExpression:
+		n.Condition = r.expr(n.Condition)
+		n.Consequence = r.block(n.Consequence)
+		n.Alternative = r.block(n.Alternative)
+	case *CallExpression:
+		n.Function = r.expr(n.Function)
+		r.expressions(n.Arguments)
+	case *IndexExpression:
+		n.Left = r.expr(n.Left)
+		n.Index = r.expr(n.Index)
+	case *SliceExpression:
+		n.Left = r.expr(n.Left)
+		n.Start = r.expr(n.Start)
+		n.End = r.expr(n.End)
+	case *MemberAccessExpression:
+		n.Object = r.expr(n.Object)
+		n.Member = r.ident(n.Member)
+	case *MethodCallExpression:
+		n.Object = r.expr(n.Object)
+		n.MethodName = r.ident(n.MethodName)
+		r.expressions(n.Arguments)
+	case *NewExpression:
+		n.ClassName = r.ident(n.ClassName)
+		r.expressions(n.Arguments)
+
+	// Types
+	case *TypeAnnotation:
+		n.KeyType = r.typ(n.KeyType)
+		n.ElementType = r.typ(n.ElementType)
+	case *TypedIdentifier:
+		n.TypeAnnot = r.typ(n.TypeAnnot)
+	case *TypeCastExpression:
+		n.Expression = r.expr(n.Expression)
+		n.TargetType = r.typ(n.TargetType)
+
+	// Structs and enums
+	case *StructDefinition:
+		n.Name = r.ident(n.Name)
+		for _, field := range n.Fields {
+			field.TypeAnnot = r.typ(field.TypeAnnot)
+		}
+	case *StructLiteral:
+		n.StructType = r.ident(n.StructType)
+		for _, name := range n.FieldOrder {
+			n.Fields[name] = r.expr(n.Fields[name])
+		}
+	case *EnumDefinition:
+		n.Name = r.ident(n.Name)
+	case *EnumValue:
+		n.EnumType = r.ident(n.EnumType)
+		n.VariantName = r.ident(n.VariantName)
+
+	// Classes and interfaces
+	case *ClassDefinition:
+		n.Name = r.ident(n.Name)
+		n.SuperClass = r.ident(n.SuperClass)
+		for i, iface := range n.Interfaces {
+			n.Interfaces[i] = r.ident(iface)
+		}
+		for _, field := range n.Fields {
+			field.TypeAnnot = r.typ(field.TypeAnnot)
+			field.Value = r.expr(field.Value)
+		}
+		for i, ctor := range n.Constructors {
+			n.Constructors[i] = r.constructor(ctor)
+		}
+		for i, method := range n.Methods {
+			n.Methods[i] = r.method(method)
+		}
+	case *ConstructorDefinition:
+		r.parameters(n.Parameters, n.ParameterTypes, n.Defaults)
+		n.Body = r.block(n.Body)
+	case *MethodDefinition:
+		n.Name = r.ident(n.Name)
+		r.parameters(n.Parameters, n.ParameterTypes, n.Defaults)
+		n.ReturnType = r.typ(n.ReturnType)
+		n.Body = r.block(n.Body)
+	case *InterfaceDefinition:
+		n.Name = r.ident(n.Name)
+		for _, method := range n.Methods {
+			method.Name = r.ident(method.Name)
+			r.parameters(method.Parameters, method.ParameterTypes, nil)
+			method.ReturnType = r.typ(method.ReturnType)
+		}
+
+	default:
+		panic(fmt.Sprintf("ast.Rewrite: unexpected node type %T", n))
+	}
+
+	return r(node)
+}
+
+// misfit reports a replacement that cannot take the place of the node
+func misfit(node, replacement Node) string {
+	return fmt.Sprintf("ast.Rewrite: cannot replace %T with %T", node, replacement)
+}
+
+func (r rewriter) stmt(s Statement) Statement {
+	if isNilNode(s) {
+		return s
+	}
+	n := r.node(s)
+	out, ok := n.(Statement)
+	if !ok {
+		panic(misfit(s, n))
+	}
+	return out
+}
+
+func (r rewriter) expr(e Expression) Expression {
+	if isNilNode(e) {
+		return e
+	}
+	n := r.node(e)
+	out, ok := n.(Expression)
+	if !ok {
+		panic(misfit(e, n))
+	}
+	return out
+}
+
+func (r rewriter) block(b *BlockStatement) *BlockStatement {
+	if b == nil {
+		return nil
+	}
+	n := r.node(b)
+	out, ok := n.(*BlockStatement)
+	if !ok {
+		panic(misfit(b, n))
+	}
+	return out
+}
+
+func (r rewriter) ident(i *Identifier) *Identifier {
+	if i == nil {
+		return nil
+	}
+	n := r.node(i)
+	out, ok := n.(*Identifier)
+	if !ok {
+		panic(misfit(i, n))
+	}
+	return out
+}
+
+func (r rewriter) typ(t *TypeAnnotation) *TypeAnnotation {
+	if t == nil {
+		return nil
+	}
+	n := r.node(t)
+	out, ok := n.(*TypeAnnotation)
+	if !ok {
+		panic(misfit(t, n))
+	}
+	return out
+}
+
+func (r rewriter) constructor(c *ConstructorDefinition) *ConstructorDefinition {
+	if c == nil {
+		return nil
+	}
+	n := r.node(c)
+	out, ok := n.(*ConstructorDefinition)
+	if !ok {
+		panic(misfit(c, n))
+	}
+	return out
+}
+
+func (r rewriter) method(m *MethodDefinition) *MethodDefinition {
+	if m == nil {
+		return nil
+	}
+	n := r.node(m)
+	out, ok := n.(*MethodDefinition)
+	if !ok {
+		panic(misfit(m, n))
+	}
+	return out
+}
+
+func (r rewriter) statements(list []Statement) {
+	for i, s := range list {
+		list[i] = r.stmt(s)
+	}
+}
+
+func (r rewriter) expressions(list []Expression) {
+	for i, e := range list {
+		list[i] = r.expr(e)
+	}
+}
+
+// parameters rewrites parameter names, types and defaults in place
+func (r rewriter) parameters(params []*Identifier, types []*TypeAnnotation, defaults []Expression) {
+	for i, param := range params {
+		params[i] = r.ident(param)
+		if i < len(types) {
+			types[i] = r.typ(types[i])
+		}
+		if i < len(defaults) {
+			defaults[i] = r.expr(defaults[i])
+		}
+	}
+}
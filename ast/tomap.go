@@ -0,0 +1,118 @@
+package ast
+
+import (
+	"bhasa/token"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ToMap converts a node into plain maps, slices and scalars that can be
+// marshalled to JSON or turned into Bhasa values. Every node becomes a map
+// with a "kind" entry naming the node type, "line"/"column" entries taken
+// from its token, and one entry per exported field, keyed by the field's
+// name in lower camel case ("statements", "typeAnnot").
+func ToMap(node Node) interface{} {
+	return toGeneric(reflect.ValueOf(node))
+}
+
+func toGeneric(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if b, ok := v.Interface().(*big.Int); ok {
+			return b.String()
+		}
+		if r, ok := v.Interface().(*big.Rat); ok {
+			return r.RatString()
+		}
+		return toGeneric(v.Elem())
+	case reflect.Struct:
+		if tok, ok := v.Interface().(token.Token); ok {
+			return tok.Literal
+		}
+		return structToMap(v)
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = toGeneric(v.Index(i))
+		}
+		return list
+	case reflect.Map:
+		return mapToGeneric(v)
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		return v.Bool()
+	default:
+		return nil
+	}
+}
+
+func structToMap(v reflect.Value) map[string]interface{} {
+	m := map[string]interface{}{"kind": v.Type().Name()}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Name == "Token" {
+			if tok, ok := v.Field(i).Interface().(token.Token); ok {
+				m["line"] = int64(tok.Line)
+				m["column"] = int64(tok.Column)
+				continue
+			}
+		}
+		m[lowerCamel(field.Name)] = toGeneric(v.Field(i))
+	}
+	return m
+}
+
+// lowerCamel lowers the first letter of a Go field name
+func lowerCamel(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// mapToGeneric keeps string-keyed maps as maps and turns maps keyed by
+// nodes (hash literals) into a list of key/value pairs in source order
+func mapToGeneric(v reflect.Value) interface{} {
+	if v.Type().Key().Kind() == reflect.String {
+		m := make(map[string]interface{}, v.Len())
+		for _, key := range v.MapKeys() {
+			m[key.String()] = toGeneric(v.MapIndex(key))
+		}
+		return m
+	}
+
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return mapKeyOrder(keys[i]) < mapKeyOrder(keys[j])
+	})
+	pairs := make([]interface{}, len(keys))
+	for i, key := range keys {
+		pairs[i] = map[string]interface{}{
+			"key":   toGeneric(key),
+			"value": toGeneric(v.MapIndex(key)),
+		}
+	}
+	return pairs
+}
+
+// mapKeyOrder sorts node keys by source position, then by text
+func mapKeyOrder(key reflect.Value) string {
+	if node, ok := key.Interface().(Node); ok {
+		tok := NodeToken(node)
+		return fmt.Sprintf("%08d:%08d:%s", tok.Line, tok.Column, node.String())
+	}
+	return fmt.Sprint(key.Interface())
+}
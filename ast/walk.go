@@ -0,0 +1,251 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Visitor is called by Walk for every node in a tree. If Visit returns a
+// non-nil visitor w, Walk visits each child of node with w and then calls
+// w.Visit(nil); returning nil skips the node's children.
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses a tree in depth-first order, children in source order.
+// Optional children that are absent (a missing else branch, an untyped
+// parameter, a parameter without a default) are skipped, so Visit never
+// sees a nil node except as the end-of-children marker. Nodes nested in
+// field, variant and signature records, such as a class field's type or
+// initial value, are visited as children of the definition holding them.
+func Walk(v Visitor, node Node) {
+	if isNilNode(node) {
+		return
+	}
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	// Statements
+	case *Program:
+		walkStatements(v, n.Statements)
+	case *LetStatement:
+		Walk(v, n.Name)
+		Walk(v, n.TypeAnnot)
+		Walk(v, n.Value)
+	case *DestructuringLetStatement:
+		for _, name := range n.Names {
+			Walk(v, name)
+		}
+		Walk(v, n.Value)
+	case *ReturnStatement:
+		Walk(v, n.ReturnValue)
+	case *ExpressionStatement:
+		Walk(v, n.Expression)
+	case *AssignmentStatement:
+		Walk(v, n.Name)
+		Walk(v, n.Value)
+	case *ImportStatement:
+		Walk(v, n.Path)
+	case *BlockStatement:
+		walkStatements(v, n.Statements)
+	case *WhileStatement:
+		Walk(v, n.Condition)
+		Walk(v, n.Body)
+	case *ForStatement:
+		Walk(v, n.Init)
+		Walk(v, n.Condition)
+		Walk(v, n.Increment)
+		Walk(v, n.Body)
+	case *BreakStatement, *ContinueStatement:
+		// no children
+	case *MemberAssignmentStatement:
+		Walk(v, n.Object)
+		Walk(v, n.Member)
+		Walk(v, n.Value)
+	case *IndexAssignmentStatement:
+		Walk(v, n.Left)
+		Walk(v, n.Index)
+		Walk(v, n.Value)
+	case *CompoundAssignmentStatement:
+		Walk(v, n.Target)
+		Walk(v, n.Value)
+	case *ForEachStatement:
+		Walk(v, n.Variable)
+		Walk(v, n.Iterable)
+		Walk(v, n.Body)
+
+	// Literals and leaves
+	case *Identifier, *BuiltinReference, *IntegerLiteral, *BigIntegerLiteral, *FloatLiteral,
+		*DecimalLiteral, *StringLiteral, *CharLiteral, *Boolean, *ThisExpression, *SuperExpression:
+		// no children
+	case *InterpolatedString:
+		walkExpressions(v, n.Parts)
+	case *ArrayLiteral:
+		walkExpressions(v, n.Elements)
+	case *TupleLiteral:
+		walkExpressions(v, n.Elements)
+	case *HashLiteral:
+		for _, key := range n.SortedKeys() {
+			Walk(v, key)
+			Walk(v, n.Pairs[key])
+		}
+	case *FunctionLiteral:
+		walkParameters(v, n.Parameters, n.ParameterTypes, n.Defaults)
+		Walk(v, n.ReturnType)
+		Walk(v, n.Body)
+
+	// Expressions
+	case *PrefixExpression:
+		Walk(v, n.Right)
+	case *InfixExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case *IfExpression:
+		Walk(v, n.Condition)
+		Walk(v, n.Consequence)
+		Walk(v, n.Alternative)
+	case *CallExpression:
+		Walk(v, n.Function)
+		walkExpressions(v, n.Arguments)
+	case *IndexExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Index)
+	case *SliceExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Start)
+		Walk(v, n.End)
+	case *MemberAccessExpression:
+		Walk(v, n.Object)
+		Walk(v, n.Member)
+	case *MethodCallExpression:
+		Walk(v, n.Object)
+		Walk(v, n.MethodName)
+		walkExpressions(v, n.Arguments)
+	case *NewExpression:
+		Walk(v, n.ClassName)
+		walkExpressions(v, n.Arguments)
+
+	// Types
+	case *TypeAnnotation:
+		Walk(v, n.KeyType)
+		Walk(v, n.ElementType)
+	case *TypedIdentifier:
+		Walk(v, n.TypeAnnot)
+	case *TypeCastExpression:
+		Walk(v, n.Expression)
+		Walk(v, n.TargetType)
+
+	// Structs and enums
+	case *StructDefinition:
+		Walk(v, n.Name)
+		for _, field := range n.Fields {
+			Walk(v, field.TypeAnnot)
+		}
+	case *StructLiteral:
+		Walk(v, n.StructType)
+		for _, name := range n.FieldOrder {
+			Walk(v, n.Fields[name])
+		}
+	case *EnumDefinition:
+		Walk(v, n.Name)
+	case *EnumValue:
+		Walk(v, n.EnumType)
+		Walk(v, n.VariantName)
+
+	// Classes and interfaces
+	case *ClassDefinition:
+		Walk(v, n.Name)
+		Walk(v, n.SuperClass)
+		for _, iface := range n.Interfaces {
+			Walk(v, iface)
+		}
+		for _, field := range n.Fields {
+			Walk(v, field.TypeAnnot)
+			Walk(v, field.Value)
+		}
+		for _, ctor := range n.Constructors {
+			Walk(v, ctor)
+		}
+		for _, method := range n.Methods {
+			Walk(v, method)
+		}
+	case *ConstructorDefinition:
+		walkParameters(v, n.Parameters, n.ParameterTypes, n.Defaults)
+		Walk(v, n.Body)
+	case *MethodDefinition:
+		Walk(v, n.Name)
+		walkParameters(v, n.Parameters, n.ParameterTypes, n.Defaults)
+		Walk(v, n.ReturnType)
+		Walk(v, n.Body)
+	case *InterfaceDefinition:
+		Walk(v, n.Name)
+		for _, method := range n.Methods {
+			Walk(v, method.Name)
+			walkParameters(v, method.Parameters, method.ParameterTypes, nil)
+			Walk(v, method.ReturnType)
+		}
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+// walkStatements visits a statement list in order
+func walkStatements(v Visitor, list []Statement) {
+	for _, stmt := range list {
+		Walk(v, stmt)
+	}
+}
+
+// walkExpressions visits an expression list in order
+func walkExpressions(v Visitor, list []Expression) {
+	for _, expr := range list {
+		Walk(v, expr)
+	}
+}
+
+// walkParameters visits each parameter's name, then its type and default
+// value when it has them; types and defaults are parallel to params and
+// may be shorter
+func walkParameters(v Visitor, params []*Identifier, types []*TypeAnnotation, defaults []Expression) {
+	for i, param := range params {
+		Walk(v, param)
+		if i < len(types) {
+			Walk(v, types[i])
+		}
+		if i < len(defaults) {
+			Walk(v, defaults[i])
+		}
+	}
+}
+
+// isNilNode reports whether node is nil, including a nil pointer stored in
+// the interface, which is how absent optional children usually arrive
+func isNilNode(node Node) bool {
+	if node == nil {
+		return true
+	}
+	v := reflect.ValueOf(node)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// inspector adapts a function to the Visitor interface
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses a tree in the same order as Walk, calling f for every
+// node. If f returns true, Inspect visits the node's children and then
+// calls f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
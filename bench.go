@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bhasa/code"
+	"bhasa/compiler"
+	"bhasa/vm"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// benchRun runs a program once in a fresh VM whose output is thrown away,
+// returning the VM and how long the run took
+func benchRun(bytecode *compiler.Bytecode, opts runOptions, profile bool) (*vm.VM, time.Duration, error) {
+	machine := vm.New(bytecode)
+	machine.SetStdout(io.Discard)
+	if opts.arena {
+		machine.EnableArena()
+	}
+	if profile {
+		machine.EnableProfile()
+		machine.CountAllocations()
+	}
+	start := time.Now()
+	err := machine.Run()
+	return machine, time.Since(start), err
+}
+
+func cmdBench(args []string) int {
+	fs := newFlagSet("bench")
+	count := fs.Int("count", 5, "Timed runs of the program")
+	arena := fs.Bool("arena", false, "Allocate arithmetic results from an arena")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) != 1 || *count < 1 {
+		fs.Usage()
+		return 2
+	}
+
+	bytecode, err := loadBytecode(files[0])
+	if err != nil {
+		return fail(err)
+	}
+	opts := runOptions{arena: *arena}
+
+	var total time.Duration
+	best, worst := time.Duration(math.MaxInt64), time.Duration(0)
+	for i := 0; i < *count; i++ {
+		_, elapsed, err := benchRun(bytecode, opts, false)
+		if err != nil {
+			return fail(fmt.Errorf("Executing bytecode failed:\n %s", err))
+		}
+		total += elapsed
+		if elapsed < best {
+			best = elapsed
+		}
+		if elapsed > worst {
+			worst = elapsed
+		}
+	}
+
+	// Timing every instruction slows the program down, so the counts of
+	// instructions and allocations come from one more run of their own
+	machine, _, err := benchRun(bytecode, opts, true)
+	if err != nil {
+		return fail(fmt.Errorf("Executing bytecode failed:\n %s", err))
+	}
+	profile := machine.Profile()
+	instructions := profile.Total()
+
+	fmt.Printf("runs:          %d\n", *count)
+	fmt.Printf("best:          %s\n", best.Round(time.Microsecond))
+	fmt.Printf("mean:          %s\n", (total / time.Duration(*count)).Round(time.Microsecond))
+	fmt.Printf("worst:         %s\n", worst.Round(time.Microsecond))
+	fmt.Printf("instructions:  %d per run, %.1fM/s in the best run\n", instructions, float64(instructions)/best.Seconds()/1e6)
+	if allocs := machine.Allocations(); allocs.Total() > 0 {
+		fmt.Println()
+		writeAllocations(os.Stdout, allocs)
+	}
+	if instructions > 0 {
+		fmt.Println()
+		writeProfile(profile)
+	}
+	return 0
+}
+
+// histogramWidth is how many columns the longest bar of the histogram takes
+const histogramWidth = 30
+
+// writeProfile prints the opcodes a profiled run executed, the one it
+// spent the most time in first, with their counts, their share of the
+// instructions run and of the time, and a bar for the time
+func writeProfile(profile *vm.Profile) {
+	var ops []int
+	var spent, most time.Duration
+	for op, n := range profile.Counts {
+		if n == 0 {
+			continue
+		}
+		ops = append(ops, op)
+		spent += profile.Times[op]
+		if profile.Times[op] > most {
+			most = profile.Times[op]
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		a, b := ops[i], ops[j]
+		if profile.Times[a] != profile.Times[b] {
+			return profile.Times[a] > profile.Times[b]
+		}
+		return a < b
+	})
+
+	total := profile.Total()
+	fmt.Printf("%-22s %12s %7s %10s %7s %7s\n", "opcode", "count", "count%", "time", "time%", "ns/op")
+	for _, op := range ops {
+		name := fmt.Sprintf("opcode %d", op)
+		if def, err := code.Lookup(byte(op)); err == nil {
+			name = def.Name
+		}
+		n, t := profile.Counts[op], profile.Times[op]
+		bar := 0
+		if most > 0 {
+			bar = int(math.Round(float64(t) / float64(most) * histogramWidth))
+		}
+		fmt.Printf("%-22s %12d %6.2f%% %10s %6.2f%% %7.1f  %s\n",
+			name, n, float64(n)*100/float64(total),
+			t.Round(time.Microsecond), percent(t, spent), float64(t)/float64(n),
+			strings.Repeat("#", bar))
+	}
+}
+
+// percent is part as a percentage of whole
+func percent(part, whole time.Duration) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(whole)
+}
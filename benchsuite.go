@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bhasa/compiler"
+	"bhasa/lexer"
+	"bhasa/parser"
+	"bhasa/version"
+	"bhasa/vm"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// benchFiles are the programs run by bench-suite. Each one ends with an
+// expression whose value is reported, so a faster VM that computes the
+// wrong answer shows up in the table.
+//
+//go:embed bench/*.bhasa
+var benchFiles embed.FS
+
+// benchResult is the timing of one benchmark
+type benchResult struct {
+	Name   string  `json:"name"`
+	Result string  `json:"result"`
+	BestMs float64 `json:"bestMs"`
+	MeanMs float64 `json:"meanMs"`
+}
+
+// benchReport is what bench-suite writes with -json and reads back with
+// -baseline to compare two builds
+type benchReport struct {
+	Version string        `json:"version"`
+	Commit  string        `json:"commit"`
+	Results []benchResult `json:"results"`
+	Score   float64       `json:"score"`
+}
+
+// compileBenchmark compiles one embedded benchmark
+func compileBenchmark(name string) (*compiler.Bytecode, error) {
+	src, err := benchFiles.ReadFile(path.Join("bench", name))
+	if err != nil {
+		return nil, err
+	}
+	l := lexer.New(string(src))
+	l.SetFile(path.Join("bench", name))
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, parseErrors(p.Errors())
+	}
+	comp := compiler.New()
+	comp.SetFile(path.Join("bench", name))
+	if err := comp.Compile(program); err != nil {
+		return nil, fmt.Errorf("Compilation failed:\n %s", err)
+	}
+	return comp.Bytecode(), nil
+}
+
+// runBenchmark runs a compiled benchmark count times in fresh VMs
+func runBenchmark(name string, bytecode *compiler.Bytecode, count int, opts runOptions) (benchResult, error) {
+	result := benchResult{Name: strings.TrimSuffix(name, ".bhasa")}
+	var total time.Duration
+	best := time.Duration(math.MaxInt64)
+	for i := 0; i < count; i++ {
+		machine := vm.New(bytecode)
+		if opts.arena {
+			machine.EnableArena()
+		}
+		start := time.Now()
+		if err := machine.Run(); err != nil {
+			return result, fmt.Errorf("%s: %v", name, err)
+		}
+		elapsed := time.Since(start)
+		total += elapsed
+		if elapsed < best {
+			best = elapsed
+		}
+		if last := machine.LastPoppedStackElem(); last != nil {
+			result.Result = last.Inspect()
+		}
+	}
+	result.BestMs = float64(best) / float64(time.Millisecond)
+	result.MeanMs = float64(total) / float64(count) / float64(time.Millisecond)
+	return result, nil
+}
+
+// benchScore is the geometric mean of runs per second over all benchmarks,
+// so each benchmark weighs the same however long it takes
+func benchScore(results []benchResult) float64 {
+	if len(results) == 0 {
+		return 0
+	}
+	logSum := 0.0
+	for _, r := range results {
+		logSum += math.Log(1000 / r.BestMs)
+	}
+	return math.Exp(logSum / float64(len(results)))
+}
+
+// readBenchReport loads a report saved with -json
+func readBenchReport(filename string) (*benchReport, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var report benchReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return &report, nil
+}
+
+func cmdBenchSuite(args []string) int {
+	fs := newFlagSet("bench-suite")
+	count := fs.Int("count", 5, "Runs per benchmark; the best run is scored")
+	jsonFile := fs.String("json", "", "Also save the results as JSON to this file")
+	baselineFile := fs.String("baseline", "", "Compare with results saved by an earlier -json run")
+	arena := fs.Bool("arena", false, "Allocate arithmetic results from an arena")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 || *count < 1 {
+		fs.Usage()
+		return 2
+	}
+
+	baseline := map[string]benchResult{}
+	var baselineScore float64
+	if *baselineFile != "" {
+		report, err := readBenchReport(*baselineFile)
+		if err != nil {
+			return fail(err)
+		}
+		for _, r := range report.Results {
+			baseline[r.Name] = r
+		}
+		baselineScore = report.Score
+	}
+
+	entries, err := benchFiles.ReadDir("bench")
+	if err != nil {
+		return fail(err)
+	}
+	info := version.Get()
+	report := benchReport{Version: info.Version, Commit: info.Commit}
+
+	fmt.Printf("%-10s %10s %10s %10s %10s  %s\n", "benchmark", "best", "mean", "runs/s", "speedup", "result")
+	for _, entry := range entries {
+		bytecode, err := compileBenchmark(entry.Name())
+		if err != nil {
+			return fail(fmt.Errorf("%s: %v", entry.Name(), err))
+		}
+		r, err := runBenchmark(entry.Name(), bytecode, *count, runOptions{arena: *arena})
+		if err != nil {
+			return fail(err)
+		}
+		report.Results = append(report.Results, r)
+
+		speedup, result := "-", r.Result
+		if old, ok := baseline[r.Name]; ok {
+			speedup = fmt.Sprintf("%.2fx", old.BestMs/r.BestMs)
+			if old.Result != r.Result {
+				result += fmt.Sprintf(" (MISMATCH: baseline %s)", old.Result)
+			}
+		}
+		fmt.Printf("%-10s %8.1fms %8.1fms %10.2f %10s  %s\n", r.Name, r.BestMs, r.MeanMs, 1000/r.BestMs, speedup, result)
+	}
+
+	report.Score = benchScore(report.Results)
+	if baselineScore > 0 {
+		fmt.Printf("\nscore: %.2f (baseline %.2f, %.2fx)\n", report.Score, baselineScore, report.Score/baselineScore)
+	} else {
+		fmt.Printf("\nscore: %.2f\n", report.Score)
+	}
+
+	if *jsonFile != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fail(err)
+		}
+		if err := os.WriteFile(*jsonFile, append(data, '\n'), 0644); err != nil {
+			return fail(err)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureStderr runs fn with standard error redirected to a pipe and
+// returns what it wrote along with fn's result
+func captureStderr(t *testing.T, fn func() int) (string, int) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan string)
+	go func() {
+		var sb strings.Builder
+		io.Copy(&sb, r)
+		r.Close()
+		done <- sb.String()
+	}()
+
+	stderr := os.Stderr
+	os.Stderr = w
+	status := fn()
+	os.Stderr = stderr
+	w.Close()
+	return <-done, status
+}
+
+// TestCheckCommandsAgree runs bhasa check and bhasa --check on the same
+// files, which must be reported identically
+func TestCheckCommandsAgree(t *testing.T) {
+	sources := map[string]string{
+		"syntax":    "ধরি ক = ;\n",
+		"undefined": "লেখ(খ);\n",
+		"break":     "বিরতি;\n",
+		"endless":   "যতক্ষণ (সত্য) { লেখ(1); }\n",
+		"valid":     "ধরি ক = 1;\nলেখ(ক);\n",
+	}
+	wantStatus := map[string]int{"syntax": 1, "undefined": 1, "break": 1, "endless": 0, "valid": 0}
+
+	dir := t.TempDir()
+	for name, src := range sources {
+		file := filepath.Join(dir, name+".ভাষা")
+		if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		subcommand, subStatus := captureStderr(t, func() int { return cmdCheck([]string{file}) })
+		flag, flagStatus := captureStderr(t, func() int { return legacyMain([]string{"--check", file}) })
+
+		if subStatus != wantStatus[name] {
+			t.Errorf("%s: bhasa check exited with %d, want %d\n%s", name, subStatus, wantStatus[name], subcommand)
+		}
+		if flagStatus != subStatus {
+			t.Errorf("%s: bhasa --check exited with %d, bhasa check with %d", name, flagStatus, subStatus)
+		}
+		if flag != subcommand {
+			t.Errorf("%s: bhasa --check reported\n%s\nbhasa check reported\n%s", name, flag, subcommand)
+		}
+		if subStatus != 0 && subcommand == "" {
+			t.Errorf("%s: failed without a diagnostic", name)
+		}
+	}
+}
@@ -75,6 +75,33 @@ const (
 	OpInherit         // Set up inheritance (প্রসারিত)
 	OpGetInstanceField // Get instance field (like OpGetStructField but for classes)
 	OpSetInstanceField // Set instance field (like OpSetStructField but for classes)
+
+	OpSlice    // Slice an array or string: left, start, end (null for open ends)
+	OpSetIndex // Assign to an element of a mutable array: collection, index, value
+
+	OpGetStatic // Get a static field or method of a class: class
+	OpSetStatic // Set a static field of a class: class, value
+
+	OpArgMissing // Push whether the call left out a parameter's argument
+
+	OpTuple  // Build a tuple from the top stack values
+	OpUnpack // Replace a tuple or array with its elements, checking their number
+
+	OpDup // Push another copy of the value on top of the stack
+
+	OpLessThan      // Less than, the left operand pushed first
+	OpLessThanEqual // Less than or equal, the left operand pushed first
+
+	OpNewCell // Replace the value on top of the stack with a cell holding it
+	OpGetCell // Replace a cell with the value it holds
+	OpSetCell // Store a value in a cell: cell, value
+
+	// Superinstructions, which the compiler fuses from pairs of the
+	// instructions above to save a dispatch
+	OpCompareJump // A comparison followed by OpJumpNotTruthy
+	OpGetLocals   // Two OpGetLocal in a row
+	OpAddConstant // OpConstant followed by OpAdd
+	OpGetLocalAdd // OpGetLocal followed by OpAdd
 )
 
 // Definition holds information about an opcode
@@ -145,6 +172,31 @@ var definitions = map[Opcode]*Definition{
 	OpInherit:          {"OpInherit", []int{2}},      // parent class index
 	OpGetInstanceField: {"OpGetInstanceField", []int{}},
 	OpSetInstanceField: {"OpSetInstanceField", []int{}},
+
+	OpSlice:    {"OpSlice", []int{}},
+	OpSetIndex: {"OpSetIndex", []int{}},
+
+	OpGetStatic: {"OpGetStatic", []int{2}}, // member name index in constants
+	OpSetStatic: {"OpSetStatic", []int{2}}, // field name index in constants
+
+	OpArgMissing: {"OpArgMissing", []int{1}}, // parameter's local index
+
+	OpTuple:  {"OpTuple", []int{2}},  // number of elements
+	OpUnpack: {"OpUnpack", []int{1}}, // number of elements expected
+
+	OpDup: {"OpDup", []int{}},
+
+	OpLessThan:      {"OpLessThan", []int{}},
+	OpLessThanEqual: {"OpLessThanEqual", []int{}},
+
+	OpNewCell: {"OpNewCell", []int{}},
+	OpGetCell: {"OpGetCell", []int{}},
+	OpSetCell: {"OpSetCell", []int{}},
+
+	OpCompareJump: {"OpCompareJump", []int{1, 2}}, // comparison opcode, jump target when false
+	OpGetLocals:   {"OpGetLocals", []int{1, 1}},   // local indexes, pushed in order
+	OpAddConstant: {"OpAddConstant", []int{2}},    // constant index
+	OpGetLocalAdd: {"OpGetLocalAdd", []int{1}},    // local index
 }
 
 // Lookup returns the definition for an opcode
@@ -210,20 +262,24 @@ func (ins Instructions) String() string {
 
 	i := 0
 	for i < len(ins) {
-		def, err := Lookup(ins[i])
-		if err != nil {
-			fmt.Fprintf(&out, "ERROR: %s\n", err)
-			continue
-		}
-
-		operands, read := ReadOperands(def, ins[i+1:])
+		text, next := ins.FormatAt(i)
+		fmt.Fprintf(&out, "%04d %s\n", i, text)
+		i = next
+	}
 
-		fmt.Fprintf(&out, "%04d %s\n", i, ins.fmtInstruction(def, operands))
+	return out.String()
+}
 
-		i += 1 + read
+// FormatAt disassembles the single instruction at offset i, returning its
+// text and the offset of the following instruction
+func (ins Instructions) FormatAt(i int) (string, int) {
+	def, err := Lookup(ins[i])
+	if err != nil {
+		return fmt.Sprintf("ERROR: %s", err), i + 1
 	}
 
-	return out.String()
+	operands, read := ReadOperands(def, ins[i+1:])
+	return ins.fmtInstruction(def, operands), i + 1 + read
 }
 
 func (ins Instructions) fmtInstruction(def *Definition, operands []int) string {
@@ -240,6 +296,10 @@ func (ins Instructions) fmtInstruction(def *Definition, operands []int) string {
 	case 1:
 		return fmt.Sprintf("%s %d", def.Name, operands[0])
 	case 2:
+		// The comparison a fused jump makes reads better by name
+		if cmp, ok := definitions[Opcode(operands[0])]; ok && def == definitions[OpCompareJump] {
+			return fmt.Sprintf("%s %s %d", def.Name, cmp.Name, operands[1])
+		}
 		return fmt.Sprintf("%s %d %d", def.Name, operands[0], operands[1])
 	}
 
@@ -0,0 +1,884 @@
+package main
+
+import (
+	"bhasa/ast"
+	"bhasa/compiler"
+	"bhasa/format"
+	"bhasa/lexer"
+	"bhasa/lsp"
+	"bhasa/object"
+	"bhasa/parser"
+	"bhasa/repl"
+	"bhasa/token"
+	"bhasa/version"
+	"bhasa/vm"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+)
+
+// parseErrors collects the parser errors of a source file
+type parseErrors []string
+
+func (e parseErrors) Error() string {
+	var sb strings.Builder
+	sb.WriteString("Parser errors:")
+	for _, msg := range e {
+		sb.WriteString("\n\t" + msg)
+	}
+	return sb.String()
+}
+
+// mainFileNames are the entry points looked for when running a directory
+var mainFileNames = []string{"প্রধান.ভাষা", "প্রধান.bhasa", "main.ভাষা", "main.bhasa"}
+
+// stdinName stands for standard input in diagnostics
+const stdinName = "<stdin>"
+
+// sniffSize is how much of a file is inspected to tell source from bytecode
+const sniffSize = 4096
+
+// isBytecodeFile checks if the file is a bytecode file by its contents:
+// bytecode starts with the BHAS magic number whatever the file is called
+func isBytecodeFile(filename string) bool {
+	isBytecode, err := sniffFile(filename)
+	return err == nil && isBytecode
+}
+
+// hasBytecodeExt checks whether the filename uses a bytecode extension
+func hasBytecodeExt(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".compiled" || ext == ".সংকলিত"
+}
+
+// sniffFile reports whether the file holds bytecode, and fails when the
+// contents are neither bytecode nor UTF-8 source text
+func sniffFile(filename string) (bool, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return false, fmt.Errorf("Error reading file: %v", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, sniffSize)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, fmt.Errorf("Error reading file: %v", err)
+	}
+	header = header[:n]
+	if compiler.HasMagicNumber(header) {
+		return true, nil
+	}
+
+	if hasBytecodeExt(filename) {
+		return false, fmt.Errorf("%s is not a Bhasa bytecode file (missing BHAS magic number)", filename)
+	}
+	// A full read may end in the middle of a multi-byte character
+	if n == sniffSize {
+		for i := 0; i < utf8.UTFMax && !utf8.Valid(header); i++ {
+			header = header[:len(header)-1]
+		}
+	}
+	if !utf8.Valid(header) || bytes.IndexByte(header, 0) >= 0 {
+		return false, fmt.Errorf("%s is neither Bhasa source (UTF-8 text) nor bytecode (BHAS magic number)", filename)
+	}
+	return false, nil
+}
+
+// resolveEntry maps a directory to the main file named by its manifest or,
+// without one, to the first of mainFileNames it contains; other paths are
+// returned unchanged
+func resolveEntry(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return path, nil
+	}
+	m, err := readManifest(path)
+	if err != nil {
+		return "", err
+	}
+	if m != nil && m.Main != "" {
+		return filepath.Join(path, m.Main), nil
+	}
+	for _, name := range mainFileNames {
+		candidate := filepath.Join(path, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no main file (%s) found in directory %s", mainFileNames[0], path)
+}
+
+// isSourceFile checks if the file is a source file based on extension
+func isSourceFile(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".bhasa" || ext == ".ভাষা"
+}
+
+// parseFile reads and parses a source file, or standard input for "-".
+// The source is tokenized as it is read, so it is never held whole.
+func parseFile(filename string) (*ast.Program, error) {
+	var r io.Reader = os.Stdin
+	name := stdinName
+	if filename != "-" {
+		name = filename
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading file: %v", err)
+		}
+		defer file.Close()
+		r = file
+	}
+	return parseReader(bufio.NewReader(r), name)
+}
+
+// parseReader parses source streamed from r; name is the file it came from
+func parseReader(r io.RuneReader, name string) (*ast.Program, error) {
+	l := lexer.NewReader(r)
+	l.SetFile(name)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if err := l.Err(); err != nil {
+		return nil, fmt.Errorf("Error reading file: %v", err)
+	}
+	if len(p.Errors()) != 0 {
+		return nil, parseErrors(p.Errors())
+	}
+	return program, nil
+}
+
+// compileFile parses and compiles a source file to bytecode
+func compileFile(filename string) (*compiler.Bytecode, error) {
+	program, err := parseFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	comp := compiler.New()
+	comp.SetFile(filename)
+	comp.SetModuleLoader(compiler.DirModuleLoader(filepath.Dir(filename)))
+	if err := comp.Compile(program); err != nil {
+		return nil, fmt.Errorf("Compilation failed:\n %s", err)
+	}
+	for _, warning := range comp.Warnings() {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	return comp.Bytecode(), nil
+}
+
+// readBytecodeFile deserializes a pre-compiled bytecode file
+func readBytecodeFile(filename string) (*compiler.Bytecode, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening bytecode file: %v", err)
+	}
+	defer file.Close()
+
+	bytecode, err := compiler.Deserialize(file)
+	if err != nil {
+		return nil, fmt.Errorf("Error deserializing bytecode: %v", err)
+	}
+	return bytecode, nil
+}
+
+// loadStdin compiles source or reads bytecode from standard input;
+// modules are looked up in the current directory
+func loadStdin() (*compiler.Bytecode, error) {
+	in := bufio.NewReader(os.Stdin)
+	if header, _ := in.Peek(4); compiler.HasMagicNumber(header) {
+		bytecode, err := compiler.Deserialize(in)
+		if err != nil {
+			return nil, fmt.Errorf("Error deserializing bytecode: %v", err)
+		}
+		return bytecode, nil
+	}
+
+	program, err := parseReader(in, stdinName)
+	if err != nil {
+		return nil, err
+	}
+	comp := compiler.New()
+	comp.SetFile(stdinName)
+	comp.SetModuleLoader(compiler.DirModuleLoader("."))
+	if err := comp.Compile(program); err != nil {
+		return nil, fmt.Errorf("Compilation failed:\n %s", err)
+	}
+	return comp.Bytecode(), nil
+}
+
+// loadBytecode compiles a source file or reads a bytecode file. A directory
+// stands for the main file inside it, and "-" for standard input.
+func loadBytecode(filename string) (*compiler.Bytecode, error) {
+	if filename == "-" {
+		return loadStdin()
+	}
+	filename, err := resolveEntry(filename)
+	if err != nil {
+		return nil, err
+	}
+	isBytecode, err := sniffFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if isBytecode {
+		return readBytecodeFile(filename)
+	}
+	return compileFile(filename)
+}
+
+// runOptions are the VM settings chosen on the command line
+type runOptions struct {
+	arena   bool      // allocate arithmetic results from an arena
+	stdout  io.Writer // where the program prints; os.Stdout when nil
+	watch   []string  // variables whose writes are reported on stderr
+	trace   bool      // every instruction run is written to stderr
+	allocs  bool      // the values the VM allocates are counted on stderr
+	bengali bool      // numbers are shown with Bengali digits
+}
+
+// hasLocal reports whether a function of the program has a local with the
+// given name
+func hasLocal(bytecode *compiler.Bytecode, name string) bool {
+	for _, constant := range bytecode.Constants {
+		if fn, ok := constant.(*object.CompiledFunction); ok {
+			for _, local := range fn.LocalNames {
+				if local == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// numeralsFlag adds the -numerals option, which picks the digits numbers
+// are printed with
+func numeralsFlag(fs *flag.FlagSet) *string {
+	return fs.String("numerals", "latin", "Print numbers with latin (5) or bengali (৫) digits")
+}
+
+// bengaliNumerals reads the value of a -numerals option, reporting
+// whether it asks for Bengali digits
+func bengaliNumerals(name string) (bool, error) {
+	switch name {
+	case "latin":
+		return false, nil
+	case "bengali", "বাংলা":
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown numerals %q; use latin or bengali", name)
+}
+
+// runProgram executes bytecode in a fresh VM; args are made available to
+// the program through আর্গুমেন্ট
+func runProgram(bytecode *compiler.Bytecode, args []string, opts runOptions) error {
+	machine := vm.New(bytecode)
+	machine.SetArgs(args)
+	machine.SetBengaliNumerals(opts.bengali)
+	if opts.stdout != nil {
+		machine.SetStdout(opts.stdout)
+	}
+	if opts.arena {
+		machine.EnableArena()
+	}
+	if opts.trace {
+		machine.Trace(os.Stderr)
+	}
+	if opts.allocs {
+		machine.CountAllocations()
+		defer writeAllocations(os.Stderr, machine.Allocations())
+	}
+	for _, name := range opts.watch {
+		index := -1
+		for i, global := range bytecode.Globals {
+			if global == name {
+				index = i
+			}
+		}
+		local := hasLocal(bytecode, name)
+		if index < 0 && !local {
+			return fmt.Errorf("cannot watch %s: the program has no variable of that name", name)
+		}
+		if index >= 0 {
+			machine.Watch(index, name)
+		}
+		if local {
+			machine.WatchLocal(name)
+		}
+	}
+	if vm.OpcodeStatsEnabled {
+		defer vm.WriteOpcodeStats(os.Stderr)
+	}
+	if err := machine.Run(); err != nil {
+		return fmt.Errorf("Executing bytecode failed:\n %s", err)
+	}
+	return nil
+}
+
+// fail prints an error to stderr and returns the failure exit code
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, err)
+	return 1
+}
+
+func cmdRun(args []string) (status int) {
+	fs := newFlagSet("run")
+	arena := fs.Bool("arena", false, "Allocate arithmetic results from an arena")
+	numerals := numeralsFlag(fs)
+	watch := fs.String("watch", "", "Report each write to these variables (comma-separated) on stderr")
+	trace := fs.Bool("trace", false, "Write every instruction run, with the top of the stack, to stderr")
+	allocs := fs.Bool("allocs", false, "Count the values the VM allocates, by type, on stderr")
+	cpuProfile := fs.String("cpuprofile", "", "Write a CPU profile for go tool pprof to this file")
+	memProfile := fs.String("memprofile", "", "Write a memory profile for go tool pprof to this file")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+	bengali, err := bengaliNumerals(*numerals)
+	if err != nil {
+		return fail(err)
+	}
+
+	// Compiling is profiled along with running
+	stopProfiling, err := startProfiling(*cpuProfile, *memProfile)
+	if err != nil {
+		return fail(err)
+	}
+	defer func() {
+		if err := stopProfiling(); err != nil && status == 0 {
+			status = fail(err)
+		}
+	}()
+
+	bytecode, err := loadBytecode(fs.Arg(0))
+	if err != nil {
+		return fail(err)
+	}
+	opts := runOptions{arena: *arena, trace: *trace, allocs: *allocs, bengali: bengali}
+	if *watch != "" {
+		opts.watch = strings.Split(*watch, ",")
+	}
+	if err := runProgram(bytecode, fs.Args()[1:], opts); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func cmdBuild(args []string) int {
+	fs := newFlagSet("build")
+	outputFile := fs.String("o", "", "Output file for compiled bytecode")
+	listingFile := fs.String("listing", "", "Also write a source/bytecode listing to this file")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) != 1 {
+		fs.Usage()
+		return 2
+	}
+	filename, err := resolveEntry(files[0])
+	if err != nil {
+		return fail(err)
+	}
+
+	bytecode, err := compileFile(filename)
+	if err != nil {
+		return fail(err)
+	}
+
+	// Determine output filename
+	output := *outputFile
+	if output == "" {
+		// Default: replace extension with .compiled
+		ext := filepath.Ext(filename)
+		output = strings.TrimSuffix(filename, ext) + ".compiled"
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return fail(fmt.Errorf("Error creating output file: %v", err))
+	}
+	defer file.Close()
+
+	if err := bytecode.Serialize(file); err != nil {
+		return fail(fmt.Errorf("Error serializing bytecode: %v", err))
+	}
+
+	if *listingFile != "" {
+		if err := writeListingFile(*listingFile, filename, bytecode); err != nil {
+			return fail(err)
+		}
+	}
+
+	fmt.Printf("Successfully compiled %s to %s\n", filename, output)
+	return 0
+}
+
+// writeListingFile writes the source/bytecode listing of filename
+func writeListingFile(listingFile, filename string, bytecode *compiler.Bytecode) error {
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("Error reading file: %v", err)
+	}
+
+	file, err := os.Create(listingFile)
+	if err != nil {
+		return fmt.Errorf("Error creating listing file: %v", err)
+	}
+	defer file.Close()
+
+	return compiler.WriteListing(file, string(source), bytecode)
+}
+
+func cmdRepl(args []string) int {
+	fs := newFlagSet("repl")
+	numerals := numeralsFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	bengali, err := bengaliNumerals(*numerals)
+	if err != nil {
+		return fail(err)
+	}
+	repl.StartWithOptions(object.DefaultStdin(), os.Stdout, repl.Options{BengaliNumerals: bengali})
+	return 0
+}
+
+func cmdLsp(args []string) int {
+	fs := newFlagSet("lsp")
+	// Editors start servers with --stdio; it is the only transport there is
+	fs.Bool("stdio", true, "Talk to the editor over standard input and output")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if err := lsp.Serve(os.Stdin, os.Stdout); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// collectSourceFiles expands directories into the source files they contain
+func collectSourceFiles(paths []string) ([]string, error) {
+	return collectFiles(paths, isSourceFile)
+}
+
+// collectFiles expands directories into the files under them that match;
+// files named directly are always kept
+func collectFiles(paths []string, match func(string) bool) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && match(p) {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// testFileSuffix ends the name of a test file, before its extension
+const testFileSuffix = "_পরীক্ষা"
+
+// testFuncPrefix starts the name of a test function
+const testFuncPrefix = "পরীক্ষা_"
+
+// isTestFile reports whether a source file holds tests, as গণিত_পরীক্ষা.bhasa
+// does
+func isTestFile(filename string) bool {
+	base := filepath.Base(filename)
+	return isSourceFile(filename) && strings.HasSuffix(strings.TrimSuffix(base, filepath.Ext(base)), testFileSuffix)
+}
+
+func cmdTest(args []string) int {
+	fs := newFlagSet("test")
+	verbose := fs.Bool("v", false, "Show the output of passing tests too")
+	paths, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(paths) == 0 {
+		paths = []string{"tests"}
+	}
+
+	files, err := collectFiles(paths, isTestFile)
+	if err != nil {
+		return fail(err)
+	}
+
+	passed, failed := 0, 0
+	for _, file := range files {
+		p, f := runTestFile(file, *verbose)
+		passed += p
+		failed += f
+	}
+
+	fmt.Printf("\n%d টি পরীক্ষা সফল, %d টি ব্যর্থ\n", passed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runTestFile runs a test file and then each of its global functions
+// named পরীক্ষা_..., in the order they are defined, printing a line for
+// each. A test fails when it ends in an error, such as a failed নিশ্চিত_
+// assertion. A file without test functions is a single test that passes
+// when it runs to the end. It returns how many tests passed and failed.
+func runTestFile(file string, verbose bool) (int, int) {
+	start := time.Now()
+	var output bytes.Buffer
+	bytecode, err := compileFile(file)
+	if err != nil {
+		printTestResult(file, err, "", 0, true)
+		return 0, 1
+	}
+	globals := make([]object.Object, vm.GlobalsSize)
+	machine := vm.NewWithGlobalsStore(bytecode, globals)
+	machine.SetStdout(&output)
+	if err := machine.Run(); err != nil {
+		printTestResult(file, err, output.String(), time.Since(start), true)
+		return 0, 1
+	}
+
+	var tests []int
+	for i, name := range bytecode.Globals {
+		if _, ok := globals[i].(*object.Closure); ok && strings.HasPrefix(name, testFuncPrefix) {
+			tests = append(tests, i)
+		}
+	}
+	if len(tests) == 0 {
+		printTestResult(file, nil, output.String(), time.Since(start), verbose)
+		return 1, 0
+	}
+
+	fmt.Println(file)
+	if verbose {
+		printTestOutput(output.String())
+	}
+	passed, failed := 0, 0
+	for _, i := range tests {
+		start := time.Now()
+		var output bytes.Buffer
+		machine.SetStdout(&output)
+		var err error
+		if errObj, ok := machine.CallFunction(globals[i]).(*object.Error); ok {
+			err = fmt.Errorf("%s", errObj.Message)
+		}
+		printTestResult("  "+bytecode.Globals[i], err, output.String(), time.Since(start), verbose)
+		if err != nil {
+			failed++
+		} else {
+			passed++
+		}
+	}
+	return passed, failed
+}
+
+// printTestResult prints whether a test passed, with the error it failed
+// with and what it printed indented under it
+func printTestResult(name string, err error, output string, elapsed time.Duration, showOutput bool) {
+	if err != nil {
+		fmt.Printf("ব্যর্থ %s\n\t%s\n", name, strings.ReplaceAll(err.Error(), "\n", "\n\t"))
+	} else {
+		fmt.Printf("সফল  %s (%s)\n", name, elapsed.Round(time.Millisecond))
+	}
+	if showOutput {
+		printTestOutput(output)
+	}
+}
+
+// printTestOutput shows what a test printed, indented under its result
+// line
+func printTestOutput(output string) {
+	if output == "" {
+		return
+	}
+	fmt.Printf("\t| %s\n", strings.ReplaceAll(strings.TrimSuffix(output, "\n"), "\n", "\n\t| "))
+}
+
+func cmdFmt(args []string) int {
+	fs := newFlagSet("fmt")
+	write := fs.Bool("w", false, "Write result to the source file instead of stdout")
+	list := fs.Bool("l", false, "List files whose formatting differs")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	status := 0
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			status = fail(fmt.Errorf("Error reading file: %v", err))
+			continue
+		}
+		program, err := parseReader(strings.NewReader(string(content)), file)
+		if err != nil {
+			status = fail(fmt.Errorf("%s: %v", file, err))
+			continue
+		}
+
+		formatted := format.Program(program, string(content))
+		changed := formatted != string(content)
+		if *list && changed {
+			fmt.Println(file)
+		}
+		if *write {
+			if changed {
+				if err := os.WriteFile(file, []byte(formatted), 0644); err != nil {
+					status = fail(err)
+				}
+			}
+		} else if !*list {
+			fmt.Print(formatted)
+		}
+	}
+	return status
+}
+
+// dumpAST prints the syntax tree of a source file as indented JSON. Each
+// node is an object naming its kind, with the line and column it starts
+// at and one entry per field; hash literals list their pairs in source
+// order.
+func dumpAST(file string) int {
+	program, err := parseFile(file)
+	if err != nil {
+		return fail(err)
+	}
+	text, err := astJSON(program)
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Print(text)
+	return 0
+}
+
+// astJSON renders a syntax tree as the indented JSON dumpAST prints
+func astJSON(program *ast.Program) (string, error) {
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false) // keep operators such as < and && readable
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(ast.ToMap(program)); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// dumpHighlights prints how `bhasa lsp` classifies the tokens of a source
+// file for highlighting, as a JSON array with each token's line, column
+// and length in characters and its kind, for editors without an LSP client
+func dumpHighlights(file string) int {
+	var src []byte
+	var err error
+	path := ""
+	if file == "-" {
+		src, err = io.ReadAll(os.Stdin)
+	} else {
+		path = file
+		src, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return fail(fmt.Errorf("Error reading file: %v", err))
+	}
+
+	highlights := lsp.Highlights(path, string(src))
+	if highlights == nil {
+		highlights = []lsp.Highlight{}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(highlights); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// dumpTokens prints the tokens of a source file, one a line, with the
+// line and column each starts at, its type and its text quoted so that
+// invisible characters such as the zero-width joiner show. Tokens holding
+// other than ASCII also list their code points, which tells a vowel sign
+// or hasanta apart from a lookalike. Numbers show the value the lexer
+// read, with Bengali digits turned into ASCII ones.
+func dumpTokens(file string) int {
+	var r io.Reader = os.Stdin
+	name := stdinName
+	if file != "-" {
+		name = file
+		f, err := os.Open(file)
+		if err != nil {
+			return fail(fmt.Errorf("Error reading file: %v", err))
+		}
+		defer f.Close()
+		r = f
+	}
+	l := lexer.NewReader(bufio.NewReader(r))
+	l.SetFile(name)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for {
+		tok := l.NextToken()
+		fmt.Fprintf(w, "%d:%d\t%s\t%q", tok.Line, tok.Column, tok.Type, tok.Literal)
+		if codes := codePoints(tok.Literal); codes != "" {
+			fmt.Fprintf(w, "\t%s", codes)
+		}
+		fmt.Fprintln(w)
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+	w.Flush()
+	if err := l.Err(); err != nil {
+		return fail(fmt.Errorf("Error reading file: %v", err))
+	}
+	return 0
+}
+
+// codePoints lists the code points of s as U+XXXX, or returns "" when s is
+// all ASCII
+func codePoints(s string) string {
+	ascii := true
+	var codes []string
+	for _, r := range s {
+		if r >= utf8.RuneSelf {
+			ascii = false
+		}
+		codes = append(codes, fmt.Sprintf("U+%04X", r))
+	}
+	if ascii {
+		return ""
+	}
+	return strings.Join(codes, " ")
+}
+
+// checkFiles reports the errors in files without running them, printing
+// each as file:line: message, and returns 1 when any file has one. Both
+// bhasa check and bhasa --check use it.
+func checkFiles(files []string) int {
+	status := 0
+	for _, file := range files {
+		if err := checkFile(file); err != nil {
+			status = fail(err)
+		}
+	}
+	return status
+}
+
+// checkFile parses and resolves a file, reporting every error the checks
+// made before compiling find. A file that passes them is then compiled
+// and its bytecode verified, which catches code generation bugs such as a
+// jump left unpatched.
+func checkFile(file string) error {
+	program, err := parseFile(file)
+	if err != nil {
+		return err
+	}
+	comp := compiler.New()
+	comp.SetFile(file)
+	comp.SetModuleLoader(compiler.DirModuleLoader(filepath.Dir(file)))
+	err = comp.Check(program)
+	for _, warning := range comp.Warnings() {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The checks have already reported the compiler's warnings
+	comp = compiler.New()
+	comp.SetFile(file)
+	comp.SetModuleLoader(compiler.DirModuleLoader(filepath.Dir(file)))
+	if err := comp.Compile(program); err != nil {
+		return err
+	}
+	if err := comp.Bytecode().Verify(); err != nil {
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	return nil
+}
+
+func cmdCheck(args []string) int {
+	fs := newFlagSet("check")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		return 2
+	}
+	return checkFiles(files)
+}
+
+func cmdDis(args []string) int {
+	fs := newFlagSet("dis")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	bytecode, err := loadBytecode(files[0])
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Print(bytecode.Disassemble(0))
+	return 0
+}
+
+func cmdVersion(args []string) int {
+	fs := newFlagSet("version")
+	asJSON := fs.Bool("json", false, "Print version information as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	info := version.Get()
+	if !*asJSON {
+		fmt.Println(info)
+		return 0
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Println(string(data))
+	return 0
+}
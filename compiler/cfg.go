@@ -0,0 +1,240 @@
+package compiler
+
+import (
+	"bhasa/code"
+	"fmt"
+	"sort"
+)
+
+// basicBlock is a run of instructions that is only entered at its first
+// instruction and only left after its last
+type basicBlock struct {
+	start, end int   // offset of the first instruction and just past the last
+	succs      []int // blocks control can pass to, by index
+}
+
+// cfg is the control-flow graph of one instruction stream. Blocks are in
+// offset order, so block 0 is the entry.
+type cfg struct {
+	name    string
+	ins     code.Instructions
+	blocks  []*basicBlock
+	blockAt map[int]int // block index by start offset
+}
+
+// buildCFG splits an instruction stream into basic blocks. It fails on an
+// unknown or truncated instruction and on a jump whose target is not the
+// start of an instruction, such as an operand left unpatched.
+func buildCFG(name string, ins code.Instructions) (*cfg, error) {
+	boundaries := map[int]bool{len(ins): true}
+	leaders := map[int]bool{0: true}
+	var jumps [][2]int // offset, target
+
+	for i := 0; i < len(ins); {
+		boundaries[i] = true
+		def, err := code.Lookup(ins[i])
+		if err != nil {
+			return nil, &VerifyError{name, i, err.Error()}
+		}
+		width := 0
+		for _, w := range def.OperandWidths {
+			width += w
+		}
+		if i+1+width > len(ins) {
+			return nil, &VerifyError{name, i, fmt.Sprintf("%s is truncated", def.Name)}
+		}
+		operands, _ := code.ReadOperands(def, ins[i+1:])
+		next := i + 1 + width
+
+		op := code.Opcode(ins[i])
+		if j := jumpOperand(op); j >= 0 {
+			jumps = append(jumps, [2]int{i, operands[j]})
+			leaders[operands[j]] = true
+			leaders[next] = true
+		} else if op == code.OpReturnValue || op == code.OpReturn {
+			leaders[next] = true
+		}
+		i = next
+	}
+
+	for _, j := range jumps {
+		if !boundaries[j[1]] {
+			return nil, &VerifyError{name, j[0], fmt.Sprintf("jump target %04d is not an instruction boundary", j[1])}
+		}
+	}
+
+	starts := make([]int, 0, len(leaders))
+	for offset := range leaders {
+		if offset < len(ins) {
+			starts = append(starts, offset)
+		}
+	}
+	sort.Ints(starts)
+
+	g := &cfg{name: name, ins: ins, blockAt: map[int]int{}}
+	for i, start := range starts {
+		end := len(ins)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		g.blockAt[start] = i
+		g.blocks = append(g.blocks, &basicBlock{start: start, end: end})
+	}
+
+	for _, b := range g.blocks {
+		last, operands, next := g.lastInstruction(b)
+		var targets []int
+		switch last {
+		case code.OpReturnValue, code.OpReturn:
+		case code.OpJump:
+			targets = []int{operands[0]}
+		case code.OpJumpNotTruthy:
+			targets = []int{next, operands[0]}
+		case code.OpCompareJump:
+			targets = []int{next, operands[1]}
+		default:
+			targets = []int{next}
+		}
+		for _, t := range targets {
+			// Falling off the end leaves the stream and has no block
+			if index, ok := g.blockAt[t]; ok {
+				b.succs = append(b.succs, index)
+			}
+		}
+	}
+	return g, nil
+}
+
+// lastInstruction decodes the final instruction of a block, returning its
+// opcode, its operands and the offset after it
+func (g *cfg) lastInstruction(b *basicBlock) (code.Opcode, []int, int) {
+	var op code.Opcode
+	var operands []int
+	for i := b.start; i < b.end; {
+		def, _ := code.Lookup(g.ins[i])
+		var read int
+		operands, read = code.ReadOperands(def, g.ins[i+1:])
+		op = code.Opcode(g.ins[i])
+		i += 1 + read
+	}
+	return op, operands, b.end
+}
+
+// checkStack follows every path through the graph, tracking the depth of
+// the operand stack. No instruction may pop more than the stack holds or
+// grow it beyond the VM's size, every path into a block must arrive with
+// the same depth, and the stack must be empty wherever control leaves the
+// stream: after OpReturnValue takes its value, at OpReturn, and at the
+// end of the main program.
+func (g *cfg) checkStack() error {
+	if len(g.blocks) == 0 {
+		return nil
+	}
+	depthAt := map[int]int{0: 0}
+	work := []int{0}
+
+	for len(work) > 0 {
+		index := work[len(work)-1]
+		work = work[:len(work)-1]
+		b := g.blocks[index]
+		depth := depthAt[index]
+
+		for i := b.start; i < b.end; {
+			op := code.Opcode(g.ins[i])
+			def, _ := code.Lookup(g.ins[i])
+			operands, read := code.ReadOperands(def, g.ins[i+1:])
+
+			effect := effectOf(op, operands)
+			if depth < effect.pop {
+				return &VerifyError{g.name, i, fmt.Sprintf("%s needs %d stack values, only %d available", def.Name, effect.pop, depth)}
+			}
+			depth += effect.push - effect.pop
+			if depth > maxVerifiedStack {
+				return &VerifyError{g.name, i, fmt.Sprintf("stack may grow beyond %d values", maxVerifiedStack)}
+			}
+
+			leaves := op == code.OpReturnValue || op == code.OpReturn || i+1+read == len(g.ins)
+			if leaves && op != code.OpJump && depth != 0 {
+				return &VerifyError{g.name, i, fmt.Sprintf("%d values left on the stack", depth)}
+			}
+			i += 1 + read
+		}
+
+		for _, s := range b.succs {
+			seen, ok := depthAt[s]
+			if !ok {
+				depthAt[s] = depth
+				work = append(work, s)
+				continue
+			}
+			if seen != depth {
+				return &VerifyError{g.name, g.blocks[s].start, fmt.Sprintf("stack depth is %d on one path here and %d on another", seen, depth)}
+			}
+		}
+	}
+	return nil
+}
+
+// checkLocals follows every path through the graph, tracking which locals
+// have been assigned. The first numParams locals hold the arguments and
+// are assigned on entry; any other local must be set by OpSetLocal on
+// every path before an instruction reads it, as the VM does not clear the
+// stack slots a call reuses.
+func (g *cfg) checkLocals(numParams, numLocals int) error {
+	if len(g.blocks) == 0 || numLocals == 0 {
+		return nil
+	}
+	entry := make([]bool, numLocals)
+	for i := 0; i < numParams && i < numLocals; i++ {
+		entry[i] = true
+	}
+	assignedAt := map[int][]bool{0: entry}
+	work := []int{0}
+
+	for len(work) > 0 {
+		index := work[len(work)-1]
+		work = work[:len(work)-1]
+		b := g.blocks[index]
+		assigned := append([]bool(nil), assignedAt[index]...)
+
+		for i := b.start; i < b.end; {
+			op := code.Opcode(g.ins[i])
+			def, _ := code.Lookup(g.ins[i])
+			operands, read := code.ReadOperands(def, g.ins[i+1:])
+
+			switch op {
+			case code.OpGetLocal, code.OpGetLocals, code.OpGetLocalAdd:
+				for _, local := range operands {
+					if !assigned[local] {
+						return &VerifyError{g.name, i, fmt.Sprintf("%s: local %d may be read before it is set", def.Name, local)}
+					}
+				}
+			case code.OpSetLocal:
+				assigned[operands[0]] = true
+			}
+			i += 1 + read
+		}
+
+		// A block is revisited whenever a new path leaves fewer locals
+		// assigned on entry to it
+		for _, s := range b.succs {
+			seen, ok := assignedAt[s]
+			if !ok {
+				assignedAt[s] = append([]bool(nil), assigned...)
+				work = append(work, s)
+				continue
+			}
+			changed := false
+			for local, set := range seen {
+				if set && !assigned[local] {
+					seen[local] = false
+					changed = true
+				}
+			}
+			if changed {
+				work = append(work, s)
+			}
+		}
+	}
+	return nil
+}
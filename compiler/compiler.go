@@ -3,11 +3,16 @@ package compiler
 import (
 	"bhasa/ast"
 	"bhasa/code"
+	"bhasa/desugar"
+	"bhasa/errors"
 	"bhasa/lexer"
 	"bhasa/object"
 	"bhasa/parser"
+	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 )
@@ -21,6 +26,12 @@ type Compiler struct {
 	loopStack    []LoopContext       // track nested loops for break/continue
 	moduleCache  map[string]bool     // track loaded modules to prevent circular imports
 	moduleLoader ModuleLoader        // function to load module files
+	className    string              // class whose body is being compiled, if any
+	file         string              // file being compiled, for diagnostics
+	resolved     map[*ast.Identifier]binding // what each identifier refers to, from resolve
+	boxed        map[*ast.BlockStatement]map[string]bool // variables kept in cells, by function body, from resolve
+	chain        *[]int                      // jumps out of the ?. chain being compiled
+	warnings     []error                     // problems that do not stop compilation
 }
 
 // LoopContext tracks loop start and break positions
@@ -30,6 +41,10 @@ type LoopContext struct {
 	contPositions  []int
 }
 
+// MaxConstants is the size of the constant pool an OpConstant operand can
+// address
+const MaxConstants = 1 << 16
+
 // ModuleLoader is a function type for loading module source code
 type ModuleLoader func(path string) (string, error)
 
@@ -38,6 +53,7 @@ type CompilationScope struct {
 	instructions        code.Instructions
 	lastInstruction     EmittedInstruction
 	previousInstruction EmittedInstruction
+	lines               []object.LineInfo
 }
 
 // EmittedInstruction tracks an emitted instruction
@@ -50,8 +66,27 @@ type EmittedInstruction struct {
 type Bytecode struct {
 	Instructions code.Instructions
 	Constants    []object.Object
+	Lines        []object.LineInfo // source lines of the main instructions
+	Globals      []string          // global names by index ("" for shadowed ones), for importing as a module
+}
+
+// CompileError is a compile error located in a source file
+type CompileError struct {
+	File   string
+	Line   int
+	Column int // column of the token at fault, 0 when unknown
+	Err    error
 }
 
+func (e *CompileError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Err)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Err)
+}
+
+func (e *CompileError) Unwrap() error { return e.Err }
+
 // New creates a new Compiler
 func New() *Compiler {
 	mainScope := CompilationScope{
@@ -74,6 +109,8 @@ func New() *Compiler {
 		scopeIndex:   0,
 		moduleCache:  make(map[string]bool),
 		moduleLoader: DefaultModuleLoader,
+		resolved:     make(map[*ast.Identifier]binding),
+		boxed:        make(map[*ast.BlockStatement]map[string]bool),
 	}
 }
 
@@ -85,18 +122,35 @@ func NewWithState(s *SymbolTable, constants []object.Object) *Compiler {
 	return compiler
 }
 
-// Compile compiles an AST node
+// Compile compiles an AST node. A program is desugared and resolved before
+// any of it is emitted, so the statements inside it can take for granted
+// that the names they use are defined and that break and continue are in
+// a loop.
 func (c *Compiler) Compile(node ast.Node) error {
+	if stmt, ok := node.(ast.Statement); ok {
+		c.markLine(ast.Line(stmt))
+	}
+
 	switch node := node.(type) {
 
 	case *ast.Program:
+		c.lintLoops(node)
+		node = desugar.Program(node)
+		if err := c.resolve(node); err != nil {
+			return err
+		}
 		for _, s := range node.Statements {
 			err := c.Compile(s)
 			if err != nil {
-				return err
+				return c.locate(s, err)
 			}
 		}
 
+		// Constant operands are two bytes wide
+		if len(c.constants) > MaxConstants {
+			return fmt.Errorf("%s", errors.TooManyConstants(len(c.constants), MaxConstants))
+		}
+
 	case *ast.ExpressionStatement:
 		err := c.Compile(node.Expression)
 		if err != nil {
@@ -105,29 +159,22 @@ func (c *Compiler) Compile(node ast.Node) error {
 		c.emit(code.OpPop)
 
 	case *ast.InfixExpression:
-		if node.Operator == "<" {
-			err := c.Compile(node.Right)
-			if err != nil {
-				return err
-			}
-			err = c.Compile(node.Left)
+		if node.Operator == "??" {
+			// Keep the left value unless it is null
+			err := c.Compile(node.Left)
 			if err != nil {
 				return err
 			}
-			c.emit(code.OpGreaterThan)
-			return nil
-		}
-
-		if node.Operator == "<=" {
-			err := c.Compile(node.Right)
-			if err != nil {
-				return err
-			}
-			err = c.Compile(node.Left)
+			c.emit(code.OpDup)
+			c.emit(code.OpNull)
+			c.emit(code.OpEqual)
+			jumpPos := c.emit(code.OpJumpNotTruthy, 9999)
+			c.emit(code.OpPop)
+			err = c.Compile(node.Right)
 			if err != nil {
 				return err
 			}
-			c.emit(code.OpGreaterThanEqual)
+			c.changeOperand(jumpPos, len(c.currentInstructions()))
 			return nil
 		}
 
@@ -151,6 +198,10 @@ func (c *Compiler) Compile(node ast.Node) error {
 			c.emit(code.OpDiv)
 		case "%":
 			c.emit(code.OpMod)
+		case "<":
+			c.emit(code.OpLessThan)
+		case "<=":
+			c.emit(code.OpLessThanEqual)
 		case ">":
 			c.emit(code.OpGreaterThan)
 		case ">=":
@@ -244,7 +295,7 @@ case *ast.IfExpression:
 		for _, s := range node.Statements {
 			err := c.Compile(s)
 			if err != nil {
-				return err
+				return c.locate(s, err)
 			}
 		}
 
@@ -256,6 +307,16 @@ case *ast.IfExpression:
 		} else {
 			symbol = c.symbolTable.Define(node.Name.Value)
 		}
+		symbol = c.symbolTable.box(symbol)
+
+		// A fresh cell is made before the value is compiled, so that a
+		// function in the value that refers to the name shares it
+		if symbol.Boxed {
+			c.emit(code.OpNull)
+			c.emit(code.OpNewCell)
+			c.emit(code.OpSetLocal, symbol.Index)
+			c.emit(code.OpGetLocal, symbol.Index)
+		}
 
 		// If value is an EnumDefinition, set its name from the binding
 		if enumDef, ok := node.Value.(*ast.EnumDefinition); ok {
@@ -273,24 +334,55 @@ case *ast.IfExpression:
 			c.emit(code.OpAssertType, typeConstIndex)
 		}
 
-		if symbol.Scope == GlobalScope {
+		if symbol.Boxed {
+			c.emit(code.OpSetCell)
+		} else if symbol.Scope == GlobalScope {
 			c.emit(code.OpSetGlobal, symbol.Index)
 		} else {
 			c.emit(code.OpSetLocal, symbol.Index)
 		}
 
+	case *ast.DestructuringLetStatement:
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		c.emit(code.OpUnpack, len(node.Names))
+
+		// The elements are pushed in order, so the last is assigned first
+		for i := len(node.Names) - 1; i >= 0; i-- {
+			symbol := c.symbolTable.box(c.symbolTable.Define(node.Names[i].Value))
+			if symbol.Boxed {
+				c.emit(code.OpNewCell)
+			}
+			if symbol.Scope == GlobalScope {
+				c.emit(code.OpSetGlobal, symbol.Index)
+			} else {
+				c.emit(code.OpSetLocal, symbol.Index)
+			}
+		}
+
 	case *ast.AssignmentStatement:
 		symbol, ok := c.symbolTable.Resolve(node.Name.Value)
 		if !ok {
 			return fmt.Errorf("undefined variable %s", node.Name.Value)
 		}
+		if symbol.Boxed {
+			c.loadCapture(symbol)
+		}
 
 		err := c.Compile(node.Value)
 		if err != nil {
 			return err
 		}
 
-		if symbol.Scope == GlobalScope {
+		// A variable declared with a type keeps it
+		if typ := c.resolved[node.Name].typ; typ != nil {
+			c.emit(code.OpAssertType, c.addConstant(&object.String{Value: typ.String()}))
+		}
+
+		if symbol.Boxed {
+			c.emit(code.OpSetCell)
+		} else if symbol.Scope == GlobalScope {
 			c.emit(code.OpSetGlobal, symbol.Index)
 		} else {
 			c.emit(code.OpSetLocal, symbol.Index)
@@ -303,6 +395,15 @@ case *ast.IfExpression:
 			return err
 		}
 
+		// ClassName.field = value sets a static field
+		if c.isClassName(node.Object) {
+			if err := c.Compile(node.Value); err != nil {
+				return err
+			}
+			c.emit(code.OpSetStatic, c.addConstant(&object.String{Value: node.Member.Value}))
+			break
+		}
+
 		// Push the field name as a constant
 		nameConstant := c.addConstant(&object.String{Value: node.Member.Value})
 		c.emit(code.OpConstant, nameConstant)
@@ -313,8 +414,25 @@ case *ast.IfExpression:
 			return err
 		}
 
-		// Emit instruction to set struct field
+		// Emit instruction to set struct field; it pushes the object back,
+		// which a statement has no use for
 		c.emit(code.OpSetStructField)
+		c.emit(code.OpPop)
+
+	case *ast.IndexAssignmentStatement:
+		err := c.Compile(node.Left)
+		if err != nil {
+			return err
+		}
+		err = c.Compile(node.Index)
+		if err != nil {
+			return err
+		}
+		err = c.Compile(node.Value)
+		if err != nil {
+			return err
+		}
+		c.emit(code.OpSetIndex)
 
 	case *ast.WhileStatement:
 		loopStart := len(c.currentInstructions())
@@ -335,10 +453,6 @@ case *ast.IfExpression:
 			return err
 		}
 
-		if c.lastInstructionIs(code.OpPop) {
-			c.removeLastPop()
-		}
-
 		c.emit(code.OpJump, loopStart)
 
 		afterLoopPos := len(c.currentInstructions())
@@ -358,8 +472,6 @@ case *ast.IfExpression:
 		// Pop loop context
 		c.loopStack = c.loopStack[:len(c.loopStack)-1]
 
-		c.emit(code.OpNull)
-
 	case *ast.ForStatement:
 		// Compile initialization
 		if node.Init != nil {
@@ -395,13 +507,20 @@ case *ast.IfExpression:
 			return err
 		}
 
-		if c.lastInstructionIs(code.OpPop) {
-			c.removeLastPop()
-		}
-
 		// Continue statements jump here (before increment)
 		continueTarget := len(c.currentInstructions())
 
+		// A loop variable closures share lives in a cell. Each iteration
+		// gets a fresh one holding the last value, so a closure made in
+		// the body keeps the value of its own iteration.
+		if let, ok := node.Init.(*ast.LetStatement); ok {
+			if symbol, ok := c.symbolTable.Resolve(let.Name.Value); ok && symbol.Boxed && symbol.Scope == LocalScope {
+				c.loadSymbol(symbol)
+				c.emit(code.OpNewCell)
+				c.emit(code.OpSetLocal, symbol.Index)
+			}
+		}
+
 		// Compile increment
 		if node.Increment != nil {
 			err := c.Compile(node.Increment)
@@ -438,12 +557,7 @@ case *ast.IfExpression:
 		// Pop loop context
 		c.loopStack = c.loopStack[:len(c.loopStack)-1]
 
-		c.emit(code.OpNull)
-
 	case *ast.BreakStatement:
-		if len(c.loopStack) == 0 {
-			return fmt.Errorf("break statement outside loop")
-		}
 		// Emit a jump that will be patched later
 		pos := c.emit(code.OpJump, 9999)
 		// Record this position in the current loop context
@@ -451,9 +565,6 @@ case *ast.IfExpression:
 		ctx.breakPositions = append(ctx.breakPositions, pos)
 
 	case *ast.ContinueStatement:
-		if len(c.loopStack) == 0 {
-			return fmt.Errorf("continue statement outside loop")
-		}
 		// Emit a jump that will be patched later
 		pos := c.emit(code.OpJump, 9999)
 		// Record this position in the current loop context
@@ -461,15 +572,10 @@ case *ast.IfExpression:
 		ctx.contPositions = append(ctx.contPositions, pos)
 
 	case *ast.ImportStatement:
-		// Evaluate the path expression (should be a string literal)
-		if pathLit, ok := node.Path.(*ast.StringLiteral); ok {
-			modulePath := pathLit.Value
-			// Load and compile the module
-			if err := c.LoadAndCompileModule(modulePath); err != nil {
-				return fmt.Errorf("error importing module: %v", err)
-			}
-		} else {
-			return fmt.Errorf("import path must be a string literal")
+		// The resolver has checked that the path is a string literal
+		modulePath := node.Path.(*ast.StringLiteral).Value
+		if err := c.LoadAndCompileModule(modulePath); err != nil {
+			return fmt.Errorf("error importing module: %v", err)
 		}
 
 	case *ast.Identifier:
@@ -480,14 +586,37 @@ case *ast.IfExpression:
 
 		c.loadSymbol(symbol)
 
+	case *ast.BuiltinReference:
+		index := object.BuiltinIndex(node.Name)
+		if index < 0 {
+			return fmt.Errorf("unknown builtin %s", node.Name)
+		}
+		c.emit(code.OpGetBuiltin, index)
+
 	case *ast.IntegerLiteral:
 		integer := &object.Integer{Value: node.Value}
 		c.emit(code.OpConstant, c.addConstant(integer))
 
+	case *ast.BigIntegerLiteral:
+		integer := &object.BigInteger{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(integer))
+
+	case *ast.DecimalLiteral:
+		decimal := &object.Decimal{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(decimal))
+
+	case *ast.FloatLiteral:
+		double := &object.Double{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(double))
+
 	case *ast.StringLiteral:
 		str := &object.String{Value: node.Value}
 		c.emit(code.OpConstant, c.addConstant(str))
 
+	case *ast.CharLiteral:
+		char := &object.Char{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(char))
+
 	case *ast.Boolean:
 		if node.Value {
 			c.emit(code.OpTrue)
@@ -505,6 +634,15 @@ case *ast.IfExpression:
 
 		c.emit(code.OpArray, len(node.Elements))
 
+	case *ast.TupleLiteral:
+		for _, el := range node.Elements {
+			if err := c.Compile(el); err != nil {
+				return err
+			}
+		}
+
+		c.emit(code.OpTuple, len(node.Elements))
+
 	case *ast.HashLiteral:
 		keys := []ast.Expression{}
 		for k := range node.Pairs {
@@ -578,12 +716,29 @@ case *ast.IfExpression:
 		c.emit(code.OpConstant, enumTypeIndex)
 
 	case *ast.MemberAccessExpression:
+		exits, root := c.enterChain()
+		defer c.leaveChain(exits, root)
+
 		// Compile the object expression
-		err := c.Compile(node.Object)
+		err := c.compileChainObject(node.Object, exits)
 		if err != nil {
 			return err
 		}
 
+		// ClassName.member reads a static field or method
+		if c.isClassName(node.Object) {
+			c.emit(code.OpGetStatic, c.addConstant(&object.String{Value: node.Member.Value}))
+			break
+		}
+
+		// a?.b leaves a null object as the value of the whole chain
+		if node.Optional {
+			c.emit(code.OpDup)
+			c.emit(code.OpNull)
+			c.emit(code.OpNotEqual)
+			*exits = append(*exits, c.emit(code.OpJumpNotTruthy, 9999))
+		}
+
 		// Push the field name as a constant
 		nameConstant := c.addConstant(&object.String{Value: node.Member.Value})
 		c.emit(code.OpConstant, nameConstant)
@@ -592,7 +747,10 @@ case *ast.IfExpression:
 		c.emit(code.OpGetStructField)
 
 	case *ast.IndexExpression:
-		err := c.Compile(node.Left)
+		exits, root := c.enterChain()
+		defer c.leaveChain(exits, root)
+
+		err := c.compileChainObject(node.Left, exits)
 		if err != nil {
 			return err
 		}
@@ -604,14 +762,40 @@ case *ast.IfExpression:
 
 		c.emit(code.OpIndex)
 
+	case *ast.SliceExpression:
+		exits, root := c.enterChain()
+		defer c.leaveChain(exits, root)
+
+		err := c.compileChainObject(node.Left, exits)
+		if err != nil {
+			return err
+		}
+
+		for _, bound := range []ast.Expression{node.Start, node.End} {
+			if bound == nil {
+				c.emit(code.OpNull)
+				continue
+			}
+			if err := c.Compile(bound); err != nil {
+				return err
+			}
+		}
+
+		c.emit(code.OpSlice)
+
 	case *ast.FunctionLiteral:
-		c.enterScope()
+		c.enterFunction(node.Body)
 
 		for _, p := range node.Parameters {
 			c.symbolTable.Define(p.Value)
 		}
+		numDefaults, err := c.compileParameterDefaults(node.Parameters, node.Defaults)
+		if err != nil {
+			return err
+		}
+		c.boxParameters(node.Parameters)
 
-		err := c.Compile(node.Body)
+		err = c.Compile(node.Body)
 		if err != nil {
 			return err
 		}
@@ -625,16 +809,22 @@ case *ast.IfExpression:
 
 		freeSymbols := c.symbolTable.FreeSymbols
 		numLocals := c.symbolTable.numDefinitions
-		instructions := c.leaveScope()
+		localNames := c.symbolTable.LocalNames()
+		instructions, lines := c.leaveScope()
 
 		for _, s := range freeSymbols {
-			c.loadSymbol(s)
+			c.loadCapture(s)
 		}
 
 		compiledFn := &object.CompiledFunction{
 			Instructions:  instructions,
+			Lines:         lines,
 			NumLocals:     numLocals,
+			LocalNames:    localNames,
 			NumParameters: len(node.Parameters),
+			NumDefaults:   numDefaults,
+			Variadic:      node.Rest,
+			ClassName:     c.className,
 		}
 		fnIndex := c.addConstant(compiledFn)
 		c.emit(code.OpClosure, fnIndex, len(freeSymbols))
@@ -659,7 +849,10 @@ case *ast.IfExpression:
 		c.emit(code.OpTypeCast, typeConstIndex)
 
 	case *ast.CallExpression:
-		err := c.Compile(node.Function)
+		exits, root := c.enterChain()
+		defer c.leaveChain(exits, root)
+
+		err := c.compileChainObject(node.Function, exits)
 		if err != nil {
 			return err
 		}
@@ -697,11 +890,35 @@ case *ast.IfExpression:
 	return nil
 }
 
+// CompileReader parses source read from r and compiles it. The source is
+// tokenized as it is read rather than loaded whole, so it may come from a
+// pipe or be larger than would comfortably fit in memory as text.
+func (c *Compiler) CompileReader(r io.Reader) error {
+	rr, ok := r.(io.RuneReader)
+	if !ok {
+		rr = bufio.NewReader(r)
+	}
+	l := lexer.NewReader(rr)
+	l.SetFile(c.file)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if err := l.Err(); err != nil {
+		return fmt.Errorf("error reading source: %v", err)
+	}
+	if len(p.Errors()) > 0 {
+		return fmt.Errorf("parser errors: %v", p.Errors())
+	}
+	return c.Compile(program)
+}
+
 // Bytecode returns the compiled bytecode
 func (c *Compiler) Bytecode() *Bytecode {
+	instructions, lines := peephole(c.currentInstructions(), c.currentLines())
 	return &Bytecode{
-		Instructions: c.currentInstructions(),
+		Instructions: instructions,
 		Constants:    c.constants,
+		Lines:        lines,
+		Globals:      c.symbolTable.GlobalNames(),
 	}
 }
 
@@ -719,6 +936,51 @@ func (c *Compiler) emit(op code.Opcode, operands ...int) int {
 	return pos
 }
 
+// SetFile names the file being compiled. Compile errors, and the line
+// table the VM uses to place runtime errors, then refer to it; imported
+// modules are named by their import path.
+func (c *Compiler) SetFile(name string) {
+	c.file = name
+}
+
+// locate attaches the file and line of stmt to an error from compiling it,
+// unless a statement nested inside it was already blamed
+func (c *Compiler) locate(stmt ast.Statement, err error) error {
+	if c.file == "" {
+		return err
+	}
+	if _, ok := err.(*CompileError); ok {
+		return err
+	}
+	return &CompileError{File: c.file, Line: ast.Line(stmt), Err: err}
+}
+
+// markLine records that the instructions emitted from now on in the
+// current scope come from the given source line
+func (c *Compiler) markLine(line int) {
+	if line <= 0 {
+		return
+	}
+	scope := &c.scopes[c.scopeIndex]
+	offset := len(scope.instructions)
+	if n := len(scope.lines); n > 0 {
+		last := &scope.lines[n-1]
+		if last.Line == line && last.File == c.file {
+			return
+		}
+		if last.Offset >= offset {
+			last.Offset, last.Line, last.File = offset, line, c.file
+			return
+		}
+	}
+	scope.lines = append(scope.lines, object.LineInfo{Offset: offset, Line: line, File: c.file})
+}
+
+// currentLines returns the line table of the current scope
+func (c *Compiler) currentLines() []object.LineInfo {
+	return c.scopes[c.scopeIndex].lines
+}
+
 func (c *Compiler) addInstruction(ins []byte) int {
 	posNewInstruction := len(c.currentInstructions())
 	updatedInstructions := append(c.currentInstructions(), ins...)
@@ -785,15 +1047,24 @@ func (c *Compiler) enterScope() {
 	c.symbolTable = NewEnclosedSymbolTable(c.symbolTable)
 }
 
-func (c *Compiler) leaveScope() code.Instructions {
-	instructions := c.currentInstructions()
+// enterFunction enters the scope of a function with the given body,
+// whose variables the resolver found closures assign are kept in cells
+func (c *Compiler) enterFunction(body *ast.BlockStatement) {
+	c.enterScope()
+	c.symbolTable.boxed = c.boxed[body]
+}
+
+// leaveScope returns to the enclosing scope, returning the finished
+// instructions of the one left and their line table
+func (c *Compiler) leaveScope() (code.Instructions, []object.LineInfo) {
+	instructions, lines := peephole(c.currentInstructions(), c.currentLines())
 
 	c.scopes = c.scopes[:len(c.scopes)-1]
 	c.scopeIndex--
 
 	c.symbolTable = c.symbolTable.Outer
 
-	return instructions
+	return instructions, lines
 }
 
 func (c *Compiler) replaceLastPopWithReturn() {
@@ -816,13 +1087,33 @@ func (c *Compiler) loadSymbol(s Symbol) {
 	case FunctionScope:
 		c.emit(code.OpCurrentClosure)
 	}
+	if s.Boxed {
+		c.emit(code.OpGetCell)
+	}
+}
+
+// loadCapture pushes a variable for a closure being made to capture: the
+// cell of a boxed variable rather than its value, so the closure shares it
+func (c *Compiler) loadCapture(s Symbol) {
+	s.Boxed = false
+	c.loadSymbol(s)
 }
 
 // DefaultModuleLoader loads modules from the filesystem
 // Supports both .ভাষা (Bengali) and .bhasa extensions
 func DefaultModuleLoader(modulePath string) (string, error) {
+	fullPath, ok := findModule(modulePath)
+	if !ok {
+		return "", fmt.Errorf("module not found: %s (tried .ভাষা and .bhasa extensions in current dir and modules/ dir)", modulePath)
+	}
+	return readModule(fullPath)
+}
+
+// findModule returns the file a module path names, trying each extension
+// in the current directory and then in modules/
+func findModule(modulePath string) (string, bool) {
 	// Try different file extensions
-	extensions := []string{".ভাষা", ".bhasa"}
+	extensions := []string{".ভাষা", ".bhasa", ".compiled", ".সংকলিত"}
 	
 	// Try different search paths
 	searchPaths := []string{
@@ -830,8 +1121,6 @@ func DefaultModuleLoader(modulePath string) (string, error) {
 		"modules/" + modulePath, // modules directory
 	}
 	
-	var fullPath string
-	
 	for _, basePath := range searchPaths {
 		for _, ext := range extensions {
 			// Try with extension if not already present
@@ -842,28 +1131,53 @@ func DefaultModuleLoader(modulePath string) (string, error) {
 			
 			// Check if file exists
 			if _, statErr := os.Stat(testPath); statErr == nil {
-				fullPath = testPath
-				break
+				return testPath, true
 			}
 		}
-		if fullPath != "" {
-			break
-		}
-	}
-	
-	if fullPath == "" {
-		return "", fmt.Errorf("module not found: %s (tried .ভাষা and .bhasa extensions in current dir and modules/ dir)", modulePath)
 	}
-	
-	// Read the file
+	return "", false
+}
+
+func readModule(fullPath string) (string, error) {
 	content, err := os.ReadFile(fullPath)
 	if err != nil {
 		return "", fmt.Errorf("error reading module %s: %v", fullPath, err)
 	}
-	
 	return string(content), nil
 }
 
+// DirModuleLoader returns a loader that resolves modules relative to dir
+// (and dir/modules) before falling back to DefaultModuleLoader
+func DirModuleLoader(dir string) ModuleLoader {
+	return func(modulePath string) (string, error) {
+		fullPath, err := FindModule(dir, modulePath)
+		if err != nil {
+			return "", err
+		}
+		return readModule(fullPath)
+	}
+}
+
+// FindModule returns the file DirModuleLoader(dir) loads for a module path
+func FindModule(dir, modulePath string) (string, error) {
+	if !filepath.IsAbs(modulePath) {
+		for _, base := range []string{filepath.Join(dir, modulePath), filepath.Join(dir, "modules", modulePath)} {
+			if fullPath, ok := findModule(base); ok {
+				return fullPath, nil
+			}
+		}
+	}
+	if fullPath, ok := findModule(modulePath); ok {
+		return fullPath, nil
+	}
+	return "", fmt.Errorf("module not found: %s (tried .ভাষা and .bhasa extensions in current dir and modules/ dir)", modulePath)
+}
+
+// SetModuleLoader replaces the function used to load imported modules
+func (c *Compiler) SetModuleLoader(loader ModuleLoader) {
+	c.moduleLoader = loader
+}
+
 // LoadAndCompileModule loads a module file, parses it, and compiles it
 func (c *Compiler) LoadAndCompileModule(modulePath string) error {
 	// Load module source code first (this will search in multiple locations)
@@ -880,17 +1194,30 @@ func (c *Compiler) LoadAndCompileModule(modulePath string) error {
 	
 	// Mark as being loaded
 	c.moduleCache[modulePath] = true
+
+	// A pre-compiled module is linked in instead of being compiled
+	if HasMagicNumber([]byte(source)) {
+		module, err := Deserialize(strings.NewReader(source))
+		if err != nil {
+			return fmt.Errorf("module %s: %v", modulePath, err)
+		}
+		return c.linkModule(module)
+	}
 	
 	// Parse the module
 	l := lexer.New(source)
+	l.SetFile(modulePath)
 	p := parser.New(l)
 	program := p.ParseProgram()
 	
 	if len(p.Errors()) > 0 {
-		return fmt.Errorf("parser errors in module %s: %v", modulePath, p.Errors())
+		return fmt.Errorf("parser errors in module %s:\n\t%s", modulePath, strings.Join(p.Errors(), "\n\t"))
 	}
 	
-	// Compile the module
+	// Compile the module, blaming its own file for errors
+	outerFile := c.file
+	c.file = modulePath
+	defer func() { c.file = outerFile }()
 	return c.Compile(program)
 }
 
@@ -908,7 +1235,7 @@ func (c *Compiler) compileClassDefinition(node *ast.ClassDefinition) error {
 		Interfaces:   []*object.Interface{},
 		Fields:       make(map[string]string),
 		Methods:      make(map[string]*object.Method),
-		Constructor:  nil,
+		Constructors: nil,
 		StaticFields: make(map[string]object.Object),
 		IsAbstract:   node.IsAbstract,
 		IsFinal:      node.IsFinal,
@@ -916,36 +1243,57 @@ func (c *Compiler) compileClassDefinition(node *ast.ClassDefinition) error {
 		FieldOrder:   []string{},
 	}
 
-	// Process fields
+	// A global class is named before its methods are compiled so they can
+	// create instances of it; the global is set before any method runs
+	var symbol Symbol
+	if c.symbolTable.Outer == nil {
+		symbol = c.symbolTable.DefineClass(node.Name.Value)
+	}
+
+	// Code in the class body may use its private members
+	outerClassName := c.className
+	c.className = node.Name.Value
+	defer func() { c.className = outerClassName }()
+
+	// Process fields; static fields belong to the class rather than to its
+	// instances and start out null until their initializers run
 	for _, field := range node.Fields {
+		if field.IsStatic {
+			class.StaticFields[field.Name] = nil
+			class.FieldAccess[field.Name] = string(field.Access)
+			continue
+		}
 		class.Fields[field.Name] = field.TypeAnnot.String()
 		class.FieldAccess[field.Name] = string(field.Access)
 		class.FieldOrder = append(class.FieldOrder, field.Name)
 	}
 
-	// Compile constructor
-	if len(node.Constructors) > 0 {
-		// Use the first constructor (in simple implementation)
-		constructor := node.Constructors[0]
-		
+	// Compile constructors; a class may have several as long as each takes
+	// a different number of arguments, which the resolver has checked
+	for _, constructor := range node.Constructors {
 		// Compile constructor as a function
-		c.enterScope()
-		
+		c.enterFunction(constructor.Body)
+
 		// Define 'this' parameter
 		c.symbolTable.Define("এই")
-		
+
 		// Define constructor parameters
 		for _, param := range constructor.Parameters {
 			c.symbolTable.Define(param.Value)
 		}
-		
+		numDefaults, err := c.compileParameterDefaults(constructor.Parameters, constructor.Defaults)
+		if err != nil {
+			return err
+		}
+		c.boxParameters(constructor.Parameters)
+
 		// Compile constructor body
 		if constructor.Body != nil {
 			if err := c.Compile(constructor.Body); err != nil {
 				return err
 			}
 		}
-		
+
 		// Constructor should always return 'this' (এই)
 		// If there's no explicit return, add implicit return of 'this'
 		if !c.lastInstructionIs(code.OpReturnValue) {
@@ -953,32 +1301,38 @@ func (c *Compiler) compileClassDefinition(node *ast.ClassDefinition) error {
 			c.emit(code.OpGetLocal, 0)
 			c.emit(code.OpReturnValue)
 		}
-		
+
 		freeSymbols := c.symbolTable.FreeSymbols
 		numLocals := c.symbolTable.numDefinitions
-		instructions := c.leaveScope()
-		
+		localNames := c.symbolTable.LocalNames()
+		instructions, lines := c.leaveScope()
+
 		compiledFn := &object.CompiledFunction{
 			Instructions:  instructions,
+			Lines:         lines,
 			NumLocals:     numLocals,
+			LocalNames:    localNames,
 			NumParameters: len(constructor.Parameters) + 1, // +1 for 'this'
+			NumDefaults:   numDefaults,
+			Variadic:      constructor.Rest,
+			ClassName:     c.className,
 		}
-		
+
 		fnIndex := c.addConstant(compiledFn)
-		
+
 		for _, s := range freeSymbols {
-			c.loadSymbol(s)
+			c.loadCapture(s)
 		}
-		
-		class.Constructor = &object.Closure{
+
+		class.Constructors = append(class.Constructors, &object.Closure{
 			Fn:   compiledFn,
 			Free: make([]object.Object, len(freeSymbols)),
-		}
-		
+		})
+
 		c.emit(code.OpClosure, fnIndex, len(freeSymbols))
 		c.emit(code.OpDefineConstructor, fnIndex)
 	}
-	
+
 	// Compile methods
 	for _, method := range node.Methods {
 		// Skip abstract methods (no body)
@@ -987,15 +1341,22 @@ func (c *Compiler) compileClassDefinition(node *ast.ClassDefinition) error {
 		}
 		
 		// Compile method as a function
-		c.enterScope()
+		c.enterFunction(method.Body)
 		
-		// Define 'this' parameter
-		c.symbolTable.Define("এই")
+		// Define 'this' parameter; static methods are called without one
+		if !method.IsStatic {
+			c.symbolTable.Define("এই")
+		}
 		
 		// Define method parameters
 		for _, param := range method.Parameters {
 			c.symbolTable.Define(param.Value)
 		}
+		numDefaults, err := c.compileParameterDefaults(method.Parameters, method.Defaults)
+		if err != nil {
+			return err
+		}
+		c.boxParameters(method.Parameters)
 		
 		// Compile method body
 		if method.Body != nil {
@@ -1012,18 +1373,27 @@ func (c *Compiler) compileClassDefinition(node *ast.ClassDefinition) error {
 		
 		freeSymbols := c.symbolTable.FreeSymbols
 		numLocals := c.symbolTable.numDefinitions
-		instructions := c.leaveScope()
+		localNames := c.symbolTable.LocalNames()
+		instructions, lines := c.leaveScope()
 		
 		compiledFn := &object.CompiledFunction{
 			Instructions:  instructions,
+			Lines:         lines,
 			NumLocals:     numLocals,
-			NumParameters: len(method.Parameters) + 1, // +1 for 'this'
+			LocalNames:    localNames,
+			NumParameters: len(method.Parameters),
+			NumDefaults:   numDefaults,
+			Variadic:      method.Rest,
+			ClassName:     c.className,
+		}
+		if !method.IsStatic {
+			compiledFn.NumParameters++ // for 'this'
 		}
 		
 		fnIndex := c.addConstant(compiledFn)
 		
 		for _, s := range freeSymbols {
-			c.loadSymbol(s)
+			c.loadCapture(s)
 		}
 		
 		closure := &object.Closure{
@@ -1054,16 +1424,143 @@ func (c *Compiler) compileClassDefinition(node *ast.ClassDefinition) error {
 	c.emit(code.OpClass, classIndex)
 	
 	// Define class in symbol table
-	symbol := c.symbolTable.Define(node.Name.Value)
+	if c.symbolTable.Outer != nil {
+		symbol = c.symbolTable.DefineClass(node.Name.Value)
+	}
 	if symbol.Scope == GlobalScope {
 		c.emit(code.OpSetGlobal, symbol.Index)
 	} else {
 		c.emit(code.OpSetLocal, symbol.Index)
 	}
+
+	return c.compileStaticInitializer(node)
+}
+
+// compileParameterDefaults emits the start of a function whose parameters
+// have default values: each parameter the caller left out is assigned its
+// default, which may use the parameters before it. It returns how many
+// parameters have defaults.
+func (c *Compiler) compileParameterDefaults(params []*ast.Identifier, defaults []ast.Expression) (int, error) {
+	numDefaults := 0
+	for i, value := range defaults {
+		if value == nil {
+			continue
+		}
+		numDefaults++
+		symbol, _ := c.symbolTable.Resolve(params[i].Value)
+		c.emit(code.OpArgMissing, symbol.Index)
+		jumpPos := c.emit(code.OpJumpNotTruthy, 9999)
+		if err := c.Compile(value); err != nil {
+			return 0, err
+		}
+		c.emit(code.OpSetLocal, symbol.Index)
+		c.changeOperand(jumpPos, len(c.currentInstructions()))
+	}
+	return numDefaults, nil
+}
+
+// boxParameters moves the parameters that closures assign into cells,
+// once any defaults have been filled in
+func (c *Compiler) boxParameters(params []*ast.Identifier) {
+	for _, param := range params {
+		symbol, _ := c.symbolTable.Resolve(param.Value)
+		if symbol = c.symbolTable.box(symbol); symbol.Boxed {
+			c.emit(code.OpGetLocal, symbol.Index)
+			c.emit(code.OpNewCell)
+			c.emit(code.OpSetLocal, symbol.Index)
+		}
+	}
+}
+
+// compileStaticInitializer assigns the initial values of a class's static
+// fields in order, once the class exists. They run in a function of their
+// own, called right away, so that they count as code inside the class and
+// may use its private members.
+func (c *Compiler) compileStaticInitializer(node *ast.ClassDefinition) error {
+	var fields []*ast.ClassField
+	for _, field := range node.Fields {
+		if field.IsStatic && field.Value != nil {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	c.enterScope()
+	for _, field := range fields {
+		symbol, _ := c.symbolTable.Resolve(node.Name.Value)
+		c.loadSymbol(symbol)
+		if err := c.Compile(field.Value); err != nil {
+			return err
+		}
+		c.emit(code.OpSetStatic, c.addConstant(&object.String{Value: field.Name}))
+	}
+	c.emit(code.OpReturn)
+
+	freeSymbols := c.symbolTable.FreeSymbols
+	numLocals := c.symbolTable.numDefinitions
+	localNames := c.symbolTable.LocalNames()
+	instructions, lines := c.leaveScope()
+
+	fnIndex := c.addConstant(&object.CompiledFunction{
+		Instructions: instructions,
+		Lines:        lines,
+		NumLocals:    numLocals,
+		LocalNames:   localNames,
+		ClassName:    node.Name.Value,
+	})
+	for _, s := range freeSymbols {
+		c.loadCapture(s)
+	}
+	c.emit(code.OpClosure, fnIndex, len(freeSymbols))
+	c.emit(code.OpCall, 0)
+	c.emit(code.OpPop)
 	
 	return nil
 }
 
+// isClassName reports whether expr is an identifier naming a class
+func (c *Compiler) isClassName(expr ast.Expression) bool {
+	ident, ok := expr.(*ast.Identifier)
+	return ok && c.resolved[ident].class
+}
+
+// enterChain starts compiling a member access, call, index or slice. An
+// expression is the root of its chain unless it is the object of the one
+// being compiled; the root owns the jumps that a?. takes past the rest of
+// the chain when a is null.
+func (c *Compiler) enterChain() (exits *[]int, root bool) {
+	exits, c.chain = c.chain, nil
+	if exits == nil {
+		exits, root = &[]int{}, true
+	}
+	return exits, root
+}
+
+// compileChainObject compiles the object of a chain link, continuing the
+// chain into it when it is a link itself
+func (c *Compiler) compileChainObject(object ast.Expression, exits *[]int) error {
+	switch object.(type) {
+	case *ast.MemberAccessExpression, *ast.CallExpression, *ast.IndexExpression, *ast.SliceExpression:
+		c.chain = exits
+	}
+	err := c.Compile(object)
+	c.chain = nil
+	return err
+}
+
+// leaveChain points the jumps out of a chain past its end once its root
+// has been compiled
+func (c *Compiler) leaveChain(exits *[]int, root bool) {
+	if !root {
+		return
+	}
+	for _, pos := range *exits {
+		c.changeOperand(pos, len(c.currentInstructions()))
+	}
+}
+
 // compileInterfaceDefinition compiles an interface definition
 func (c *Compiler) compileInterfaceDefinition(node *ast.InterfaceDefinition) error {
 	// Create interface object
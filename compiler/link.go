@@ -0,0 +1,107 @@
+package compiler
+
+import (
+	"bhasa/code"
+	"bhasa/object"
+	"fmt"
+)
+
+// constantOperandOps are the opcodes whose first operand is an index into
+// the constant pool
+var constantOperandOps = map[code.Opcode]bool{
+	code.OpConstant:          true,
+	code.OpClosure:           true,
+	code.OpTypeCheck:         true,
+	code.OpTypeCast:          true,
+	code.OpAssertType:        true,
+	code.OpClass:             true,
+	code.OpDefineMethod:      true,
+	code.OpDefineConstructor: true,
+	code.OpInterface:         true,
+	code.OpCheckInterface:    true,
+	code.OpInherit:           true,
+	code.OpGetStatic:         true,
+	code.OpSetStatic:         true,
+	code.OpAddConstant:       true,
+}
+
+// linkModule adds a pre-compiled module to the program being compiled, as
+// if its source had been compiled in place: its constants are appended to
+// the constant pool, its globals are defined under their own names and its
+// main instructions are emitted at the current position.
+func (c *Compiler) linkModule(module *Bytecode) error {
+	if c.symbolTable.Outer != nil {
+		return fmt.Errorf("a pre-compiled module can only be imported at the top level")
+	}
+	if len(module.Globals) == 0 && len(module.Instructions) > 0 {
+		return fmt.Errorf("the module was compiled without global names; rebuild it")
+	}
+
+	globals := make([]int, len(module.Globals))
+	for i, name := range module.Globals {
+		if name == "" {
+			globals[i] = c.symbolTable.Reserve()
+		} else {
+			globals[i] = c.symbolTable.Define(name).Index
+		}
+	}
+
+	constOffset := len(c.constants)
+	for _, constant := range module.Constants {
+		if fn, ok := constant.(*object.CompiledFunction); ok {
+			linked := *fn
+			ins, _, err := relocate(fn.Instructions, constOffset, globals, 0)
+			if err != nil {
+				return err
+			}
+			linked.Instructions = ins
+			constant = &linked
+		}
+		c.addConstant(constant)
+	}
+
+	start := len(c.currentInstructions())
+	ins, last, err := relocate(module.Instructions, constOffset, globals, start)
+	if err != nil {
+		return err
+	}
+	if len(ins) == 0 {
+		return nil
+	}
+	c.addInstruction(ins)
+	c.setLastInstruction(code.Opcode(ins[last]), start+last)
+	return nil
+}
+
+// relocate copies instructions, shifting constant indices by constOffset,
+// renumbering globals through the globals table and moving jump targets by
+// jumpOffset. It also returns the offset of the last instruction.
+func relocate(ins code.Instructions, constOffset int, globals []int, jumpOffset int) (code.Instructions, int, error) {
+	out := make(code.Instructions, 0, len(ins))
+	last := 0
+	for i := 0; i < len(ins); {
+		op := code.Opcode(ins[i])
+		def, err := code.Lookup(ins[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		operands, read := code.ReadOperands(def, ins[i+1:])
+
+		switch {
+		case constantOperandOps[op]:
+			operands[0] += constOffset
+		case op == code.OpGetGlobal || op == code.OpSetGlobal:
+			if operands[0] >= len(globals) {
+				return nil, 0, fmt.Errorf("global %d out of range (%d globals)", operands[0], len(globals))
+			}
+			operands[0] = globals[operands[0]]
+		case jumpOperand(op) >= 0:
+			operands[jumpOperand(op)] += jumpOffset
+		}
+
+		last = len(out)
+		out = append(out, code.Make(op, operands...)...)
+		i += 1 + read
+	}
+	return out, last, nil
+}
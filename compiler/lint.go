@@ -0,0 +1,146 @@
+package compiler
+
+import (
+	"bhasa/ast"
+	"fmt"
+)
+
+// Warnings returns the problems found in the last program compiled that
+// do not stop it from compiling, such as a loop that can never end
+func (c *Compiler) Warnings() []error {
+	return c.warnings
+}
+
+// warnf records a warning at the line of node
+func (c *Compiler) warnf(node ast.Node, format string, a ...interface{}) {
+	err := fmt.Errorf(format, a...)
+	if c.file != "" {
+		err = &CompileError{File: c.file, Line: ast.Line(node), Column: ast.NodeToken(node).Column, Err: err}
+	}
+	c.warnings = append(c.warnings, err)
+}
+
+// lintLoops warns about loops that plainly never end, which otherwise
+// just hang the program: a loop whose condition is সত্য or missing and
+// that has no বিরতি or ফেরত, and a পর্যন্ত loop whose counter is changed
+// neither by its increment nor by its body. It runs before desugaring, on
+// the loops as they were written.
+func (c *Compiler) lintLoops(program *ast.Program) {
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.WhileStatement:
+			if isTrueLiteral(n.Condition) && !leavesLoop(n.Body, false) {
+				c.warnf(n, "this যতক্ষণ loop never ends: its condition is always সত্য and it has no বিরতি or ফেরত")
+			}
+		case *ast.ForStatement:
+			if (n.Condition == nil || isTrueLiteral(n.Condition)) && !leavesLoop(n.Body, false) {
+				c.warnf(n, "this পর্যন্ত loop never ends: it has no condition that can fail and no বিরতি or ফেরত")
+			} else if name, stuck := stuckCounter(n); stuck {
+				c.warnf(n, "this পর্যন্ত loop never ends: %s in its condition is not changed by the increment or the body", name)
+			}
+		}
+		return true
+	})
+}
+
+// isTrueLiteral reports whether expr is the literal সত্য
+func isTrueLiteral(expr ast.Expression) bool {
+	b, ok := expr.(*ast.Boolean)
+	return ok && b.Value
+}
+
+// leavesLoop reports whether a loop body can leave the loop: it contains a
+// ফেরত, or a বিরতি belonging to the loop rather than to a loop nested in
+// it. In a nested loop (nested set) only ফেরত counts. Functions and
+// classes defined in the body are skipped, as their ফেরত leaves them only.
+func leavesLoop(body ast.Node, nested bool) bool {
+	leaves := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		if leaves {
+			return false
+		}
+		switch n := node.(type) {
+		case *ast.FunctionLiteral, *ast.ClassDefinition:
+			return false
+		case *ast.ReturnStatement:
+			leaves = true
+		case *ast.BreakStatement:
+			leaves = !nested
+		case *ast.WhileStatement:
+			leaves = leavesLoop(n.Body, true)
+			return false
+		case *ast.ForStatement:
+			leaves = leavesLoop(n.Body, true)
+			return false
+		case *ast.ForEachStatement:
+			leaves = leavesLoop(n.Body, true)
+			return false
+		}
+		return true
+	})
+	return leaves
+}
+
+// stuckCounter finds a পর্যন্ত loop whose condition tests the counter its
+// initializer sets up, while nothing in the loop assigns the counter or
+// any other name in the condition. Loops that might change those names
+// some other way (a call in the condition, a call in the body when the
+// condition reads more than the counter, a function or class defined in
+// the body) or that can be left with বিরতি or ফেরত are not reported.
+func stuckCounter(loop *ast.ForStatement) (string, bool) {
+	var counter string
+	switch init := loop.Init.(type) {
+	case *ast.LetStatement:
+		counter = init.Name.Value
+	case *ast.AssignmentStatement:
+		counter = init.Name.Value
+	default:
+		return "", false
+	}
+
+	names := map[string]bool{}
+	pure := true
+	ast.Inspect(loop.Condition, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.Identifier:
+			names[n.Value] = true
+		case *ast.InfixExpression, *ast.PrefixExpression, *ast.IntegerLiteral, *ast.FloatLiteral,
+			*ast.StringLiteral, *ast.Boolean, nil:
+		default:
+			pure = false
+		}
+		return true
+	})
+	if !pure || !names[counter] || leavesLoop(loop.Body, false) {
+		return "", false
+	}
+
+	changed, calls := false, false
+	for _, part := range []ast.Node{loop.Increment, loop.Body} {
+		ast.Inspect(part, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.FunctionLiteral, *ast.ClassDefinition:
+				changed = true // a closure might assign the counter
+			case *ast.CallExpression, *ast.MethodCallExpression, *ast.NewExpression:
+				calls = true
+			case *ast.AssignmentStatement:
+				changed = changed || names[n.Name.Value]
+			case *ast.LetStatement:
+				changed = changed || names[n.Name.Value]
+			case *ast.DestructuringLetStatement:
+				for _, name := range n.Names {
+					changed = changed || names[name.Value]
+				}
+			case *ast.CompoundAssignmentStatement:
+				if ident, ok := n.Target.(*ast.Identifier); ok {
+					changed = changed || names[ident.Value]
+				}
+			}
+			return !changed
+		})
+	}
+	if changed || (calls && len(names) > 1) {
+		return "", false
+	}
+	return counter, true
+}
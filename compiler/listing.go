@@ -0,0 +1,71 @@
+package compiler
+
+import (
+	"bhasa/code"
+	"bhasa/object"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteListing writes a combined source/disassembly listing: each source
+// line is followed by the bytecode compiled from it. Compiled functions are
+// listed after the main program, each in its own section.
+func WriteListing(w io.Writer, source string, bytecode *Bytecode) error {
+	sourceLines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+
+	var sb strings.Builder
+	sb.WriteString("== main ==\n")
+	writeListingSection(&sb, sourceLines, bytecode.Instructions, bytecode.Lines)
+
+	for i, constant := range bytecode.Constants {
+		fn, ok := constant.(*object.CompiledFunction)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n== constant %d: function (params=%d, locals=%d) ==\n",
+			i, fn.NumParameters, fn.NumLocals)
+		writeListingSection(&sb, sourceLines, fn.Instructions, fn.Lines)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Disassemble renders the main instructions followed by the constant pool
+// from index first on, including the instructions of every compiled
+// function. A REPL passes the size of the pool before its last input to
+// show only what that input added.
+func (b *Bytecode) Disassemble(first int) string {
+	var sb strings.Builder
+	sb.WriteString("== main ==\n")
+	sb.WriteString(b.Instructions.String())
+	for i := first; i < len(b.Constants); i++ {
+		c := b.Constants[i]
+		fn, ok := c.(*object.CompiledFunction)
+		if !ok {
+			fmt.Fprintf(&sb, "\nconstant %d: %s %s\n", i, c.Type(), c.Inspect())
+			continue
+		}
+		fmt.Fprintf(&sb, "\n== constant %d: function (params=%d, locals=%d) ==\n",
+			i, fn.NumParameters, fn.NumLocals)
+		sb.WriteString(code.Instructions(fn.Instructions).String())
+	}
+	return sb.String()
+}
+
+// writeListingSection interleaves one instruction stream with its source
+func writeListingSection(sb *strings.Builder, sourceLines []string, ins code.Instructions, lines []object.LineInfo) {
+	lastLine := -1
+	for i := 0; i < len(ins); {
+		line := object.LineForOffset(lines, i)
+		if line != lastLine && line > 0 && line <= len(sourceLines) {
+			fmt.Fprintf(sb, "%5d | %s\n", line, strings.TrimRight(sourceLines[line-1], " \t"))
+		}
+		lastLine = line
+
+		text, next := ins.FormatAt(i)
+		fmt.Fprintf(sb, "      |     %04d %s\n", i, text)
+		i = next
+	}
+}
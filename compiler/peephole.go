@@ -0,0 +1,128 @@
+package compiler
+
+import (
+	"bhasa/code"
+	"bhasa/object"
+)
+
+// comparisonOps are the opcodes OpCompareJump can make
+var comparisonOps = map[code.Opcode]bool{
+	code.OpEqual:            true,
+	code.OpNotEqual:         true,
+	code.OpGreaterThan:      true,
+	code.OpGreaterThanEqual: true,
+	code.OpLessThan:         true,
+	code.OpLessThanEqual:    true,
+}
+
+// jumpOperand returns which operand of an instruction is a jump target,
+// or -1 when it does not jump
+func jumpOperand(op code.Opcode) int {
+	switch op {
+	case code.OpJump, code.OpJumpNotTruthy:
+		return 0
+	case code.OpCompareJump:
+		return 1
+	}
+	return -1
+}
+
+// fusePair returns the superinstruction doing the work of two
+// instructions, if there is one
+func fusePair(first code.Opcode, firstOperands []int, second code.Opcode, secondOperands []int) ([]byte, bool) {
+	switch {
+	case comparisonOps[first] && second == code.OpJumpNotTruthy:
+		return code.Make(code.OpCompareJump, int(first), secondOperands[0]), true
+	case first == code.OpGetLocal && second == code.OpGetLocal:
+		return code.Make(code.OpGetLocals, firstOperands[0], secondOperands[0]), true
+	case first == code.OpConstant && second == code.OpAdd:
+		return code.Make(code.OpAddConstant, firstOperands[0]), true
+	case first == code.OpGetLocal && second == code.OpAdd:
+		return code.Make(code.OpGetLocalAdd, firstOperands[0]), true
+	}
+	return nil, false
+}
+
+// peephole replaces pairs of instructions that loops run over and over,
+// such as a comparison and the jump on its result, with superinstructions
+// that do both in one dispatch. A pair is only fused when nothing jumps to
+// its second instruction and a new source line does not start there, so
+// jump targets and line table entries stay at instruction boundaries and
+// only move to the instructions' new offsets. Streams it cannot decode are
+// returned as they are, for the verifier to report.
+func peephole(ins code.Instructions, lines []object.LineInfo) (code.Instructions, []object.LineInfo) {
+	boundaries := map[int]bool{len(ins): true}
+	entries := map[int]bool{} // offsets control can arrive at other than by falling through
+	for _, l := range lines {
+		entries[l.Offset] = true
+	}
+	for i := 0; i < len(ins); {
+		boundaries[i] = true
+		op, operands, next, ok := decodeAt(ins, i)
+		if !ok {
+			return ins, lines
+		}
+		if j := jumpOperand(op); j >= 0 {
+			entries[operands[j]] = true
+		}
+		i = next
+	}
+	for target := range entries {
+		if !boundaries[target] {
+			return ins, lines
+		}
+	}
+
+	out := make(code.Instructions, 0, len(ins))
+	moved := map[int]int{} // new offset of each instruction
+	for i := 0; i < len(ins); {
+		op, operands, next, _ := decodeAt(ins, i)
+		moved[i] = len(out)
+		if next < len(ins) && !entries[next] {
+			secondOp, secondOperands, after, _ := decodeAt(ins, next)
+			if fused, ok := fusePair(op, operands, secondOp, secondOperands); ok {
+				out = append(out, fused...)
+				i = after
+				continue
+			}
+		}
+		out = append(out, ins[i:next]...)
+		i = next
+	}
+	moved[len(ins)] = len(out)
+
+	for i := 0; i < len(out); {
+		op, operands, next, _ := decodeAt(out, i)
+		if j := jumpOperand(op); j >= 0 {
+			operands[j] = moved[operands[j]]
+			copy(out[i:], code.Make(op, operands...))
+		}
+		i = next
+	}
+
+	var movedLines []object.LineInfo
+	for _, l := range lines {
+		l.Offset = moved[l.Offset]
+		movedLines = append(movedLines, l)
+	}
+	return out, movedLines
+}
+
+// decodeAt reads the instruction at offset i, returning its opcode,
+// operands and the offset after it; ok is false for an unknown or
+// truncated instruction
+func decodeAt(ins code.Instructions, i int) (op code.Opcode, operands []int, next int, ok bool) {
+	def, err := code.Lookup(ins[i])
+	if err != nil {
+		return 0, nil, 0, false
+	}
+	width := 0
+	for _, w := range def.OperandWidths {
+		width += w
+	}
+	if i+1+width > len(ins) {
+		return 0, nil, 0, false
+	}
+	operands, read := code.ReadOperands(def, ins[i+1:])
+	return code.Opcode(ins[i]), operands, i + 1 + read, true
+}
@@ -0,0 +1,435 @@
+package compiler
+
+import (
+	"bhasa/ast"
+	"bhasa/desugar"
+	"bhasa/lexer"
+	"bhasa/parser"
+	"fmt"
+	"strings"
+)
+
+// binding is what the resolver learned about the declaration a name
+// refers to
+type binding struct {
+	typ    *ast.TypeAnnotation // declared type, if any
+	class  bool                // names a class, so Name.member is static
+	decl   *ast.Identifier     // the name as declared, nil for builtins and REPL globals
+	module string              // import path of the module declaring it, "" for the program
+}
+
+// resolveScope holds the names declared in one function. Blocks do not
+// open a scope of their own, as in the symbol table.
+type resolveScope struct {
+	outer    *resolveScope
+	names    map[string]binding
+	captured map[string]bool // names used by a function nested in this one
+	assigned map[string]bool // names given a new value after being declared
+}
+
+func newResolveScope(outer *resolveScope) *resolveScope {
+	return &resolveScope{
+		outer:    outer,
+		names:    map[string]binding{},
+		captured: map[string]bool{},
+		assigned: map[string]bool{},
+	}
+}
+
+// resolver is the semantic pass run over a program before any code is
+// emitted. It follows the scoping of the emitter, reports every undefined
+// name and misplaced statement in the program at once, and records in
+// Compiler.resolved the binding each identifier refers to.
+type resolver struct {
+	c        *Compiler
+	scope    *resolveScope
+	loops    int // loops enclosing the current statement in its function
+	errs     ErrorList
+	imported map[string]bool
+	unknown  bool   // a module that could not be read was imported
+	module   string // import path of the module being resolved, "" for the program
+}
+
+// ErrorList is the compile errors found in a program, in source order
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, err := range l {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Check makes the checks Compile does before emitting any code, without
+// emitting it: it warns about loops that never end and reports undefined
+// names and misplaced statements. The warnings are left for Warnings.
+func (c *Compiler) Check(program *ast.Program) error {
+	c.lintLoops(program)
+	return c.resolve(desugar.Program(program))
+}
+
+// Definition is what Check learned about the declaration an identifier
+// refers to
+type Definition struct {
+	Name   *ast.Identifier     // the name as declared, nil for builtins and REPL globals
+	Module string              // import path of the module declaring it, "" for the program
+	Type   *ast.TypeAnnotation // declared type, if any
+	Class  bool                // names a class
+}
+
+// Definitions returns the declaration each identifier of the programs
+// checked or compiled so far refers to, declared names included. Names
+// only the desugarer introduced are left out.
+func (c *Compiler) Definitions() map[*ast.Identifier]Definition {
+	defs := make(map[*ast.Identifier]Definition, len(c.resolved))
+	for ident, b := range c.resolved {
+		if strings.HasPrefix(ident.Value, "#") {
+			continue
+		}
+		defs[ident] = Definition{Name: b.decl, Module: b.module, Type: b.typ, Class: b.class}
+	}
+	return defs
+}
+
+// resolve checks a desugared program and annotates its identifiers. It
+// returns the first error alone, or an ErrorList when there are several.
+func (c *Compiler) resolve(program *ast.Program) error {
+	r := &resolver{
+		c:        c,
+		scope:    newResolveScope(nil),
+		imported: map[string]bool{},
+	}
+	r.statements(program.Statements)
+
+	switch len(r.errs) {
+	case 0:
+		return nil
+	case 1:
+		return r.errs[0]
+	}
+	return r.errs
+}
+
+// errorf records an error at the line of node
+func (r *resolver) errorf(node ast.Node, format string, a ...interface{}) {
+	err := fmt.Errorf(format, a...)
+	if r.c.file != "" {
+		err = &CompileError{File: r.c.file, Line: ast.Line(node), Column: ast.NodeToken(node).Column, Err: err}
+	}
+	r.errs = append(r.errs, err)
+}
+
+// define declares a name in the current function
+func (r *resolver) define(name string, b binding) {
+	b.module = r.module
+	r.scope.names[name] = b
+}
+
+// declare declares the name of a let, parameter, class or interface and
+// records that it refers to itself
+func (r *resolver) declare(ident *ast.Identifier, b binding) {
+	b.decl, b.module = ident, r.module
+	r.scope.names[ident.Value] = b
+	r.c.resolved[ident] = b
+}
+
+// use resolves an identifier, falling back to the symbols the compiler
+// already knows: builtins, globals from earlier REPL input and the
+// variables of enclosing code when a module is imported in a function. It
+// returns the scope of the function declaring the name, if it was found
+// in the program, and notes there that the name was captured when it
+// belongs to an enclosing function.
+func (r *resolver) use(ident *ast.Identifier) *resolveScope {
+	for scope := r.scope; scope != nil; scope = scope.outer {
+		if b, ok := scope.names[ident.Value]; ok {
+			r.c.resolved[ident] = b
+			if scope != r.scope && scope.outer != nil {
+				scope.captured[ident.Value] = true
+			}
+			return scope
+		}
+	}
+	if symbol, ok := r.c.symbolTable.Lookup(ident.Value); ok {
+		r.c.resolved[ident] = binding{typ: symbol.TypeAnnot, class: symbol.IsClass}
+		return nil
+	}
+	if !r.unknown {
+		r.errorf(ident, "undefined variable %s", ident.Value)
+	}
+	return nil
+}
+
+// function resolves a function body in a scope of its own, where the
+// given names are already declared. The variables of the function that a
+// nested function uses and that are assigned anywhere are recorded in
+// Compiler.boxed, as they must be kept in cells to be shared.
+func (r *resolver) function(names []string, params []*ast.Identifier, defaults []ast.Expression, body *ast.BlockStatement) {
+	outerScope, outerLoops := r.scope, r.loops
+	scope := newResolveScope(outerScope)
+	r.scope = scope
+	r.loops = 0
+	defer func() {
+		r.scope, r.loops = outerScope, outerLoops
+		boxed := map[string]bool{}
+		for name := range scope.captured {
+			if scope.assigned[name] {
+				boxed[name] = true
+			}
+		}
+		if len(boxed) > 0 && body != nil {
+			r.c.boxed[body] = boxed
+		}
+	}()
+
+	for _, name := range names {
+		r.define(name, binding{})
+	}
+	for _, param := range params {
+		r.declare(param, binding{})
+	}
+	r.expressions(defaults)
+	if body != nil {
+		r.node(body)
+	}
+}
+
+func (r *resolver) statements(list []ast.Statement) {
+	for _, s := range list {
+		r.node(s)
+	}
+}
+
+func (r *resolver) expressions(list []ast.Expression) {
+	for _, e := range list {
+		if e != nil {
+			r.node(e)
+		}
+	}
+}
+
+func (r *resolver) node(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.Program:
+		r.statements(node.Statements)
+	case *ast.BlockStatement:
+		r.statements(node.Statements)
+	case *ast.ExpressionStatement:
+		r.expressions([]ast.Expression{node.Expression})
+	case *ast.ReturnStatement:
+		r.expressions([]ast.Expression{node.ReturnValue})
+
+	case *ast.LetStatement:
+		// The name is declared before its value is compiled, which is
+		// what lets a function refer to itself
+		r.declare(node.Name, binding{typ: node.TypeAnnot})
+		r.expressions([]ast.Expression{node.Value})
+
+	case *ast.DestructuringLetStatement:
+		if len(node.Names) > 255 {
+			r.errorf(node, "too many names to unpack: %d (at most 255)", len(node.Names))
+		}
+		seen := map[string]bool{}
+		for _, name := range node.Names {
+			if seen[name.Value] {
+				r.errorf(name, "%s is declared twice", name.Value)
+			}
+			seen[name.Value] = true
+		}
+		r.expressions([]ast.Expression{node.Value})
+		for _, name := range node.Names {
+			r.declare(name, binding{})
+		}
+
+	case *ast.AssignmentStatement:
+		if scope := r.use(node.Name); scope != nil {
+			scope.assigned[node.Name.Value] = true
+		}
+		r.expressions([]ast.Expression{node.Value})
+
+	case *ast.MemberAssignmentStatement:
+		r.expressions([]ast.Expression{node.Object, node.Value})
+
+	case *ast.IndexAssignmentStatement:
+		r.expressions([]ast.Expression{node.Left, node.Index, node.Value})
+
+	case *ast.WhileStatement:
+		r.expressions([]ast.Expression{node.Condition})
+		r.loops++
+		r.node(node.Body)
+		r.loops--
+
+	case *ast.ForStatement:
+		if node.Init != nil {
+			r.node(node.Init)
+		}
+		r.expressions([]ast.Expression{node.Condition})
+		r.loops++
+		r.node(node.Body)
+		r.loops--
+		if node.Increment != nil {
+			r.node(node.Increment)
+		}
+
+	case *ast.BreakStatement:
+		if r.loops == 0 {
+			r.errorf(node, "break statement outside loop")
+		}
+
+	case *ast.ContinueStatement:
+		if r.loops == 0 {
+			r.errorf(node, "continue statement outside loop")
+		}
+
+	case *ast.ImportStatement:
+		path, ok := node.Path.(*ast.StringLiteral)
+		if !ok {
+			r.errorf(node, "import path must be a string literal")
+			return
+		}
+		r.importModule(path.Value)
+
+	case *ast.Identifier:
+		r.use(node)
+
+	case *ast.PrefixExpression:
+		r.expressions([]ast.Expression{node.Right})
+	case *ast.InfixExpression:
+		r.expressions([]ast.Expression{node.Left, node.Right})
+	case *ast.IfExpression:
+		r.expressions([]ast.Expression{node.Condition})
+		r.node(node.Consequence)
+		if node.Alternative != nil {
+			r.node(node.Alternative)
+		}
+	case *ast.CallExpression:
+		r.expressions([]ast.Expression{node.Function})
+		r.expressions(node.Arguments)
+	case *ast.IndexExpression:
+		r.expressions([]ast.Expression{node.Left, node.Index})
+	case *ast.SliceExpression:
+		r.expressions([]ast.Expression{node.Left, node.Start, node.End})
+	case *ast.MemberAccessExpression:
+		r.expressions([]ast.Expression{node.Object})
+	case *ast.MethodCallExpression:
+		r.expressions([]ast.Expression{node.Object})
+		r.expressions(node.Arguments)
+	case *ast.NewExpression:
+		r.expressions(node.Arguments)
+		r.use(node.ClassName)
+	case *ast.TypeCastExpression:
+		r.expressions([]ast.Expression{node.Expression})
+
+	case *ast.ArrayLiteral:
+		r.expressions(node.Elements)
+	case *ast.TupleLiteral:
+		r.expressions(node.Elements)
+	case *ast.HashLiteral:
+		for _, key := range node.SortedKeys() {
+			r.expressions([]ast.Expression{key, node.Pairs[key]})
+		}
+	case *ast.StructLiteral:
+		for _, name := range node.FieldOrder {
+			r.expressions([]ast.Expression{node.Fields[name]})
+		}
+	case *ast.FunctionLiteral:
+		r.function(nil, node.Parameters, node.Defaults, node.Body)
+
+	case *ast.ClassDefinition:
+		r.class(node)
+	case *ast.InterfaceDefinition:
+		r.declare(node.Name, binding{})
+	}
+}
+
+// class resolves a class definition. A global class is declared before
+// its methods so they can create instances of it; a local one only after.
+func (r *resolver) class(node *ast.ClassDefinition) {
+	global := r.scope.outer == nil && r.c.symbolTable.Outer == nil
+	if global {
+		r.declare(node.Name, binding{class: true})
+	}
+
+	for _, field := range node.Fields {
+		if !field.IsStatic && field.Value != nil {
+			r.errorf(node, "field %s of class %s: only static fields can have an initial value", field.Name, node.Name.Value)
+		}
+	}
+
+	arities := map[int]bool{}
+	for _, ctor := range node.Constructors {
+		if arities[len(ctor.Parameters)] {
+			r.errorf(ctor, "class %s has more than one constructor with %d parameters", node.Name.Value, len(ctor.Parameters))
+		}
+		arities[len(ctor.Parameters)] = true
+		r.function([]string{"এই"}, ctor.Parameters, ctor.Defaults, ctor.Body)
+	}
+	for _, method := range node.Methods {
+		if method.IsAbstract {
+			continue
+		}
+		var names []string
+		if !method.IsStatic {
+			names = []string{"এই"}
+		}
+		r.function(names, method.Parameters, method.Defaults, method.Body)
+	}
+
+	if !global {
+		r.declare(node.Name, binding{class: true})
+	}
+
+	// Static field initializers run in a function of their own
+	var values []ast.Expression
+	for _, field := range node.Fields {
+		if field.IsStatic && field.Value != nil {
+			values = append(values, field.Value)
+		}
+	}
+	if len(values) > 0 {
+		r.function(nil, nil, values, nil)
+	}
+}
+
+// importModule declares the names a module defines where it is imported.
+// When a module cannot be loaded or parsed, any name might have come from
+// it, so undefined names are no longer reported; the emitter fails on the
+// import and says why.
+func (r *resolver) importModule(path string) {
+	if r.c.moduleCache[path] || r.imported[path] {
+		return
+	}
+	r.imported[path] = true
+
+	source, err := r.c.moduleLoader(path)
+	if err != nil {
+		r.unknown = true
+		return
+	}
+	if HasMagicNumber([]byte(source)) {
+		module, err := Deserialize(strings.NewReader(source))
+		if err != nil {
+			r.unknown = true
+			return
+		}
+		for _, name := range module.Globals {
+			if name != "" {
+				r.define(name, binding{})
+			}
+		}
+		return
+	}
+
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		r.unknown = true
+		return
+	}
+
+	// The module's own errors are reported when it is compiled
+	errs, file, module := r.errs, r.c.file, r.module
+	r.c.file, r.module = path, path
+	r.node(desugar.Program(program))
+	r.errs, r.c.file, r.module = errs, file, module
+}
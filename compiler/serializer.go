@@ -1,19 +1,26 @@
 package compiler
 
 import (
-	"bhasa/code"
 	"bhasa/object"
+	"bhasa/version"
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math/big"
 )
 
 // Magic number for Bhasa bytecode files: "BHASA" in hex
 const (
 	MagicNumber uint32 = 0x42484153 // "BHAS"
-	Version     uint32 = 1
+	Version     uint32 = version.BytecodeVersion
 )
 
+// HasMagicNumber reports whether data starts with the bytecode magic number
+func HasMagicNumber(data []byte) bool {
+	return len(data) >= 4 && binary.BigEndian.Uint32(data) == MagicNumber
+}
+
 // Serialize writes the bytecode to a writer in binary format
 func (b *Bytecode) Serialize(w io.Writer) error {
 	// Write magic number
@@ -50,6 +57,19 @@ func (b *Bytecode) Serialize(w io.Writer) error {
 		}
 	}
 
+	// Write global names, so the file can be imported as a module
+	if err := binary.Write(w, binary.BigEndian, uint32(len(b.Globals))); err != nil {
+		return fmt.Errorf("failed to write globals count: %w", err)
+	}
+	for _, name := range b.Globals {
+		if err := binary.Write(w, binary.BigEndian, uint32(len(name))); err != nil {
+			return fmt.Errorf("failed to write global name: %w", err)
+		}
+		if _, err := io.WriteString(w, name); err != nil {
+			return fmt.Errorf("failed to write global name: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -80,8 +100,8 @@ func Deserialize(r io.Reader) (*Bytecode, error) {
 	}
 
 	// Read instructions
-	instructions := make(code.Instructions, instructionsLen)
-	if _, err := io.ReadFull(r, instructions); err != nil {
+	instructions, err := readBytes(r, instructionsLen)
+	if err != nil {
 		return nil, fmt.Errorf("failed to read instructions: %w", err)
 	}
 
@@ -92,19 +112,72 @@ func Deserialize(r io.Reader) (*Bytecode, error) {
 	}
 
 	// Read each constant
-	constants := make([]object.Object, constantsCount)
+	constants := make([]object.Object, 0, preallocated(constantsCount))
 	for i := uint32(0); i < constantsCount; i++ {
 		constant, err := deserializeObject(r)
 		if err != nil {
 			return nil, fmt.Errorf("failed to deserialize constant %d: %w", i, err)
 		}
-		constants[i] = constant
+		constants = append(constants, constant)
+	}
+
+	// Read global names
+	var globalsCount uint32
+	if err := binary.Read(r, binary.BigEndian, &globalsCount); err != nil {
+		return nil, fmt.Errorf("failed to read globals count: %w", err)
+	}
+	globals := make([]string, 0, preallocated(globalsCount))
+	for i := uint32(0); i < globalsCount; i++ {
+		var nameLen uint32
+		if err := binary.Read(r, binary.BigEndian, &nameLen); err != nil {
+			return nil, fmt.Errorf("failed to read global name: %w", err)
+		}
+		name, err := readBytes(r, nameLen)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read global name: %w", err)
+		}
+		globals = append(globals, string(name))
 	}
 
-	return &Bytecode{
+	bytecode := &Bytecode{
 		Instructions: instructions,
 		Constants:    constants,
-	}, nil
+		Globals:      globals,
+	}
+
+	// Reject malformed files before they reach the VM
+	if err := bytecode.Verify(); err != nil {
+		return nil, err
+	}
+
+	return bytecode, nil
+}
+
+// maxPreallocated caps the room made for the items a count in a file
+// announces; a list that really is longer grows as it is read
+const maxPreallocated = 1024
+
+// preallocated returns how many items to make room for given a count read
+// from a file, which may be corrupted
+func preallocated(count uint32) int {
+	if count > maxPreallocated {
+		return maxPreallocated
+	}
+	return int(count)
+}
+
+// readBytes reads n bytes whose length was read from a file. The buffer
+// grows as the bytes arrive, so a corrupted length fails at the end of
+// the input rather than allocating all of it up front.
+func readBytes(r io.Reader, n uint32) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(n)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 // Object type identifiers for serialization
@@ -123,6 +196,8 @@ const (
 	objTypeCompiledFunc    byte = 12
 	objTypeArray           byte = 13
 	objTypeHash            byte = 14
+	objTypeBigInteger      byte = 15
+	objTypeDecimal         byte = 16
 )
 
 // serializeObject writes an object to the writer
@@ -199,6 +274,30 @@ func serializeObject(w io.Writer, obj object.Object) error {
 		_, err := w.Write([]byte(o.Value))
 		return err
 
+	case *object.BigInteger:
+		if err := binary.Write(w, binary.BigEndian, objTypeBigInteger); err != nil {
+			return err
+		}
+		// Write the value as length-prefixed decimal text
+		text := o.Value.String()
+		if err := binary.Write(w, binary.BigEndian, uint32(len(text))); err != nil {
+			return err
+		}
+		_, err := w.Write([]byte(text))
+		return err
+
+	case *object.Decimal:
+		if err := binary.Write(w, binary.BigEndian, objTypeDecimal); err != nil {
+			return err
+		}
+		// Write the value as length-prefixed "numerator/denominator" text
+		text := o.Value.RatString()
+		if err := binary.Write(w, binary.BigEndian, uint32(len(text))); err != nil {
+			return err
+		}
+		_, err := w.Write([]byte(text))
+		return err
+
 	case *object.Null:
 		return binary.Write(w, binary.BigEndian, objTypeNull)
 
@@ -220,7 +319,15 @@ func serializeObject(w io.Writer, obj object.Object) error {
 			return err
 		}
 		// Write NumParameters
-		return binary.Write(w, binary.BigEndian, uint32(o.NumParameters))
+		if err := binary.Write(w, binary.BigEndian, uint32(o.NumParameters)); err != nil {
+			return err
+		}
+		// Write NumDefaults
+		if err := binary.Write(w, binary.BigEndian, uint32(o.NumDefaults)); err != nil {
+			return err
+		}
+		// Write Variadic
+		return binary.Write(w, binary.BigEndian, o.Variadic)
 
 	case *object.Array:
 		if err := binary.Write(w, binary.BigEndian, objTypeArray); err != nil {
@@ -249,7 +356,7 @@ func serializeObject(w io.Writer, obj object.Object) error {
 			return err
 		}
 		// Write each key-value pair
-		for _, pair := range o.Pairs {
+		for _, pair := range o.SortedPairs() {
 			if err := serializeObject(w, pair.Key); err != nil {
 				return err
 			}
@@ -333,7 +440,7 @@ func deserializeObject(r io.Reader) (object.Object, error) {
 		if err := binary.Read(r, binary.BigEndian, &value); err != nil {
 			return nil, err
 		}
-		return &object.Boolean{Value: value == 1}, nil
+		return object.NativeBool(value == 1), nil
 
 	case objTypeString:
 		// Read string length
@@ -342,14 +449,44 @@ func deserializeObject(r io.Reader) (object.Object, error) {
 			return nil, err
 		}
 		// Read string bytes
-		strBytes := make([]byte, strLen)
-		if _, err := io.ReadFull(r, strBytes); err != nil {
+		strBytes, err := readBytes(r, strLen)
+		if err != nil {
 			return nil, err
 		}
 		return &object.String{Value: string(strBytes)}, nil
 
+	case objTypeBigInteger:
+		var textLen uint32
+		if err := binary.Read(r, binary.BigEndian, &textLen); err != nil {
+			return nil, err
+		}
+		text, err := readBytes(r, textLen)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := new(big.Int).SetString(string(text), 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid big integer constant %q", text)
+		}
+		return &object.BigInteger{Value: value}, nil
+
+	case objTypeDecimal:
+		var textLen uint32
+		if err := binary.Read(r, binary.BigEndian, &textLen); err != nil {
+			return nil, err
+		}
+		text, err := readBytes(r, textLen)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := new(big.Rat).SetString(string(text))
+		if !ok {
+			return nil, fmt.Errorf("invalid decimal constant %q", text)
+		}
+		return &object.Decimal{Value: value}, nil
+
 	case objTypeNull:
-		return &object.Null{}, nil
+		return object.NULL, nil
 
 	case objTypeCompiledFunc:
 		// Read instructions length
@@ -358,8 +495,8 @@ func deserializeObject(r io.Reader) (object.Object, error) {
 			return nil, err
 		}
 		// Read instructions
-		instructions := make([]byte, insLen)
-		if _, err := io.ReadFull(r, instructions); err != nil {
+		instructions, err := readBytes(r, insLen)
+		if err != nil {
 			return nil, err
 		}
 		// Read NumLocals
@@ -372,10 +509,22 @@ func deserializeObject(r io.Reader) (object.Object, error) {
 		if err := binary.Read(r, binary.BigEndian, &numParameters); err != nil {
 			return nil, err
 		}
+		// Read NumDefaults
+		var numDefaults uint32
+		if err := binary.Read(r, binary.BigEndian, &numDefaults); err != nil {
+			return nil, err
+		}
+		// Read Variadic
+		var variadic bool
+		if err := binary.Read(r, binary.BigEndian, &variadic); err != nil {
+			return nil, err
+		}
 		return &object.CompiledFunction{
 			Instructions:  instructions,
 			NumLocals:     int(numLocals),
 			NumParameters: int(numParameters),
+			NumDefaults:   int(numDefaults),
+			Variadic:      variadic,
 		}, nil
 
 	case objTypeArray:
@@ -385,13 +534,13 @@ func deserializeObject(r io.Reader) (object.Object, error) {
 			return nil, err
 		}
 		// Read each element
-		elements := make([]object.Object, arrLen)
+		elements := make([]object.Object, 0, preallocated(arrLen))
 		for i := uint32(0); i < arrLen; i++ {
 			elem, err := deserializeObject(r)
 			if err != nil {
 				return nil, err
 			}
-			elements[i] = elem
+			elements = append(elements, elem)
 		}
 		return &object.Array{Elements: elements}, nil
 
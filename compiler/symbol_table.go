@@ -19,6 +19,8 @@ type Symbol struct {
 	Scope      SymbolScope
 	Index      int
 	TypeAnnot  *ast.TypeAnnotation // Optional type annotation
+	IsClass    bool                // names a class, so Name.member is static
+	Boxed      bool                // the slot holds a cell shared with closures
 }
 
 // SymbolTable tracks symbols and their scopes
@@ -27,8 +29,11 @@ type SymbolTable struct {
 
 	store          map[string]Symbol
 	numDefinitions int
+	names          []string // every name defined, by index
 
 	FreeSymbols []Symbol
+
+	boxed map[string]bool // variables closures assign, kept in cells
 }
 
 // NewSymbolTable creates a new symbol table
@@ -55,6 +60,7 @@ func (s *SymbolTable) Define(name string) Symbol {
 	}
 
 	s.store[name] = symbol
+	s.names = append(s.names, name)
 	s.numDefinitions++
 	return symbol
 }
@@ -69,10 +75,29 @@ func (s *SymbolTable) DefineWithType(name string, typeAnnot *ast.TypeAnnotation)
 	}
 
 	s.store[name] = symbol
+	s.names = append(s.names, name)
 	s.numDefinitions++
 	return symbol
 }
 
+// DefineClass defines a symbol naming a class
+func (s *SymbolTable) DefineClass(name string) Symbol {
+	symbol := s.Define(name)
+	symbol.IsClass = true
+	s.store[name] = symbol
+	return symbol
+}
+
+// box marks a variable defined in the table as kept in a cell, when it is
+// one of those a closure assigns
+func (s *SymbolTable) box(symbol Symbol) Symbol {
+	if s.boxed[symbol.Name] && symbol.Scope == LocalScope {
+		symbol.Boxed = true
+		s.store[symbol.Name] = symbol
+	}
+	return symbol
+}
+
 // DefineBuiltin defines a builtin symbol
 func (s *SymbolTable) DefineBuiltin(index int, name string) Symbol {
 	symbol := Symbol{Name: name, Scope: BuiltinScope, Index: index}
@@ -87,6 +112,37 @@ func (s *SymbolTable) DefineFunctionName(name string) Symbol {
 	return symbol
 }
 
+// Reserve allocates a global or local slot that no name refers to
+func (s *SymbolTable) Reserve() int {
+	s.names = append(s.names, "")
+	s.numDefinitions++
+	return s.numDefinitions - 1
+}
+
+// GlobalNames lists the names of the globals defined in the table by
+// index; a global whose name was defined again later is left as ""
+func (s *SymbolTable) GlobalNames() []string {
+	if s.Outer != nil {
+		return nil
+	}
+	names := make([]string, s.numDefinitions)
+	for _, symbol := range s.store {
+		if symbol.Scope == GlobalScope {
+			names[symbol.Index] = symbol.Name
+		}
+	}
+	return names
+}
+
+// LocalNames lists the names of the locals defined in a function's table
+// by index, including those a later definition of the name shadows
+func (s *SymbolTable) LocalNames() []string {
+	if s.Outer == nil {
+		return nil
+	}
+	return s.names
+}
+
 // Resolve resolves a symbol by name
 func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
 	obj, ok := s.store[name]
@@ -107,10 +163,21 @@ func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
 	return obj, ok
 }
 
+// Lookup finds the symbol a name refers to in this table or an enclosing
+// one without recording it as a free variable, as Resolve would
+func (s *SymbolTable) Lookup(name string) (Symbol, bool) {
+	for table := s; table != nil; table = table.Outer {
+		if symbol, ok := table.store[name]; ok {
+			return symbol, true
+		}
+	}
+	return Symbol{}, false
+}
+
 func (s *SymbolTable) defineFree(original Symbol) Symbol {
 	s.FreeSymbols = append(s.FreeSymbols, original)
 
-	symbol := Symbol{Name: original.Name, Index: len(s.FreeSymbols) - 1}
+	symbol := Symbol{Name: original.Name, Index: len(s.FreeSymbols) - 1, IsClass: original.IsClass, Boxed: original.Boxed}
 	symbol.Scope = FreeScope
 
 	s.store[original.Name] = symbol
@@ -0,0 +1,266 @@
+package compiler
+
+import (
+	"bhasa/code"
+	"bhasa/errors"
+	"bhasa/object"
+	"fmt"
+)
+
+// maxVerifiedStack mirrors the VM's stack size; a function whose stack
+// could grow beyond it is rejected
+const maxVerifiedStack = 2048
+
+// VerifyError describes why bytecode was rejected
+type VerifyError struct {
+	Function string // "main" or "constant N"
+	Offset   int
+	Message  string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("invalid bytecode: %s, offset %04d: %s", e.Function, e.Offset, e.Message)
+}
+
+// stackEffect is how many values an instruction pops and pushes
+type stackEffect struct {
+	pop, push int
+}
+
+// fixedEffects lists the stack effect of every opcode whose effect does not
+// depend on its operands
+var fixedEffects = map[code.Opcode]stackEffect{
+	code.OpConstant:          {0, 1},
+	code.OpPop:               {1, 0},
+	code.OpAdd:               {2, 1},
+	code.OpSub:               {2, 1},
+	code.OpMul:               {2, 1},
+	code.OpDiv:               {2, 1},
+	code.OpMod:               {2, 1},
+	code.OpBitAnd:            {2, 1},
+	code.OpBitOr:             {2, 1},
+	code.OpBitXor:            {2, 1},
+	code.OpLeftShift:         {2, 1},
+	code.OpRightShift:        {2, 1},
+	code.OpBitNot:            {1, 1},
+	code.OpTrue:              {0, 1},
+	code.OpFalse:             {0, 1},
+	code.OpEqual:             {2, 1},
+	code.OpNotEqual:          {2, 1},
+	code.OpGreaterThan:       {2, 1},
+	code.OpGreaterThanEqual:  {2, 1},
+	code.OpMinus:             {1, 1},
+	code.OpBang:              {1, 1},
+	code.OpAnd:               {2, 1},
+	code.OpOr:                {2, 1},
+	code.OpJumpNotTruthy:     {1, 0},
+	code.OpJump:              {0, 0},
+	code.OpNull:              {0, 1},
+	code.OpGetGlobal:         {0, 1},
+	code.OpSetGlobal:         {1, 0},
+	code.OpIndex:             {2, 1},
+	code.OpReturnValue:       {1, 0},
+	code.OpReturn:            {0, 0},
+	code.OpGetLocal:          {0, 1},
+	code.OpSetLocal:          {1, 0},
+	code.OpGetBuiltin:        {0, 1},
+	code.OpGetFree:           {0, 1},
+	code.OpCurrentClosure:    {0, 1},
+	code.OpTypeCheck:         {1, 1},
+	code.OpTypeCast:          {1, 1},
+	code.OpAssertType:        {1, 1},
+	code.OpGetStructField:    {2, 1},
+	code.OpSetStructField:    {3, 1},
+	code.OpClass:             {0, 1},
+	code.OpGetThis:           {0, 1},
+	code.OpGetSuper:          {0, 1},
+	code.OpDefineMethod:      {1, 0},
+	code.OpDefineConstructor: {1, 0},
+	code.OpInterface:         {0, 1},
+	code.OpGetInstanceField:  {2, 1},
+	code.OpSetInstanceField:  {3, 1},
+	code.OpSlice:             {3, 1},
+	code.OpSetIndex:          {3, 0},
+	code.OpGetStatic:         {1, 1},
+	code.OpSetStatic:         {2, 0},
+	code.OpArgMissing:        {0, 1},
+	code.OpDup:               {1, 2},
+	code.OpLessThan:          {2, 1},
+	code.OpLessThanEqual:     {2, 1},
+	code.OpNewCell:           {1, 1},
+	code.OpGetCell:           {1, 1},
+	code.OpSetCell:           {2, 0},
+	code.OpCompareJump:       {2, 0},
+	code.OpGetLocals:         {0, 2},
+	code.OpAddConstant:       {1, 1},
+	code.OpGetLocalAdd:       {1, 1},
+}
+
+// verifiedFunction is one instruction stream checked by Verify; index is
+// its constant index, or -1 for the main program
+type verifiedFunction struct {
+	name      string
+	index     int
+	ins       code.Instructions
+	numParams int
+	numLocals int
+}
+
+// Verify checks bytecode before it is run: every opcode must be known and
+// complete, constant, builtin, local and free-variable operands must be in
+// range, jumps must land on instruction boundaries, the operand stack
+// must never underflow or exceed the VM's stack size and must be balanced
+// on every path through each function, and no local may be read on a
+// path that has not set it. Whether a global is set when it is read
+// depends on the order functions run in, so the VM checks that instead.
+func (b *Bytecode) Verify() error {
+	// Free variable counts come from the OpClosure instructions that
+	// create each function
+	numFree := map[int]int{}
+
+	functions := []verifiedFunction{{"main", -1, b.Instructions, 0, 0}}
+	for i, c := range b.Constants {
+		if fn, ok := c.(*object.CompiledFunction); ok {
+			if fn.NumParameters > fn.NumLocals {
+				return &VerifyError{fmt.Sprintf("constant %d", i), 0,
+					fmt.Sprintf("%d parameters but only %d locals", fn.NumParameters, fn.NumLocals)}
+			}
+			functions = append(functions, verifiedFunction{fmt.Sprintf("constant %d", i), i, fn.Instructions, fn.NumParameters, fn.NumLocals})
+		}
+	}
+
+	// Globals are numbered as they are defined, so a global that is read
+	// must be assigned somewhere in the program
+	setGlobals := map[int]bool{}
+
+	for _, fn := range functions {
+		if err := b.verifyOperands(fn.name, fn.ins, fn.numLocals, fn.index < 0, numFree, setGlobals); err != nil {
+			return err
+		}
+	}
+	for _, fn := range functions {
+		if err := verifyVariables(fn.name, fn.ins, numFree[fn.index], setGlobals); err != nil {
+			return err
+		}
+		g, err := buildCFG(fn.name, fn.ins)
+		if err != nil {
+			return err
+		}
+		if err := g.checkStack(); err != nil {
+			return err
+		}
+		if err := g.checkLocals(fn.numParams, fn.numLocals); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyOperands decodes every instruction of one function and checks its
+// operands, recording the free variable count of closures it creates
+func (b *Bytecode) verifyOperands(name string, ins code.Instructions, numLocals int, isMain bool, numFree map[int]int, setGlobals map[int]bool) error {
+	for i := 0; i < len(ins); {
+		op := code.Opcode(ins[i])
+		def, err := code.Lookup(ins[i])
+		if err != nil {
+			return &VerifyError{name, i, err.Error()}
+		}
+
+		width := 0
+		for _, w := range def.OperandWidths {
+			width += w
+		}
+		if i+1+width > len(ins) {
+			return &VerifyError{name, i, fmt.Sprintf("%s is truncated", def.Name)}
+		}
+		operands, _ := code.ReadOperands(def, ins[i+1:])
+		fail := func(format string, a ...interface{}) error {
+			return &VerifyError{name, i, def.Name + ": " + fmt.Sprintf(format, a...)}
+		}
+
+		switch op {
+		case code.OpConstant, code.OpTypeCheck, code.OpTypeCast, code.OpAssertType,
+			code.OpClass, code.OpDefineMethod, code.OpInterface, code.OpGetStatic, code.OpSetStatic,
+			code.OpAddConstant:
+			if operands[0] >= len(b.Constants) {
+				return fail("%s", errors.ConstantIndexOutOfRange(operands[0], len(b.Constants)))
+			}
+		case code.OpClosure:
+			if operands[0] >= len(b.Constants) {
+				return fail("%s", errors.ConstantIndexOutOfRange(operands[0], len(b.Constants)))
+			}
+			if _, ok := b.Constants[operands[0]].(*object.CompiledFunction); !ok {
+				return fail("constant %d is %s, not a function", operands[0], b.Constants[operands[0]].Type())
+			}
+			if n, seen := numFree[operands[0]]; seen && n != operands[1] {
+				return fail("function %d created with %d and %d free variables", operands[0], n, operands[1])
+			}
+			numFree[operands[0]] = operands[1]
+		case code.OpGetBuiltin:
+			if operands[0] >= len(object.Builtins) {
+				return fail("builtin index %d out of range (%d builtins)", operands[0], len(object.Builtins))
+			}
+		case code.OpSetGlobal:
+			setGlobals[operands[0]] = true
+		case code.OpGetLocal, code.OpSetLocal, code.OpArgMissing, code.OpGetLocals, code.OpGetLocalAdd:
+			if isMain {
+				return fail("local variable used outside a function")
+			}
+			for _, index := range operands {
+				if index >= numLocals {
+					return fail("local index %d out of range (%d locals)", index, numLocals)
+				}
+			}
+		case code.OpCompareJump:
+			if !comparisonOps[code.Opcode(operands[0])] {
+				return fail("opcode %d is not a comparison", operands[0])
+			}
+		case code.OpEnum, code.OpInherit, code.OpCheckInterface:
+			return fail("opcode is not supported by the VM")
+		}
+
+		i += 1 + width
+	}
+	return nil
+}
+
+// verifyVariables checks OpGetFree operands against the number of free
+// variables the function's closures are created with, and OpGetGlobal
+// operands against the globals the program assigns
+func verifyVariables(name string, ins code.Instructions, numFree int, setGlobals map[int]bool) error {
+	for i := 0; i < len(ins); {
+		def, _ := code.Lookup(ins[i])
+		operands, read := code.ReadOperands(def, ins[i+1:])
+		switch code.Opcode(ins[i]) {
+		case code.OpGetFree:
+			if operands[0] >= numFree {
+				return &VerifyError{name, i, fmt.Sprintf("OpGetFree: free variable %d out of range (%d free variables)", operands[0], numFree)}
+			}
+		case code.OpGetGlobal:
+			if !setGlobals[operands[0]] {
+				return &VerifyError{name, i, fmt.Sprintf("OpGetGlobal: global %d is never assigned", operands[0])}
+			}
+		}
+		i += 1 + read
+	}
+	return nil
+}
+
+// effectOf returns the stack effect of an instruction
+func effectOf(op code.Opcode, operands []int) stackEffect {
+	switch op {
+	case code.OpArray, code.OpHash, code.OpStruct, code.OpTuple:
+		return stackEffect{operands[0], 1}
+	case code.OpUnpack:
+		return stackEffect{1, operands[0]}
+	case code.OpCall:
+		return stackEffect{operands[0] + 1, 1}
+	case code.OpNewInstance:
+		return stackEffect{operands[0] + 1, 1}
+	case code.OpCallMethod:
+		return stackEffect{operands[0] + 2, 1}
+	case code.OpClosure:
+		return stackEffect{operands[1], 1}
+	}
+	return fixedEffects[op]
+}
@@ -0,0 +1,197 @@
+// Package desugar rewrites convenience syntax into the core constructs that
+// the compiler and the evaluator understand, so new syntax that can be
+// spelled in existing constructs needs no compiler or evaluator changes.
+package desugar
+
+import (
+	"bhasa/ast"
+	"bhasa/token"
+	"fmt"
+)
+
+// Program rewrites the sugar in a parsed program, in place:
+//
+//	x += v                      x = x + v (also -=, *=, /= and %=)
+//	প্রতিটি (x মধ্যে xs) { ... }   a পর্যন্ত loop counting through xs, or its keys
+//	"মোট ${n} টি"                "মোট " + ফরম্যাট("%s", n) + " টি"
+//
+// The compiler and the evaluator call it on every program before running
+// it. Temporaries it introduces are named with a leading #, which no
+// identifier in source can have, and the builtins it calls are referred to
+// with ast.BuiltinReference so that programs may reuse their names.
+func Program(program *ast.Program) *ast.Program {
+	d := &desugarer{}
+	return ast.Rewrite(program, d.rewrite).(*ast.Program)
+}
+
+// desugarer numbers the temporaries of one program
+type desugarer struct {
+	temps int
+}
+
+func (d *desugarer) rewrite(node ast.Node) ast.Node {
+	switch n := node.(type) {
+	case *ast.CompoundAssignmentStatement:
+		return d.compoundAssignment(n)
+	case *ast.ForEachStatement:
+		return d.forEach(n)
+	case *ast.InterpolatedString:
+		return d.interpolatedString(n)
+	}
+	return node
+}
+
+// compoundAssignment turns target op= value into target = target op value.
+// The collection and index of an index target, and the object of a member
+// target, are evaluated once into temporaries unless they are plain
+// variables or literals.
+func (d *desugarer) compoundAssignment(n *ast.CompoundAssignmentStatement) ast.Statement {
+	apply := func(current ast.Expression) ast.Expression {
+		opToken := n.Token
+		opToken.Type = token.TokenType(n.Operator)
+		opToken.Literal = n.Operator
+		return &ast.InfixExpression{Token: opToken, Left: current, Operator: n.Operator, Right: n.Value}
+	}
+
+	switch target := n.Target.(type) {
+	case *ast.IndexExpression:
+		var setup []ast.Statement
+		left := d.once(target.Left, &setup)
+		index := d.once(target.Index, &setup)
+		current := &ast.IndexExpression{Token: target.Token, Left: left, Index: index}
+		return block(n.Token, setup, &ast.IndexAssignmentStatement{
+			Token: target.Token, Left: left, Index: index, Value: apply(current),
+		})
+	case *ast.MemberAccessExpression:
+		var setup []ast.Statement
+		object := d.once(target.Object, &setup)
+		current := &ast.MemberAccessExpression{Token: target.Token, Object: object, Member: target.Member}
+		return block(n.Token, setup, &ast.MemberAssignmentStatement{
+			Token: target.Token, Object: object, Member: target.Member, Value: apply(current),
+		})
+	default:
+		// The parser only accepts identifiers, index and member targets
+		ident := target.(*ast.Identifier)
+		return &ast.AssignmentStatement{Token: ident.Token, Name: ident, Value: apply(ident)}
+	}
+}
+
+// forEach turns a প্রতিটি loop into
+//
+//	ধরি #seq = xs;
+//	যদি (টাইপ(#seq) == "HASH") { #seq = চাবিগুলো(#seq); }
+//	পর্যন্ত (ধরি #i = 0; #i < দৈর্ঘ্য(#seq); #i = #i + 1) { ধরি x = #seq[#i]; ... }
+//
+// so বিরতি and চালিয়ে_যাও behave as in any পর্যন্ত loop. A hash is
+// iterated over its keys, in the order চাবিগুলো gives them.
+func (d *desugarer) forEach(n *ast.ForEachStatement) ast.Statement {
+	tok := n.Token
+	seq := d.temp(tok, "seq")
+	i := d.temp(tok, "i")
+
+	length := &ast.CallExpression{
+		Token:     tok,
+		Function:  builtin(tok, "দৈর্ঘ্য"),
+		Arguments: []ast.Expression{seq},
+	}
+	body := &ast.BlockStatement{Token: n.Body.Token}
+	body.Statements = append([]ast.Statement{&ast.LetStatement{
+		Token: tok,
+		Name:  n.Variable,
+		Value: &ast.IndexExpression{Token: tok, Left: seq, Index: i},
+	}}, n.Body.Statements...)
+
+	loop := &ast.ForStatement{
+		Token:     tok,
+		Init:      &ast.LetStatement{Token: tok, Name: i, Value: &ast.IntegerLiteral{Token: tok, Value: 0}},
+		Condition: infix(tok, i, "<", length),
+		Increment: &ast.AssignmentStatement{Token: tok, Name: i, Value: infix(tok, i, "+", &ast.IntegerLiteral{Token: tok, Value: 1})},
+		Body:      body,
+	}
+	isHash := infix(tok,
+		&ast.CallExpression{Token: tok, Function: builtin(tok, "টাইপ"), Arguments: []ast.Expression{seq}},
+		"==", &ast.StringLiteral{Token: tok, Value: "HASH"})
+	keys := &ast.AssignmentStatement{Token: tok, Name: seq, Value: &ast.CallExpression{
+		Token:     tok,
+		Function:  builtin(tok, "চাবিগুলো"),
+		Arguments: []ast.Expression{seq},
+	}}
+	setup := []ast.Statement{
+		&ast.LetStatement{Token: tok, Name: seq, Value: n.Iterable},
+		&ast.ExpressionStatement{Token: tok, Expression: &ast.IfExpression{
+			Token:       tok,
+			Condition:   isHash,
+			Consequence: &ast.BlockStatement{Token: tok, Statements: []ast.Statement{keys}},
+		}},
+	}
+	return block(tok, setup, loop)
+}
+
+// interpolatedString joins the text and holes of a string with +, turning
+// each hole into text the way ফরম্যাট's %s does
+func (d *desugarer) interpolatedString(n *ast.InterpolatedString) ast.Expression {
+	var result ast.Expression
+	for _, part := range n.Parts {
+		if _, ok := part.(*ast.StringLiteral); !ok {
+			part = &ast.CallExpression{
+				Token:     n.Token,
+				Function:  builtin(n.Token, "ফরম্যাট"),
+				Arguments: []ast.Expression{&ast.StringLiteral{Token: n.Token, Value: "%s"}, part},
+			}
+		}
+		if result == nil {
+			result = part
+		} else {
+			result = infix(n.Token, result, "+", part)
+		}
+	}
+	if result == nil {
+		return &ast.StringLiteral{Token: n.Token, Value: ""}
+	}
+	return result
+}
+
+// once returns expr if evaluating it twice is harmless, and otherwise
+// adds a statement to setup that saves its value in a temporary
+func (d *desugarer) once(expr ast.Expression, setup *[]ast.Statement) ast.Expression {
+	switch expr.(type) {
+	case *ast.Identifier, *ast.ThisExpression, *ast.IntegerLiteral, *ast.StringLiteral, *ast.CharLiteral, *ast.Boolean:
+		return expr
+	}
+	tok := ast.NodeToken(expr)
+	temp := d.temp(tok, "tmp")
+	*setup = append(*setup, &ast.LetStatement{Token: tok, Name: temp, Value: expr})
+	return temp
+}
+
+// temp returns a fresh temporary
+func (d *desugarer) temp(tok token.Token, role string) *ast.Identifier {
+	d.temps++
+	return identifier(tok, fmt.Sprintf("#%s%d", role, d.temps))
+}
+
+func identifier(tok token.Token, name string) *ast.Identifier {
+	tok.Type = token.IDENT
+	tok.Literal = name
+	return &ast.Identifier{Token: tok, Value: name}
+}
+
+func builtin(tok token.Token, name string) *ast.BuiltinReference {
+	tok.Type = token.IDENT
+	tok.Literal = name
+	return &ast.BuiltinReference{Token: tok, Name: name}
+}
+
+func infix(tok token.Token, left ast.Expression, op string, right ast.Expression) *ast.InfixExpression {
+	tok.Type = token.TokenType(op)
+	tok.Literal = op
+	return &ast.InfixExpression{Token: tok, Left: left, Operator: op, Right: right}
+}
+
+// block returns stmt alone, or preceded by setup in a block
+func block(tok token.Token, setup []ast.Statement, stmt ast.Statement) ast.Statement {
+	if len(setup) == 0 {
+		return stmt
+	}
+	return &ast.BlockStatement{Token: tok, Statements: append(setup, stmt)}
+}
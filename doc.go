@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bhasa/ast"
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// docModule is what bhasa doc shows of one source file: the names it
+// defines at the top level, which are the names a file importing it gets
+type docModule struct {
+	name    string // the file name without its extension, as it is imported
+	symbols []*docSymbol
+}
+
+// docSymbol is a documented name: a top-level definition, or a
+// constructor or method of a class
+type docSymbol struct {
+	kind      string // the section it is listed in
+	name      string
+	signature string // how it is declared, without its body
+	doc       string // the text of its /// comment
+	members   []*docSymbol
+}
+
+// docSections are the sections of a module's page, in order
+var docSections = []string{"শ্রেণী", "চুক্তি", "ফাংশন", "গণনা", "স্ট্রাক্ট", "মান"}
+
+// describeDocs collects the top-level definitions of a program with their
+// doc comments. Private class members are left out, as importers cannot
+// use them.
+func describeDocs(name string, program *ast.Program) *docModule {
+	module := &docModule{name: name}
+	for _, stmt := range program.Statements {
+		switch s := stmt.(type) {
+		case *ast.LetStatement:
+			module.symbols = append(module.symbols, letDoc(s))
+		case *ast.ClassDefinition:
+			module.symbols = append(module.symbols, classDoc(s))
+		case *ast.InterfaceDefinition:
+			module.symbols = append(module.symbols, interfaceDoc(s))
+		}
+	}
+	return module
+}
+
+func letDoc(s *ast.LetStatement) *docSymbol {
+	symbol := &docSymbol{kind: "মান", name: s.Name.Value, doc: s.Token.Doc}
+	switch v := s.Value.(type) {
+	case *ast.FunctionLiteral:
+		symbol.kind = "ফাংশন"
+		symbol.signature = fmt.Sprintf("ধরি %s = ফাংশন(%s)%s", s.Name.Value,
+			ast.FormatParameters(v.Parameters, v.ParameterTypes, v.Defaults, v.Rest), returnType(v.ReturnType))
+		if v.Doc != "" {
+			symbol.doc = v.Doc
+		}
+		return symbol
+	case *ast.EnumDefinition:
+		symbol.kind = "গণনা"
+	case *ast.StructDefinition:
+		symbol.kind = "স্ট্রাক্ট"
+	}
+
+	symbol.signature = "ধরি " + s.Name.Value
+	if s.TypeAnnot != nil {
+		symbol.signature += ": " + s.TypeAnnot.String()
+	}
+	// Long values, such as a table of data, would bury the documentation
+	if value := s.Value.String(); len([]rune(value)) <= 80 && !strings.Contains(value, "\n") {
+		symbol.signature += " = " + value
+	}
+	return symbol
+}
+
+// classDoc documents a class: its fields go in its declaration, and its
+// constructors and methods are listed under it
+func classDoc(c *ast.ClassDefinition) *docSymbol {
+	var fields []string
+	for _, f := range c.Fields {
+		if f.Access == ast.PRIVATE {
+			continue
+		}
+		field := memberModifiers(f.Access, f.IsStatic, f.IsFinal, false, false) + f.Name
+		if f.TypeAnnot != nil {
+			field += ": " + f.TypeAnnot.String()
+		}
+		if f.Value != nil {
+			field += " = " + f.Value.String()
+		}
+		fields = append(fields, field)
+	}
+	symbol := &docSymbol{
+		kind:      "শ্রেণী",
+		name:      c.Name.Value,
+		signature: declaration(strings.TrimSuffix(c.String(), " { ... }"), fields),
+		doc:       c.Doc,
+	}
+	for _, ctor := range c.Constructors {
+		if ctor.Access == ast.PRIVATE {
+			continue
+		}
+		symbol.members = append(symbol.members, &docSymbol{
+			signature: memberModifiers(ctor.Access, false, false, false, false) + "নির্মাতা(" +
+				ast.FormatParameters(ctor.Parameters, ctor.ParameterTypes, ctor.Defaults, ctor.Rest) + ")",
+			doc: ctor.Doc,
+		})
+	}
+	for _, m := range c.Methods {
+		if m.Access == ast.PRIVATE {
+			continue
+		}
+		symbol.members = append(symbol.members, &docSymbol{
+			signature: memberModifiers(m.Access, m.IsStatic, m.IsFinal, m.IsAbstract, m.IsOverride) + "পদ্ধতি " + m.Name.Value + "(" +
+				ast.FormatParameters(m.Parameters, m.ParameterTypes, m.Defaults, m.Rest) + ")" + returnType(m.ReturnType),
+			doc: m.Doc,
+		})
+	}
+	return symbol
+}
+
+// interfaceDoc documents an interface, with its methods in its declaration
+func interfaceDoc(i *ast.InterfaceDefinition) *docSymbol {
+	var methods []string
+	for _, m := range i.Methods {
+		methods = append(methods, "পদ্ধতি "+m.Name.Value+"("+ast.FormatParameters(m.Parameters, m.ParameterTypes, nil, false)+")"+returnType(m.ReturnType))
+	}
+	return &docSymbol{
+		kind:      "চুক্তি",
+		name:      i.Name.Value,
+		signature: declaration("চুক্তি "+i.Name.Value, methods),
+		doc:       i.Token.Doc,
+	}
+}
+
+// declaration writes the head of a class or interface with the lines of
+// its body, if any, indented under it
+func declaration(head string, lines []string) string {
+	if len(lines) == 0 {
+		return head
+	}
+	return head + " {\n    " + strings.Join(lines, ";\n    ") + ";\n}"
+}
+
+// memberModifiers writes the modifiers of a class member before its
+// name; members are public unless they say otherwise, so that is not
+// repeated
+func memberModifiers(access ast.AccessModifier, static, final, abstract, override bool) string {
+	var out []string
+	if access != "" && access != ast.PUBLIC {
+		out = append(out, string(access))
+	}
+	for _, m := range []struct {
+		on   bool
+		word string
+	}{{static, "স্থির"}, {final, "চূড়ান্ত"}, {abstract, "বিমূর্ত"}, {override, "পুনর্সংজ্ঞা"}} {
+		if m.on {
+			out = append(out, m.word)
+		}
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, " ") + " "
+}
+
+func returnType(t *ast.TypeAnnotation) string {
+	if t == nil {
+		return ""
+	}
+	return ": " + t.String()
+}
+
+// slugger makes the anchors of headings the way GitHub does, so links in
+// Markdown keep working when it is rendered there: letters, marks such as
+// Bengali vowel signs, digits, - and _ are kept, spaces become -, and a
+// repeated anchor gets -1, -2, ... added
+type slugger map[string]int
+
+func (s slugger) slug(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '_' || r == '-':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	slug := b.String()
+	n := s[slug]
+	s[slug] = n + 1
+	if n > 0 {
+		slug = fmt.Sprintf("%s-%d", slug, n)
+	}
+	return slug
+}
+
+// docWriter renders modules as Markdown or, with html set, as an HTML page
+type docWriter struct {
+	out   bytes.Buffer
+	html  bool
+	slugs slugger
+}
+
+func (w *docWriter) heading(level int, text string) string {
+	id := w.slugs.slug(text)
+	if w.html {
+		fmt.Fprintf(&w.out, "<h%d id=\"%s\">%s</h%d>\n", level, html.EscapeString(id), html.EscapeString(text), level)
+	} else {
+		fmt.Fprintf(&w.out, "%s %s\n\n", strings.Repeat("#", level), text)
+	}
+	return id
+}
+
+// member writes the heading of a constructor or method, set in code
+func (w *docWriter) member(signature string) {
+	id := w.slugs.slug(signature)
+	if w.html {
+		fmt.Fprintf(&w.out, "<h4 id=\"%s\"><code>%s</code></h4>\n", html.EscapeString(id), html.EscapeString(signature))
+	} else {
+		fmt.Fprintf(&w.out, "#### `%s`\n\n", signature)
+	}
+}
+
+func (w *docWriter) code(text string) {
+	if w.html {
+		fmt.Fprintf(&w.out, "<pre><code>%s</code></pre>\n", html.EscapeString(text))
+	} else {
+		fmt.Fprintf(&w.out, "```bengali\n%s\n```\n\n", text)
+	}
+}
+
+// text writes a doc comment. Markdown keeps it as written; HTML makes a
+// paragraph of each run of lines, and preformatted text of indented ones.
+func (w *docWriter) text(doc string) {
+	if doc == "" {
+		return
+	}
+	if !w.html {
+		w.out.WriteString(doc + "\n\n")
+		return
+	}
+	var para, pre []string
+	flush := func() {
+		if len(para) > 0 {
+			fmt.Fprintf(&w.out, "<p>%s</p>\n", html.EscapeString(strings.Join(para, "\n")))
+		}
+		if len(pre) > 0 {
+			fmt.Fprintf(&w.out, "<pre>%s</pre>\n", html.EscapeString(strings.Join(pre, "\n")))
+		}
+		para, pre = nil, nil
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case line[0] == ' ' || line[0] == '\t':
+			if len(para) > 0 {
+				flush()
+			}
+			pre = append(pre, line)
+		default:
+			if len(pre) > 0 {
+				flush()
+			}
+			para = append(para, line)
+		}
+	}
+	flush()
+}
+
+// docLink is an entry of a module's index
+type docLink struct {
+	text, id string
+}
+
+// module writes a module's page: its name and how to import it, an index
+// of its names and then each section. The body is rendered before the
+// index so that the index can link to the anchors it was given.
+func (w *docWriter) module(m *docModule) {
+	w.heading(1, m.name)
+	w.code(fmt.Sprintf("অন্তর্ভুক্ত \"%s\";", m.name))
+	if len(m.symbols) == 0 {
+		return
+	}
+	w.heading(2, "সূচি")
+
+	head := w.out
+	w.out = bytes.Buffer{}
+	var index []docLink
+	for _, section := range docSections {
+		first := true
+		for _, s := range m.symbols {
+			if s.kind != section {
+				continue
+			}
+			if first {
+				w.heading(2, section)
+				first = false
+			}
+			id := w.heading(3, s.name)
+			index = append(index, docLink{section + " " + s.name, id})
+			w.code(s.signature)
+			w.text(s.doc)
+			for _, member := range s.members {
+				w.member(member.signature)
+				w.text(member.doc)
+			}
+		}
+	}
+	body := w.out
+	w.out = head
+
+	if w.html {
+		w.out.WriteString("<ul>\n")
+		for _, link := range index {
+			fmt.Fprintf(&w.out, "<li><a href=\"#%s\">%s</a></li>\n", html.EscapeString(link.id), html.EscapeString(link.text))
+		}
+		w.out.WriteString("</ul>\n")
+	} else {
+		for _, link := range index {
+			fmt.Fprintf(&w.out, "- [%s](#%s)\n", link.text, link.id)
+		}
+		w.out.WriteString("\n")
+	}
+	w.out.Write(body.Bytes())
+}
+
+// docPage wraps the HTML of the modules in a page for Bengali text
+const docPage = `<!DOCTYPE html>
+<html lang="bn">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>%s</title>
+<style>
+body { font-family: "Noto Sans Bengali", "Hind Siliguri", "Kalpurush", "SolaimanLipi", sans-serif; line-height: 1.7; max-width: 52rem; margin: 2rem auto; padding: 0 1rem; }
+pre { background: #f6f8fa; padding: .6rem .8rem; overflow-x: auto; }
+code, pre { font-family: "Noto Sans Mono", "Noto Sans Bengali", monospace; }
+h1 { border-bottom: 1px solid #ddd; }
+h4 code { font-weight: normal; }
+</style>
+</head>
+<body>
+%s</body>
+</html>
+`
+
+func cmdDoc(args []string) int {
+	fs := newFlagSet("doc")
+	asHTML := fs.Bool("html", false, "Write an HTML page instead of Markdown")
+	output := fs.String("o", "", "Write to this file instead of standard output")
+	paths, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(paths) == 0 {
+		fs.Usage()
+		return 2
+	}
+	files, err := collectSourceFiles(paths)
+	if err != nil {
+		return fail(err)
+	}
+
+	status := 0
+	w := &docWriter{html: *asHTML, slugs: slugger{}}
+	var names []string
+	for _, file := range files {
+		program, err := parseFile(file)
+		if err != nil {
+			status = fail(fmt.Errorf("%s: %v", file, err))
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		names = append(names, name)
+		w.module(describeDocs(name, program))
+	}
+
+	result := w.out.String()
+	if *asHTML {
+		result = fmt.Sprintf(docPage, html.EscapeString(strings.Join(names, ", ")), result)
+	}
+	if *output == "" {
+		fmt.Print(result)
+	} else if err := os.WriteFile(*output, []byte(result), 0644); err != nil {
+		return fail(err)
+	}
+	return status
+}
@@ -60,6 +60,8 @@ const (
 	ErrMethodNotFound      = "পদ্ধতি পাওয়া যায়নি: %s"                                  // Method not found: %s
 	ErrInvalidThis         = "'এই' শুধুমাত্র পদ্ধতির মধ্যে ব্যবহার করা যায়"              // 'this' can only be used in methods
 	ErrInvalidSuper        = "'উর্ধ্ব' শুধুমাত্র চাইল্ড ক্লাসে ব্যবহার করা যায়"           // 'super' can only be used in child classes
+	ErrPrivateMember       = "'%s' শ্রেণী '%s' এর ব্যক্তিগত সদস্য; শুধু এই শ্রেণীর ভেতর থেকে ব্যবহার করা যায়"               // '%s' is a private member of class '%s'; it can only be used inside the class
+	ErrProtectedMember     = "'%s' শ্রেণী '%s' এর সুরক্ষিত সদস্য; শুধু এই শ্রেণী ও এর উপশ্রেণীর ভেতর থেকে ব্যবহার করা যায়" // '%s' is a protected member of class '%s'; it can only be used inside the class and its subclasses
 )
 
 // Helper functions for formatted error messages
@@ -142,3 +144,11 @@ func PropertyNotFound(prop string) string {
 func MethodNotFound(method string) string {
 	return fmt.Sprintf(ErrMethodNotFound, method)
 }
+
+func PrivateMember(member, class string) string {
+	return fmt.Sprintf(ErrPrivateMember, member, class)
+}
+
+func ProtectedMember(member, class string) string {
+	return fmt.Sprintf(ErrProtectedMember, member, class)
+}
@@ -6,10 +6,10 @@ import (
 )
 
 var builtins = map[string]*object.Builtin{
-	"লেখ": { // "write" - prints to console
-		Fn: func(args ...object.Object) object.Object {
+	"লেখ": { // "write" - prints to the runtime's standard output
+		RuntimeFn: func(rt object.Runtime, args ...object.Object) object.Object {
 			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+				fmt.Fprintln(rt.Stdout(), arg.Inspect())
 			}
 			return NULL
 		},
@@ -21,9 +21,11 @@ var builtins = map[string]*object.Builtin{
 			}
 			switch arg := args[0].(type) {
 			case *object.String:
-				return &object.Integer{Value: int64(len([]rune(arg.Value)))}
+				return object.NewInteger(int64(len([]rune(arg.Value))))
 			case *object.Array:
-				return &object.Integer{Value: int64(len(arg.Elements))}
+				return object.NewInteger(int64(len(arg.Elements)))
+			case *object.Tuple:
+				return object.NewInteger(int64(len(arg.Elements)))
 			default:
 				return newError("argument to 'দৈর্ঘ্য' not supported, got %s", args[0].Type())
 			}
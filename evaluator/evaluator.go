@@ -2,14 +2,16 @@ package evaluator
 
 import (
 	"bhasa/ast"
+	"bhasa/desugar"
 	"bhasa/object"
 	"fmt"
+	"math/big"
 )
 
 var (
-	NULL  = &object.Null{}
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
+	NULL  = object.NULL
+	TRUE  = object.TRUE
+	FALSE = object.FALSE
 )
 
 // Eval evaluates an AST node
@@ -18,7 +20,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 	// Statements
 	case *ast.Program:
-		return evalProgram(node, env)
+		return evalProgram(desugar.Program(node), env)
 
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
@@ -40,23 +42,70 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		env.Set(node.Name.Value, val)
 
+	case *ast.DestructuringLetStatement:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		elements, err := unpackValues(val, len(node.Names))
+		if err != nil {
+			return err
+		}
+		for i, name := range node.Names {
+			env.Set(name.Value, elements[i])
+		}
+
 	case *ast.AssignmentStatement:
 		val := Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		if !env.Assign(node.Name.Value, val) {
+			return newError("identifier not found: " + node.Name.Value)
+		}
+
+	case *ast.IndexAssignmentStatement:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := Eval(node.Index, env)
+		if isError(index) {
+			return index
+		}
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		if err := object.SetIndex(left, index, val); err != nil {
+			return err
+		}
 
 	case *ast.WhileStatement:
 		return evalWhileStatement(node, env)
 
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+
 	// Expressions
 	case *ast.IntegerLiteral:
-		return &object.Integer{Value: node.Value}
+		return object.NewInteger(node.Value)
+
+	case *ast.BigIntegerLiteral:
+		return &object.BigInteger{Value: node.Value}
+
+	case *ast.DecimalLiteral:
+		return &object.Decimal{Value: node.Value}
+
+	case *ast.FloatLiteral:
+		return &object.Double{Value: node.Value}
 
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 
+	case *ast.CharLiteral:
+		return &object.Char{Value: node.Value}
+
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 
@@ -72,10 +121,16 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(left) {
 			return left
 		}
+		if node.Operator == "??" && left != NULL {
+			return left
+		}
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
+		if node.Operator == "??" {
+			return right
+		}
 		return evalInfixExpression(node.Operator, left, right)
 
 	case *ast.IfExpression:
@@ -84,21 +139,19 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 
+	case *ast.BuiltinReference:
+		return evalBuiltinReference(node)
+
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
-		return &object.Function{Parameters: params, Env: env, Body: body}
+		return &object.Function{Parameters: params, Defaults: node.Defaults, Rest: node.Rest, Env: env, Body: body}
 
-	case *ast.CallExpression:
-		function := Eval(node.Function, env)
-		if isError(function) {
-			return function
-		}
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
+	case *ast.MemberAccessExpression, *ast.CallExpression, *ast.IndexExpression, *ast.SliceExpression:
+		if result := evalChain(node.(ast.Expression), env); result != chainSkipped {
+			return result
 		}
-		return applyFunction(function, args)
+		return NULL
 
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
@@ -107,9 +160,54 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return &object.Array{Elements: elements}
 
+	case *ast.TupleLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return &object.Tuple{Elements: elements}
+
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+	}
+
+	return nil
+}
+
+// chainSkipped is what a link of a chain such as a?.b.c evaluates to
+// once a ?. has found null, so the links after it are skipped without
+// evaluating their arguments or indices. The root of the chain turns it
+// into null.
+var chainSkipped = &object.Null{}
+
+// evalChain evaluates a member access, call, index or slice, passing
+// chainSkipped on from the link it is applied to
+func evalChain(node ast.Expression, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	case *ast.MemberAccessExpression:
+		obj := evalChainObject(node.Object, env)
+		if obj == chainSkipped || isError(obj) {
+			return obj
+		}
+		if node.Optional && obj == NULL {
+			return chainSkipped
+		}
+		return evalMemberAccess(obj, node.Member.Value)
+
+	case *ast.CallExpression:
+		function := evalChainObject(node.Function, env)
+		if function == chainSkipped || isError(function) {
+			return function
+		}
+		args := evalExpressions(node.Arguments, env)
+		if len(args) == 1 && isError(args[0]) {
+			return args[0]
+		}
+		return applyFunction(function, args)
+
 	case *ast.IndexExpression:
-		left := Eval(node.Left, env)
-		if isError(left) {
+		left := evalChainObject(node.Left, env)
+		if left == chainSkipped || isError(left) {
 			return left
 		}
 		index := Eval(node.Index, env)
@@ -118,11 +216,51 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return evalIndexExpression(left, index)
 
-	case *ast.HashLiteral:
-		return evalHashLiteral(node, env)
+	case *ast.SliceExpression:
+		left := evalChainObject(node.Left, env)
+		if left == chainSkipped || isError(left) {
+			return left
+		}
+		bounds := []object.Object{NULL, NULL}
+		for i, bound := range []ast.Expression{node.Start, node.End} {
+			if bound != nil {
+				bounds[i] = Eval(bound, env)
+				if isError(bounds[i]) {
+					return bounds[i]
+				}
+			}
+		}
+		return object.Slice(left, bounds[0], bounds[1])
 	}
+	return Eval(node, env)
+}
 
-	return nil
+// evalChainObject evaluates the object of a chain link, continuing the
+// chain into it when it is a link itself
+func evalChainObject(node ast.Expression, env *object.Environment) object.Object {
+	switch node.(type) {
+	case *ast.MemberAccessExpression, *ast.CallExpression, *ast.IndexExpression, *ast.SliceExpression:
+		return evalChain(node, env)
+	}
+	return Eval(node, env)
+}
+
+// evalMemberAccess reads a field of a struct or a variant of an enum type,
+// the values with members that the evaluator can hold
+func evalMemberAccess(obj object.Object, name string) object.Object {
+	switch obj := obj.(type) {
+	case *object.Struct:
+		if value, ok := obj.Fields[name]; ok {
+			return value
+		}
+		return newError("struct has no field named '%s'", name)
+	case *object.EnumType:
+		if value, ok := obj.Variants[name]; ok {
+			return &object.Enum{EnumType: obj.Name, VariantName: name, Value: value}
+		}
+		return newError("enum %s has no variant '%s'", obj.Name, name)
+	}
+	return newError("cannot access field on type: %s", obj.Type())
 }
 
 func evalProgram(program *ast.Program, env *object.Environment) object.Object {
@@ -186,6 +324,59 @@ func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.
 	return result
 }
 
+// evalForStatement runs a পর্যন্ত loop. In a function, the variable the
+// loop declares gets a fresh binding for each iteration, holding the value
+// the last one ended with, so closures made in the body keep the value of
+// their own iteration as on the VM. At the top level it is a global like
+// any other.
+func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	loopEnv := env
+	var name string
+	if let, ok := fs.Init.(*ast.LetStatement); ok && env.Outer() != nil {
+		loopEnv = object.NewEnclosedEnvironment(env)
+		name = let.Name.Value
+	}
+	if fs.Init != nil {
+		if init := Eval(fs.Init, loopEnv); isError(init) {
+			return init
+		}
+	}
+
+	var result object.Object = NULL
+	for {
+		if fs.Condition != nil {
+			condition := Eval(fs.Condition, loopEnv)
+			if isError(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				break
+			}
+		}
+
+		result = Eval(fs.Body, loopEnv)
+		if isError(result) {
+			return result
+		}
+		if result != nil && result.Type() == object.RETURN_VALUE_OBJ {
+			return result
+		}
+
+		if name != "" {
+			value, _ := loopEnv.Get(name)
+			loopEnv = object.NewEnclosedEnvironment(env)
+			loopEnv.Set(name, value)
+		}
+		if fs.Increment != nil {
+			if increment := Eval(fs.Increment, loopEnv); isError(increment) {
+				return increment
+			}
+		}
+	}
+
+	return result
+}
+
 func nativeBoolToBooleanObject(input bool) *object.Boolean {
 	if input {
 		return TRUE
@@ -218,20 +409,40 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+	if b, ok := right.(*object.BigInteger); ok {
+		return &object.BigInteger{Value: new(big.Int).Neg(b.Value)}
+	}
 	if right.Type() != object.INTEGER_OBJ {
 		return newError("unknown operator: -%s", right.Type())
 	}
 
 	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+	return object.NewInteger(-value)
 }
 
 func evalInfixExpression(operator string, left, right object.Object) object.Object {
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isInteger(left) && isInteger(right):
+		return evalBigIntegerInfixExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
+	case left.Type() == object.CHAR_OBJ && right.Type() == object.CHAR_OBJ:
+		return evalCharInfixExpression(operator, left, right)
+	case operator == "+" && (left.Type() == object.STRING_OBJ && right.Type() == object.CHAR_OBJ ||
+		left.Type() == object.CHAR_OBJ && right.Type() == object.STRING_OBJ):
+		// A character joins a string as the one-character string it is
+		return &object.String{Value: left.Inspect() + right.Inspect()}
+	case left.Type() == object.TUPLE_OBJ && right.Type() == object.TUPLE_OBJ && (operator == "==" || operator == "!="):
+		// Tuples that can be hash keys are equal when their keys are
+		lk, lok := object.HashKeyOf(left)
+		rk, rok := object.HashKeyOf(right)
+		equal := left == right || (lok && rok && lk == rk)
+		return nativeBoolToBooleanObject(equal == (operator == "=="))
+	case (left == NULL || right == NULL) && (operator == "<" || operator == ">" || operator == "<=" || operator == ">="):
+		// As on the VM, null orders neither before nor after a value
+		return nativeBoolToBooleanObject(left == right && (operator == "<=" || operator == ">="))
 	case operator == "==":
 		return nativeBoolToBooleanObject(left == right)
 	case operator == "!=":
@@ -243,24 +454,55 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	}
 }
 
+// evalCharInfixExpression compares two characters by code point
+func evalCharInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Char).Value
+	rightVal := right.(*object.Char).Value
+
+	switch operator {
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
 func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 
+	// Integers grow into big integers instead of wrapping around
+	if object.IntegerOverflows(operator, leftVal, rightVal) {
+		return evalBigIntegerInfixExpression(operator, left, right)
+	}
+
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		return object.NewInteger(leftVal + rightVal)
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		return object.NewInteger(leftVal - rightVal)
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		return object.NewInteger(leftVal * rightVal)
 	case "/":
 		if rightVal == 0 {
 			return newError("division by zero")
 		}
-		return &object.Integer{Value: leftVal / rightVal}
+		return object.NewInteger(leftVal / rightVal)
 	case "%":
-		return &object.Integer{Value: leftVal % rightVal}
+		if rightVal == 0 {
+			return newError("modulo by zero")
+		}
+		return object.NewInteger(leftVal % rightVal)
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
@@ -278,14 +520,53 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	}
 }
 
-func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
-	if operator != "+" {
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+// isInteger reports whether obj is an Integer or a BigInteger
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIG_INTEGER_OBJ
+}
+
+// evalBigIntegerInfixExpression handles integers where one is a BigInteger
+// or where Integer arithmetic overflowed
+func evalBigIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal, _ := object.BigValue(left)
+	rightVal, _ := object.BigValue(right)
+
+	switch cmp := leftVal.Cmp(rightVal); operator {
+	case "<":
+		return nativeBoolToBooleanObject(cmp < 0)
+	case ">":
+		return nativeBoolToBooleanObject(cmp > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(cmp <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(cmp >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(cmp == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(cmp != 0)
 	}
 
+	result, err := object.BigArithmetic(operator, leftVal, rightVal)
+	if err != nil {
+		return err
+	}
+	return result
+}
+
+func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
-	return &object.String{Value: leftVal + rightVal}
+
+	switch operator {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
 }
 
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
@@ -315,6 +596,18 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	return newError("identifier not found: " + node.Value)
 }
 
+// evalBuiltinReference looks a builtin up without consulting the
+// environment, preferring the evaluator's own version of it
+func evalBuiltinReference(node *ast.BuiltinReference) object.Object {
+	if builtin, ok := builtins[node.Name]; ok {
+		return builtin
+	}
+	if builtin := object.GetBuiltinByName(node.Name); builtin != nil {
+		return builtin
+	}
+	return newError("unknown builtin: " + node.Name)
+}
+
 func isTruthy(obj object.Object) bool {
 	switch obj {
 	case NULL:
@@ -345,26 +638,49 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 func applyFunction(fn object.Object, args []object.Object) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
+		extendedEnv, err := extendFunctionEnv(fn, args)
+		if err != nil {
+			return err
+		}
 		evaluated := Eval(fn.Body, extendedEnv)
 		return unwrapReturnValue(evaluated)
 
 	case *object.Builtin:
-		return fn.Fn(args...)
+		return fn.Call(object.DefaultRuntime, args...)
 
 	default:
 		return newError("not a function: %s", fn.Type())
 	}
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+func extendFunctionEnv(fn *object.Function, args []object.Object) (*object.Environment, *object.Error) {
 	env := object.NewEnclosedEnvironment(fn.Env)
 
 	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx])
+		if fn.Rest && paramIdx == len(fn.Parameters)-1 {
+			rest := []object.Object{}
+			if paramIdx < len(args) {
+				rest = append(rest, args[paramIdx:]...)
+			}
+			env.Set(param.Value, &object.Array{Elements: rest})
+			continue
+		}
+		if paramIdx < len(args) {
+			env.Set(param.Value, args[paramIdx])
+			continue
+		}
+		// A left-out argument takes the parameter's default value
+		if paramIdx >= len(fn.Defaults) || fn.Defaults[paramIdx] == nil {
+			return nil, newError("wrong number of arguments: want=%d, got=%d", len(fn.Parameters), len(args))
+		}
+		value := Eval(fn.Defaults[paramIdx], env)
+		if errObj, ok := value.(*object.Error); ok {
+			return nil, errObj
+		}
+		env.Set(param.Value, value)
 	}
 
-	return env
+	return env, nil
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
@@ -378,6 +694,25 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.TUPLE_OBJ && index.Type() == object.INTEGER_OBJ:
+		elements := left.(*object.Tuple).Elements
+		idx, ok := object.ResolveIndex(index.(*object.Integer).Value, len(elements))
+		if !ok {
+			return NULL
+		}
+		return elements[idx]
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		char, ok := object.IndexString(left.(*object.String), index.(*object.Integer).Value)
+		if !ok {
+			return NULL
+		}
+		return char
+	case left.Type() == object.BYTES_OBJ && index.Type() == object.INTEGER_OBJ:
+		b, ok := object.IndexBytes(left.(*object.Bytes), index.(*object.Integer).Value)
+		if !ok {
+			return NULL
+		}
+		return b
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
 	default:
@@ -387,26 +722,43 @@ func evalIndexExpression(left, index object.Object) object.Object {
 
 func evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrayObject := array.(*object.Array)
-	idx := index.(*object.Integer).Value
-	max := int64(len(arrayObject.Elements) - 1)
-
-	if idx < 0 || idx > max {
+	idx, ok := object.ResolveIndex(index.(*object.Integer).Value, len(arrayObject.Elements))
+	if !ok {
 		return NULL
 	}
 
 	return arrayObject.Elements[idx]
 }
 
+// unpackValues returns the elements of a tuple or array destructured into
+// numNames names, or an error when their number differs
+func unpackValues(value object.Object, numNames int) ([]object.Object, *object.Error) {
+	var elements []object.Object
+	switch value := value.(type) {
+	case *object.Tuple:
+		elements = value.Elements
+	case *object.Array:
+		elements = value.Elements
+	default:
+		return nil, newError("cannot unpack %s into %d names", value.Type(), numNames)
+	}
+	if len(elements) != numNames {
+		return nil, newError("cannot unpack %d values into %d names", len(elements), numNames)
+	}
+	return elements, nil
+}
+
 func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
 	pairs := make(map[object.HashKey]object.HashPair)
 
-	for keyNode, valueNode := range node.Pairs {
+	for _, keyNode := range node.SortedKeys() {
+		valueNode := node.Pairs[keyNode]
 		key := Eval(keyNode, env)
 		if isError(key) {
 			return key
 		}
 
-		hashKey, ok := key.(object.Hashable)
+		hashed, ok := object.HashKeyOf(key)
 		if !ok {
 			return newError("unusable as hash key: %s", key.Type())
 		}
@@ -416,7 +768,6 @@ func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Obje
 			return value
 		}
 
-		hashed := hashKey.HashKey()
 		pairs[hashed] = object.HashPair{Key: key, Value: value}
 	}
 
@@ -426,12 +777,12 @@ func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Obje
 func evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObject := hash.(*object.Hash)
 
-	key, ok := index.(object.Hashable)
+	key, ok := object.HashKeyOf(index)
 	if !ok {
 		return newError("unusable as hash key: %s", index.Type())
 	}
 
-	pair, ok := hashObject.Pairs[key.HashKey()]
+	pair, ok := hashObject.Pairs[key]
 	if !ok {
 		return NULL
 	}
@@ -0,0 +1,853 @@
+// Package format prints programs in the canonical layout that bhasa fmt
+// writes: four-space indentation, one statement per line ending in a
+// semicolon, single spaces around binary operators and after commas, and
+// parentheses only where the grammar needs them. Comments and single
+// blank lines between statements are kept where they were written.
+package format
+
+import (
+	"bhasa/ast"
+	"bhasa/parser"
+	"bhasa/token"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// indentUnit is one level of indentation
+const indentUnit = "    "
+
+// Program prints a parsed program in canonical form. src is the text it
+// was parsed from, which holds the comments and the spelling of number
+// literals that the syntax tree does not keep.
+func Program(program *ast.Program, src string) string {
+	p := &printer{src: scan(src), lineStart: true, fresh: true}
+	p.statements(program.Statements)
+	p.flushComments(len(p.src.lines) + 1)
+
+	out := strings.TrimRight(p.alignComments(), "\n")
+	if out == "" {
+		return ""
+	}
+	return out + "\n"
+}
+
+// printer writes a program out line by line. Indentation is written when
+// the first text of a line is, so a comment can still be added to the end
+// of the line before.
+type printer struct {
+	buf       bytes.Buffer
+	src       *source
+	indent    int
+	lineStart bool // nothing has been written on the current line
+	fresh     bool // nothing has been written in the current block
+	comment   int  // the first comment not yet printed
+	trailing  []trailing
+}
+
+// trailing is a comment printed after the code on a line of the output
+type trailing struct {
+	line int // the output line, counted from 0
+	code int // the length in bytes of the code before it
+}
+
+func (p *printer) write(s string) {
+	if p.lineStart {
+		p.buf.WriteString(strings.Repeat(indentUnit, p.indent))
+		p.lineStart = false
+	}
+	p.buf.WriteString(s)
+}
+
+func (p *printer) newline() {
+	p.buf.WriteByte('\n')
+	p.lineStart = true
+}
+
+// startItem begins a line for a statement, member or comment written at
+// line of the source, keeping one blank line above it if the source had
+// one or more
+func (p *printer) startItem(line int) {
+	if !p.lineStart {
+		p.newline()
+	}
+	if !p.fresh && p.src.blank(line-1) && !bytes.HasSuffix(p.buf.Bytes(), []byte("\n\n")) {
+		p.newline()
+	}
+	p.fresh = false
+}
+
+// flushComments prints the comments written before line. One that
+// followed code on its line goes at the end of the last line printed;
+// the others get lines of their own. Only call it at the start of a line.
+func (p *printer) flushComments(line int) {
+	for ; p.comment < len(p.src.comments); p.comment++ {
+		c := p.src.comments[p.comment]
+		if c.line >= line {
+			return
+		}
+		if !c.ownLine && p.buf.Len() > 0 && p.lineStart {
+			p.buf.Truncate(p.buf.Len() - 1)
+			out := p.buf.Bytes()
+			p.trailing = append(p.trailing, trailing{
+				line: bytes.Count(out, []byte("\n")),
+				code: len(out) - (bytes.LastIndexByte(out, '\n') + 1),
+			})
+			p.buf.WriteString(" " + c.text)
+			p.newline()
+			continue
+		}
+		if line := bytes.Count(p.buf.Bytes(), []byte("\n")); c.continues && p.lineStart &&
+			len(p.trailing) > 0 && p.trailing[len(p.trailing)-1].line == line-1 {
+			// Lined up under the comment above, as part of it
+			p.trailing = append(p.trailing, trailing{line: line})
+			p.buf.WriteString(" " + c.text)
+			p.newline()
+			continue
+		}
+		p.startItem(c.line)
+		p.write(c.text)
+		p.newline()
+	}
+}
+
+// alignComments returns the output with the comments that follow code on
+// consecutive lines lined up one space after the longest of those lines,
+// counting characters as the source's own aligned comments do
+func (p *printer) alignComments() string {
+	lines := strings.Split(p.buf.String(), "\n")
+	for start := 0; start < len(p.trailing); {
+		end := start + 1
+		for end < len(p.trailing) && p.trailing[end].line == p.trailing[end-1].line+1 {
+			end++
+		}
+		width := 0
+		for _, t := range p.trailing[start:end] {
+			if w := utf8.RuneCountInString(lines[t.line][:t.code]); w > width {
+				width = w
+			}
+		}
+		for _, t := range p.trailing[start:end] {
+			code, text := lines[t.line][:t.code], lines[t.line][t.code+1:]
+			lines[t.line] = code + strings.Repeat(" ", width-utf8.RuneCountInString(code)+1) + text
+		}
+		start = end
+	}
+	return strings.Join(lines, "\n")
+}
+
+// statements prints a list of statements, each on its own lines with the
+// comments above it
+func (p *printer) statements(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		line := lineOf(stmt)
+		p.flushComments(line)
+		p.startItem(line)
+		p.statement(stmt)
+		p.newline()
+	}
+}
+
+// block prints { statements } with the statements indented, and the
+// comments inside it before the closing brace
+func (p *printer) block(block *ast.BlockStatement) {
+	closing := p.src.closeAfter(block.Token.Line, block.Token.Column)
+	p.body(len(block.Statements) == 0, closing, func() {
+		p.statements(block.Statements)
+	})
+}
+
+// body prints braces around content, which is printed indented on the
+// lines between them. closing is the source line of the closing brace, or
+// 0 when it is not known.
+func (p *printer) body(empty bool, closing int, content func()) {
+	if empty && !p.commentBefore(closing) {
+		p.write("{}")
+		return
+	}
+	p.write("{")
+	p.newline()
+	p.indent++
+	p.fresh = true
+	content()
+	if closing > 0 {
+		p.flushComments(closing)
+	}
+	p.indent--
+	p.write("}")
+	p.fresh = false
+}
+
+// commentBefore reports whether a comment not yet printed comes before
+// line
+func (p *printer) commentBefore(line int) bool {
+	return p.comment < len(p.src.comments) && p.src.comments[p.comment].line < line
+}
+
+func (p *printer) statement(stmt ast.Statement) {
+	switch s := stmt.(type) {
+	case *ast.ExpressionStatement:
+		p.expr(s.Expression, parser.LOWEST)
+		if _, ok := s.Expression.(*ast.IfExpression); !ok {
+			p.write(";")
+		}
+	case *ast.ReturnStatement:
+		p.write(string(token.RETURN) + " ")
+		if tuple, ok := s.ReturnValue.(*ast.TupleLiteral); ok && tuple.Token.Type != token.LPAREN {
+			p.list(tuple.Elements)
+		} else {
+			p.expr(s.ReturnValue, parser.LOWEST)
+		}
+		p.write(";")
+	case *ast.ImportStatement:
+		p.write(string(token.IMPORT) + " ")
+		p.expr(s.Path, parser.LOWEST)
+		p.write(";")
+	case *ast.BreakStatement, *ast.ContinueStatement:
+		p.write(ast.NodeToken(s).Literal + ";")
+	case *ast.WhileStatement:
+		p.write(string(token.WHILE) + " (")
+		p.expr(s.Condition, parser.LOWEST)
+		p.write(") ")
+		p.block(s.Body)
+	case *ast.ForStatement:
+		p.write(string(token.FOR) + " (")
+		if s.Init != nil {
+			p.clause(s.Init)
+		}
+		p.write(";")
+		if s.Condition != nil {
+			p.write(" ")
+			p.expr(s.Condition, parser.LOWEST)
+		}
+		p.write(";")
+		if s.Increment != nil {
+			p.write(" ")
+			p.clause(s.Increment)
+		}
+		p.write(") ")
+		p.block(s.Body)
+	case *ast.ForEachStatement:
+		p.write(string(token.FOREACH) + " (" + s.Variable.Value + " " + string(token.IN) + " ")
+		p.expr(s.Iterable, parser.LOWEST)
+		p.write(") ")
+		p.block(s.Body)
+	case *ast.ClassDefinition:
+		p.class(s)
+	case *ast.InterfaceDefinition:
+		p.iface(s)
+	default:
+		p.clause(stmt)
+		p.write(";")
+	}
+}
+
+// clause prints a declaration, assignment or expression without its
+// semicolon, as it is written in the header of a পর্যন্ত loop
+func (p *printer) clause(stmt ast.Statement) {
+	switch s := stmt.(type) {
+	case *ast.LetStatement:
+		p.write(string(token.LET) + " " + s.Name.Value)
+		if s.TypeAnnot != nil {
+			p.write(": " + s.TypeAnnot.String())
+		}
+		p.write(" = ")
+		p.expr(s.Value, parser.LOWEST)
+	case *ast.DestructuringLetStatement:
+		names := make([]string, len(s.Names))
+		for i, name := range s.Names {
+			names[i] = name.Value
+		}
+		p.write(string(token.LET) + " " + strings.Join(names, ", ") + " = ")
+		p.expr(s.Value, parser.LOWEST)
+	case *ast.AssignmentStatement:
+		p.write(s.Name.Value + " = ")
+		p.expr(s.Value, parser.LOWEST)
+	case *ast.MemberAssignmentStatement:
+		p.expr(s.Object, parser.CALL)
+		p.write("." + s.Member.Value + " = ")
+		p.expr(s.Value, parser.LOWEST)
+	case *ast.IndexAssignmentStatement:
+		p.expr(s.Left, parser.CALL)
+		p.write("[")
+		p.expr(s.Index, parser.LOWEST)
+		p.write("] = ")
+		p.expr(s.Value, parser.LOWEST)
+	case *ast.CompoundAssignmentStatement:
+		p.expr(s.Target, parser.LOWEST)
+		p.write(" " + s.Operator + "= ")
+		p.expr(s.Value, parser.LOWEST)
+	case *ast.ExpressionStatement:
+		p.expr(s.Expression, parser.LOWEST)
+	default:
+		p.write(stmt.String())
+	}
+}
+
+// member is a field, constructor or method of a class, with the source
+// line it starts at
+type member struct {
+	line  int
+	print func()
+}
+
+func (p *printer) class(c *ast.ClassDefinition) {
+	if c.IsAbstract {
+		p.write(string(token.ABSTRACT) + " ")
+	}
+	if c.IsFinal {
+		p.write(string(token.FINAL) + " ")
+	}
+	p.write(string(token.CLASS) + " " + c.Name.Value)
+	last := c.Name
+	if c.SuperClass != nil {
+		p.write(" " + string(token.EXTENDS) + " " + c.SuperClass.Value)
+		last = c.SuperClass
+	}
+	if len(c.Interfaces) > 0 {
+		names := make([]string, len(c.Interfaces))
+		for i, iface := range c.Interfaces {
+			names[i] = iface.Value
+		}
+		p.write(" " + string(token.IMPLEMENTS) + " " + strings.Join(names, ", "))
+		last = c.Interfaces[len(c.Interfaces)-1]
+	}
+	p.write(" ")
+
+	var members []member
+	for _, f := range c.Fields {
+		f := f
+		members = append(members, member{f.Token.Line, func() { p.field(f) }})
+	}
+	for _, ctor := range c.Constructors {
+		ctor := ctor
+		members = append(members, member{ctor.Token.Line, func() {
+			p.write(p.modifiers(ctor.Token, ctor.Access, false, false, false, false) + string(token.CONSTRUCTOR))
+			p.parameters(ctor.Parameters, ctor.ParameterTypes, ctor.Defaults, ctor.Rest)
+			p.write(" ")
+			p.block(ctor.Body)
+		}})
+	}
+	for _, m := range c.Methods {
+		m := m
+		members = append(members, member{m.Token.Line, func() { p.method(m) }})
+	}
+	sort.SliceStable(members, func(i, j int) bool { return members[i].line < members[j].line })
+
+	p.members(members, p.src.closeAfter(last.Token.Line, last.Token.Column))
+}
+
+// members prints the members of a class or interface in braces
+func (p *printer) members(members []member, closing int) {
+	p.body(len(members) == 0, closing, func() {
+		for _, m := range members {
+			p.flushComments(m.line)
+			p.startItem(m.line)
+			m.print()
+			p.newline()
+		}
+	})
+}
+
+func (p *printer) field(f *ast.ClassField) {
+	p.write(p.modifiers(f.Token, f.Access, f.IsStatic, f.IsFinal, false, false) + f.Name)
+	if f.TypeAnnot != nil {
+		p.write(": " + f.TypeAnnot.String())
+	}
+	if f.Value != nil {
+		p.write(" = ")
+		p.expr(f.Value, parser.LOWEST)
+	}
+	p.write(";")
+}
+
+func (p *printer) method(m *ast.MethodDefinition) {
+	p.write(p.modifiers(m.Token, m.Access, m.IsStatic, m.IsFinal, m.IsAbstract, m.IsOverride) + string(token.METHOD) + " " + m.Name.Value)
+	p.parameters(m.Parameters, m.ParameterTypes, m.Defaults, m.Rest)
+	if m.ReturnType != nil {
+		p.write(": " + m.ReturnType.String())
+	}
+	if m.Body == nil {
+		p.write(";")
+		return
+	}
+	p.write(" ")
+	p.block(m.Body)
+}
+
+// modifiers spells out the modifiers of the class member at tok in the
+// order they are conventionally written, each followed by a space.
+// Members are public unless marked otherwise, so সার্বজনীন is only
+// printed where it was written.
+func (p *printer) modifiers(tok token.Token, access ast.AccessModifier, static, final, abstract, override bool) string {
+	var out strings.Builder
+	if access != "" && (access != ast.PUBLIC || p.writtenBefore(tok, string(ast.PUBLIC))) {
+		out.WriteString(string(access) + " ")
+	}
+	for _, m := range []struct {
+		set bool
+		tok token.TokenType
+	}{{static, token.STATIC}, {final, token.FINAL}, {abstract, token.ABSTRACT}, {override, token.OVERRIDE}} {
+		if m.set {
+			out.WriteString(string(m.tok) + " ")
+		}
+	}
+	return out.String()
+}
+
+// writtenBefore reports whether word is written before tok on its line
+func (p *printer) writtenBefore(tok token.Token, word string) bool {
+	line := p.src.text(tok.Line, 1)
+	if tok.Column-1 > len(line) {
+		return false
+	}
+	return strings.Contains(string(line[:tok.Column-1]), word)
+}
+
+func (p *printer) iface(i *ast.InterfaceDefinition) {
+	p.write(string(token.INTERFACE) + " " + i.Name.Value + " ")
+	var members []member
+	for _, m := range i.Methods {
+		m := m
+		members = append(members, member{m.Name.Token.Line, func() {
+			p.write(string(token.METHOD) + " " + m.Name.Value)
+			p.parameters(m.Parameters, m.ParameterTypes, nil, false)
+			if m.ReturnType != nil {
+				p.write(": " + m.ReturnType.String())
+			}
+			p.write(";")
+		}})
+	}
+	p.members(members, p.src.closeAfter(i.Name.Token.Line, i.Name.Token.Column))
+}
+
+// parameters prints a parenthesized parameter list with its types,
+// default values and rest parameter
+func (p *printer) parameters(params []*ast.Identifier, types []*ast.TypeAnnotation, defaults []ast.Expression, rest bool) {
+	p.write("(")
+	for i, param := range params {
+		if i > 0 {
+			p.write(", ")
+		}
+		if rest && i == len(params)-1 {
+			p.write("...")
+		}
+		p.write(param.Value)
+		if i < len(types) && types[i] != nil {
+			p.write(": " + types[i].String())
+		}
+		if i < len(defaults) && defaults[i] != nil {
+			p.write(" = ")
+			p.expr(defaults[i], parser.LOWEST)
+		}
+	}
+	p.write(")")
+}
+
+// binaryPrecedence is how tightly each infix operator binds, as the
+// parser sees it
+var binaryPrecedence = map[string]int{
+	"??": parser.COALESCE,
+	"||": parser.LOGICAL_OR,
+	"&&": parser.LOGICAL_AND,
+	"|":  parser.BIT_OR,
+	"^":  parser.BIT_XOR,
+	"&":  parser.BIT_AND,
+	"==": parser.EQUALS,
+	"!=": parser.EQUALS,
+	"<":  parser.LESSGREATER,
+	">":  parser.LESSGREATER,
+	"<=": parser.LESSGREATER,
+	">=": parser.LESSGREATER,
+	"<<": parser.SHIFT,
+	">>": parser.SHIFT,
+	"+":  parser.SUM,
+	"-":  parser.SUM,
+	"*":  parser.PRODUCT,
+	"/":  parser.PRODUCT,
+	"%":  parser.PRODUCT,
+}
+
+// precedence is how tightly an expression holds together: an operand
+// printed where a higher precedence is needed gets parentheses
+func precedence(e ast.Expression) int {
+	switch e := e.(type) {
+	case *ast.InfixExpression:
+		return binaryPrecedence[e.Operator]
+	case *ast.PrefixExpression:
+		return parser.PREFIX
+	case *ast.FunctionLiteral:
+		if e.Arrow {
+			return parser.LAMBDA
+		}
+	case *ast.CallExpression, *ast.IndexExpression, *ast.SliceExpression, *ast.MemberAccessExpression,
+		*ast.MethodCallExpression, *ast.TypeCastExpression:
+		return parser.CALL
+	}
+	return parser.INDEX + 1
+}
+
+// expr prints an expression, in parentheses if it binds less tightly than
+// prec
+func (p *printer) expr(e ast.Expression, prec int) {
+	if precedence(e) < prec {
+		p.write("(")
+		defer p.write(")")
+	}
+
+	switch e := e.(type) {
+	case *ast.Identifier:
+		p.write(e.Value)
+	case *ast.IntegerLiteral, *ast.BigIntegerLiteral, *ast.FloatLiteral, *ast.DecimalLiteral:
+		p.write(p.number(ast.NodeToken(e)))
+	case *ast.StringLiteral:
+		if e.Token.Type == token.RAW_STRING {
+			p.write("`" + e.Token.Literal + "`")
+		} else {
+			p.write(`"` + e.Token.Literal + `"`)
+		}
+	case *ast.InterpolatedString:
+		p.write(`"` + e.Token.Literal + `"`)
+	case *ast.CharLiteral:
+		if e.Value == '\'' {
+			p.write("'''")
+		} else {
+			p.write("'" + string(e.Value) + "'")
+		}
+	case *ast.Boolean:
+		p.write(e.Token.Literal)
+	case *ast.ThisExpression:
+		p.write(string(token.THIS))
+	case *ast.SuperExpression:
+		p.write(string(token.SUPER))
+	case *ast.PrefixExpression:
+		p.write(e.Operator)
+		p.expr(e.Right, parser.PREFIX)
+	case *ast.InfixExpression:
+		prec := binaryPrecedence[e.Operator]
+		p.operand(e.Left, e.Operator, prec)
+		p.write(" " + e.Operator + " ")
+		p.operand(e.Right, e.Operator, prec+1)
+	case *ast.IfExpression:
+		p.write(string(token.IF) + " (")
+		p.expr(e.Condition, parser.LOWEST)
+		p.write(") ")
+		p.block(e.Consequence)
+		if e.Alternative != nil {
+			p.write(" " + string(token.ELSE) + " ")
+			p.block(e.Alternative)
+		}
+	case *ast.FunctionLiteral:
+		p.function(e)
+	case *ast.CallExpression:
+		p.expr(e.Function, parser.CALL)
+		p.write("(")
+		p.list(e.Arguments)
+		p.write(")")
+	case *ast.MethodCallExpression:
+		p.expr(e.Object, parser.CALL)
+		p.write("." + e.MethodName.Value + "(")
+		p.list(e.Arguments)
+		p.write(")")
+	case *ast.NewExpression:
+		p.write(string(token.NEW) + " " + e.ClassName.Value + "(")
+		p.list(e.Arguments)
+		p.write(")")
+	case *ast.IndexExpression:
+		p.expr(e.Left, parser.CALL)
+		p.write("[")
+		p.expr(e.Index, parser.LOWEST)
+		p.write("]")
+	case *ast.SliceExpression:
+		p.expr(e.Left, parser.CALL)
+		p.write("[")
+		if e.Start != nil {
+			p.expr(e.Start, parser.LOWEST)
+		}
+		p.write(":")
+		if e.End != nil {
+			p.expr(e.End, parser.LOWEST)
+		}
+		p.write("]")
+	case *ast.MemberAccessExpression:
+		p.expr(e.Object, parser.CALL)
+		if e.Optional {
+			p.write("?.")
+		} else {
+			p.write(".")
+		}
+		p.write(e.Member.Value)
+	case *ast.EnumValue:
+		p.write(e.EnumType.Value + "." + e.VariantName.Value)
+	case *ast.TypeCastExpression:
+		p.expr(e.Expression, parser.CALL)
+		p.write(" " + string(token.AS) + " " + e.TargetType.String())
+	case *ast.TupleLiteral:
+		p.write("(")
+		p.list(e.Elements)
+		if len(e.Elements) == 1 {
+			p.write(",")
+		}
+		p.write(")")
+	case *ast.ArrayLiteral:
+		p.elements("[", "]", e.Token.Line, len(e.Elements), func(i int) {
+			p.expr(e.Elements[i], parser.LOWEST)
+		}, func(i int) int { return lineOf(e.Elements[i]) })
+	case *ast.HashLiteral:
+		keys := e.SortedKeys()
+		p.elements("{", "}", e.Token.Line, len(keys), func(i int) {
+			p.expr(keys[i], parser.LOWEST)
+			p.write(": ")
+			p.expr(e.Pairs[keys[i]], parser.LOWEST)
+		}, func(i int) int { return lineOf(keys[i]) })
+	case *ast.StructLiteral:
+		if e.StructType != nil {
+			p.write(e.StructType.Value)
+		} else {
+			p.write(string(token.STRUCT) + " ")
+		}
+		p.elements("{", "}", e.Token.Line, len(e.FieldOrder), func(i int) {
+			p.write(e.FieldOrder[i] + ": ")
+			p.expr(e.Fields[e.FieldOrder[i]], parser.LOWEST)
+		}, func(i int) int { return lineOf(e.Fields[e.FieldOrder[i]]) })
+	case *ast.EnumDefinition:
+		p.enum(e)
+	case *ast.StructDefinition:
+		fields := make([]string, len(e.Fields))
+		for i, f := range e.Fields {
+			fields[i] = f.Name + ": " + f.TypeAnnot.String()
+		}
+		p.write(string(token.STRUCT) + " {" + strings.Join(fields, ", ") + "}")
+	default:
+		p.write(e.String())
+	}
+}
+
+// clarified are the operators whose mixing is easy to misread, such as
+// a & b | c; an operand using another of them keeps its parentheses
+var clarified = map[string]bool{
+	"&": true, "|": true, "^": true, "<<": true, ">>": true,
+	"&&": true, "||": true, "??": true,
+}
+
+// operand prints an operand of the infix operator op, which needs prec
+func (p *printer) operand(e ast.Expression, op string, prec int) {
+	if infix, ok := e.(*ast.InfixExpression); ok && infix.Operator != op && clarified[op] && clarified[infix.Operator] {
+		p.write("(")
+		p.expr(e, parser.LOWEST)
+		p.write(")")
+		return
+	}
+	p.expr(e, prec)
+}
+
+// list prints expressions separated by commas
+func (p *printer) list(exprs []ast.Expression) {
+	for i, e := range exprs {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.expr(e, parser.LOWEST)
+	}
+}
+
+// elements prints the n elements of a literal between open and close. It
+// stays on one line unless the source put elements on lines after the
+// opening one; then each element gets a line of its own.
+func (p *printer) elements(open, close string, line, n int, element func(int), lineOf func(int) int) {
+	multiline := false
+	for i := 0; i < n; i++ {
+		if lineOf(i) > line {
+			multiline = true
+		}
+	}
+	p.write(open)
+	if !multiline {
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				p.write(", ")
+			}
+			element(i)
+		}
+		p.write(close)
+		return
+	}
+
+	p.newline()
+	p.indent++
+	for i := 0; i < n; i++ {
+		p.flushComments(lineOf(i))
+		p.write("")
+		element(i)
+		if i < n-1 {
+			p.write(",")
+		}
+		p.newline()
+	}
+	p.indent--
+	p.write(close)
+}
+
+func (p *printer) function(f *ast.FunctionLiteral) {
+	if f.Arrow {
+		p.parameters(f.Parameters, nil, nil, false)
+		p.write(" => ")
+		if body := f.ArrowExpression(); body != nil && startsWithBrace(body) {
+			p.write("(")
+			p.expr(body, parser.LOWEST)
+			p.write(")")
+		} else if body != nil {
+			p.expr(body, parser.LOWEST)
+		} else {
+			p.block(f.Body)
+		}
+		return
+	}
+	p.write(string(token.FUNCTION))
+	p.parameters(f.Parameters, f.ParameterTypes, f.Defaults, f.Rest)
+	if f.ReturnType != nil {
+		p.write(": " + f.ReturnType.String())
+	}
+	p.write(" ")
+	p.block(f.Body)
+}
+
+func (p *printer) enum(e *ast.EnumDefinition) {
+	p.write(string(token.ENUM) + " ")
+	if len(e.Variants) == 0 {
+		p.write("{}")
+		return
+	}
+	variants := make([]string, len(e.Variants))
+	for i, v := range e.Variants {
+		variants[i] = v.Name
+		if v.Value != nil {
+			variants[i] += " = " + p.enumValue(v)
+		}
+	}
+	p.write("{ " + strings.Join(variants, ", ") + " }")
+}
+
+// enumValue spells the explicit value of an enum variant as the source
+// did, read after its name and =
+func (p *printer) enumValue(v *ast.EnumVariant) string {
+	want := fmt.Sprint(*v.Value)
+	text := p.src.text(v.Token.Line, v.Token.Column+len([]rune(v.Name)))
+	i := 0
+	for i < len(text) && (text[i] == ' ' || text[i] == '\t' || text[i] == '=') {
+		i++
+	}
+	if spelled := numberText(text[i:]); token.ConvertBengaliNumber(spelled) == want {
+		return spelled
+	}
+	return want
+}
+
+// number spells a number literal as the source did, in Bengali or Arabic
+// digits and with any _ separators, falling back to the token's text
+func (p *printer) number(tok token.Token) string {
+	spelled := numberText(p.src.text(tok.Line, tok.Column))
+	normal := token.NormalizeFloat(strings.ReplaceAll(spelled, "_", ""))
+	if tok.Type == token.DECIMAL {
+		normal = strings.TrimRight(normal, string(token.DecimalSuffix)+"d")
+		if normal == tok.Literal {
+			return spelled
+		}
+		return tok.Literal + "d"
+	}
+	if normal == tok.Literal {
+		return spelled
+	}
+	return tok.Literal
+}
+
+// numberText returns the number literal at the start of text the way the
+// lexer reads one: digits, a fraction, an exponent and a decimal suffix
+func numberText(text []rune) string {
+	i := digits(text, 0)
+	if i < len(text)-1 && text[i] == '.' && isDigit(text[i+1]) {
+		i = digits(text, i+1)
+	}
+	if i < len(text)-1 && (text[i] == 'e' || text[i] == 'E' || text[i] == token.ExponentMarker) {
+		if (text[i+1] == '+' || text[i+1] == '-') && i+2 < len(text) && isDigit(text[i+2]) {
+			i = digits(text, i+2)
+		} else if isDigit(text[i+1]) {
+			i = digits(text, i+1)
+		}
+	}
+	if i < len(text) && (text[i] == token.DecimalSuffix || text[i] == 'd') {
+		i++
+	}
+	return string(text[:i])
+}
+
+// digits returns the index just past a run of digits and _ separators
+func digits(text []rune, i int) int {
+	for i < len(text) && (isDigit(text[i]) || (text[i] == '_' && i+1 < len(text) && isDigit(text[i+1]))) {
+		i++
+	}
+	return i
+}
+
+func isDigit(r rune) bool {
+	return ('0' <= r && r <= '9') || ('০' <= r && r <= '৯')
+}
+
+// startsWithBrace reports whether an expression is printed starting with
+// {, which after => would be read as a block
+func startsWithBrace(e ast.Expression) bool {
+	switch e := e.(type) {
+	case *ast.HashLiteral:
+		return true
+	case *ast.InfixExpression:
+		return startsWithBrace(e.Left)
+	case *ast.CallExpression:
+		return startsWithBrace(e.Function)
+	case *ast.IndexExpression:
+		return startsWithBrace(e.Left)
+	case *ast.SliceExpression:
+		return startsWithBrace(e.Left)
+	case *ast.MemberAccessExpression:
+		return startsWithBrace(e.Object)
+	case *ast.TypeCastExpression:
+		return startsWithBrace(e.Expression)
+	}
+	return false
+}
+
+// lineOf returns the source line a node starts at. Assignments and
+// postfix expressions keep the token of their operator, so their line is
+// that of the expression on the left.
+func lineOf(node ast.Node) int {
+	switch n := node.(type) {
+	case *ast.InfixExpression:
+		return lineOf(n.Left)
+	case *ast.CallExpression:
+		return lineOf(n.Function)
+	case *ast.IndexExpression:
+		return lineOf(n.Left)
+	case *ast.SliceExpression:
+		return lineOf(n.Left)
+	case *ast.MemberAccessExpression:
+		return lineOf(n.Object)
+	case *ast.MethodCallExpression:
+		return lineOf(n.Object)
+	case *ast.TypeCastExpression:
+		return lineOf(n.Expression)
+	case *ast.MemberAssignmentStatement:
+		return lineOf(n.Object)
+	case *ast.IndexAssignmentStatement:
+		return lineOf(n.Left)
+	case *ast.CompoundAssignmentStatement:
+		return lineOf(n.Target)
+	case *ast.FunctionLiteral:
+		if n.Arrow && len(n.Parameters) > 0 {
+			return n.Parameters[0].Token.Line
+		}
+	case *ast.ClassDefinition:
+		return n.Token.Line
+	}
+	return ast.Line(node)
+}
@@ -0,0 +1,193 @@
+package format
+
+import (
+	"sort"
+	"strings"
+)
+
+// comment is a // or /* */ comment found in the source
+type comment struct {
+	line, column int
+	text         string
+	ownLine      bool // nothing but spaces comes before it on its line
+	continues    bool // on its own line, lined up under a comment after code
+}
+
+// position is a line and column, both counted from 1 as the lexer does
+type position struct {
+	line, column int
+}
+
+// source is the text a program was parsed from, with what the parser does
+// not keep: its comments and where each block's closing brace is
+type source struct {
+	lines    [][]rune
+	comments []comment
+	opens    []position       // every {, in order
+	closes   map[position]int // the line of the } that closes each {
+}
+
+// scan finds the comments and braces of src, skipping over strings,
+// raw strings and character literals the way the lexer reads them
+func scan(src string) *source {
+	s := &source{closes: map[position]int{}}
+	for _, line := range strings.Split(src, "\n") {
+		s.lines = append(s.lines, []rune(strings.TrimRight(line, "\r")))
+	}
+
+	var stack []position
+	line, col := 0, 0
+	for line < len(s.lines) {
+		text := s.lines[line]
+		if col >= len(text) {
+			line, col = line+1, 0
+			continue
+		}
+		ch := text[col]
+		at := position{line + 1, col + 1}
+		switch {
+		case ch == '/' && col+1 < len(text) && text[col+1] == '/':
+			s.addComment(at, string(text[col:]))
+			line, col = line+1, 0
+			continue
+		case ch == '/' && col+1 < len(text) && text[col+1] == '*':
+			endLine, endCol := s.skipTo(line, col+2, "*/")
+			s.addComment(at, s.between(line, col, endLine, endCol))
+			line, col = endLine, endCol
+			continue
+		case ch == '"':
+			line, col = s.skipString(line, col+1)
+			continue
+		case ch == '`':
+			line, col = s.skipTo(line, col+1, "`")
+			continue
+		case ch == '\'':
+			col = skipChar(text, col)
+			continue
+		case ch == '{':
+			stack = append(stack, at)
+			s.opens = append(s.opens, at)
+		case ch == '}' && len(stack) > 0:
+			s.closes[stack[len(stack)-1]] = at.line
+			stack = stack[:len(stack)-1]
+		}
+		col++
+	}
+	return s
+}
+
+func (s *source) addComment(at position, text string) {
+	c := comment{
+		line:    at.line,
+		column:  at.column,
+		text:    strings.TrimRight(text, " \t"),
+		ownLine: strings.TrimSpace(string(s.lines[at.line-1][:at.column-1])) == "",
+	}
+	if n := len(s.comments); n > 0 && c.ownLine {
+		prev := s.comments[n-1]
+		c.continues = prev.line == c.line-1 && prev.column == c.column && (!prev.ownLine || prev.continues)
+	}
+	s.comments = append(s.comments, c)
+}
+
+// skipTo returns the position just past the next end, or the end of the
+// source when there is none
+func (s *source) skipTo(line, col int, end string) (int, int) {
+	e := []rune(end)
+	for line < len(s.lines) {
+		text := s.lines[line]
+		for ; col+len(e) <= len(text); col++ {
+			if string(text[col:col+len(e)]) == end {
+				return line, col + len(e)
+			}
+		}
+		line, col = line+1, 0
+	}
+	return line, 0
+}
+
+// skipString returns the position just past the quote that ends a string
+// starting at col. Inside a ${...} hole a quote starts a nested string.
+func (s *source) skipString(line, col int) (int, int) {
+	holes := 0
+	for line < len(s.lines) {
+		text := s.lines[line]
+		for ; col < len(text); col++ {
+			switch ch := text[col]; {
+			case ch == '"' && holes == 0:
+				return line, col + 1
+			case ch == '$' && col+1 < len(text) && text[col+1] == '{':
+				holes++
+				col++
+			case holes > 0 && ch == '{':
+				holes++
+			case holes > 0 && ch == '}':
+				holes--
+			case holes > 0 && ch == '"':
+				if line, col = s.skipString(line, col+1); line == len(s.lines) {
+					return line, 0
+				}
+				text = s.lines[line]
+				col--
+			}
+		}
+		line, col = line+1, 0
+	}
+	return line, 0
+}
+
+// skipChar returns the column just past a 'ক' literal starting at col, or
+// past the quote alone when the line ends before a closing one
+func skipChar(text []rune, col int) int {
+	if col+2 < len(text) && text[col+1] == '\'' && text[col+2] == '\'' {
+		return col + 3
+	}
+	for i := col + 1; i < len(text); i++ {
+		if text[i] == '\'' {
+			return i + 1
+		}
+	}
+	return col + 1
+}
+
+// between returns the source from one position to another
+func (s *source) between(line, col, endLine, endCol int) string {
+	if endLine >= len(s.lines) {
+		endLine, endCol = len(s.lines)-1, len(s.lines[len(s.lines)-1])
+	}
+	if line == endLine {
+		return string(s.lines[line][col:endCol])
+	}
+	parts := []string{string(s.lines[line][col:])}
+	for l := line + 1; l < endLine; l++ {
+		parts = append(parts, string(s.lines[l]))
+	}
+	parts = append(parts, string(s.lines[endLine][:endCol]))
+	return strings.Join(parts, "\n")
+}
+
+// blank reports whether a line of the source holds nothing but spaces
+func (s *source) blank(line int) bool {
+	return line >= 1 && line <= len(s.lines) && strings.TrimSpace(string(s.lines[line-1])) == ""
+}
+
+// closeAfter returns the line of the } closing the first { at or after a
+// position, or 0 when there is none
+func (s *source) closeAfter(line, column int) int {
+	i := sort.Search(len(s.opens), func(i int) bool {
+		o := s.opens[i]
+		return o.line > line || (o.line == line && o.column >= column)
+	})
+	if i == len(s.opens) {
+		return 0
+	}
+	return s.closes[s.opens[i]]
+}
+
+// text returns the source from a position to the end of its line
+func (s *source) text(line, column int) []rune {
+	if line < 1 || line > len(s.lines) || column < 1 || column > len(s.lines[line-1]) {
+		return nil
+	}
+	return s.lines[line-1][column-1:]
+}
@@ -0,0 +1,446 @@
+package main
+
+import (
+	"bhasa/compiler"
+	"bhasa/evaluator"
+	"bhasa/lexer"
+	"bhasa/object"
+	"bhasa/parser"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fuzzNode is a piece of a generated program. Its text has a %s for each
+// child; the children of a block are statements, joined by newlines, and
+// any of them can be dropped while shrinking.
+type fuzzNode struct {
+	text  string
+	kids  []*fuzzNode
+	typ   string // type of an expression: int, bool, str or arr; "" for statements
+	block bool
+}
+
+func (n *fuzzNode) String() string {
+	if n.block {
+		lines := make([]string, len(n.kids))
+		for i, kid := range n.kids {
+			lines[i] = kid.String()
+		}
+		return strings.Join(lines, "\n")
+	}
+	args := make([]interface{}, len(n.kids))
+	for i, kid := range n.kids {
+		args[i] = kid.String()
+	}
+	return fmt.Sprintf(n.text, args...)
+}
+
+// clone copies a node deeply so a shrinking step can edit the copy
+func (n *fuzzNode) clone() *fuzzNode {
+	c := *n
+	c.kids = make([]*fuzzNode, len(n.kids))
+	for i, kid := range n.kids {
+		c.kids[i] = kid.clone()
+	}
+	return &c
+}
+
+// fuzzVar is a variable a generated program may use
+type fuzzVar struct {
+	name string
+	typ  string
+}
+
+// fuzzFunc is a generated function taking and returning integers
+type fuzzFunc struct {
+	name  string
+	arity int
+}
+
+// fuzzGen generates random programs from a grammar of the features both
+// engines implement: integers, booleans, strings, arrays, variables,
+// if/else, bounded while loops and non-recursive functions. Only names
+// already declared in scope are used, so every program compiles, and
+// every loop ends. A statement reads at most one string variable, so
+// strings grow by a bounded amount each time one runs rather than
+// doubling in a loop.
+type fuzzGen struct {
+	rnd      *rand.Rand
+	vars     []fuzzVar
+	funcs    []fuzzFunc
+	next     int  // for fresh names
+	depth    int  // nesting of blocks
+	strsRead bool // the current statement reads a string variable
+}
+
+var fuzzStrings = []string{`""`, `"ক"`, `"খগ"`, `"ভাষা"`, `"a"`}
+
+func (g *fuzzGen) fresh(prefix string) string {
+	g.next++
+	return fmt.Sprintf("%s%d", prefix, g.next)
+}
+
+func leaf(text, typ string) *fuzzNode {
+	return &fuzzNode{text: strings.ReplaceAll(text, "%", "%%"), typ: typ}
+}
+
+// program generates a whole program
+func (g *fuzzGen) program() *fuzzNode {
+	return g.block(4 + g.rnd.Intn(8))
+}
+
+// block generates n statements. Names declared inside a nested block are
+// forgotten at its end, since a branch that did not run leaves them unset.
+func (g *fuzzGen) block(n int) *fuzzNode {
+	vars, funcs := len(g.vars), len(g.funcs)
+	b := &fuzzNode{block: true}
+	for i := 0; i < n; i++ {
+		b.kids = append(b.kids, g.statement())
+	}
+	if g.depth > 0 {
+		g.vars, g.funcs = g.vars[:vars], g.funcs[:funcs]
+	}
+	return b
+}
+
+func (g *fuzzGen) nested(n int) *fuzzNode {
+	g.depth++
+	defer func() { g.depth-- }()
+	return g.block(n)
+}
+
+func (g *fuzzGen) statement() *fuzzNode {
+	g.strsRead = false
+	switch r := g.rnd.Intn(10); {
+	case r < 3 || len(g.vars) == 0:
+		typ := g.anyType()
+		value := g.expr(typ, 3)
+		name := g.fresh("v")
+		g.vars = append(g.vars, fuzzVar{name, typ})
+		return &fuzzNode{text: "ধরি " + name + " = %s;", kids: []*fuzzNode{value}}
+	case r < 5:
+		return &fuzzNode{text: "লেখ(%s);", kids: []*fuzzNode{g.expr(g.anyType(), 3)}}
+	case r < 6:
+		v := g.vars[g.rnd.Intn(len(g.vars))]
+		return &fuzzNode{text: v.name + " = %s;", kids: []*fuzzNode{g.expr(v.typ, 3)}}
+	case r < 7 && g.depth < 2:
+		return &fuzzNode{
+			text: "যদি (%s) {\n%s\n} নাহলে {\n%s\n}",
+			kids: []*fuzzNode{g.expr("bool", 2), g.nested(1 + g.rnd.Intn(3)), g.nested(g.rnd.Intn(3))},
+		}
+	case r < 8 && g.depth < 2:
+		// The counter is part of the loop's own text, so shrinking can
+		// never remove the increment and leave a loop that does not end
+		i := g.fresh("i")
+		text := fmt.Sprintf("ধরি %s = 0;\nযতক্ষণ (%s < %d) {\n%s = %s + 1;\n%%s\n}", i, i, 1+g.rnd.Intn(4), i, i)
+		return &fuzzNode{text: text, kids: []*fuzzNode{g.nested(1 + g.rnd.Intn(3))}}
+	case r < 9 && g.depth == 0:
+		return g.function()
+	}
+	return &fuzzNode{text: "লেখ(%s);", kids: []*fuzzNode{g.expr("int", 3)}}
+}
+
+// function declares a function of integers. Its body sees its parameters
+// and the names declared before it, and cannot call itself.
+func (g *fuzzGen) function() *fuzzNode {
+	name := g.fresh("f")
+	arity := g.rnd.Intn(3)
+	params := make([]string, arity)
+	outer := g.vars
+	for i := range params {
+		params[i] = g.fresh("p")
+		g.vars = append(g.vars, fuzzVar{params[i], "int"})
+	}
+	g.depth++
+	body := g.block(g.rnd.Intn(3))
+	result := g.expr("int", 3)
+	g.depth--
+	g.vars = outer
+
+	g.funcs = append(g.funcs, fuzzFunc{name, arity})
+	text := fmt.Sprintf("ধরি %s = ফাংশন(%s) {\n%%s\nফেরত %%s;\n};", name, strings.Join(params, ", "))
+	return &fuzzNode{text: text, kids: []*fuzzNode{body, result}}
+}
+
+func (g *fuzzGen) anyType() string {
+	return []string{"int", "int", "bool", "str", "arr"}[g.rnd.Intn(5)]
+}
+
+// variable picks a variable of the given type, or "" if there is none
+func (g *fuzzGen) variable(typ string) string {
+	var names []string
+	for _, v := range g.vars {
+		if v.typ == typ {
+			names = append(names, v.name)
+		}
+	}
+	if len(names) == 0 || (typ == "str" && g.strsRead) {
+		return ""
+	}
+	g.strsRead = g.strsRead || typ == "str"
+	return names[g.rnd.Intn(len(names))]
+}
+
+// expr generates an expression of the given type, at most depth deep
+func (g *fuzzGen) expr(typ string, depth int) *fuzzNode {
+	if depth == 0 || g.rnd.Intn(4) == 0 {
+		if name := g.variable(typ); name != "" && g.rnd.Intn(2) == 0 {
+			return leaf(name, typ)
+		}
+		return g.literal(typ)
+	}
+	node := func(text string, kids ...*fuzzNode) *fuzzNode {
+		return &fuzzNode{text: text, kids: kids, typ: typ}
+	}
+
+	switch typ {
+	case "int":
+		switch g.rnd.Intn(6) {
+		case 0, 1:
+			op := []string{"+", "-", "*", "/", "%%"}[g.rnd.Intn(5)]
+			return node("(%s "+op+" %s)", g.expr("int", depth-1), g.expr("int", depth-1))
+		case 2:
+			return node("-%s", g.expr("int", depth-1))
+		case 3:
+			return node("দৈর্ঘ্য(%s)", g.expr([]string{"str", "arr"}[g.rnd.Intn(2)], depth-1))
+		case 4:
+			return node("%s[%s]", g.expr("arr", depth-1), g.expr("int", depth-1))
+		case 5:
+			if len(g.funcs) > 0 {
+				f := g.funcs[g.rnd.Intn(len(g.funcs))]
+				args := make([]*fuzzNode, f.arity)
+				holes := make([]string, f.arity)
+				for i := range args {
+					args[i] = g.expr("int", depth-1)
+					holes[i] = "%s"
+				}
+				return node(f.name+"("+strings.Join(holes, ", ")+")", args...)
+			}
+		}
+	case "bool":
+		switch g.rnd.Intn(4) {
+		case 0:
+			op := []string{"<", ">", "<=", ">=", "==", "!="}[g.rnd.Intn(6)]
+			return node("(%s "+op+" %s)", g.expr("int", depth-1), g.expr("int", depth-1))
+		case 1:
+			return node("!%s", g.expr("bool", depth-1))
+		case 2:
+			op := []string{"==", "!="}[g.rnd.Intn(2)]
+			return node("(%s "+op+" %s)", g.expr("bool", depth-1), g.expr("bool", depth-1))
+		case 3:
+			op := []string{"==", "!="}[g.rnd.Intn(2)]
+			return node("(%s "+op+" %s)", g.expr("str", depth-1), g.expr("str", depth-1))
+		}
+	case "str":
+		return node("(%s + %s)", g.expr("str", depth-1), g.expr("str", depth-1))
+	case "arr":
+		n := g.rnd.Intn(4)
+		kids := make([]*fuzzNode, n)
+		holes := make([]string, n)
+		for i := range kids {
+			kids[i] = g.expr("int", depth-1)
+			holes[i] = "%s"
+		}
+		return node("["+strings.Join(holes, ", ")+"]", kids...)
+	}
+	return g.literal(typ)
+}
+
+func (g *fuzzGen) literal(typ string) *fuzzNode {
+	switch typ {
+	case "int":
+		return leaf(fmt.Sprint(g.rnd.Intn(21)-5), typ)
+	case "bool":
+		return leaf([]string{"সত্য", "মিথ্যা"}[g.rnd.Intn(2)], typ)
+	case "str":
+		return leaf(fuzzStrings[g.rnd.Intn(len(fuzzStrings))], typ)
+	}
+	return leaf("[]", typ)
+}
+
+// simplest is the smallest expression of each type, tried first when
+// shrinking
+var simplest = map[string]string{"int": "0", "bool": "সত্য", "str": `""`, "arr": "[]"}
+
+// fuzzResult is what one engine did with a program
+type fuzzResult struct {
+	output string
+	err    error
+}
+
+// agrees reports whether two runs printed the same and either both
+// failed or both succeeded. Error messages are not compared, as each
+// engine words its own.
+func (r fuzzResult) agrees(other fuzzResult) bool {
+	return r.output == other.output && (r.err == nil) == (other.err == nil)
+}
+
+func (r fuzzResult) String() string {
+	s := fmt.Sprintf("output %q", r.output)
+	if r.err != nil {
+		s += fmt.Sprintf(", error: %s", strings.ReplaceAll(r.err.Error(), "\n", " "))
+	}
+	return s
+}
+
+// runFuzzVM compiles and runs a program on the VM. A compile error means
+// the program is not one the generator could have produced, and is
+// returned separately.
+func runFuzzVM(src string) (fuzzResult, error) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return fuzzResult{}, fmt.Errorf("parser errors: %v", p.Errors())
+	}
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		return fuzzResult{}, err
+	}
+	var output strings.Builder
+	err := runProgram(comp.Bytecode(), nil, runOptions{stdout: &output})
+	return fuzzResult{output.String(), err}, nil
+}
+
+// runFuzzEval runs a program on the tree-walking evaluator
+func runFuzzEval(src string) fuzzResult {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	output, err := captureStdout(func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("evaluator panicked: %v", r)
+			}
+		}()
+		if result, ok := evaluator.Eval(program, object.NewEnvironment()).(*object.Error); ok {
+			return errors.New(result.Message)
+		}
+		return nil
+	})
+	return fuzzResult{output, err}
+}
+
+// diverges runs a program on both engines and reports whether they
+// disagree. Programs the VM cannot compile never count.
+func diverges(src string) (bool, fuzzResult, fuzzResult) {
+	vmResult, err := runFuzzVM(src)
+	if err != nil {
+		return false, fuzzResult{}, fuzzResult{}
+	}
+	evalResult := runFuzzEval(src)
+	return !vmResult.agrees(evalResult), vmResult, evalResult
+}
+
+// shrink makes a diverging program as small as it can while the engines
+// still disagree, by dropping statements and replacing expressions with
+// one of their operands or the simplest value of their type
+func shrink(root *fuzzNode) *fuzzNode {
+	for {
+		smaller := false
+		for _, candidate := range shrinkCandidates(root) {
+			if ok, _, _ := diverges(candidate.String()); ok {
+				root, smaller = candidate, true
+				break
+			}
+		}
+		if !smaller {
+			return root
+		}
+	}
+}
+
+// shrinkCandidates lists the programs one step smaller than root
+func shrinkCandidates(root *fuzzNode) []*fuzzNode {
+	var out []*fuzzNode
+	// edit applies change to the copy of the node at path in a fresh copy
+	// of the program
+	edit := func(path []int, change func(n *fuzzNode) *fuzzNode) {
+		copied := root.clone()
+		if len(path) == 0 {
+			out = append(out, change(copied))
+			return
+		}
+		parent := copied
+		for _, i := range path[:len(path)-1] {
+			parent = parent.kids[i]
+		}
+		last := path[len(path)-1]
+		parent.kids[last] = change(parent.kids[last])
+		out = append(out, copied)
+	}
+
+	var walk func(n *fuzzNode, path []int)
+	walk = func(n *fuzzNode, path []int) {
+		path = append(path[:len(path):len(path)], 0)
+		if n.block {
+			for i := range n.kids {
+				edit(path[:len(path)-1], func(b *fuzzNode) *fuzzNode {
+					b.kids = append(b.kids[:i], b.kids[i+1:]...)
+					return b
+				})
+			}
+		}
+		for i, kid := range n.kids {
+			path[len(path)-1] = i
+			if kid.typ != "" && kid.String() != simplest[kid.typ] {
+				edit(path, func(*fuzzNode) *fuzzNode { return leaf(simplest[kid.typ], kid.typ) })
+				for _, grandchild := range kid.kids {
+					if grandchild.typ == kid.typ {
+						edit(path, func(*fuzzNode) *fuzzNode { return grandchild.clone() })
+					}
+				}
+			}
+			walk(kid, path)
+		}
+	}
+	walk(root, nil)
+	return out
+}
+
+func cmdFuzz(args []string) int {
+	fs := newFlagSet("fuzz")
+	count := fs.Int("n", 500, "Number of programs to generate")
+	seed := fs.Int64("seed", 0, "Random seed (default: from the clock)")
+	outDir := fs.String("o", "", "Write the shrunk program of a divergence to this directory")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	fmt.Printf("seed %d\n", *seed)
+	rnd := rand.New(rand.NewSource(*seed))
+
+	for i := 1; i <= *count; i++ {
+		g := &fuzzGen{rnd: rnd}
+		program := g.program()
+		src := program.String()
+
+		vmResult, err := runFuzzVM(src)
+		if err != nil {
+			return fail(fmt.Errorf("program %d does not compile, which is a bug in the generator: %v\n%s", i, err, src))
+		}
+		if vmResult.agrees(runFuzzEval(src)) {
+			continue
+		}
+
+		small := shrink(program).String()
+		_, vmResult, evalResult := diverges(small)
+		fmt.Printf("FAIL program %d: the engines disagree\n\n%s\n\n  vm:   %s\n  eval: %s\n", i, small, vmResult, evalResult)
+		if *outDir != "" {
+			name := filepath.Join(*outDir, fmt.Sprintf("fuzz_%d_%d.ভাষা", *seed, i))
+			header := fmt.Sprintf("// Found by bhasa fuzz -seed %d (program %d)\n// vm:   %s\n// eval: %s\n", *seed, i, vmResult, evalResult)
+			if err := os.WriteFile(name, []byte(header+small+"\n"), 0644); err != nil {
+				return fail(err)
+			}
+			fmt.Printf("\nwrote %s\n", name)
+		}
+		return 1
+	}
+	fmt.Printf("%d programs, no divergence\n", *count)
+	return 0
+}
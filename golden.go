@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// goldenDir holds the fixtures checked by the golden command: source files
+// whose disassembly is stored next to them with a .golden extension. The
+// golden files of fixtures in a directory named ast hold the JSON syntax
+// tree that bhasa --ast prints instead.
+const goldenDir = "tests/golden"
+
+// goldenPath returns the golden file of a fixture
+func goldenPath(file string) string {
+	return file + ".golden"
+}
+
+// checkGolden compiles a fixture and compares its disassembly with the
+// golden file, or rewrites the golden file when update is set. It returns
+// a description of the difference, or "" when there is none.
+func checkGolden(file string, update bool) (string, error) {
+	got, err := goldenOutput(file)
+	if err != nil {
+		return "", err
+	}
+
+	if update {
+		return "", os.WriteFile(goldenPath(file), []byte(got), 0644)
+	}
+	want, err := os.ReadFile(goldenPath(file))
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no golden file %s; run bhasa golden -update to create it", goldenPath(file))
+	}
+	if err != nil {
+		return "", err
+	}
+	if string(want) == got {
+		return "", nil
+	}
+	return lineDiff(strings.Split(string(want), "\n"), strings.Split(got, "\n")), nil
+}
+
+// goldenOutput renders a fixture the way its golden file holds it
+func goldenOutput(file string) (string, error) {
+	if filepath.Base(filepath.Dir(file)) == "ast" {
+		program, err := parseFile(file)
+		if err != nil {
+			return "", err
+		}
+		return astJSON(program)
+	}
+	bytecode, err := compileFile(file)
+	if err != nil {
+		return "", err
+	}
+	return bytecode.Disassemble(0), nil
+}
+
+// lineDiff lists the lines removed from want (-) and added in got (+),
+// with the line number in want where each change starts
+func lineDiff(want, got []string) string {
+	// lcs[i][j] is the length of the longest common subsequence of
+	// want[i:] and got[j:]
+	lcs := make([][]int, len(want)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(got)+1)
+	}
+	for i := len(want) - 1; i >= 0; i-- {
+		for j := len(got) - 1; j >= 0; j-- {
+			if want[i] == got[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var sb strings.Builder
+	inChange := false
+	i, j := 0, 0
+	for i < len(want) || j < len(got) {
+		switch {
+		case i < len(want) && j < len(got) && want[i] == got[j]:
+			inChange = false
+			i++
+			j++
+			continue
+		case j == len(got) || (i < len(want) && lcs[i+1][j] >= lcs[i][j+1]):
+			if !inChange {
+				fmt.Fprintf(&sb, "@@ line %d\n", i+1)
+			}
+			fmt.Fprintf(&sb, "-%s\n", want[i])
+			i++
+		default:
+			if !inChange {
+				fmt.Fprintf(&sb, "@@ line %d\n", i+1)
+			}
+			fmt.Fprintf(&sb, "+%s\n", got[j])
+			j++
+		}
+		inChange = true
+	}
+	return sb.String()
+}
+
+func cmdGolden(args []string) int {
+	fs := newFlagSet("golden")
+	update := fs.Bool("update", false, "Rewrite the golden files from the current compiler")
+	paths, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(paths) == 0 {
+		paths = []string{goldenDir}
+	}
+
+	files, err := collectSourceFiles(paths)
+	if err != nil {
+		return fail(err)
+	}
+
+	failed := 0
+	for _, file := range files {
+		diff, err := checkGolden(file, *update)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("FAIL %s\n\t%s\n", file, strings.ReplaceAll(err.Error(), "\n", "\n\t"))
+		case diff != "":
+			failed++
+			fmt.Printf("FAIL %s: output differs from %s\n%s", file, goldenPath(file), diff)
+		case *update:
+			fmt.Printf("updated %s\n", goldenPath(file))
+		default:
+			fmt.Printf("ok   %s\n", file)
+		}
+	}
+
+	if !*update {
+		fmt.Printf("\n%d passed, %d failed\n", len(files)-failed, failed)
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
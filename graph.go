@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bhasa/ast"
+	"bhasa/compiler"
+	"bhasa/desugar"
+	"bhasa/lexer"
+	"bhasa/parser"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// graphModule is one file or imported module of a program, with the
+// classes and interfaces it defines and the modules it imports
+type graphModule struct {
+	id         string
+	label      string
+	classes    []*ast.ClassDefinition
+	interfaces []*ast.InterfaceDefinition
+	imports    []string
+}
+
+// collectGraph reads a program and every module it imports, directly or
+// not, in the order they are first imported. Modules that cannot be read
+// are still shown, as a module without contents.
+func collectGraph(file string) ([]*graphModule, error) {
+	program, err := parseFile(file)
+	if err != nil {
+		return nil, err
+	}
+	loader := compiler.DirModuleLoader(filepath.Dir(file))
+
+	root := describeModule("file:"+file, filepath.Base(file), program)
+	modules := []*graphModule{root}
+	seen := map[string]bool{}
+	for i := 0; i < len(modules); i++ {
+		for _, path := range modules[i].imports {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			module := &graphModule{id: "module:" + path, label: path}
+			if source, err := loader(path); err == nil && !compiler.HasMagicNumber([]byte(source)) {
+				p := parser.New(lexer.New(source))
+				if parsed := p.ParseProgram(); len(p.Errors()) == 0 {
+					module = describeModule(module.id, module.label, parsed)
+				}
+			}
+			modules = append(modules, module)
+		}
+	}
+	return modules, nil
+}
+
+// describeModule finds the classes, interfaces and imports of a program,
+// including those inside functions
+func describeModule(id, label string, program *ast.Program) *graphModule {
+	module := &graphModule{id: id, label: label}
+	ast.Inspect(desugar.Program(program), func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.ClassDefinition:
+			module.classes = append(module.classes, n)
+		case *ast.InterfaceDefinition:
+			module.interfaces = append(module.interfaces, n)
+		case *ast.ImportStatement:
+			if path, ok := n.Path.(*ast.StringLiteral); ok {
+				module.imports = append(module.imports, path.Value)
+			}
+		}
+		return true
+	})
+	return module
+}
+
+// writeGraph writes modules as a Graphviz digraph. Each module is a
+// cluster holding a folder node for the module and its classes and
+// interfaces, drawn as UML-style records. Solid arrows with hollow heads
+// point from a class to its superclass, dashed ones to the interfaces it
+// implements, and dotted arrows from a module to the modules it imports.
+func writeGraph(w io.Writer, modules []*graphModule) {
+	fmt.Fprintln(w, "digraph bhasa {")
+	fmt.Fprintln(w, "\trankdir=BT;")
+	fmt.Fprintln(w, "\tnode [shape=record, fontname=\"sans-serif\"];")
+
+	for i, module := range modules {
+		fmt.Fprintf(w, "\n\tsubgraph cluster_%d {\n", i)
+		fmt.Fprintln(w, "\t\tstyle=dashed; color=gray;")
+		fmt.Fprintf(w, "\t\t%s [label=%s, shape=folder];\n", dotQuote(module.id), dotQuote(module.label))
+		for _, iface := range module.interfaces {
+			var methods []string
+			for _, m := range iface.Methods {
+				methods = append(methods, m.Name.Value+"()")
+			}
+			label := "{«চুক্তি»\\n" + iface.Name.Value + "|" + recordLines(methods) + "}"
+			fmt.Fprintf(w, "\t\t%s [label=%s];\n", dotQuote("class:"+iface.Name.Value), dotQuote(label))
+		}
+		for _, class := range module.classes {
+			fmt.Fprintf(w, "\t\t%s [label=%s];\n", dotQuote("class:"+class.Name.Value), dotQuote(classRecord(class)))
+		}
+		fmt.Fprintln(w, "\t}")
+	}
+
+	fmt.Fprintln(w)
+	for _, module := range modules {
+		for _, class := range module.classes {
+			from := dotQuote("class:" + class.Name.Value)
+			if class.SuperClass != nil {
+				fmt.Fprintf(w, "\t%s -> %s [arrowhead=empty];\n", from, dotQuote("class:"+class.SuperClass.Value))
+			}
+			for _, iface := range class.Interfaces {
+				fmt.Fprintf(w, "\t%s -> %s [arrowhead=empty, style=dashed];\n", from, dotQuote("class:"+iface.Value))
+			}
+		}
+		for _, path := range module.imports {
+			fmt.Fprintf(w, "\t%s -> %s [style=dotted];\n", dotQuote(module.id), dotQuote("module:"+path))
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// classRecord is the record label of a class: its name, then its fields,
+// then its methods, each marked + public, - private or # protected
+func classRecord(class *ast.ClassDefinition) string {
+	name := class.Name.Value
+	if class.IsAbstract {
+		name = "«বিমূর্ত»\\n" + name
+	}
+	var fields, methods []string
+	for _, field := range class.Fields {
+		fields = append(fields, accessMark(field.Access)+field.Name)
+	}
+	for _, method := range class.Methods {
+		methods = append(methods, accessMark(method.Access)+method.Name.Value+"()")
+	}
+	return "{" + name + "|" + recordLines(fields) + "|" + recordLines(methods) + "}"
+}
+
+// accessMark is the UML marker of an access level; members without one
+// are public
+func accessMark(access ast.AccessModifier) string {
+	switch access {
+	case ast.PRIVATE:
+		return "-"
+	case ast.PROTECTED:
+		return "#"
+	}
+	return "+"
+}
+
+// recordLines joins entries of a record field as left-aligned lines
+func recordLines(entries []string) string {
+	var sb strings.Builder
+	for _, entry := range entries {
+		sb.WriteString(entry)
+		sb.WriteString("\\l")
+	}
+	return sb.String()
+}
+
+// dotQuote quotes s as a DOT string
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func cmdGraph(args []string) int {
+	fs := newFlagSet("graph")
+	output := fs.String("o", "", "Write the graph to this file instead of standard output")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	file, err := resolveEntry(files[0])
+	if err != nil {
+		return fail(err)
+	}
+	modules, err := collectGraph(file)
+	if err != nil {
+		return fail(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fail(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	writeGraph(w, modules)
+	return 0
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// manifestName is the project manifest written by bhasa init
+const manifestName = "bhasa.json"
+
+// manifest describes a project. Main names the file run when the project
+// directory is run.
+type manifest struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Main    string `json:"main"`
+}
+
+// readManifest loads the manifest of a project directory. A directory
+// without one gives a nil manifest and no error.
+func readManifest(dir string) (*manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("%s: %v", filepath.Join(dir, manifestName), err)
+	}
+	return m, nil
+}
+
+// scaffold is the content of a new project, by path within it
+func scaffold(name string) (map[string]string, error) {
+	data, err := json.MarshalIndent(manifest{Name: name, Version: "0.1.0", Main: mainFileNames[0]}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		manifestName: string(data) + "\n",
+
+		mainFileNames[0]: `// ` + name + ` এর প্রধান ফাইল: bhasa run . দিয়ে চালাও
+অন্তর্ভুক্ত "শুভেচ্ছা";
+
+লেখ(শুভেচ্ছা("বিশ্ব"));
+`,
+
+		filepath.Join("modules", "শুভেচ্ছা.ভাষা"): `/// নামটিকে শুভেচ্ছা জানানোর বাক্য
+ধরি শুভেচ্ছা = ফাংশন(নাম) {
+    ফেরত "নমস্কার, " + নাম + "!";
+};
+`,
+
+		filepath.Join("tests", "শুভেচ্ছা_পরীক্ষা.ভাষা"): `// bhasa test tests দিয়ে চালাও; পরীক্ষা_ দিয়ে শুরু প্রতিটি ফাংশন একটি পরীক্ষা
+অন্তর্ভুক্ত "../modules/শুভেচ্ছা";
+
+ধরি পরীক্ষা_শুভেচ্ছা = ফাংশন() {
+    নিশ্চিত_সমান(শুভেচ্ছা("বিশ্ব"), "নমস্কার, বিশ্ব!");
+};
+`,
+
+		".gitignore": `# bhasa build এর আউটপুট
+*.compiled
+*.সংকলিত
+`,
+	}, nil
+}
+
+func cmdInit(args []string) int {
+	fs := newFlagSet("init")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	dir := fs.Arg(0)
+
+	// Refuse to mix the skeleton into an existing project
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fail(fmt.Errorf("%s already exists and is not empty", dir))
+	}
+
+	name := filepath.Base(filepath.Clean(dir))
+	files, err := scaffold(name)
+	if err != nil {
+		return fail(err)
+	}
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			return fail(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			return fail(err)
+		}
+	}
+
+	fmt.Printf("Created project %s in %s\n", name, dir)
+	fmt.Printf("\n  bhasa run %s\n  bhasa test %s\n", dir, filepath.Join(dir, "tests"))
+	return 0
+}
@@ -2,9 +2,18 @@ package lexer
 
 import (
 	"bhasa/token"
+	"io"
+	"strings"
 	"unicode"
 )
 
+// streamWindow is how many runes a streaming lexer buffers before it drops
+// the ones already turned into tokens
+const streamWindow = 4096
+
+// streamChunk is how many runes a streaming lexer reads ahead at a time
+const streamChunk = 512
+
 // Lexer represents a lexical analyzer
 type Lexer struct {
 	input        []rune
@@ -13,6 +22,14 @@ type Lexer struct {
 	ch           rune // current char under examination
 	line         int  // current line number
 	column       int  // current column number
+	file         string
+	doc          []string // /// comment lines waiting for the next token
+
+	// A streaming lexer reads input from reader as it goes; input then
+	// holds only the runes from offset on
+	reader io.RuneReader
+	offset int
+	err    error
 }
 
 // New creates a new Lexer
@@ -26,13 +43,91 @@ func New(input string) *Lexer {
 	return l
 }
 
+// NewReader creates a Lexer that reads its input from r while tokenizing,
+// keeping only a small window of it in memory. A read error ends the input
+// like end of file does; Err reports it.
+func NewReader(r io.RuneReader) *Lexer {
+	l := &Lexer{
+		reader: r,
+		line:   1,
+		column: 0,
+	}
+	l.readChar()
+	return l
+}
+
+// SetFile names the file the input comes from, for diagnostics
+func (l *Lexer) SetFile(name string) {
+	l.file = name
+}
+
+// File returns the name set with SetFile, or ""
+func (l *Lexer) File() string {
+	return l.file
+}
+
+// Err returns the error that stopped a streaming lexer from reading its
+// input, or nil
+func (l *Lexer) Err() error {
+	return l.err
+}
+
+// Offset returns how many characters of the input come before the first
+// one not yet read into a token, which is just after the last token
+// returned
+func (l *Lexer) Offset() int {
+	return l.position
+}
+
+// charAt returns the character at position pos, or 0 past the end of the
+// input
+func (l *Lexer) charAt(pos int) rune {
+	if i := pos - l.offset; i < len(l.input) {
+		return l.input[i]
+	}
+	return l.fill(pos)
+}
+
+// fill reads a streaming lexer's input up to and a little beyond position
+// pos and returns the character there
+func (l *Lexer) fill(pos int) rune {
+	i := pos - l.offset
+	for l.reader != nil && len(l.input) <= i+streamChunk {
+		ch, _, err := l.reader.ReadRune()
+		if err != nil {
+			if err != io.EOF {
+				l.err = err
+			}
+			l.reader = nil
+			break
+		}
+		l.input = append(l.input, ch)
+	}
+	if i >= len(l.input) {
+		return 0
+	}
+	return l.input[i]
+}
+
+// text returns the input between positions start and end
+func (l *Lexer) text(start, end int) string {
+	return string(l.input[start-l.offset : end-l.offset])
+}
+
+// discard drops buffered input before the current character once a
+// streaming lexer's window is full; no token may be in progress
+func (l *Lexer) discard() {
+	if l.reader == nil || l.position-l.offset < streamWindow {
+		return
+	}
+	n := copy(l.input, l.input[l.position-l.offset:])
+	l.input = l.input[:n]
+	l.offset = l.position
+}
+
 // readChar reads the next character and advances position
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
-		l.ch = 0
-	} else {
-		l.ch = l.input[l.readPosition]
-	}
+	l.ch = l.charAt(l.readPosition)
 	l.position = l.readPosition
 	l.readPosition++
 	l.column++
@@ -45,17 +140,25 @@ func (l *Lexer) readChar() {
 
 // peekChar looks ahead at the next character without advancing
 func (l *Lexer) peekChar() rune {
-	if l.readPosition >= len(l.input) {
-		return 0
-	}
-	return l.input[l.readPosition]
+	return l.charAt(l.readPosition)
 }
 
-// NextToken returns the next token from the input
+// NextToken returns the next token from the input, carrying the text of
+// any /// doc comment written directly above it
 func (l *Lexer) NextToken() token.Token {
+	tok := l.nextToken()
+	if len(l.doc) > 0 {
+		tok.Doc = strings.Join(l.doc, "\n")
+		l.doc = nil
+	}
+	return tok
+}
+
+func (l *Lexer) nextToken() token.Token {
 	var tok token.Token
 
 	l.skipWhitespace()
+	l.discard()
 	
 	// Record position at start of token
 	tokLine := l.line
@@ -75,9 +178,9 @@ func (l *Lexer) NextToken() token.Token {
 			tok = l.newTokenWithPos(token.ASSIGN, string(l.ch))
 		}
 	case '+':
-		tok = l.newTokenWithPos(token.PLUS, string(l.ch))
+		tok = l.newOperator(token.PLUS, token.PLUS_ASSIGN)
 	case '-':
-		tok = l.newTokenWithPos(token.MINUS, string(l.ch))
+		tok = l.newOperator(token.MINUS, token.MINUS_ASSIGN)
 	case '!':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -87,16 +190,22 @@ func (l *Lexer) NextToken() token.Token {
 			tok = l.newTokenWithPos(token.BANG, string(l.ch))
 		}
 	case '*':
-		tok = l.newTokenWithPos(token.ASTERISK, string(l.ch))
+		tok = l.newOperator(token.ASTERISK, token.ASTERISK_ASSIGN)
 	case '/':
-		if l.peekChar() == '/' {
+		switch l.peekChar() {
+		case '/':
 			l.skipComment()
-			return l.NextToken()
-		} else {
-			tok = l.newTokenWithPos(token.SLASH, string(l.ch))
+			return l.nextToken()
+		case '*':
+			if !l.skipBlockComment() {
+				return token.Token{Type: token.ILLEGAL, Literal: "/*", Line: tokLine, Column: tokCol}
+			}
+			return l.nextToken()
+		default:
+			tok = l.newOperator(token.SLASH, token.SLASH_ASSIGN)
 		}
 	case '%':
-		tok = l.newTokenWithPos(token.PERCENT, string(l.ch))
+		tok = l.newOperator(token.PERCENT, token.PERCENT_ASSIGN)
 	case '<':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -147,8 +256,25 @@ func (l *Lexer) NextToken() token.Token {
 		tok = l.newTokenWithPos(token.SEMICOLON, string(l.ch))
 	case ':':
 		tok = l.newTokenWithPos(token.COLON, string(l.ch))
+	case '?':
+		switch l.peekChar() {
+		case '.':
+			l.readChar()
+			tok = l.newTokenWithPos(token.QUESTION_DOT, "?.")
+		case '?':
+			l.readChar()
+			tok = l.newTokenWithPos(token.NULL_COALESCE, "??")
+		default:
+			tok = l.newTokenWithPos(token.ILLEGAL, string(l.ch))
+		}
 	case '.':
-		tok = l.newTokenWithPos(token.DOT, string(l.ch))
+		if l.peekChar() == '.' && l.peekCharAt(1) == '.' {
+			l.readChar()
+			l.readChar()
+			tok = l.newTokenWithPos(token.ELLIPSIS, "...")
+		} else {
+			tok = l.newTokenWithPos(token.DOT, string(l.ch))
+		}
 	case '(':
 		tok = l.newTokenWithPos(token.LPAREN, string(l.ch))
 	case ')':
@@ -169,6 +295,28 @@ func (l *Lexer) NextToken() token.Token {
 			Line:    tokLine,
 			Column:  tokCol,
 		}
+	case '\'':
+		literal, ok := l.readCharLiteral()
+		tok = token.Token{
+			Type:    token.CHAR,
+			Literal: literal,
+			Line:    tokLine,
+			Column:  tokCol,
+		}
+		if !ok {
+			tok.Type, tok.Literal = token.ILLEGAL, "'"
+		}
+	case '`':
+		literal, ok := l.readRawString()
+		tok = token.Token{
+			Type:    token.RAW_STRING,
+			Literal: literal,
+			Line:    tokLine,
+			Column:  tokCol,
+		}
+		if !ok {
+			tok.Type, tok.Literal = token.ILLEGAL, "`"
+		}
 	case 0:
 		tok = token.Token{
 			Type:    token.EOF,
@@ -187,9 +335,9 @@ func (l *Lexer) NextToken() token.Token {
 			}
 			return tok
 		} else if isDigit(l.ch) || isBengaliDigit(l.ch) {
-			literal := l.readNumber()
+			literal, tokType := l.readNumber()
 			tok = token.Token{
-				Type:    token.INT,
+				Type:    tokType,
 				Literal: literal,
 				Line:    tokLine,
 				Column:  tokCol,
@@ -212,45 +360,180 @@ func (l *Lexer) readIdentifier() string {
 	for isLetter(l.ch) || isDigit(l.ch) || isBengaliDigit(l.ch) {
 		l.readChar()
 	}
-	return string(l.input[startPos:l.position])
+	return l.text(startPos, l.position)
 }
 
-// readNumber reads a number (supports both Arabic and Bengali numerals)
-func (l *Lexer) readNumber() string {
+// readNumber reads a number (supports both Arabic and Bengali numerals).
+// A fraction (1.5) or an exponent (1e9, ১ই৯) makes it a float, and a
+// trailing দ or d (১৯.৯৯দ, 0.1d) an exact decimal.
+func (l *Lexer) readNumber() (string, token.TokenType) {
 	startPos := l.position
-	for isDigit(l.ch) || isBengaliDigit(l.ch) {
+	isFloat := false
+	l.readDigits()
+
+	if l.ch == '.' && isAnyDigit(l.peekChar()) {
+		isFloat = true
+		l.readChar()
+		l.readDigits()
+	}
+
+	if l.ch == 'e' || l.ch == 'E' || l.ch == token.ExponentMarker {
+		next := l.peekChar()
+		if (next == '+' || next == '-') && isAnyDigit(l.peekCharAt(1)) {
+			isFloat = true
+			l.readChar()
+			l.readChar()
+			l.readDigits()
+		} else if isAnyDigit(next) {
+			isFloat = true
+			l.readChar()
+			l.readDigits()
+		}
+	}
+
+	if l.ch == '_' {
+		// An underscore not between two digits, as in 1_ or 1__000
+		for isLetter(l.ch) || isAnyDigit(l.ch) {
+			l.readChar()
+		}
+		return l.text(startPos, l.position), token.ILLEGAL
+	}
+
+	result := strings.ReplaceAll(l.text(startPos, l.position), "_", "")
+	if (l.ch == token.DecimalSuffix || l.ch == 'd') && !isLetter(l.peekChar()) && !isAnyDigit(l.peekChar()) {
 		l.readChar()
+		return token.NormalizeFloat(result), token.DECIMAL
+	}
+	if isFloat {
+		return token.NormalizeFloat(result), token.FLOAT
 	}
-	result := string(l.input[startPos:l.position])
 	// Convert Bengali digits to Arabic
-	return token.ConvertBengaliNumber(result)
+	return token.ConvertBengaliNumber(result), token.INT
+}
+
+// readDigits advances over a run of Arabic or Bengali digits, which may be
+// grouped by single underscores between digits (1_000_000)
+func (l *Lexer) readDigits() {
+	for isAnyDigit(l.ch) || (l.ch == '_' && isAnyDigit(l.peekChar())) {
+		l.readChar()
+	}
 }
 
-// readString reads a string literal
+// peekCharAt looks ahead n characters past the next one
+func (l *Lexer) peekCharAt(n int) rune {
+	return l.charAt(l.readPosition + n)
+}
+
+// readString reads a string literal. Inside a ${...} hole the string
+// does not end at a quote, which starts a string nested in the hole.
 func (l *Lexer) readString() string {
+	startPos := l.position + 1
+	holes := 0
+	for {
+		l.readChar()
+		if l.ch == 0 || (l.ch == '"' && holes == 0) {
+			break
+		}
+		switch {
+		case l.ch == '$' && l.peekChar() == '{':
+			l.readChar()
+			holes++
+		case holes > 0 && l.ch == '{':
+			holes++
+		case holes > 0 && l.ch == '}':
+			holes--
+		case holes > 0 && l.ch == '"':
+			l.readString()
+		}
+	}
+	return l.text(startPos, l.position)
+}
+
+// readRawString reads a `raw string`, which runs to the next backquote
+// across any number of lines. Nothing inside is special: no ${...} holes
+// are filled in and quotes need no care. Carriage returns are dropped so a
+// file saved with CRLF line endings gives the same string. It reports
+// false when the input ends first.
+func (l *Lexer) readRawString() (string, bool) {
 	startPos := l.position + 1
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
+		if l.ch == 0 {
+			return "", false
+		}
+		if l.ch == '`' {
 			break
 		}
 	}
-	return string(l.input[startPos:l.position])
+	return strings.ReplaceAll(l.text(startPos, l.position), "\r", ""), true
 }
 
-// skipWhitespace skips whitespace characters
-func (l *Lexer) skipWhitespace() {
+// readCharLiteral reads the text of a 'ক' literal, leaving the parser to
+// check that it is a single character. ''' is the quote character itself.
+// It reports false when the line ends before the closing quote.
+func (l *Lexer) readCharLiteral() (string, bool) {
+	l.readChar()
+	startPos := l.position
+	if l.ch == '\'' && l.peekChar() == '\'' {
+		l.readChar()
+		return "'", true
+	}
+	for l.ch != '\'' {
+		if l.ch == '\n' || l.ch == 0 {
+			return "", false
+		}
+		l.readChar()
+	}
+	return l.text(startPos, l.position), true
+}
+
+// skipWhitespace skips whitespace characters and returns how many line
+// breaks it passed
+func (l *Lexer) skipWhitespace() int {
+	lines := 0
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+		if l.ch == '\n' {
+			lines++
+		}
 		l.readChar()
 	}
+	return lines
 }
 
-// skipComment skips comments until end of line
+// skipComment skips comments until end of line. The text of a /// doc
+// comment is kept for the next token, unless a blank line follows it.
 func (l *Lexer) skipComment() {
+	start := l.position
 	for l.ch != '\n' && l.ch != 0 {
 		l.readChar()
 	}
+	text := l.text(start, l.position)
+	isDoc := strings.HasPrefix(text, "///") && !strings.HasPrefix(text, "////")
+	if isDoc {
+		text = strings.TrimPrefix(text, "///")
+		text = strings.TrimPrefix(text, " ")
+		l.doc = append(l.doc, strings.TrimRight(text, " \t\r"))
+	}
+	if l.skipWhitespace() > 1 && isDoc {
+		l.doc = nil
+	}
+}
+
+// skipBlockComment skips a /* ... */ comment, which may span lines but
+// does not nest. It reports false when the input ends first.
+func (l *Lexer) skipBlockComment() bool {
+	l.readChar() // the *
+	l.readChar()
+	for !(l.ch == '*' && l.peekChar() == '/') {
+		if l.ch == 0 {
+			return false
+		}
+		l.readChar()
+	}
+	l.readChar() // the /
+	l.readChar()
 	l.skipWhitespace()
+	return true
 }
 
 // isLetter checks if a character is a letter (including Bengali)
@@ -271,6 +554,11 @@ func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
+// isAnyDigit checks if a character is an Arabic or Bengali digit
+func isAnyDigit(ch rune) bool {
+	return isDigit(ch) || isBengaliDigit(ch)
+}
+
 // isBengaliDigit checks if a character is a Bengali digit
 func isBengaliDigit(ch rune) bool {
 	return '০' <= ch && ch <= '৯'
@@ -281,6 +569,17 @@ func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
+// newOperator creates the token for an arithmetic operator, or for its
+// compound assignment form when the operator is followed by =
+func (l *Lexer) newOperator(op, assign token.TokenType) token.Token {
+	if l.peekChar() == '=' {
+		ch := l.ch
+		l.readChar()
+		return l.newTokenWithPos(assign, string(ch)+string(l.ch))
+	}
+	return l.newTokenWithPos(op, string(l.ch))
+}
+
 // newTokenWithPos creates a new token with position information
 func (l *Lexer) newTokenWithPos(tokenType token.TokenType, literal string) token.Token {
 	return token.Token{
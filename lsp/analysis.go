@@ -0,0 +1,426 @@
+package lsp
+
+import (
+	"bhasa/ast"
+	"bhasa/compiler"
+	"bhasa/lexer"
+	"bhasa/object"
+	"bhasa/parser"
+	"bhasa/token"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// document is an open file and what was learned from its last text
+type document struct {
+	uri         string
+	path        string // "" when the document is not a file
+	text        string
+	lines       []string
+	diagnostics []Diagnostic
+
+	program *ast.Program
+	defs    map[*ast.Identifier]compiler.Definition // empty when the text does not parse
+	idents  []*ast.Identifier                       // every identifier in the program
+	decls   map[*ast.Identifier]ast.Node            // the let, function or definition declaring each name
+}
+
+// parseErrorPattern splits a parser error into its position and message
+var parseErrorPattern = regexp.MustCompile(`^\[Line (\d+), Col (\d+)\] (.*)$`)
+
+// analyze parses and checks the text of a document the way `bhasa --check`
+// does, importing modules relative to its file. Names are only resolved
+// when the text parses, as the checker needs a whole program.
+func analyze(uri, text string) *document {
+	doc := &document{uri: uri, path: uriPath(uri), text: text, lines: lines(text)}
+
+	p := parser.New(lexer.New(text))
+	doc.program = p.ParseProgram()
+	for _, msg := range p.Errors() {
+		line, col := 0, 0
+		if m := parseErrorPattern.FindStringSubmatch(msg); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			col, _ = strconv.Atoi(m[2])
+			msg = m[3]
+		}
+		doc.diagnostics = append(doc.diagnostics, Diagnostic{Range: doc.span(line, col), Severity: severityError, Source: "bhasa", Message: msg})
+	}
+
+	if len(p.Errors()) == 0 {
+		// The checker only places its errors when it has a file name
+		file := doc.path
+		if file == "" {
+			file = doc.uri
+		}
+		c := compiler.New()
+		c.SetFile(file)
+		c.SetModuleLoader(compiler.DirModuleLoader(doc.dir()))
+
+		err := c.Check(doc.program)
+		for _, warning := range c.Warnings() {
+			doc.diagnostics = append(doc.diagnostics, doc.compileDiagnostic(warning, severityWarning))
+		}
+		var list compiler.ErrorList
+		if errors.As(err, &list) {
+			for _, err := range list {
+				doc.diagnostics = append(doc.diagnostics, doc.compileDiagnostic(err, severityError))
+			}
+		} else if err != nil {
+			doc.diagnostics = append(doc.diagnostics, doc.compileDiagnostic(err, severityError))
+		}
+		doc.defs = c.Definitions()
+	}
+
+	doc.collect()
+	return doc
+}
+
+// collect notes the identifiers of the program and the declarations of
+// the names in it
+func (doc *document) collect() {
+	doc.decls = map[*ast.Identifier]ast.Node{}
+	ast.Inspect(doc.program, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.InterpolatedString:
+			// The holes are parsed on their own, so their positions are
+			// within the hole rather than the document
+			return false
+		case *ast.Identifier:
+			doc.idents = append(doc.idents, node)
+		case *ast.LetStatement:
+			doc.decls[node.Name] = node
+		case *ast.DestructuringLetStatement:
+			for _, name := range node.Names {
+				doc.decls[name] = node
+			}
+		case *ast.FunctionLiteral:
+			doc.declareParameters(node, node.Parameters)
+		case *ast.MethodDefinition:
+			doc.declareParameters(node, node.Parameters)
+		case *ast.ConstructorDefinition:
+			doc.declareParameters(node, node.Parameters)
+		case *ast.ClassDefinition:
+			doc.decls[node.Name] = node
+		case *ast.InterfaceDefinition:
+			doc.decls[node.Name] = node
+		}
+		return true
+	})
+}
+
+// dir is where the modules the document imports are looked for first
+func (doc *document) dir() string {
+	if doc.path == "" {
+		return "."
+	}
+	return filepath.Dir(doc.path)
+}
+
+// module reads the file of a module the document imports, which is parsed
+// but not checked. ok is false when it cannot be found or read.
+func (doc *document) module(path string) (module *document, ok bool) {
+	file, err := compiler.FindModule(doc.dir(), path)
+	if err != nil {
+		return nil, false
+	}
+	source, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+	module = &document{uri: pathURI(file), path: file, text: string(source), lines: lines(string(source))}
+	module.program = parser.New(lexer.New(string(source))).ParseProgram()
+	module.collect()
+	return module, true
+}
+
+// declared finds the name a module declares at the same place as name,
+// which the checker read from the module's file
+func (doc *document) declared(name *ast.Identifier) *ast.Identifier {
+	for decl := range doc.decls {
+		if decl.Token.Line == name.Token.Line && decl.Token.Column == name.Token.Column {
+			return decl
+		}
+	}
+	return nil
+}
+
+func (doc *document) declareParameters(fn ast.Node, params []*ast.Identifier) {
+	for _, param := range params {
+		doc.decls[param] = fn
+	}
+}
+
+// compileDiagnostic places an error from the checker, which knows the
+// line and usually the column of what it reports
+func (doc *document) compileDiagnostic(err error, severity int) Diagnostic {
+	line, col, msg := 0, 0, err.Error()
+	var located *compiler.CompileError
+	if errors.As(err, &located) {
+		line, col, msg = located.Line, located.Column, located.Err.Error()
+	}
+	return Diagnostic{Range: doc.span(line, col), Severity: severity, Source: "bhasa", Message: msg}
+}
+
+// span is the range of the word at a 1-based line and rune column, or of
+// the whole line without its indentation when the column is not known
+func (doc *document) span(line, col int) Range {
+	if line < 1 {
+		line = 1
+	}
+	if line > len(doc.lines) {
+		line = len(doc.lines)
+	}
+	text := []rune(doc.lines[line-1])
+
+	start, end := col-1, col
+	if col < 1 {
+		start, end = 0, len(text)
+		for start < end && unicode.IsSpace(text[start]) {
+			start++
+		}
+	} else {
+		if start > len(text) {
+			start = len(text)
+		}
+		end = start
+		for end < len(text) && isWordRune(text[end]) {
+			end++
+		}
+		if end == start && end < len(text) {
+			end++ // a symbol rather than a word
+		}
+	}
+	return Range{
+		Start: Position{Line: line - 1, Character: utf16Offset(string(text), start)},
+		End:   Position{Line: line - 1, Character: utf16Offset(string(text), end)},
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// identRange is the range an identifier takes in the given lines
+func identRange(lines []string, ident *ast.Identifier) Range {
+	line, col := ident.Token.Line-1, ident.Token.Column-1
+	text := ""
+	if line >= 0 && line < len(lines) {
+		text = lines[line]
+	}
+	return Range{
+		Start: Position{Line: line, Character: utf16Offset(text, col)},
+		End:   Position{Line: line, Character: utf16Offset(text, col+len([]rune(ident.Value)))},
+	}
+}
+
+// identAt finds the identifier under the cursor, which may also be just
+// after its last letter
+func (doc *document) identAt(pos Position) *ast.Identifier {
+	if pos.Line < 0 || pos.Line >= len(doc.lines) {
+		return nil
+	}
+	line, col := pos.Line+1, runeOffset(doc.lines[pos.Line], pos.Character)+1
+	for _, ident := range doc.idents {
+		start := ident.Token.Column
+		if ident.Token.Line == line && start <= col && col <= start+len([]rune(ident.Value)) {
+			return ident
+		}
+	}
+	return nil
+}
+
+func (doc *document) hover(pos Position) *hover {
+	ident := doc.identAt(pos)
+	if ident == nil {
+		return nil
+	}
+	def, ok := doc.defs[ident]
+	if !ok {
+		return nil
+	}
+
+	var signature, text string
+	switch {
+	case def.Name == nil:
+		if !isBuiltin(ident.Value) {
+			return nil
+		}
+		signature, text = ident.Value, "অন্তর্নির্মিত ফাংশন"
+	case def.Module != "":
+		signature = "ধরি " + ident.Value + typeSuffix(def.Type)
+		if module, ok := doc.module(def.Module); ok {
+			if decl := module.declared(def.Name); decl != nil {
+				signature, text = module.describe(decl)
+			}
+		}
+		if text != "" {
+			text += "\n\n"
+		}
+		text += "মডিউল `" + def.Module + "` থেকে"
+	default:
+		signature, text = doc.describe(def.Name)
+	}
+
+	value := "```bhasa\n" + signature + "\n```"
+	if text != "" {
+		value += "\n\n" + text
+	}
+	return &hover{Contents: markupContent{Kind: "markdown", Value: value}, Range: identRange(doc.lines, ident)}
+}
+
+// describe writes how a name of this document was declared, with its
+// type, and the doc comment of the declaration
+func (doc *document) describe(name *ast.Identifier) (string, string) {
+	switch node := doc.decls[name].(type) {
+	case *ast.LetStatement:
+		if fn, ok := node.Value.(*ast.FunctionLiteral); ok {
+			text := fn.Doc
+			if text == "" {
+				text = node.Token.Doc
+			}
+			return "ধরি " + name.Value + " = " + functionSignature(fn.Parameters, fn.ParameterTypes, fn.Defaults, fn.Rest, fn.ReturnType), text
+		}
+		typ := typeSuffix(node.TypeAnnot)
+		if typ == "" {
+			if inferred := valueType(node.Value); inferred != "" {
+				typ = ": " + inferred
+			}
+		}
+		return "ধরি " + name.Value + typ, node.Token.Doc
+	case *ast.FunctionLiteral:
+		return parameter(name, node.Parameters, node.ParameterTypes), "প্যারামিটার"
+	case *ast.MethodDefinition:
+		return parameter(name, node.Parameters, node.ParameterTypes), "`" + node.Name.Value + "` পদ্ধতির প্যারামিটার"
+	case *ast.ConstructorDefinition:
+		return parameter(name, node.Parameters, node.ParameterTypes), "নির্মাতার প্যারামিটার"
+	case *ast.ClassDefinition:
+		return strings.TrimSuffix(node.String(), " { ... }"), node.Doc
+	case *ast.InterfaceDefinition:
+		return "চুক্তি " + name.Value, node.Token.Doc
+	}
+	return "ধরি " + name.Value, ""
+}
+
+func functionSignature(params []*ast.Identifier, types []*ast.TypeAnnotation, defaults []ast.Expression, rest bool, ret *ast.TypeAnnotation) string {
+	return "ফাংশন(" + ast.FormatParameters(params, types, defaults, rest) + ")" + typeSuffix(ret)
+}
+
+func parameter(name *ast.Identifier, params []*ast.Identifier, types []*ast.TypeAnnotation) string {
+	for i, param := range params {
+		if param == name && i < len(types) {
+			return name.Value + typeSuffix(types[i])
+		}
+	}
+	return name.Value
+}
+
+func typeSuffix(t *ast.TypeAnnotation) string {
+	if t == nil {
+		return ""
+	}
+	return ": " + t.String()
+}
+
+// valueType is the type a literal value plainly has, or "" when it takes
+// running the program to know
+func valueType(value ast.Expression) string {
+	switch value := value.(type) {
+	case *ast.IntegerLiteral:
+		return token.TYPE_INT
+	case *ast.FloatLiteral:
+		return token.TYPE_FLOAT
+	case *ast.StringLiteral:
+		return token.TYPE_STRING
+	case *ast.CharLiteral:
+		return token.TYPE_CHAR
+	case *ast.Boolean:
+		return token.TYPE_BOOLEAN
+	case *ast.ArrayLiteral:
+		return token.TYPE_ARRAY
+	case *ast.HashLiteral:
+		return token.TYPE_HASH
+	case *ast.NewExpression:
+		return value.ClassName.Value
+	}
+	return ""
+}
+
+func isBuiltin(name string) bool {
+	for _, def := range object.Builtins {
+		if def.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// definition is where the name under the cursor was declared, in this
+// document or in the module it was imported from
+func (doc *document) definition(pos Position) *Location {
+	ident := doc.identAt(pos)
+	if ident == nil {
+		return nil
+	}
+	def, ok := doc.defs[ident]
+	if !ok || def.Name == nil {
+		return nil
+	}
+	if def.Module == "" {
+		return &Location{URI: doc.uri, Range: identRange(doc.lines, def.Name)}
+	}
+	module, ok := doc.module(def.Module)
+	if !ok {
+		return nil
+	}
+	return &Location{URI: module.uri, Range: identRange(module.lines, def.Name)}
+}
+
+// completion offers the keywords, the builtins and every name declared in
+// the document; the editor narrows them down by what has been typed
+func (doc *document) completion() []completionItem {
+	var items []completionItem
+	for _, keyword := range token.Keywords() {
+		items = append(items, completionItem{Label: keyword, Kind: kindKeyword})
+	}
+	for _, def := range object.Builtins {
+		items = append(items, completionItem{Label: def.Name, Kind: kindFunction, Detail: "অন্তর্নির্মিত ফাংশন"})
+	}
+
+	// The first declaration of a name in the text describes it
+	var declared []*ast.Identifier
+	for name := range doc.decls {
+		declared = append(declared, name)
+	}
+	sort.Slice(declared, func(i, j int) bool {
+		a, b := declared[i].Token, declared[j].Token
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+
+	seen := map[string]bool{}
+	var names []completionItem
+	for _, name := range declared {
+		if seen[name.Value] {
+			continue
+		}
+		seen[name.Value] = true
+		item := completionItem{Label: name.Value, Kind: kindVariable}
+		switch node := doc.decls[name].(type) {
+		case *ast.ClassDefinition, *ast.InterfaceDefinition:
+			item.Kind = kindClass
+		case *ast.LetStatement:
+			if _, ok := node.Value.(*ast.FunctionLiteral); ok {
+				item.Kind = kindFunction
+			}
+		}
+		item.Detail, _ = doc.describe(name)
+		names = append(names, item)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i].Label < names[j].Label })
+	return append(items, names...)
+}
@@ -0,0 +1,192 @@
+package lsp
+
+import (
+	"bhasa/ast"
+	"bhasa/lexer"
+	"bhasa/token"
+	"strings"
+)
+
+// Highlight is the kind of one token of a program, for an editor to color
+// it by. Line and Column are 1-based and, like Length, count characters.
+//
+// The kinds are keyword, type (পূর্ণসংখ্যা, পাঠ্য, ...), builtin, function,
+// class (classes and interfaces), parameter, variable, property (a name
+// after . or ?.), string, number and operator. Names are told apart by
+// what they were declared as, so a builtin shadowed by a variable is a
+// variable. Punctuation is not highlighted.
+type Highlight struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Length int    `json:"length"`
+	Kind   string `json:"kind"`
+}
+
+// Highlights classifies the tokens of a program the way `bhasa lsp` does
+// for semantic highlighting. file is the file the text was read from, for
+// finding the modules it imports, or "" when it is not a file.
+func Highlights(file, text string) []Highlight {
+	uri := "untitled:"
+	if file != "" {
+		uri = pathURI(file)
+	}
+	return analyze(uri, text).highlights()
+}
+
+// highlights classifies the tokens of the document's text. Tokens the
+// lexer reads across lines, such as multi-line strings, are split into one
+// highlight a line, as editors need.
+func (doc *document) highlights() []Highlight {
+	// Where each line starts, in characters from the start of the text
+	starts := []int{0}
+	for _, line := range strings.SplitAfter(doc.text, "\n") {
+		starts = append(starts, starts[len(starts)-1]+len([]rune(line)))
+	}
+
+	idents := map[[2]int]*ast.Identifier{}
+	for _, ident := range doc.idents {
+		idents[[2]int{ident.Token.Line, ident.Token.Column}] = ident
+	}
+
+	var list []Highlight
+	var prev token.Token
+	l := lexer.New(doc.text)
+	for tok := l.NextToken(); tok.Type != token.EOF; prev, tok = tok, l.NextToken() {
+		kind := doc.tokenKind(tok, prev, idents)
+		if kind == "" || tok.Line < 1 || tok.Line > len(doc.lines) {
+			continue
+		}
+
+		// The lexer is left just after the token
+		line, col := tok.Line, tok.Column
+		end := l.Offset()
+		for line <= len(doc.lines) {
+			lineEnd := starts[line-1] + len([]rune(doc.lines[line-1]))
+			stop := end
+			if stop > lineEnd {
+				stop = lineEnd
+			}
+			if n := stop - (starts[line-1] + col - 1); n > 0 {
+				list = append(list, Highlight{Line: line, Column: col, Length: n, Kind: kind})
+			}
+			if end <= lineEnd {
+				break
+			}
+			line, col = line+1, 1
+		}
+	}
+	return list
+}
+
+// tokenKind is the kind of highlight a token gets, or "" for punctuation
+func (doc *document) tokenKind(tok, prev token.Token, idents map[[2]int]*ast.Identifier) string {
+	switch tok.Type {
+	case token.IDENT:
+		if prev.Type == token.DOT || prev.Type == token.QUESTION_DOT {
+			return "property"
+		}
+		return doc.nameKind(tok.Literal, idents[[2]int{tok.Line, tok.Column}])
+	case token.STRING, token.RAW_STRING, token.CHAR:
+		return "string"
+	case token.INT, token.FLOAT, token.DECIMAL:
+		return "number"
+	case token.ILLEGAL, token.COMMA, token.SEMICOLON, token.COLON, token.DOT,
+		token.LPAREN, token.RPAREN, token.LBRACE, token.RBRACE, token.LBRACKET, token.RBRACKET:
+		return ""
+	}
+	if token.IsType(tok.Type) {
+		return "type"
+	}
+	if token.LookupIdent(tok.Literal) == tok.Type {
+		return "keyword"
+	}
+	return "operator"
+}
+
+// nameKind is the kind of a name: by the declaration the checker found
+// for it when the program could be checked, and otherwise by the first
+// declaration of a name spelled the same in the document
+func (doc *document) nameKind(name string, ident *ast.Identifier) string {
+	if def, ok := doc.defs[ident]; ok {
+		switch {
+		case def.Class:
+			return "class"
+		case def.Name == nil:
+			if isBuiltin(name) {
+				return "builtin"
+			}
+			return "variable"
+		case def.Module != "":
+			return "variable"
+		}
+		return declKind(doc.decls[def.Name])
+	}
+
+	var first *ast.Identifier
+	for decl := range doc.decls {
+		if decl.Value == name && (first == nil || decl.Token.Line < first.Token.Line ||
+			decl.Token.Line == first.Token.Line && decl.Token.Column < first.Token.Column) {
+			first = decl
+		}
+	}
+	if first != nil {
+		return declKind(doc.decls[first])
+	}
+	if isBuiltin(name) {
+		return "builtin"
+	}
+	return "variable"
+}
+
+// declKind is the kind of the names a declaration declares
+func declKind(node ast.Node) string {
+	switch node := node.(type) {
+	case *ast.ClassDefinition, *ast.InterfaceDefinition:
+		return "class"
+	case *ast.FunctionLiteral, *ast.MethodDefinition, *ast.ConstructorDefinition:
+		return "parameter"
+	case *ast.LetStatement:
+		if _, ok := node.Value.(*ast.FunctionLiteral); ok {
+			return "function"
+		}
+	}
+	return "variable"
+}
+
+// semanticTokens encodes highlights the way the protocol sends them: five
+// numbers a token, its line and start relative to the token before, its
+// length, its type as an index into the legend and its modifiers as bits
+func (doc *document) semanticTokens() []int {
+	data := []int{}
+	prevLine, prevStart := 0, 0
+	for _, h := range doc.highlights() {
+		typ, modifiers := h.Kind, 0
+		if typ == "builtin" {
+			typ, modifiers = "function", 1 // defaultLibrary
+		}
+
+		text := doc.lines[h.Line-1]
+		line := h.Line - 1
+		start := utf16Offset(text, h.Column-1)
+		length := utf16Offset(text, h.Column-1+h.Length) - start
+		if line != prevLine {
+			prevStart = 0
+		}
+		data = append(data, line-prevLine, start-prevStart, length, legendIndex(typ), modifiers)
+		prevLine, prevStart = line, start
+	}
+	return data
+}
+
+// semanticTokenTypes is the legend of the token types the server sends,
+// the standard ones of the protocol
+var semanticTokenTypes = []string{"keyword", "type", "function", "class", "parameter", "variable", "property", "string", "number", "operator"}
+
+func legendIndex(typ string) int {
+	for i, name := range semanticTokenTypes {
+		if name == typ {
+			return i
+		}
+	}
+	return len(semanticTokenTypes) - 1
+}
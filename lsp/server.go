@@ -0,0 +1,202 @@
+// Package lsp is a language server for Bhasa. It speaks the Language
+// Server Protocol over a pair of streams, so that any editor with an LSP
+// client gets diagnostics, hover, go to definition and completion.
+package lsp
+
+import (
+	"bhasa/version"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"path/filepath"
+)
+
+// server is the state of one editor session: the documents it has open
+type server struct {
+	out      io.Writer
+	docs     map[string]*document // by URI
+	shutdown bool                 // shutdown was requested, only exit may follow
+	err      error                // the first failure to write to the editor
+}
+
+// Serve answers the messages an editor sends on in, writing to out, until
+// the editor sends exit. Documents are checked each time they change and
+// their errors sent back as diagnostics. It fails when the messages cannot
+// be read or written, or when the editor exits without asking the server
+// to shut down first.
+func Serve(in io.Reader, out io.Writer) error {
+	s := &server{out: out, docs: map[string]*document{}}
+	r := bufio.NewReader(in)
+	for s.err == nil {
+		msg, err := readMessage(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit without shutdown")
+			}
+			return nil
+		}
+		s.handle(msg)
+	}
+	return s.err
+}
+
+// handle answers a request, or acts on a notification, which has no ID
+func (s *server) handle(msg *message) {
+	result, rerr := s.dispatch(msg)
+	if msg.ID == nil {
+		return
+	}
+	if rerr != nil {
+		s.send(errorResponse{JSONRPC: "2.0", ID: msg.ID, Error: *rerr})
+		return
+	}
+	s.send(response{JSONRPC: "2.0", ID: msg.ID, Result: result})
+}
+
+func (s *server) dispatch(msg *message) (interface{}, *responseError) {
+	if s.shutdown {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "the server is shutting down"}
+	}
+
+	switch msg.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   1, // the whole text is sent on every change
+				"hoverProvider":      true,
+				"definitionProvider": true,
+				"completionProvider": map[string]interface{}{},
+				"semanticTokensProvider": map[string]interface{}{
+					"legend": map[string]interface{}{
+						"tokenTypes":     semanticTokenTypes,
+						"tokenModifiers": []string{"defaultLibrary"}, // builtins
+					},
+					"full": true,
+				},
+			},
+			"serverInfo": map[string]string{"name": "bhasa", "version": version.Version},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.update(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+	case "textDocument/didClose":
+		var params documentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		delete(s.docs, params.TextDocument.URI)
+		s.publish(params.TextDocument.URI, nil)
+
+	case "textDocument/semanticTokens/full":
+		var params documentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		doc := s.docs[params.TextDocument.URI]
+		if doc == nil {
+			return nil, nil
+		}
+		return semanticTokens{Data: doc.semanticTokens()}, nil
+
+	case "textDocument/hover", "textDocument/definition", "textDocument/completion":
+		var params positionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		doc := s.docs[params.TextDocument.URI]
+		if doc == nil {
+			return nil, nil
+		}
+		switch msg.Method {
+		case "textDocument/hover":
+			return doc.hover(params.Position), nil
+		case "textDocument/definition":
+			return doc.definition(params.Position), nil
+		}
+		return doc.completion(), nil
+
+	default:
+		// Notifications the server has no use for, such as initialized
+		// and $/cancelRequest, are ignored
+		if msg.ID != nil {
+			return nil, &responseError{Code: codeMethodNotFound, Message: "unsupported method " + msg.Method}
+		}
+	}
+	return nil, nil
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{Code: codeInvalidParams, Message: err.Error()}
+}
+
+// update checks the new text of a document and sends its diagnostics
+func (s *server) update(uri, text string) {
+	doc := analyze(uri, text)
+	s.docs[uri] = doc
+	s.publish(uri, doc.diagnostics)
+}
+
+func (s *server) publish(uri string, diagnostics []Diagnostic) {
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+func (s *server) notify(method string, params interface{}) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		if s.err == nil {
+			s.err = err
+		}
+		return
+	}
+	s.send(message{JSONRPC: "2.0", Method: method, Params: raw})
+}
+
+// send writes a message to the editor, keeping the first error
+func (s *server) send(v interface{}) {
+	if s.err == nil {
+		s.err = writeMessage(s.out, v)
+	}
+}
+
+// uriPath is the file a file: URI names, or "" for other schemes, such as
+// the untitled: of a buffer that was never saved
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -1,73 +1,207 @@
 package main
 
 import (
-	"bhasa/compiler"
-	"bhasa/lexer"
-	"bhasa/parser"
-	"bhasa/repl"
-	"bhasa/vm"
+	"bhasa/version"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"path/filepath"
-	"strings"
 )
 
+// command is a bhasa subcommand such as "run" or "build"
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(args []string) int
+}
+
+// commands is the table of subcommands, filled in by init to avoid an
+// initialization cycle with the help command
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{"init", "init <directory>", "Create a new project", cmdInit},
+		{"run", "run [-arena] [-numerals latin|bengali] [-watch names] [-trace] [-allocs] [-cpuprofile f] [-memprofile f] <file|-> [args...]", "Run a source or bytecode file, or standard input", cmdRun},
+		{"build", "build [-o output] [--listing file] <file>", "Compile source to bytecode", cmdBuild},
+		{"repl", "repl", "Start the interactive REPL", cmdRepl},
+		{"lsp", "lsp", "Start a language server for editors on standard input and output", cmdLsp},
+		{"test", "test [paths...]", "Run the tests in *_পরীক্ষা files under the given paths", cmdTest},
+		{"fmt", "fmt [-l] [-w] <files...>", "Format source files", cmdFmt},
+		{"check", "check <files...>", "Report errors in files without running them", cmdCheck},
+		{"spec", "spec [-engine vm|eval] [paths...]", "Check the output of spec files on both engines", cmdSpec},
+		{"fuzz", "fuzz [-n count] [-seed n] [-o dir]", "Compare the VM and the evaluator on random programs", cmdFuzz},
+		{"golden", "golden [-update] [paths...]", "Compare disassembly of fixtures with golden files", cmdGolden},
+		{"dis", "dis <file>", "Disassemble a source or bytecode file", cmdDis},
+		{"doc", "doc [-html] [-o file] <files or directories...>", "Write Markdown or HTML documentation from doc comments", cmdDoc},
+		{"graph", "graph [-o file] <file|directory>", "Draw classes and imports as a Graphviz graph", cmdGraph},
+		{"bench", "bench [-count n] [-arena] <file>", "Time a program and count the instructions it runs", cmdBench},
+		{"bench-suite", "bench-suite [-count n] [-arena] [-json file] [-baseline file]", "Run the built-in VM benchmarks", cmdBenchSuite},
+		{"version", "version [--json]", "Show version information", cmdVersion},
+		{"help", "help", "Show this help message", cmdHelp},
+	}
+}
+
+// findCommand looks up a subcommand by name
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
 func main() {
-	// Define CLI flags
-	compileMode := flag.Bool("c", false, "Compile source to bytecode")
-	outputFile := flag.String("o", "", "Output file for compiled bytecode")
-	showHelp := flag.Bool("h", false, "Show help message")
-	showVersion := flag.Bool("v", false, "Show version information")
+	if len(os.Args) > 1 {
+		if c := findCommand(os.Args[1]); c != nil {
+			os.Exit(c.run(os.Args[2:]))
+		}
+	}
+	os.Exit(legacyMain(os.Args[1:]))
+}
 
-	flag.Parse()
+// legacyMain keeps the original flag-only interface working:
+// bhasa [-c] [-o out] [-h] [-v] [file]
+func legacyMain(args []string) int {
+	fs := flag.NewFlagSet("bhasa", flag.ContinueOnError)
+	compileMode := fs.Bool("c", false, "Compile source to bytecode")
+	outputFile := fs.String("o", "", "Output file for compiled bytecode")
+	listingFile := fs.String("listing", "", "Also write a source/bytecode listing to this file")
+	dumpTree := fs.Bool("ast", false, "Print the syntax tree of a file as JSON instead of running it")
+	dumpLexed := fs.Bool("tokens", false, "Print the tokens of a file instead of running it")
+	highlight := fs.Bool("highlight", false, "Print the kind of each token of a file as JSON, for editors to color it by")
+	traceRun := fs.Bool("trace", false, "Write every instruction run, with the top of the stack, to stderr")
+	cpuProfile := fs.String("cpuprofile", "", "Write a CPU profile for go tool pprof to this file")
+	memProfile := fs.String("memprofile", "", "Write a memory profile for go tool pprof to this file")
+	checkOnly := fs.Bool("check", false, "Report the errors in files without running them (same as bhasa check)")
+	showHelp := fs.Bool("h", false, "Show help message")
+	showVersion := fs.Bool("v", false, "Show version information")
+	fs.Usage = printHelp
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
 
 	// Show help
 	if *showHelp {
 		printHelp()
-		return
+		return 0
 	}
 
 	// Show version
 	if *showVersion {
-		fmt.Println("Bhasa (ভাষা) Programming Language v1.0.0")
-		fmt.Println("Bytecode Compiler & VM")
-		return
+		fmt.Println(version.Get())
+		return 0
 	}
 
 	// Get remaining arguments (non-flag arguments)
-	args := flag.Args()
+	rest := fs.Args()
 
-	if len(args) < 1 {
+	if len(rest) < 1 && (*dumpTree || *dumpLexed || *highlight) {
+		fmt.Fprintln(os.Stderr, "Usage: bhasa --ast|--tokens|--highlight <file|->")
+		return 2
+	}
+	if len(rest) < 1 && *checkOnly {
+		fmt.Fprintln(os.Stderr, "Usage: bhasa --check <files...>")
+		return 2
+	}
+
+	if len(rest) < 1 {
 		// Start REPL if no file is provided
-		repl.Start(os.Stdin, os.Stdout)
-		return
+		return cmdRepl(nil)
 	}
 
-	filename := args[0]
+	if *dumpTree {
+		return dumpAST(rest[0])
+	}
+	if *dumpLexed {
+		return dumpTokens(rest[0])
+	}
+	if *highlight {
+		return dumpHighlights(rest[0])
+	}
+	if *checkOnly {
+		return checkFiles(rest)
+	}
 
-	// Check if file is bytecode or source
-	if isBytecodeFile(filename) {
-		// Execute pre-compiled bytecode
-		runBytecode(filename)
-	} else if *compileMode {
+	if *compileMode && !isBytecodeFile(rest[0]) {
 		// Compile source to bytecode
-		compileFile(filename, *outputFile)
-	} else {
-		// Run source file directly
-		runFile(filename)
+		buildArgs := append([]string{"-o", *outputFile, "-listing", *listingFile}, rest...)
+		return cmdBuild(buildArgs)
+	}
+
+	// Run source or bytecode file directly
+	var runArgs []string
+	if *traceRun {
+		runArgs = append(runArgs, "-trace")
+	}
+	if *cpuProfile != "" {
+		runArgs = append(runArgs, "-cpuprofile", *cpuProfile)
+	}
+	if *memProfile != "" {
+		runArgs = append(runArgs, "-memprofile", *memProfile)
+	}
+	return cmdRun(append(runArgs, rest...))
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments, returning the positional arguments in order
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
 	}
 }
 
+// newFlagSet creates a flag set for a subcommand with a usage line
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("bhasa "+name, flag.ContinueOnError)
+	fs.Usage = func() {
+		c := findCommand(name)
+		fmt.Fprintf(os.Stderr, "Usage: bhasa %s\n", c.usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func cmdHelp(args []string) int {
+	printHelp()
+	return 0
+}
+
 func printHelp() {
 	fmt.Println("Bhasa (ভাষা) Programming Language - Usage:")
 	fmt.Println()
+	fmt.Println("  bhasa <command> [arguments]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, c := range commands {
+		fmt.Printf("  %-30s %s\n", c.usage, c.summary)
+	}
+	fmt.Println()
+	fmt.Println("Shorthand:")
 	fmt.Println("  bhasa                         Start REPL (interactive mode)")
 	fmt.Println("  bhasa <file>                  Run source file (.bhasa or .ভাষা)")
 	fmt.Println("  bhasa <bytecode>              Execute bytecode file (.compiled or .সংকলিত)")
 	fmt.Println("  bhasa -c <file>               Compile source to bytecode")
 	fmt.Println("  bhasa -c -o <output> <file>   Compile with custom output name")
+	fmt.Println("  bhasa -c --listing out.txt <file>  Also write a source/bytecode listing")
+	fmt.Println("  bhasa --ast <file>            Print the syntax tree as JSON")
+	fmt.Println("  bhasa --tokens <file>         Print the tokens with their positions")
+	fmt.Println("  bhasa --highlight <file>      Print the kind of each token as JSON")
+	fmt.Println("  bhasa --check <files...>      Report errors without running (same as check)")
+	fmt.Println("  bhasa --trace <file>          Run, writing every instruction to stderr")
+	fmt.Println("  bhasa --cpuprofile cpu.out <file>  Run, writing a CPU profile for go tool pprof")
+	fmt.Println("  bhasa --memprofile mem.out <file>  Run, writing a memory profile for go tool pprof")
 	fmt.Println("  bhasa -h                      Show this help message")
 	fmt.Println("  bhasa -v                      Show version information")
 	fmt.Println()
@@ -76,134 +210,10 @@ func printHelp() {
 	fmt.Println("  Bytecode:  .compiled or .সংকলিত")
 	fmt.Println()
 	fmt.Println("Examples:")
-	fmt.Println("  bhasa program.bhasa                   # Run source file")
-	fmt.Println("  bhasa -c program.bhasa                # Compile to program.compiled")
-	fmt.Println("  bhasa -c -o output.compiled program.bhasa")
-	fmt.Println("  bhasa -c -o output.সংকলিত program.ভাষা   # Bengali extensions")
-	fmt.Println("  bhasa program.compiled                # Execute compiled bytecode")
-	fmt.Println()
-	fmt.Println("Note: Flags must appear before the filename argument")
-}
-
-// isBytecodeFile checks if the file is a bytecode file based on extension
-func isBytecodeFile(filename string) bool {
-	ext := strings.ToLower(filepath.Ext(filename))
-	return ext == ".compiled" || ext == ".সংকলিত"
-}
-
-// runFile compiles and runs a source file
-func runFile(filename string) {
-	content, err := ioutil.ReadFile(filename)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
-		os.Exit(1)
-	}
-
-	l := lexer.New(string(content))
-	p := parser.New(l)
-
-	program := p.ParseProgram()
-	if len(p.Errors()) != 0 {
-		fmt.Fprintln(os.Stderr, "Parser errors:")
-		for _, msg := range p.Errors() {
-			fmt.Fprintf(os.Stderr, "\t%s\n", msg)
-		}
-		os.Exit(1)
-	}
-
-	comp := compiler.New()
-	err = comp.Compile(program)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Compilation failed:\n %s\n", err)
-		os.Exit(1)
-	}
-
-	machine := vm.New(comp.Bytecode())
-	err = machine.Run()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Executing bytecode failed:\n %s\n", err)
-		os.Exit(1)
-	}
-}
-
-// compileFile compiles a source file to bytecode
-func compileFile(filename string, outputFile string) {
-	// Read source file
-	content, err := ioutil.ReadFile(filename)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Parse source
-	l := lexer.New(string(content))
-	p := parser.New(l)
-
-	program := p.ParseProgram()
-	if len(p.Errors()) != 0 {
-		fmt.Fprintln(os.Stderr, "Parser errors:")
-		for _, msg := range p.Errors() {
-			fmt.Fprintf(os.Stderr, "\t%s\n", msg)
-		}
-		os.Exit(1)
-	}
-
-	// Compile to bytecode
-	comp := compiler.New()
-	err = comp.Compile(program)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Compilation failed:\n %s\n", err)
-		os.Exit(1)
-	}
-
-	// Determine output filename
-	if outputFile == "" {
-		// Default: replace extension with .compiled
-		ext := filepath.Ext(filename)
-		outputFile = strings.TrimSuffix(filename, ext) + ".compiled"
-	}
-
-	// Create output file
-	file, err := os.Create(outputFile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
-		os.Exit(1)
-	}
-	defer file.Close()
-
-	// Serialize bytecode to file
-	bytecode := comp.Bytecode()
-	err = bytecode.Serialize(file)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error serializing bytecode: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Printf("Successfully compiled %s to %s\n", filename, outputFile)
-}
-
-// runBytecode executes a pre-compiled bytecode file
-func runBytecode(filename string) {
-	// Open bytecode file
-	file, err := os.Open(filename)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening bytecode file: %v\n", err)
-		os.Exit(1)
-	}
-	defer file.Close()
-
-	// Deserialize bytecode
-	bytecode, err := compiler.Deserialize(file)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error deserializing bytecode: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Execute bytecode in VM
-	machine := vm.New(bytecode)
-	err = machine.Run()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Executing bytecode failed:\n %s\n", err)
-		os.Exit(1)
-	}
+	fmt.Println("  bhasa run program.bhasa               # Run source file")
+	fmt.Println("  bhasa build program.bhasa             # Compile to program.compiled")
+	fmt.Println("  bhasa build program.bhasa -o output.compiled")
+	fmt.Println("  bhasa build -o output.সংকলিত program.ভাষা   # Bengali extensions")
+	fmt.Println("  bhasa run program.compiled            # Execute compiled bytecode")
+	fmt.Println("  bhasa check a.bhasa b.bhasa           # Report errors without running")
 }
@@ -0,0 +1,220 @@
+package object
+
+// arrayArg returns args[i] as an array, or an error naming the builtin
+func arrayArg(name string, args []Object, i int) (*Array, *Error) {
+	arr, ok := args[i].(*Array)
+	if !ok {
+		return nil, newError("argument %d to '%s' must be ARRAY, got %s", i+1, name, args[i].Type())
+	}
+	return arr, nil
+}
+
+// arrayPosition reads the index at args[i] for an array of the given
+// length. Negative indices count from the end; an insertion may also use
+// length itself, meaning after the last element.
+func arrayPosition(name string, args []Object, i, length int, insert bool) (int, *Error) {
+	n, ok := integerValue(args[i])
+	if !ok {
+		return 0, newError("index argument to '%s' must be INTEGER, got %s", name, args[i].Type())
+	}
+	if insert && n == int64(length) {
+		return length, nil
+	}
+	pos, ok := ResolveIndex(n, length)
+	if !ok {
+		return 0, newError("index %d out of range for '%s'", n, name)
+	}
+	return pos, nil
+}
+
+// truncate shortens a mutable array in place, clearing the dropped slots
+// so their values can be collected
+func (ao *Array) truncate(n int) {
+	for i := n; i < len(ao.Elements); i++ {
+		ao.Elements[i] = nil
+	}
+	ao.Elements = ao.Elements[:n]
+}
+
+// SetIndex assigns to an element of a mutable array; negative indices
+// count from the end
+func (ao *Array) SetIndex(index, value Object) *Error {
+	if !ao.Mutable {
+		return newError("cannot assign to an element of an immutable array (make a mutable copy with পরিবর্তনীয়)")
+	}
+	n, ok := integerValue(index)
+	if !ok {
+		return newError("array index must be INTEGER, got %s", index.Type())
+	}
+	i, ok := ResolveIndex(n, len(ao.Elements))
+	if !ok {
+		return newError("index %d out of range for an array of length %d", n, len(ao.Elements))
+	}
+	ao.Elements[i] = value
+	return nil
+}
+
+// SetIndex assigns a byte, given as an integer 0-255
+func (b *Bytes) SetIndex(index, value Object) *Error {
+	n, ok := integerValue(index)
+	if !ok {
+		return newError("byte array index must be INTEGER, got %s", index.Type())
+	}
+	i, ok := ResolveIndex(n, len(b.Value))
+	if !ok {
+		return newError("index %d out of range for a byte array of length %d", n, len(b.Value))
+	}
+	c, err := byteValue("index assignment", value)
+	if err != nil {
+		return err
+	}
+	b.Value[i] = c
+	return nil
+}
+
+// SetIndex performs collection[index] = value
+func SetIndex(collection, index, value Object) *Error {
+	switch collection := collection.(type) {
+	case *Array:
+		return collection.SetIndex(index, value)
+	case *Bytes:
+		return collection.SetIndex(index, value)
+	}
+	return newError("index assignment not supported: %s", collection.Type())
+}
+
+// arrayBuiltins build changed copies of immutable arrays, leaving the
+// array passed in unchanged, and change mutable arrays in place
+var arrayBuiltins = []BuiltinDef{
+	{
+		"সন্নিবেশ", // insert before an index: (arr, index, value)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=3", len(args))
+			}
+			arr, err := arrayArg("সন্নিবেশ", args, 0)
+			if err != nil {
+				return err
+			}
+			pos, err := arrayPosition("সন্নিবেশ", args, 1, len(arr.Elements), true)
+			if err != nil {
+				return err
+			}
+			if arr.Mutable {
+				arr.Elements = append(arr.Elements, nil)
+				copy(arr.Elements[pos+1:], arr.Elements[pos:])
+				arr.Elements[pos] = args[2]
+				return arr
+			}
+			elements := make([]Object, 0, len(arr.Elements)+1)
+			elements = append(elements, arr.Elements[:pos]...)
+			elements = append(elements, args[2])
+			elements = append(elements, arr.Elements[pos:]...)
+			return &Array{Elements: elements}
+		}},
+	},
+	{
+		"সরাও", // remove the element at an index: (arr, index)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			arr, err := arrayArg("সরাও", args, 0)
+			if err != nil {
+				return err
+			}
+			pos, err := arrayPosition("সরাও", args, 1, len(arr.Elements), false)
+			if err != nil {
+				return err
+			}
+			if arr.Mutable {
+				copy(arr.Elements[pos:], arr.Elements[pos+1:])
+				arr.truncate(len(arr.Elements) - 1)
+				return arr
+			}
+			elements := make([]Object, 0, len(arr.Elements)-1)
+			elements = append(elements, arr.Elements[:pos]...)
+			elements = append(elements, arr.Elements[pos+1:]...)
+			return &Array{Elements: elements}
+		}},
+	},
+	{
+		"শেষ_বাদ", // pop: all but the last element; শেষ gives the element itself
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			arr, err := arrayArg("শেষ_বাদ", args, 0)
+			if err != nil {
+				return err
+			}
+			if len(arr.Elements) == 0 {
+				return newError("'শেষ_বাদ' called on an empty array")
+			}
+			if arr.Mutable {
+				arr.truncate(len(arr.Elements) - 1)
+				return arr
+			}
+			elements := make([]Object, len(arr.Elements)-1)
+			copy(elements, arr.Elements)
+			return &Array{Elements: elements}
+		}},
+	},
+	{
+		"খালি_করো", // clear: an empty array
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			arr, err := arrayArg("খালি_করো", args, 0)
+			if err != nil {
+				return err
+			}
+			if arr.Mutable {
+				arr.Elements = []Object{}
+				return arr
+			}
+			return &Array{Elements: []Object{}}
+		}},
+	},
+}
+
+// mutabilityBuiltins switch arrays between value and in-place semantics
+var mutabilityBuiltins = []BuiltinDef{
+	{
+		"পরিবর্তনীয়", // mutable copy of an array
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			arr, err := arrayArg("পরিবর্তনীয়", args, 0)
+			if err != nil {
+				return err
+			}
+			return &Array{Elements: append([]Object(nil), arr.Elements...), Mutable: true}
+		}},
+	},
+	{
+		"অপরিবর্তনীয়", // immutable copy of an array
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			arr, err := arrayArg("অপরিবর্তনীয়", args, 0)
+			if err != nil {
+				return err
+			}
+			return &Array{Elements: append([]Object(nil), arr.Elements...)}
+		}},
+	},
+	{
+		"পরিবর্তনীয়_কি", // whether an array is mutable
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			arr, ok := args[0].(*Array)
+			return NativeBool(ok && arr.Mutable)
+		}},
+	},
+}
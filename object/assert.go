@@ -0,0 +1,151 @@
+package object
+
+import "fmt"
+
+// assertion makes an assertion builtin taking want arguments and then an
+// optional message. check returns why the assertion failed, or "" when it
+// holds. A failed assertion stops the program with an error naming the
+// assertion, which `bhasa test` reports as the reason a test failed.
+func assertion(name string, want int, check func(rt Runtime, args []Object) string) *Builtin {
+	return &Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+		if len(args) != want && len(args) != want+1 {
+			return &Error{Message: fmt.Sprintf("wrong number of arguments to '%s'. got=%d, want=%d or %d", name, len(args), want, want+1), Abort: true}
+		}
+		reason := check(rt, args[:want])
+		if reason == "" {
+			return NULL
+		}
+		message := name + " ব্যর্থ: " + reason
+		if len(args) > want {
+			message = objectText(args[want]) + " — " + message
+		}
+		return &Error{Message: message, Abort: true}
+	}}
+}
+
+// shown writes a value for an assertion message, quoting strings so that
+// "১" and ১ or a trailing space can be told apart
+func shown(obj Object) string {
+	if str, ok := obj.(*String); ok {
+		return fmt.Sprintf("%q", str.Value)
+	}
+	return obj.Inspect()
+}
+
+// equalValues compares values by what they hold: numbers by value even of
+// different types, arrays and tuples element by element, hashes pair by
+// pair, other hash keys by their key and anything else by identity
+func equalValues(a, b Object) bool {
+	if x, ok := integerValue(a); ok {
+		if y, ok := integerValue(b); ok {
+			return x == y
+		}
+	}
+	if x, ok := floatValue(a); ok {
+		if y, ok := floatValue(b); ok {
+			return x == y
+		}
+	}
+
+	switch a := a.(type) {
+	case *Array:
+		b, ok := b.(*Array)
+		return ok && equalElements(a.Elements, b.Elements)
+	case *Tuple:
+		b, ok := b.(*Tuple)
+		return ok && equalElements(a.Elements, b.Elements)
+	case *Hash:
+		b, ok := b.(*Hash)
+		if !ok || len(a.Pairs) != len(b.Pairs) {
+			return false
+		}
+		for key, pair := range a.Pairs {
+			other, ok := b.Pairs[key]
+			if !ok || !equalValues(pair.Value, other.Value) {
+				return false
+			}
+		}
+		return true
+	case *Null:
+		_, ok := b.(*Null)
+		return ok
+	}
+
+	if x, ok := HashKeyOf(a); ok {
+		if y, ok := HashKeyOf(b); ok {
+			return x == y
+		}
+	}
+	return a == b
+}
+
+func equalElements(a, b []Object) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalValues(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// assertBuiltins check what tests expect. Each takes a message to show
+// before the reason as its last, optional argument.
+var assertBuiltins = []BuiltinDef{
+	{
+		"নিশ্চিত_সমান", // (actual, expected, [message])
+		assertion("নিশ্চিত_সমান", 2, func(rt Runtime, args []Object) string {
+			if equalValues(args[0], args[1]) {
+				return ""
+			}
+			return fmt.Sprintf("প্রত্যাশিত %s, পাওয়া গেছে %s", shown(args[1]), shown(args[0]))
+		}),
+	},
+	{
+		"নিশ্চিত_অসমান", // (actual, unexpected, [message])
+		assertion("নিশ্চিত_অসমান", 2, func(rt Runtime, args []Object) string {
+			if !equalValues(args[0], args[1]) {
+				return ""
+			}
+			return fmt.Sprintf("দুটি মানই %s", shown(args[0]))
+		}),
+	},
+	{
+		"নিশ্চিত_সত্য", // (value, [message])
+		assertion("নিশ্চিত_সত্য", 1, func(rt Runtime, args []Object) string {
+			if b, ok := args[0].(*Boolean); ok && b.Value {
+				return ""
+			}
+			return fmt.Sprintf("প্রত্যাশিত সত্য, পাওয়া গেছে %s", shown(args[0]))
+		}),
+	},
+	{
+		"নিশ্চিত_মিথ্যা", // (value, [message])
+		assertion("নিশ্চিত_মিথ্যা", 1, func(rt Runtime, args []Object) string {
+			if b, ok := args[0].(*Boolean); ok && !b.Value {
+				return ""
+			}
+			return fmt.Sprintf("প্রত্যাশিত মিথ্যা, পাওয়া গেছে %s", shown(args[0]))
+		}),
+	},
+	{
+		"নিশ্চিত_নাল", // (value, [message])
+		assertion("নিশ্চিত_নাল", 1, func(rt Runtime, args []Object) string {
+			if _, ok := args[0].(*Null); ok {
+				return ""
+			}
+			return fmt.Sprintf("প্রত্যাশিত নাল, পাওয়া গেছে %s", shown(args[0]))
+		}),
+	},
+	{
+		"নিশ্চিত_ত্রুটি", // calls fn, which must fail: (fn, [message])
+		assertion("নিশ্চিত_ত্রুটি", 1, func(rt Runtime, args []Object) string {
+			if _, ok := rt.CallFunction(args[0]).(*Error); ok {
+				return ""
+			}
+			return "ফাংশনটি কোনো ত্রুটি ছাড়াই শেষ হয়েছে"
+		}),
+	},
+}
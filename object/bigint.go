@@ -0,0 +1,148 @@
+package object
+
+import (
+	"bhasa/token"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// maxBigShift bounds << on big integers so a typo cannot exhaust memory
+const maxBigShift = 1 << 20
+
+// BigInteger is an arbitrary-precision integer. Integer arithmetic that
+// overflows 64 bits produces one, and অসীম_সংখ্যা creates one explicitly.
+// Operations with a BigInteger operand give BigInteger results.
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (b *BigInteger) Type() ObjectType { return BIG_INTEGER_OBJ }
+func (b *BigInteger) Inspect() string  { return b.Value.String() }
+
+// HashKey matches the key of an equal Integer, so 5 and অসীম_সংখ্যা(5)
+// find the same hash entry
+func (b *BigInteger) HashKey() HashKey {
+	if b.Value.IsInt64() {
+		return (NewInteger(b.Value.Int64())).HashKey()
+	}
+	h := fnv.New64a()
+	h.Write([]byte(b.Value.String()))
+	return HashKey{Type: b.Type(), Value: h.Sum64()}
+}
+
+// BigValue converts an integer object, big or not, to a big.Int
+func BigValue(obj Object) (*big.Int, bool) {
+	if b, ok := obj.(*BigInteger); ok {
+		return b.Value, true
+	}
+	if n, ok := integerValue(obj); ok {
+		return big.NewInt(n), true
+	}
+	return nil, false
+}
+
+// BigArithmetic applies one of + - * / % & | ^ << >> to two big integers.
+// Division and remainder truncate toward zero like the Integer operators.
+func BigArithmetic(operator string, a, b *big.Int) (Object, *Error) {
+	result := new(big.Int)
+	switch operator {
+	case "+":
+		result.Add(a, b)
+	case "-":
+		result.Sub(a, b)
+	case "*":
+		result.Mul(a, b)
+	case "/":
+		if b.Sign() == 0 {
+			return nil, newError("division by zero")
+		}
+		result.Quo(a, b)
+	case "%":
+		if b.Sign() == 0 {
+			return nil, newError("modulo by zero")
+		}
+		result.Rem(a, b)
+	case "&":
+		result.And(a, b)
+	case "|":
+		result.Or(a, b)
+	case "^":
+		result.Xor(a, b)
+	case "<<", ">>":
+		if b.Sign() < 0 {
+			return nil, newError("negative shift amount: %s", b)
+		}
+		if !b.IsInt64() || b.Int64() > maxBigShift {
+			return nil, newError("shift amount too large: %s", b)
+		}
+		if operator == "<<" {
+			result.Lsh(a, uint(b.Int64()))
+		} else {
+			result.Rsh(a, uint(b.Int64()))
+		}
+	default:
+		return nil, newError("unknown operator for BIG_INTEGER: %s", operator)
+	}
+	return &BigInteger{Value: result}, nil
+}
+
+// IntegerOverflows reports whether one of + - * / << on two Integers falls
+// outside int64, in which case it is computed with big integers instead
+func IntegerOverflows(operator string, a, b int64) bool {
+	switch operator {
+	case "+":
+		r := a + b
+		return (a > 0 && b > 0 && r < 0) || (a < 0 && b < 0 && r >= 0)
+	case "-":
+		r := a - b
+		return (a >= 0 && b < 0 && r < 0) || (a < 0 && b > 0 && r >= 0)
+	case "*":
+		if a == 0 || b == 0 {
+			return false
+		}
+		r := a * b
+		return r/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64)
+	case "/":
+		return a == math.MinInt64 && b == -1
+	case "<<":
+		return b >= 64 || (b > 0 && (a<<uint(b))>>uint(b) != a)
+	}
+	return false
+}
+
+// bigIntegerBuiltins create arbitrary-precision integers
+var bigIntegerBuiltins = []BuiltinDef{
+	{
+		"অসীম_সংখ্যা", // big integer from an integer or a string: (x, [radix])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			if str, ok := args[0].(*String); ok {
+				radix, err := radixArg("অসীম_সংখ্যা", args, 1)
+				if err != nil {
+					return err
+				}
+				if radix < 2 || radix > 36 {
+					return newError("radix must be between 2 and 36, got %d", radix)
+				}
+				text := token.ConvertBengaliNumber(strings.TrimSpace(str.Value))
+				value, ok := new(big.Int).SetString(strings.TrimPrefix(text, "+"), radix)
+				if !ok {
+					return newError("could not parse %q as an integer", str.Value)
+				}
+				return &BigInteger{Value: value}
+			}
+			if len(args) == 2 {
+				return newError("a radix can only be given with a STRING argument to 'অসীম_সংখ্যা'")
+			}
+			value, ok := BigValue(args[0])
+			if !ok {
+				return newError("argument to 'অসীম_সংখ্যা' must be INTEGER or STRING, got %s", args[0].Type())
+			}
+			return &BigInteger{Value: new(big.Int).Set(value)}
+		}},
+	},
+}
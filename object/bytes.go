@@ -0,0 +1,157 @@
+package object
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Bytes is a mutable buffer of raw bytes created by বাইট_তালিকা, for binary
+// files and protocols. Indexing yields each byte as an integer 0-255.
+type Bytes struct {
+	Value []byte
+}
+
+func (b *Bytes) Type() ObjectType { return BYTES_OBJ }
+func (b *Bytes) Inspect() string {
+	parts := make([]string, len(b.Value))
+	for i, c := range b.Value {
+		parts[i] = fmt.Sprintf("%d", c)
+	}
+	return "বাইট_তালিকা[" + strings.Join(parts, ", ") + "]"
+}
+
+// IndexBytes returns the byte at index as an integer
+func IndexBytes(b *Bytes, index int64) (Object, bool) {
+	i, ok := ResolveIndex(index, len(b.Value))
+	if !ok {
+		return nil, false
+	}
+	return NewInteger(int64(b.Value[i])), true
+}
+
+// byteValue converts obj to a byte, failing outside 0-255
+func byteValue(name string, obj Object) (byte, *Error) {
+	n, ok := integerValue(obj)
+	if !ok {
+		return 0, newError("bytes passed to '%s' must be INTEGER, got %s", name, obj.Type())
+	}
+	if n < 0 || n > 255 {
+		return 0, newError("byte value %d passed to '%s' is outside 0-255", n, name)
+	}
+	return byte(n), nil
+}
+
+// bytesArg returns args[i] as a byte array
+func bytesArg(name string, args []Object, i int) (*Bytes, *Error) {
+	b, ok := args[i].(*Bytes)
+	if !ok {
+		return nil, newError("argument %d to '%s' must be BYTES, got %s", i+1, name, args[i].Type())
+	}
+	return b, nil
+}
+
+// bytesBuiltins create byte arrays and move them to and from strings and
+// files
+var bytesBuiltins = []BuiltinDef{
+	{
+		"বাইট_তালিকা", // new byte array: (UTF-8 of a string | array of 0-255 | size)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			switch arg := args[0].(type) {
+			case *String:
+				return &Bytes{Value: []byte(arg.Value)}
+			case *Array:
+				value := make([]byte, len(arg.Elements))
+				for i, el := range arg.Elements {
+					c, err := byteValue("বাইট_তালিকা", el)
+					if err != nil {
+						return err
+					}
+					value[i] = c
+				}
+				return &Bytes{Value: value}
+			case *Bytes:
+				return &Bytes{Value: append([]byte(nil), arg.Value...)}
+			}
+			if size, ok := integerValue(args[0]); ok && size >= 0 {
+				return &Bytes{Value: make([]byte, size)}
+			}
+			return newError("argument to 'বাইট_তালিকা' must be STRING, ARRAY or a size, got %s", args[0].Type())
+		}},
+	},
+	{
+		"বাইট_লেখা", // decode a byte array as UTF-8 text
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			b, err := bytesArg("বাইট_লেখা", args, 0)
+			if err != nil {
+				return err
+			}
+			return &String{Value: strings.ToValidUTF8(string(b.Value), "�")}
+		}},
+	},
+	{
+		"বাইট_বসাও", // set one byte in place: (bytes, index, value)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=3", len(args))
+			}
+			b, err := bytesArg("বাইট_বসাও", args, 0)
+			if err != nil {
+				return err
+			}
+			pos, err := arrayPosition("বাইট_বসাও", args, 1, len(b.Value), false)
+			if err != nil {
+				return err
+			}
+			c, err := byteValue("বাইট_বসাও", args[2])
+			if err != nil {
+				return err
+			}
+			b.Value[pos] = c
+			return b
+		}},
+	},
+	{
+		"ফাইল_বাইট_পড়ো", // read a whole file as bytes
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			path, err := stringArg("ফাইল_বাইট_পড়ো", args, 0)
+			if err != nil {
+				return err
+			}
+			data, readErr := os.ReadFile(path)
+			if readErr != nil {
+				return newError("error reading file: %s", readErr)
+			}
+			return &Bytes{Value: data}
+		}},
+	},
+	{
+		"ফাইল_বাইট_লেখো", // write bytes to a file, replacing it: (path, bytes)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			path, err := stringArg("ফাইল_বাইট_লেখো", args, 0)
+			if err != nil {
+				return err
+			}
+			b, err := bytesArg("ফাইল_বাইট_লেখো", args, 1)
+			if err != nil {
+				return err
+			}
+			if writeErr := os.WriteFile(path, b.Value, 0644); writeErr != nil {
+				return newError("error writing file: %s", writeErr)
+			}
+			return NULL
+		}},
+	},
+}
@@ -0,0 +1,220 @@
+package object
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Channel passes values between tasks, created by প্রণালী. Sends block
+// until a receiver takes the value, or while the buffer is full when the
+// channel has one; receives block until a value arrives. A closed channel
+// still gives the values sent before it was closed, then নাল.
+type Channel struct {
+	ch chan Object
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func (c *Channel) Type() ObjectType { return CHANNEL_OBJ }
+func (c *Channel) Inspect() string {
+	state := ""
+	if c.isClosed() {
+		state = ", বন্ধ"
+	}
+	return fmt.Sprintf("প্রণালী(%d/%d%s)", len(c.ch), cap(c.ch), state)
+}
+
+func (c *Channel) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// Send blocks until value is taken or buffered, failing if the channel is
+// or becomes closed
+func (c *Channel) Send(value Object) (err *Error) {
+	// Closing wakes a blocked send with a panic, which becomes the error
+	defer func() {
+		if recover() != nil {
+			err = newError("send on closed channel")
+		}
+	}()
+	c.ch <- value
+	return nil
+}
+
+// Receive blocks until a value arrives; ok is false once the channel is
+// closed and empty
+func (c *Channel) Receive() (value Object, ok bool) {
+	value, ok = <-c.ch
+	if !ok {
+		return NULL, false
+	}
+	return value, true
+}
+
+// Close stops further sends; receivers get what is left, then নাল
+func (c *Channel) Close() *Error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return newError("channel is already closed")
+	}
+	c.closed = true
+	close(c.ch)
+	return nil
+}
+
+// channelArg checks that args[i] is a channel
+func channelArg(name string, args []Object, i int) (*Channel, *Error) {
+	c, ok := args[i].(*Channel)
+	if !ok {
+		return nil, newError("argument to '%s' must be CHANNEL, got %s", name, args[i].Type())
+	}
+	return c, nil
+}
+
+// selectCases converts the cases of নির্বাচন: a channel to receive from,
+// or a [channel, value] pair to send on
+func selectCases(arr *Array) ([]reflect.SelectCase, *Error) {
+	cases := make([]reflect.SelectCase, len(arr.Elements))
+	for i, el := range arr.Elements {
+		switch el := el.(type) {
+		case *Channel:
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(el.ch)}
+		case *Array:
+			if len(el.Elements) != 2 {
+				return nil, newError("send case %d of 'নির্বাচন' must be [channel, value], got %d elements", i, len(el.Elements))
+			}
+			c, ok := el.Elements[0].(*Channel)
+			if !ok {
+				return nil, newError("send case %d of 'নির্বাচন' must start with a CHANNEL, got %s", i, el.Elements[0].Type())
+			}
+			if c.isClosed() {
+				return nil, newError("send on closed channel")
+			}
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(c.ch), Send: reflect.ValueOf(&el.Elements[1]).Elem()}
+		default:
+			return nil, newError("cases of 'নির্বাচন' must be CHANNEL or [channel, value], got %s", el.Type())
+		}
+	}
+	return cases, nil
+}
+
+// channelBuiltins make and use channels. নির্বাচন waits on several
+// channels at once, like Go's select, and gives [index, value] for the
+// case that went ahead: value is নাল for sends and closed channels, and
+// index is -1 when the timeout passed first (a timeout of 0 does not wait).
+var channelBuiltins = []BuiltinDef{
+	{
+		"প্রণালী", // new channel: ([buffer size])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+			}
+			size := int64(0)
+			if len(args) == 1 {
+				n, ok := integerValue(args[0])
+				if !ok || n < 0 {
+					return newError("buffer size of 'প্রণালী' must be a non-negative INTEGER, got %s", args[0].Inspect())
+				}
+				size = n
+			}
+			return &Channel{ch: make(chan Object, size)}
+		}},
+	},
+	{
+		"প্রণালী_পাঠাও", // send, waiting for room: (channel, value)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			c, err := channelArg("প্রণালী_পাঠাও", args, 0)
+			if err != nil {
+				return err
+			}
+			if err := c.Send(args[1]); err != nil {
+				return err
+			}
+			return NULL
+		}},
+	},
+	{
+		"প্রণালী_গ্রহণ", // receive, waiting for a value; নাল once closed and empty
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			c, err := channelArg("প্রণালী_গ্রহণ", args, 0)
+			if err != nil {
+				return err
+			}
+			value, _ := c.Receive()
+			return value
+		}},
+	},
+	{
+		"প্রণালী_বন্ধ", // close: (channel)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			c, err := channelArg("প্রণালী_বন্ধ", args, 0)
+			if err != nil {
+				return err
+			}
+			if err := c.Close(); err != nil {
+				return err
+			}
+			return NULL
+		}},
+	},
+	{
+		"নির্বাচন", // wait for the first ready case: (cases, [timeout ms])
+		&Builtin{Fn: func(args ...Object) (result Object) {
+			if len(args) < 1 || len(args) > 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			arr, ok := args[0].(*Array)
+			if !ok {
+				return newError("first argument to 'নির্বাচন' must be ARRAY, got %s", args[0].Type())
+			}
+			cases, err := selectCases(arr)
+			if err != nil {
+				return err
+			}
+			if len(args) == 2 {
+				ms, ok := integerValue(args[1])
+				if !ok || ms < 0 {
+					return newError("timeout of 'নির্বাচন' must be a non-negative INTEGER, got %s", args[1].Inspect())
+				}
+				if ms == 0 {
+					cases = append(cases, reflect.SelectCase{Dir: reflect.SelectDefault})
+				} else {
+					timer := time.NewTimer(time.Duration(ms) * time.Millisecond)
+					defer timer.Stop()
+					cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timer.C)})
+				}
+			}
+
+			// A channel closed while a send case waits panics like Send
+			defer func() {
+				if recover() != nil {
+					result = newError("send on closed channel")
+				}
+			}()
+			chosen, value, ok := reflect.Select(cases)
+			if chosen == len(arr.Elements) {
+				return &Array{Elements: []Object{NewInteger(-1), NULL}}
+			}
+			received := Object(NULL)
+			if ok {
+				received = value.Interface().(Object)
+			}
+			return &Array{Elements: []Object{NewInteger(int64(chosen)), received}}
+		}},
+	},
+}
@@ -0,0 +1,205 @@
+package object
+
+import (
+	"bhasa/token"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalPlaces is how many places are shown for a decimal, such as 1/3,
+// whose expansion never ends
+const maxDecimalPlaces = 20
+
+// Decimal is an exact decimal number for money and other values that
+// binary floating point cannot hold exactly. It is a big.Rat, so sums,
+// products and quotients are never rounded unless নির্ভুল_গোল is asked to.
+type Decimal struct {
+	Value *big.Rat
+}
+
+func (d *Decimal) Type() ObjectType { return DECIMAL_OBJ }
+func (d *Decimal) Inspect() string  { return FormatDecimal(d.Value) }
+
+// FormatDecimal writes r in decimal notation: exactly when the expansion
+// ends, otherwise rounded to maxDecimalPlaces places
+func FormatDecimal(r *big.Rat) string {
+	places, exact := decimalPlaces(r.Denom())
+	if !exact {
+		text := r.FloatString(maxDecimalPlaces)
+		return strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	return r.FloatString(places)
+}
+
+// decimalPlaces returns how many places the decimal expansion of a
+// fraction with this denominator has, and false when it does not end
+func decimalPlaces(denom *big.Int) (int, bool) {
+	d := new(big.Int).Set(denom)
+	two, five := big.NewInt(2), big.NewInt(5)
+	rem := new(big.Int)
+	twos, fives := 0, 0
+	for {
+		if q, r := new(big.Int).QuoRem(d, two, rem); r.Sign() == 0 {
+			d, twos = q, twos+1
+			continue
+		}
+		if q, r := new(big.Int).QuoRem(d, five, rem); r.Sign() == 0 {
+			d, fives = q, fives+1
+			continue
+		}
+		break
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+	if twos > fives {
+		return twos, true
+	}
+	return fives, true
+}
+
+// ParseDecimal parses decimal text such as "19.99", "-১২.৫" or "1e-3"
+func ParseDecimal(text string) (*big.Rat, bool) {
+	s := token.NormalizeFloat(strings.TrimSpace(text))
+	s = strings.TrimPrefix(s, "+")
+	if s == "" || strings.Contains(s, "/") {
+		return nil, false
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// DecimalValue converts a decimal or any integer to a big.Rat. Floats are
+// not converted implicitly, since their binary value is rarely the decimal
+// that was meant.
+func DecimalValue(obj Object) (*big.Rat, bool) {
+	if d, ok := obj.(*Decimal); ok {
+		return d.Value, true
+	}
+	if n, ok := BigValue(obj); ok {
+		return new(big.Rat).SetInt(n), true
+	}
+	return nil, false
+}
+
+// DecimalArithmetic applies + - * or / to two decimals
+func DecimalArithmetic(operator string, a, b *big.Rat) (Object, *Error) {
+	result := new(big.Rat)
+	switch operator {
+	case "+":
+		result.Add(a, b)
+	case "-":
+		result.Sub(a, b)
+	case "*":
+		result.Mul(a, b)
+	case "/":
+		if b.Sign() == 0 {
+			return nil, newError("division by zero")
+		}
+		result.Quo(a, b)
+	default:
+		return nil, newError("unknown operator for DECIMAL: %s", operator)
+	}
+	return &Decimal{Value: result}, nil
+}
+
+// RoundDecimal rounds r to the given number of places, halves away from
+// zero
+func RoundDecimal(r *big.Rat, places int) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(scale))
+
+	// Add or subtract one half, then truncate toward zero
+	half := big.NewRat(1, 2)
+	if scaled.Sign() < 0 {
+		half.Neg(half)
+	}
+	scaled.Add(scaled, half)
+	truncated := new(big.Int).Quo(scaled.Num(), scaled.Denom())
+	return new(big.Rat).SetFrac(truncated, scale)
+}
+
+// toDecimal converts a builtin argument to a decimal: decimals, integers,
+// decimal strings and, by their shortest decimal form, floats
+func toDecimal(name string, obj Object) (*big.Rat, *Error) {
+	if r, ok := DecimalValue(obj); ok {
+		return r, nil
+	}
+	switch obj := obj.(type) {
+	case *String:
+		if r, ok := ParseDecimal(obj.Value); ok {
+			return r, nil
+		}
+		return nil, newError("could not parse %q as a decimal", obj.Value)
+	case *Float:
+		if r, ok := new(big.Rat).SetString(strconv.FormatFloat(float64(obj.Value), 'g', -1, 32)); ok {
+			return r, nil
+		}
+	case *Double:
+		if r, ok := new(big.Rat).SetString(strconv.FormatFloat(obj.Value, 'g', -1, 64)); ok {
+			return r, nil
+		}
+	}
+	return nil, newError("argument to '%s' must be a number or a decimal string, got %s", name, obj.Inspect())
+}
+
+// decimalOperation builds the builtin applying operator to two decimals
+func decimalOperation(name, operator string) *Builtin {
+	return &Builtin{Fn: func(args ...Object) Object {
+		if len(args) != 2 {
+			return newError("wrong number of arguments. got=%d, want=2", len(args))
+		}
+		a, err := toDecimal(name, args[0])
+		if err != nil {
+			return err
+		}
+		b, err := toDecimal(name, args[1])
+		if err != nil {
+			return err
+		}
+		result, err := DecimalArithmetic(operator, a, b)
+		if err != nil {
+			return err
+		}
+		return result
+	}}
+}
+
+// decimalBuiltins create exact decimals and do arithmetic on them. The
+// operators + - * / and comparisons also work on decimals.
+var decimalBuiltins = []BuiltinDef{
+	{
+		"নির্ভুল", // exact decimal from a number or a string such as "19.99"
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			r, err := toDecimal("নির্ভুল", args[0])
+			if err != nil {
+				return err
+			}
+			return &Decimal{Value: new(big.Rat).Set(r)}
+		}},
+	},
+	{"নির্ভুল_যোগ", decimalOperation("নির্ভুল_যোগ", "+")},
+	{"নির্ভুল_বিয়োগ", decimalOperation("নির্ভুল_বিয়োগ", "-")},
+	{"নির্ভুল_গুণ", decimalOperation("নির্ভুল_গুণ", "*")},
+	{"নির্ভুল_ভাগ", decimalOperation("নির্ভুল_ভাগ", "/")},
+	{
+		"নির্ভুল_গোল", // round to a number of places, halves away from zero: (x, places)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			r, err := toDecimal("নির্ভুল_গোল", args[0])
+			if err != nil {
+				return err
+			}
+			places, ok := integerValue(args[1])
+			if !ok || places < 0 || places > 1000 {
+				return newError("places argument to 'নির্ভুল_গোল' must be an INTEGER from 0 to 1000, got %s", args[1].Inspect())
+			}
+			return &Decimal{Value: RoundDecimal(r, int(places))}
+		}},
+	},
+}
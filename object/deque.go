@@ -0,0 +1,181 @@
+package object
+
+import "strings"
+
+// Deque is a double-ended queue created by ডেক. It is a ring buffer, so
+// adding and removing at either end take constant time; used from one end
+// it is a stack, from both a queue.
+type Deque struct {
+	buf   []Object
+	head  int // index of the front element in buf
+	count int
+}
+
+func (d *Deque) Type() ObjectType { return DEQUE_OBJ }
+func (d *Deque) Inspect() string {
+	parts := make([]string, d.count)
+	for i := range parts {
+		parts[i] = d.At(i).Inspect()
+	}
+	return "ডেক[" + strings.Join(parts, ", ") + "]"
+}
+
+// Len returns the number of elements
+func (d *Deque) Len() int { return d.count }
+
+// At returns the element i places from the front
+func (d *Deque) At(i int) Object {
+	return d.buf[(d.head+i)%len(d.buf)]
+}
+
+// grow doubles the buffer when it is full
+func (d *Deque) grow() {
+	if d.count < len(d.buf) {
+		return
+	}
+	size := 2 * len(d.buf)
+	if size == 0 {
+		size = 8
+	}
+	buf := make([]Object, size)
+	for i := 0; i < d.count; i++ {
+		buf[i] = d.At(i)
+	}
+	d.buf, d.head = buf, 0
+}
+
+// PushFront adds value before the first element
+func (d *Deque) PushFront(value Object) {
+	d.grow()
+	d.head = (d.head - 1 + len(d.buf)) % len(d.buf)
+	d.buf[d.head] = value
+	d.count++
+}
+
+// PushBack adds value after the last element
+func (d *Deque) PushBack(value Object) {
+	d.grow()
+	d.buf[(d.head+d.count)%len(d.buf)] = value
+	d.count++
+}
+
+// PopFront removes and returns the first element, or nil when empty
+func (d *Deque) PopFront() Object {
+	if d.count == 0 {
+		return nil
+	}
+	value := d.buf[d.head]
+	d.buf[d.head] = nil
+	d.head = (d.head + 1) % len(d.buf)
+	d.count--
+	return value
+}
+
+// PopBack removes and returns the last element, or nil when empty
+func (d *Deque) PopBack() Object {
+	if d.count == 0 {
+		return nil
+	}
+	i := (d.head + d.count - 1) % len(d.buf)
+	value := d.buf[i]
+	d.buf[i] = nil
+	d.count--
+	return value
+}
+
+// dequeArg returns args[0] as a deque
+func dequeArg(name string, args []Object) (*Deque, *Error) {
+	d, ok := args[0].(*Deque)
+	if !ok {
+		return nil, newError("first argument to '%s' must be DEQUE, got %s", name, args[0].Type())
+	}
+	return d, nil
+}
+
+// dequePush builds the builtins adding to one end of a deque
+func dequePush(name string, front bool) *Builtin {
+	return &Builtin{Fn: func(args ...Object) Object {
+		if len(args) != 2 {
+			return newError("wrong number of arguments. got=%d, want=2", len(args))
+		}
+		d, err := dequeArg(name, args)
+		if err != nil {
+			return err
+		}
+		if front {
+			d.PushFront(args[1])
+		} else {
+			d.PushBack(args[1])
+		}
+		return d
+	}}
+}
+
+// dequeTake builds the builtins that return, and with remove also take
+// away, the element at one end of a deque; an empty deque gives null
+func dequeTake(name string, front, remove bool) *Builtin {
+	return &Builtin{Fn: func(args ...Object) Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1", len(args))
+		}
+		d, err := dequeArg(name, args)
+		if err != nil {
+			return err
+		}
+		if d.count == 0 {
+			return NULL
+		}
+		switch {
+		case front && remove:
+			return d.PopFront()
+		case remove:
+			return d.PopBack()
+		case front:
+			return d.At(0)
+		default:
+			return d.At(d.count - 1)
+		}
+	}}
+}
+
+// dequeBuiltins create and use deques, which are changed in place
+var dequeBuiltins = []BuiltinDef{
+	{
+		"ডেক", // new deque: ([initial array])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+			}
+			d := &Deque{}
+			if len(args) == 1 {
+				arr, err := arrayArg("ডেক", args, 0)
+				if err != nil {
+					return err
+				}
+				for _, el := range arr.Elements {
+					d.PushBack(el)
+				}
+			}
+			return d
+		}},
+	},
+	{"ডেক_সামনে_যোগ", dequePush("ডেক_সামনে_যোগ", true)},          // pushFront
+	{"ডেক_পিছনে_যোগ", dequePush("ডেক_পিছনে_যোগ", false)},         // pushBack
+	{"ডেক_সামনে_তোলো", dequeTake("ডেক_সামনে_তোলো", true, true)},  // popFront
+	{"ডেক_পিছনে_তোলো", dequeTake("ডেক_পিছনে_তোলো", false, true)}, // popBack
+	{"ডেক_সামনে", dequeTake("ডেক_সামনে", true, false)},           // peek front
+	{"ডেক_পিছনে", dequeTake("ডেক_পিছনে", false, false)},          // peek back
+	{
+		"ডেক_আকার", // number of elements
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			d, err := dequeArg("ডেক_আকার", args)
+			if err != nil {
+				return err
+			}
+			return NewInteger(int64(d.count))
+		}},
+	},
+}
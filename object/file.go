@@ -0,0 +1,186 @@
+package object
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// File is an open file handle returned by ফাইল_খোলো. Reads and writes are
+// buffered, so large files can be processed a line at a time.
+type File struct {
+	Path   string
+	Mode   string // "r", "w" or "a"
+	file   *os.File
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+func (f *File) Type() ObjectType { return FILE_OBJ }
+func (f *File) Inspect() string {
+	state := ""
+	if f.file == nil {
+		state = ", বন্ধ"
+	}
+	return fmt.Sprintf("ফাইল(%s, %s%s)", f.Path, f.Mode, state)
+}
+
+// Close flushes pending writes and closes the file
+func (f *File) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	var err error
+	if f.writer != nil {
+		err = f.writer.Flush()
+	}
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	f.file, f.reader, f.writer = nil, nil, nil
+	return err
+}
+
+// openFile opens path in one of the modes accepted by ফাইল_খোলো
+func openFile(path, mode string) (*File, error) {
+	var flags int
+	switch mode {
+	case "r":
+		flags = os.O_RDONLY
+	case "w":
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	case "a":
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	default:
+		return nil, fmt.Errorf("unknown mode %q (use \"r\", \"w\" or \"a\")", mode)
+	}
+
+	handle, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{Path: path, Mode: mode, file: handle}
+	if mode == "r" {
+		f.reader = bufio.NewReader(handle)
+	} else {
+		f.writer = bufio.NewWriter(handle)
+	}
+	return f, nil
+}
+
+// fileArg returns args[0] as an open file
+func fileArg(name string, args []Object) (*File, *Error) {
+	f, ok := args[0].(*File)
+	if !ok {
+		return nil, newError("first argument to '%s' must be FILE, got %s", name, args[0].Type())
+	}
+	if f.file == nil {
+		return nil, newError("'%s': file %s is closed", name, f.Path)
+	}
+	return f, nil
+}
+
+// fileBuiltins open files and stream them instead of loading them whole
+var fileBuiltins = []BuiltinDef{
+	{
+		"ফাইল_খোলো", // open a file: (path, [mode]) with mode "r", "w" or "a"
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			path, err := stringArg("ফাইল_খোলো", args, 0)
+			if err != nil {
+				return err
+			}
+			mode := "r"
+			if len(args) == 2 {
+				if mode, err = stringArg("ফাইল_খোলো", args, 1); err != nil {
+					return err
+				}
+			}
+			f, openErr := openFile(path, mode)
+			if openErr != nil {
+				return newError("error opening file: %s", openErr)
+			}
+			return f
+		}},
+	},
+	{
+		"ফাইল_লাইন_পড়ো", // read the next line without its newline; null at end of file
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			f, err := fileArg("ফাইল_লাইন_পড়ো", args)
+			if err != nil {
+				return err
+			}
+			if f.reader == nil {
+				return newError("'ফাইল_লাইন_পড়ো': file %s is not open for reading", f.Path)
+			}
+			line, readErr := f.reader.ReadString('\n')
+			if readErr == io.EOF && line == "" {
+				return NULL
+			}
+			if readErr != nil && readErr != io.EOF {
+				return newError("error reading file: %s", readErr)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			return &String{Value: strings.TrimSuffix(line, "\r")}
+		}},
+	},
+	{
+		"ফাইল_শেষ", // whether a file open for reading has no more input
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			f, err := fileArg("ফাইল_শেষ", args)
+			if err != nil {
+				return err
+			}
+			if f.reader == nil {
+				return newError("'ফাইল_শেষ': file %s is not open for reading", f.Path)
+			}
+			_, peekErr := f.reader.Peek(1)
+			return NativeBool(peekErr != nil)
+		}},
+	},
+	{
+		"ফাইলে_লেখো", // write a value to an open file
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			f, err := fileArg("ফাইলে_লেখো", args)
+			if err != nil {
+				return err
+			}
+			if f.writer == nil {
+				return newError("'ফাইলে_লেখো': file %s is not open for writing", f.Path)
+			}
+			if _, writeErr := f.writer.WriteString(objectText(args[1])); writeErr != nil {
+				return newError("error writing file: %s", writeErr)
+			}
+			return NULL
+		}},
+	},
+	{
+		"ফাইল_বন্ধ", // flush and close a file; closing twice is allowed
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			f, ok := args[0].(*File)
+			if !ok {
+				return newError("argument to 'ফাইল_বন্ধ' must be FILE, got %s", args[0].Type())
+			}
+			if closeErr := f.Close(); closeErr != nil {
+				return newError("error closing file: %s", closeErr)
+			}
+			return NULL
+		}},
+	},
+}
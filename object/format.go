@@ -0,0 +1,199 @@
+package object
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// floatVerb matches a single printf-style float verb such as "%.2f",
+// "%10.3e" or "%-8g"
+var floatVerb = regexp.MustCompile(`^%[-+ 0#]*[0-9]*(\.[0-9]+)?[feEgG]$`)
+
+// formatFloat formats x with a number of decimal places or a float verb
+func formatFloat(x float64, spec Object) Object {
+	switch spec := spec.(type) {
+	case *String:
+		if !floatVerb.MatchString(spec.Value) {
+			return newError("invalid number format %q (use a verb such as \"%%.2f\", \"%%e\" or \"%%g\")", spec.Value)
+		}
+		return &String{Value: fmt.Sprintf(spec.Value, x)}
+	default:
+		digits, ok := integerValue(spec)
+		if !ok {
+			return newError("second argument to 'সংখ্যা_ফরম্যাট' must be INTEGER or STRING, got %s", spec.Type())
+		}
+		if digits < 0 || digits > 100 {
+			return newError("number of decimal places must be between 0 and 100, got %d", digits)
+		}
+		return &String{Value: strconv.FormatFloat(x, 'f', int(digits), 64)}
+	}
+}
+
+// decimalSpec splits the flags, width and precision of a %f directive
+var decimalSpec = regexp.MustCompile(`^%([-+ 0#]*)([0-9]*)(?:\.([0-9]*))?$`)
+
+// formatDecimal formats an exact decimal for a %f directive without going
+// through float64, rounding halves away from zero
+func formatDecimal(spec string, r *big.Rat) string {
+	m := decimalSpec.FindStringSubmatch(spec)
+	flags := m[1]
+	width, _ := strconv.Atoi(m[2])
+	places := 6
+	if strings.Contains(spec, ".") {
+		places, _ = strconv.Atoi(m[3])
+	}
+
+	digits := RoundDecimal(r, places).FloatString(places)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	} else if strings.Contains(flags, "+") {
+		sign = "+"
+	} else if strings.Contains(flags, " ") {
+		sign = " "
+	}
+
+	pad := width - len(sign) - len(digits)
+	switch {
+	case pad <= 0:
+		return sign + digits
+	case strings.Contains(flags, "-"):
+		return sign + digits + strings.Repeat(" ", pad)
+	case strings.Contains(flags, "0"):
+		return sign + strings.Repeat("0", pad) + digits
+	}
+	return strings.Repeat(" ", pad) + sign + digits
+}
+
+// formatString formats args according to a printf-style format. Each
+// directive is %[flags][width][.precision]verb where the verbs are
+//
+//	d, x, X, o, b   integers
+//	f, e, E, g, G   numbers as floating point
+//	s               text (strings without quotes)
+//	v               the value as লেখ prints it
+//	%               a literal percent sign
+//
+// The flags are Go's (-, +, space, 0, #) plus ব, which writes the digits
+// of the result in Bengali. Width and precision may use Bengali digits.
+// Every argument must be used exactly once. Values are shown with
+// DisplayText, so rt runs the লেখা_রূপ methods of class instances.
+func formatString(rt Runtime, format string, args []Object) (string, *Error) {
+	var out strings.Builder
+	runes := []rune(format)
+	next := 0
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '%' {
+			out.WriteRune(runes[i])
+			continue
+		}
+		i++
+		if i < len(runes) && runes[i] == '%' {
+			out.WriteRune('%')
+			continue
+		}
+
+		spec := "%"
+		bengali := false
+		for ; i < len(runes) && strings.ContainsRune("-+ 0#ব", runes[i]); i++ {
+			if runes[i] == 'ব' {
+				bengali = true
+			} else {
+				spec += string(runes[i])
+			}
+		}
+		for ; i < len(runes) && (isFormatDigit(runes[i]) || runes[i] == '.'); i++ {
+			spec += fromBengaliDigits(string(runes[i]))
+		}
+		if i >= len(runes) {
+			return "", newError("format %q ends in the middle of a directive", format)
+		}
+		verb := runes[i]
+		if next >= len(args) {
+			return "", newError("format %q needs more than %d arguments", format, len(args))
+		}
+		arg := args[next]
+		next++
+
+		var text string
+		switch verb {
+		case 'd', 'x', 'X', 'o', 'b':
+			n, ok := integerValue(arg)
+			if !ok {
+				return "", newError("%%%c needs an integer, got %s", verb, arg.Type())
+			}
+			text = fmt.Sprintf(spec+string(verb), n)
+		case 'f', 'e', 'E', 'g', 'G':
+			if d, ok := arg.(*Decimal); ok && verb == 'f' {
+				text = formatDecimal(spec, d.Value)
+				break
+			}
+			x, ok := floatValue(arg)
+			if !ok {
+				return "", newError("%%%c needs a number, got %s", verb, arg.Type())
+			}
+			text = fmt.Sprintf(spec+string(verb), x)
+		case 's', 'v':
+			shown, err := DisplayText(rt, arg)
+			if err != nil {
+				return "", err
+			}
+			text = fmt.Sprintf(spec+"s", shown)
+		default:
+			return "", newError("unknown format verb %%%c in %q", verb, format)
+		}
+		if bengali || (rt.BengaliNumerals() && verb != 's' && verb != 'v') {
+			text = toBengaliDigits(text)
+		}
+		out.WriteString(text)
+	}
+
+	if next != len(args) {
+		return "", newError("format %q uses %d of %d arguments", format, next, len(args))
+	}
+	return out.String(), nil
+}
+
+// isFormatDigit reports whether ch is an ASCII or Bengali digit, as used
+// in widths and precisions
+func isFormatDigit(ch rune) bool {
+	return (ch >= '0' && ch <= '9') || (ch >= '০' && ch <= '৯')
+}
+
+// formatBuiltins control how numbers are turned into text
+var formatBuiltins = []BuiltinDef{
+	{
+		"সংখ্যা_ফরম্যাট", // format a number: (x, decimal places or "%.2f"-style verb)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			x, ok := floatValue(args[0])
+			if !ok {
+				return newError("first argument to 'সংখ্যা_ফরম্যাট' must be a number, got %s", args[0].Type())
+			}
+			return formatFloat(x, args[1])
+		}},
+	},
+	{
+		"ফরম্যাট", // printf-style formatting: (format, args...)
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			if len(args) < 1 {
+				return newError("wrong number of arguments. got=%d, want at least 1", len(args))
+			}
+			format, ok := args[0].(*String)
+			if !ok {
+				return newError("first argument to 'ফরম্যাট' must be STRING, got %s", args[0].Type())
+			}
+			text, err := formatString(rt, format.Value, args[1:])
+			if err != nil {
+				return err
+			}
+			return &String{Value: text}
+		}},
+	},
+}
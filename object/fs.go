@@ -0,0 +1,118 @@
+package object
+
+import "os"
+
+// stringArg returns args[i] as a Go string, or an error naming the builtin
+func stringArg(name string, args []Object, i int) (string, *Error) {
+	str, ok := args[i].(*String)
+	if !ok {
+		return "", newError("argument %d to '%s' must be STRING, got %s", i+1, name, args[i].Type())
+	}
+	return str.Value, nil
+}
+
+// fileInfoObject converts file information into
+// {"নাম", "আকার", "পরিবর্তন", "ফোল্ডার", "অনুমতি"}
+func fileInfoObject(info os.FileInfo) Object {
+	result := &Hash{Pairs: make(map[HashKey]HashPair)}
+	result.Set("নাম", &String{Value: info.Name()})
+	result.Set("আকার", NewInteger(info.Size()))
+	result.Set("পরিবর্তন", NewInteger(info.ModTime().Unix()))
+	result.Set("ফোল্ডার", NativeBool(info.IsDir()))
+	result.Set("অনুমতি", &String{Value: info.Mode().String()})
+	return result
+}
+
+// fsBuiltins inspect and change the file system beyond reading and
+// writing single files
+var fsBuiltins = []BuiltinDef{
+	{
+		"ফোল্ডার_তালিকা", // list a directory: sorted array of entry names
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			dir, err := stringArg("ফোল্ডার_তালিকা", args, 0)
+			if err != nil {
+				return err
+			}
+			entries, readErr := os.ReadDir(dir)
+			if readErr != nil {
+				return newError("error reading directory: %s", readErr)
+			}
+			names := make([]Object, len(entries))
+			for i, entry := range entries {
+				names[i] = &String{Value: entry.Name()}
+			}
+			return &Array{Elements: names}
+		}},
+	},
+	{
+		"ফোল্ডার_তৈরি", // create a directory and any missing parents
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			dir, err := stringArg("ফোল্ডার_তৈরি", args, 0)
+			if err != nil {
+				return err
+			}
+			if mkErr := os.MkdirAll(dir, 0755); mkErr != nil {
+				return newError("error creating directory: %s", mkErr)
+			}
+			return NULL
+		}},
+	},
+	{
+		"ফাইল_মুছো", // delete a file or empty directory: (path, [recursive])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			path, err := stringArg("ফাইল_মুছো", args, 0)
+			if err != nil {
+				return err
+			}
+			recursive := false
+			if len(args) == 2 {
+				flag, ok := args[1].(*Boolean)
+				if !ok {
+					return newError("second argument to 'ফাইল_মুছো' must be BOOLEAN, got %s", args[1].Type())
+				}
+				recursive = flag.Value
+			}
+
+			// RemoveAll succeeds on missing paths; report them like Remove
+			if _, statErr := os.Lstat(path); statErr != nil {
+				return newError("error deleting: %s", statErr)
+			}
+			var rmErr error
+			if recursive {
+				rmErr = os.RemoveAll(path)
+			} else {
+				rmErr = os.Remove(path)
+			}
+			if rmErr != nil {
+				return newError("error deleting: %s", rmErr)
+			}
+			return NULL
+		}},
+	},
+	{
+		"ফাইল_তথ্য", // stat a path
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			path, err := stringArg("ফাইল_তথ্য", args, 0)
+			if err != nil {
+				return err
+			}
+			info, statErr := os.Stat(path)
+			if statErr != nil {
+				return newError("error reading file information: %s", statErr)
+			}
+			return fileInfoObject(info)
+		}},
+	},
+}
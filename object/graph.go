@@ -0,0 +1,340 @@
+package object
+
+import (
+	"container/heap"
+	"math"
+	"strings"
+)
+
+// edge is one entry of a vertex's adjacency list
+type edge struct {
+	to     int
+	weight float64
+}
+
+// Graph is an adjacency-list graph created by গ্রাফ. Vertices are any
+// hashable values and are kept in the order they were added, so
+// traversals are deterministic. Graphs are changed in place.
+type Graph struct {
+	Directed bool
+	vertices []Object
+	index    map[HashKey]int
+	adj      [][]edge
+}
+
+func (g *Graph) Type() ObjectType { return GRAPH_OBJ }
+func (g *Graph) Inspect() string {
+	var out strings.Builder
+	out.WriteString("গ্রাফ{")
+	arrow := " - "
+	if g.Directed {
+		arrow = " -> "
+	}
+	for i, v := range g.vertices {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(v.Inspect())
+		out.WriteString(arrow)
+		out.WriteString("[")
+		for j, e := range g.adj[i] {
+			if j > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(g.vertices[e.to].Inspect())
+		}
+		out.WriteString("]")
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
+// vertex returns the number of v, adding it when add is true; it returns
+// -1 for a missing vertex
+func (g *Graph) vertex(v Object, add bool) (int, *Error) {
+	key, ok := HashKeyOf(v)
+	if !ok {
+		return 0, newError("unusable as graph vertex: %s", v.Type())
+	}
+	if i, ok := g.index[key]; ok {
+		return i, nil
+	}
+	if !add {
+		return -1, nil
+	}
+	g.index[key] = len(g.vertices)
+	g.vertices = append(g.vertices, v)
+	g.adj = append(g.adj, nil)
+	return len(g.vertices) - 1, nil
+}
+
+// AddEdge connects from to to, and to to from unless the graph is directed
+func (g *Graph) AddEdge(from, to Object, weight float64) *Error {
+	f, err := g.vertex(from, true)
+	if err != nil {
+		return err
+	}
+	t, err := g.vertex(to, true)
+	if err != nil {
+		return err
+	}
+	g.adj[f] = append(g.adj[f], edge{t, weight})
+	if !g.Directed && f != t {
+		g.adj[t] = append(g.adj[t], edge{f, weight})
+	}
+	return nil
+}
+
+// traverse visits the vertices reachable from start breadth first, or depth
+// first when depth is true, in the order their edges were added
+func (g *Graph) traverse(start int, depth bool) []Object {
+	visited := make([]bool, len(g.vertices))
+	var order []Object
+	pending := []int{start}
+	for len(pending) > 0 {
+		var v int
+		if depth {
+			v, pending = pending[len(pending)-1], pending[:len(pending)-1]
+		} else {
+			v, pending = pending[0], pending[1:]
+		}
+		if visited[v] {
+			continue
+		}
+		visited[v] = true
+		order = append(order, g.vertices[v])
+
+		edges := g.adj[v]
+		for i := range edges {
+			// A stack pops the last neighbour first, so push in reverse
+			e := edges[i]
+			if depth {
+				e = edges[len(edges)-1-i]
+			}
+			if !visited[e.to] {
+				pending = append(pending, e.to)
+			}
+		}
+	}
+	return order
+}
+
+// distanceQueue is the priority queue of Dijkstra's algorithm
+type distanceQueue []edge
+
+func (q distanceQueue) Len() int            { return len(q) }
+func (q distanceQueue) Less(i, j int) bool  { return q[i].weight < q[j].weight }
+func (q distanceQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *distanceQueue) Push(x interface{}) { *q = append(*q, x.(edge)) }
+func (q *distanceQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// ShortestPath finds the lightest path between two vertices with
+// Dijkstra's algorithm, returning its vertices and total weight; the path
+// is nil when to cannot be reached
+func (g *Graph) ShortestPath(from, to int) ([]Object, float64) {
+	dist := make([]float64, len(g.vertices))
+	prev := make([]int, len(g.vertices))
+	for i := range dist {
+		dist[i] = math.Inf(1)
+		prev[i] = -1
+	}
+	dist[from] = 0
+	queue := &distanceQueue{{from, 0}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(edge)
+		if item.weight > dist[item.to] {
+			continue
+		}
+		if item.to == to {
+			break
+		}
+		for _, e := range g.adj[item.to] {
+			if d := dist[item.to] + e.weight; d < dist[e.to] {
+				dist[e.to] = d
+				prev[e.to] = item.to
+				heap.Push(queue, edge{e.to, d})
+			}
+		}
+	}
+	if math.IsInf(dist[to], 1) {
+		return nil, 0
+	}
+
+	var path []Object
+	for v := to; v != -1; v = prev[v] {
+		path = append([]Object{g.vertices[v]}, path...)
+	}
+	return path, dist[to]
+}
+
+// graphArgs checks the argument count and returns args[0] as a graph
+func graphArgs(name string, args []Object, min, max int) (*Graph, *Error) {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			return nil, newError("wrong number of arguments. got=%d, want=%d", len(args), min)
+		}
+		return nil, newError("wrong number of arguments. got=%d, want=%d or %d", len(args), min, max)
+	}
+	g, ok := args[0].(*Graph)
+	if !ok {
+		return nil, newError("first argument to '%s' must be GRAPH, got %s", name, args[0].Type())
+	}
+	return g, nil
+}
+
+// existingVertex returns the number of a vertex that must be in the graph
+func existingVertex(name string, g *Graph, v Object) (int, *Error) {
+	i, err := g.vertex(v, false)
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 {
+		return 0, newError("'%s': %s is not a vertex of the graph", name, v.Inspect())
+	}
+	return i, nil
+}
+
+// graphBuiltins build graphs and run the standard algorithms on them
+var graphBuiltins = []BuiltinDef{
+	{
+		"গ্রাফ", // new graph: ([directed])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+			}
+			g := &Graph{index: make(map[HashKey]int)}
+			if len(args) == 1 {
+				directed, ok := args[0].(*Boolean)
+				if !ok {
+					return newError("argument to 'গ্রাফ' must be BOOLEAN, got %s", args[0].Type())
+				}
+				g.Directed = directed.Value
+			}
+			return g
+		}},
+	},
+	{
+		"গ্রাফ_শীর্ষ_যোগ", // add a vertex: (graph, vertex)
+		&Builtin{Fn: func(args ...Object) Object {
+			g, err := graphArgs("গ্রাফ_শীর্ষ_যোগ", args, 2, 2)
+			if err != nil {
+				return err
+			}
+			if _, err := g.vertex(args[1], true); err != nil {
+				return err
+			}
+			return g
+		}},
+	},
+	{
+		"গ্রাফ_প্রান্ত_যোগ", // add an edge: (graph, from, to, [weight])
+		&Builtin{Fn: func(args ...Object) Object {
+			g, err := graphArgs("গ্রাফ_প্রান্ত_যোগ", args, 3, 4)
+			if err != nil {
+				return err
+			}
+			weight := 1.0
+			if len(args) == 4 {
+				w, ok := floatValue(args[3])
+				if !ok || w < 0 || math.IsNaN(w) {
+					return newError("edge weight must be a non-negative number, got %s", args[3].Inspect())
+				}
+				weight = w
+			}
+			if err := g.AddEdge(args[1], args[2], weight); err != nil {
+				return err
+			}
+			return g
+		}},
+	},
+	{
+		"গ্রাফ_শীর্ষগুলো", // vertices in the order they were added
+		&Builtin{Fn: func(args ...Object) Object {
+			g, err := graphArgs("গ্রাফ_শীর্ষগুলো", args, 1, 1)
+			if err != nil {
+				return err
+			}
+			return &Array{Elements: append([]Object(nil), g.vertices...)}
+		}},
+	},
+	{
+		"গ্রাফ_প্রতিবেশী", // neighbours of a vertex: (graph, vertex)
+		&Builtin{Fn: func(args ...Object) Object {
+			g, err := graphArgs("গ্রাফ_প্রতিবেশী", args, 2, 2)
+			if err != nil {
+				return err
+			}
+			v, err := existingVertex("গ্রাফ_প্রতিবেশী", g, args[1])
+			if err != nil {
+				return err
+			}
+			neighbours := make([]Object, len(g.adj[v]))
+			for i, e := range g.adj[v] {
+				neighbours[i] = g.vertices[e.to]
+			}
+			return &Array{Elements: neighbours}
+		}},
+	},
+	{
+		"গ্রাফ_বিএফএস", // breadth-first order from a vertex: (graph, start)
+		&Builtin{Fn: func(args ...Object) Object {
+			g, err := graphArgs("গ্রাফ_বিএফএস", args, 2, 2)
+			if err != nil {
+				return err
+			}
+			start, err := existingVertex("গ্রাফ_বিএফএস", g, args[1])
+			if err != nil {
+				return err
+			}
+			return &Array{Elements: g.traverse(start, false)}
+		}},
+	},
+	{
+		"গ্রাফ_ডিএফএস", // depth-first order from a vertex: (graph, start)
+		&Builtin{Fn: func(args ...Object) Object {
+			g, err := graphArgs("গ্রাফ_ডিএফএস", args, 2, 2)
+			if err != nil {
+				return err
+			}
+			start, err := existingVertex("গ্রাফ_ডিএফএস", g, args[1])
+			if err != nil {
+				return err
+			}
+			return &Array{Elements: g.traverse(start, true)}
+		}},
+	},
+	{
+		"গ্রাফ_সংক্ষিপ্ত_পথ", // lightest path: (graph, from, to) -> {"পথ", "দূরত্ব"} or null
+		&Builtin{Fn: func(args ...Object) Object {
+			g, err := graphArgs("গ্রাফ_সংক্ষিপ্ত_পথ", args, 3, 3)
+			if err != nil {
+				return err
+			}
+			from, err := existingVertex("গ্রাফ_সংক্ষিপ্ত_পথ", g, args[1])
+			if err != nil {
+				return err
+			}
+			to, err := existingVertex("গ্রাফ_সংক্ষিপ্ত_পথ", g, args[2])
+			if err != nil {
+				return err
+			}
+			path, distance := g.ShortestPath(from, to)
+			if path == nil {
+				return NULL
+			}
+			result := &Hash{Pairs: make(map[HashKey]HashPair)}
+			result.Set("পথ", &Array{Elements: path})
+			if distance == math.Trunc(distance) {
+				result.Set("দূরত্ব", NewInteger(int64(distance)))
+			} else {
+				result.Set("দূরত্ব", &Double{Value: distance})
+			}
+			return result
+		}},
+	},
+}
@@ -0,0 +1,184 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Heap is a priority queue created by হিপ. The element that sorts first,
+// the smallest unless a comparator says otherwise, is always on top.
+type Heap struct {
+	Elements   []Object // binary heap order
+	Comparator Object   // nil for the default ascending order
+}
+
+func (h *Heap) Type() ObjectType { return HEAP_OBJ }
+func (h *Heap) Inspect() string {
+	parts := make([]string, len(h.Elements))
+	for i, el := range h.Elements {
+		parts[i] = el.Inspect()
+	}
+	return fmt.Sprintf("হিপ[%s]", strings.Join(parts, ", "))
+}
+
+// less reports whether element i belongs above element j
+func (h *Heap) less(rt Runtime, i, j int) (bool, *Error) {
+	c, err := compareValues(rt, h.Comparator, h.Elements[i], h.Elements[j])
+	return c < 0, err
+}
+
+// Push adds value to the heap
+func (h *Heap) Push(rt Runtime, value Object) *Error {
+	h.Elements = append(h.Elements, value)
+	for i := len(h.Elements) - 1; i > 0; {
+		parent := (i - 1) / 2
+		less, err := h.less(rt, i, parent)
+		if err != nil {
+			return err
+		}
+		if !less {
+			break
+		}
+		h.Elements[i], h.Elements[parent] = h.Elements[parent], h.Elements[i]
+		i = parent
+	}
+	return nil
+}
+
+// Pop removes and returns the top element, or nil when the heap is empty
+func (h *Heap) Pop(rt Runtime) (Object, *Error) {
+	n := len(h.Elements)
+	if n == 0 {
+		return nil, nil
+	}
+	top := h.Elements[0]
+	h.Elements[0] = h.Elements[n-1]
+	h.Elements[n-1] = nil
+	h.Elements = h.Elements[:n-1]
+	n--
+
+	for i := 0; ; {
+		smallest := i
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child >= n {
+				continue
+			}
+			less, err := h.less(rt, child, smallest)
+			if err != nil {
+				return nil, err
+			}
+			if less {
+				smallest = child
+			}
+		}
+		if smallest == i {
+			break
+		}
+		h.Elements[i], h.Elements[smallest] = h.Elements[smallest], h.Elements[i]
+		i = smallest
+	}
+	return top, nil
+}
+
+// heapArg returns args[0] as a heap
+func heapArg(name string, args []Object) (*Heap, *Error) {
+	h, ok := args[0].(*Heap)
+	if !ok {
+		return nil, newError("first argument to '%s' must be HEAP, got %s", name, args[0].Type())
+	}
+	return h, nil
+}
+
+// heapBuiltins create and use priority queues. Unlike arrays, heaps are
+// changed in place.
+var heapBuiltins = []BuiltinDef{
+	{
+		"হিপ", // new heap: ([comparator], [initial array])
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			if len(args) > 2 {
+				return newError("wrong number of arguments. got=%d, want=0 to 2", len(args))
+			}
+			h := &Heap{}
+			for _, arg := range args {
+				switch arg := arg.(type) {
+				case *Closure, *Builtin:
+					h.Comparator = arg
+				case *Array:
+					for _, el := range arg.Elements {
+						if err := h.Push(rt, el); err != nil {
+							return err
+						}
+					}
+				default:
+					return newError("arguments to 'হিপ' must be a FUNCTION or an ARRAY, got %s", arg.Type())
+				}
+			}
+			return h
+		}},
+	},
+	{
+		"হিপ_যোগ", // push: (heap, value)
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			h, err := heapArg("হিপ_যোগ", args)
+			if err != nil {
+				return err
+			}
+			if err := h.Push(rt, args[1]); err != nil {
+				return err
+			}
+			return h
+		}},
+	},
+	{
+		"হিপ_তোলো", // pop the top element, or null when empty
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			h, err := heapArg("হিপ_তোলো", args)
+			if err != nil {
+				return err
+			}
+			top, err := h.Pop(rt)
+			if err != nil {
+				return err
+			}
+			if top == nil {
+				return NULL
+			}
+			return top
+		}},
+	},
+	{
+		"হিপ_শীর্ষ", // peek at the top element, or null when empty
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			h, err := heapArg("হিপ_শীর্ষ", args)
+			if err != nil {
+				return err
+			}
+			if len(h.Elements) == 0 {
+				return NULL
+			}
+			return h.Elements[0]
+		}},
+	},
+	{
+		"হিপ_আকার", // number of elements
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			h, err := heapArg("হিপ_আকার", args)
+			if err != nil {
+				return err
+			}
+			return NewInteger(int64(len(h.Elements)))
+		}},
+	},
+}
@@ -0,0 +1,132 @@
+package object
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// httpClient is shared by the জাল builtins
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// applyHeaders copies a hash of header names to values onto a request
+func applyHeaders(req *http.Request, headers Object) *Error {
+	hash, ok := headers.(*Hash)
+	if !ok {
+		return newError("headers must be HASH, got %s", headers.Type())
+	}
+	for _, pair := range hash.Pairs {
+		req.Header.Set(objectText(pair.Key), objectText(pair.Value))
+	}
+	return nil
+}
+
+// objectText returns the raw value of strings and the inspected form of
+// everything else
+func objectText(obj Object) string {
+	if str, ok := obj.(*String); ok {
+		return str.Value
+	}
+	return obj.Inspect()
+}
+
+// doRequest performs req and converts the response into
+// {"স্ট্যাটাস", "হেডার", "বডি"}
+func doRequest(req *http.Request) Object {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return newError("HTTP request failed: %s", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return newError("error reading HTTP response: %s", err)
+	}
+
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	headers := &Hash{Pairs: make(map[HashKey]HashPair)}
+	for _, name := range names {
+		headers.Set(name, &String{Value: strings.Join(resp.Header[name], ", ")})
+	}
+
+	result := &Hash{Pairs: make(map[HashKey]HashPair)}
+	result.Set("স্ট্যাটাস", NewInteger(int64(resp.StatusCode)))
+	result.Set("হেডার", headers)
+	result.Set("বডি", &String{Value: string(body)})
+	return result
+}
+
+// httpClientBuiltins make HTTP requests
+var httpClientBuiltins = []BuiltinDef{
+	{
+		"জাল_পাও", // HTTP GET: (url, [headers])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			url, ok := args[0].(*String)
+			if !ok {
+				return newError("first argument to 'জাল_পাও' must be STRING, got %s", args[0].Type())
+			}
+			req, err := http.NewRequest(http.MethodGet, url.Value, nil)
+			if err != nil {
+				return newError("invalid request: %s", err)
+			}
+			if len(args) == 2 {
+				if errObj := applyHeaders(req, args[1]); errObj != nil {
+					return errObj
+				}
+			}
+			return doRequest(req)
+		}},
+	},
+	{
+		"জাল_পাঠাও", // HTTP POST: (url, body, [headers]); hashes and arrays are sent as JSON
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) < 2 || len(args) > 3 {
+				return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+			}
+			url, ok := args[0].(*String)
+			if !ok {
+				return newError("first argument to 'জাল_পাঠাও' must be STRING, got %s", args[0].Type())
+			}
+
+			var body []byte
+			contentType := "text/plain; charset=utf-8"
+			switch b := args[1].(type) {
+			case *String:
+				body = []byte(b.Value)
+			case *Hash, *Array:
+				data, err := json.Marshal(objectToJSON(b))
+				if err != nil {
+					return newError("error creating JSON: %s", err)
+				}
+				body = data
+				contentType = "application/json"
+			default:
+				body = []byte(objectText(b))
+			}
+
+			req, err := http.NewRequest(http.MethodPost, url.Value, bytes.NewReader(body))
+			if err != nil {
+				return newError("invalid request: %s", err)
+			}
+			req.Header.Set("Content-Type", contentType)
+			if len(args) == 3 {
+				if errObj := applyHeaders(req, args[2]); errObj != nil {
+					return errObj
+				}
+			}
+			return doRequest(req)
+		}},
+	},
+}
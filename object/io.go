@@ -0,0 +1,74 @@
+package object
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// readLine reads one line from the runtime's stdin without the line ending.
+// It returns null at end of input.
+func readLine(rt Runtime) Object {
+	line, err := rt.Stdin().ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return NULL
+		}
+		return newError("error reading input: %s", err)
+	}
+	return &String{Value: strings.TrimRight(line, "\r\n")}
+}
+
+// ioBuiltins read from standard input and the command line
+var ioBuiltins = []BuiltinDef{
+	{
+		"পড়ো", // read a line from standard input
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+			return readLine(rt)
+		}},
+	},
+	{
+		"সব_পড়ো", // read all of standard input
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+			data, err := io.ReadAll(rt.Stdin())
+			if err != nil {
+				return newError("error reading input: %s", err)
+			}
+			return &String{Value: string(data)}
+		}},
+	},
+	{
+		"জিজ্ঞাসা", // prompt - print a message, then read a line
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			prompt := args[0].Inspect()
+			if str, ok := args[0].(*String); ok {
+				prompt = str.Value
+			}
+			fmt.Fprint(rt.Stdout(), prompt)
+			return readLine(rt)
+		}},
+	},
+	{
+		"আর্গুমেন্ট", // command-line arguments given after the script name
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+			argv := rt.Args()
+			elements := make([]Object, len(argv))
+			for i, arg := range argv {
+				elements[i] = &String{Value: arg}
+			}
+			return &Array{Elements: elements}
+		}},
+	},
+}
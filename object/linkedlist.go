@@ -0,0 +1,183 @@
+package object
+
+import (
+	"container/list"
+	"strings"
+)
+
+// LinkedList is a doubly linked list created by লিংক_তালিকা. Its nodes are
+// ListNode objects, which act as iterators: from any node a program can
+// step to its neighbours, or insert and remove around it in constant time.
+type LinkedList struct {
+	list *list.List // element values are *ListNode
+}
+
+func (l *LinkedList) Type() ObjectType { return LINKED_LIST_OBJ }
+func (l *LinkedList) Inspect() string {
+	parts := make([]string, 0, l.list.Len())
+	for e := l.list.Front(); e != nil; e = e.Next() {
+		parts = append(parts, e.Value.(*ListNode).Value.Inspect())
+	}
+	return "লিংক[" + strings.Join(parts, " ⇄ ") + "]"
+}
+
+// ListNode is one element of a LinkedList. The same node object is
+// returned each time the element is reached, so nodes compare by identity.
+type ListNode struct {
+	Value   Object
+	element *list.Element // nil once the node is removed
+	owner   *LinkedList
+}
+
+func (n *ListNode) Type() ObjectType { return LIST_NODE_OBJ }
+func (n *ListNode) Inspect() string  { return "নোড(" + n.Value.Inspect() + ")" }
+
+// NewLinkedList returns a list holding elements in order
+func NewLinkedList(elements []Object) *LinkedList {
+	l := &LinkedList{list: list.New()}
+	for _, el := range elements {
+		l.PushBack(el)
+	}
+	return l
+}
+
+// PushFront adds value at the front and returns its node
+func (l *LinkedList) PushFront(value Object) *ListNode {
+	node := &ListNode{Value: value, owner: l}
+	node.element = l.list.PushFront(node)
+	return node
+}
+
+// PushBack adds value at the back and returns its node
+func (l *LinkedList) PushBack(value Object) *ListNode {
+	node := &ListNode{Value: value, owner: l}
+	node.element = l.list.PushBack(node)
+	return node
+}
+
+// nodeOf returns the node of e, or null at either end of the list
+func nodeOf(e *list.Element) Object {
+	if e == nil {
+		return NULL
+	}
+	return e.Value.(*ListNode)
+}
+
+func linkedListArg(name string, args []Object) (*LinkedList, *Error) {
+	l, ok := args[0].(*LinkedList)
+	if !ok {
+		return nil, newError("first argument to '%s' must be LINKED_LIST, got %s", name, args[0].Type())
+	}
+	return l, nil
+}
+
+// listNodeArg returns args[0] as a node that is still in its list
+func listNodeArg(name string, args []Object) (*ListNode, *Error) {
+	n, ok := args[0].(*ListNode)
+	if !ok {
+		return nil, newError("first argument to '%s' must be LIST_NODE, got %s", name, args[0].Type())
+	}
+	if n.element == nil {
+		return nil, newError("'%s': the node has been removed from its list", name)
+	}
+	return n, nil
+}
+
+// listBuiltin builds a builtin taking a list and want-1 more arguments
+func listBuiltin(name string, want int, fn func(l *LinkedList, args []Object) Object) *Builtin {
+	return &Builtin{Fn: func(args ...Object) Object {
+		if len(args) != want {
+			return newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+		}
+		l, err := linkedListArg(name, args)
+		if err != nil {
+			return err
+		}
+		return fn(l, args)
+	}}
+}
+
+// nodeBuiltin builds a builtin taking a node and want-1 more arguments
+func nodeBuiltin(name string, want int, fn func(n *ListNode, args []Object) Object) *Builtin {
+	return &Builtin{Fn: func(args ...Object) Object {
+		if len(args) != want {
+			return newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+		}
+		n, err := listNodeArg(name, args)
+		if err != nil {
+			return err
+		}
+		return fn(n, args)
+	}}
+}
+
+// linkedListBuiltins create linked lists and walk and change them through
+// their nodes
+var linkedListBuiltins = []BuiltinDef{
+	{
+		"লিংক_তালিকা", // new list: ([array of elements])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+			}
+			if len(args) == 0 {
+				return NewLinkedList(nil)
+			}
+			arr, err := arrayArg("লিংক_তালিকা", args, 0)
+			if err != nil {
+				return err
+			}
+			return NewLinkedList(arr.Elements)
+		}},
+	},
+	{"লিংক_অ্যারে", listBuiltin("লিংক_অ্যারে", 1, func(l *LinkedList, args []Object) Object {
+		elements := make([]Object, 0, l.list.Len())
+		for e := l.list.Front(); e != nil; e = e.Next() {
+			elements = append(elements, e.Value.(*ListNode).Value)
+		}
+		return &Array{Elements: elements}
+	})},
+	{"লিংক_আকার", listBuiltin("লিংক_আকার", 1, func(l *LinkedList, args []Object) Object {
+		return NewInteger(int64(l.list.Len()))
+	})},
+	{"লিংক_সামনে_যোগ", listBuiltin("লিংক_সামনে_যোগ", 2, func(l *LinkedList, args []Object) Object {
+		return l.PushFront(args[1])
+	})},
+	{"লিংক_পিছনে_যোগ", listBuiltin("লিংক_পিছনে_যোগ", 2, func(l *LinkedList, args []Object) Object {
+		return l.PushBack(args[1])
+	})},
+	{"লিংক_প্রথম", listBuiltin("লিংক_প্রথম", 1, func(l *LinkedList, args []Object) Object {
+		return nodeOf(l.list.Front())
+	})},
+	{"লিংক_শেষ", listBuiltin("লিংক_শেষ", 1, func(l *LinkedList, args []Object) Object {
+		return nodeOf(l.list.Back())
+	})},
+	{"লিংক_পরবর্তী", nodeBuiltin("লিংক_পরবর্তী", 1, func(n *ListNode, args []Object) Object {
+		return nodeOf(n.element.Next())
+	})},
+	{"লিংক_পূর্ববর্তী", nodeBuiltin("লিংক_পূর্ববর্তী", 1, func(n *ListNode, args []Object) Object {
+		return nodeOf(n.element.Prev())
+	})},
+	{"লিংক_মান", nodeBuiltin("লিংক_মান", 1, func(n *ListNode, args []Object) Object {
+		return n.Value
+	})},
+	{"লিংক_মান_বসাও", nodeBuiltin("লিংক_মান_বসাও", 2, func(n *ListNode, args []Object) Object {
+		n.Value = args[1]
+		return n
+	})},
+	{"লিংক_পরে_যোগ", nodeBuiltin("লিংক_পরে_যোগ", 2, func(n *ListNode, args []Object) Object {
+		node := &ListNode{Value: args[1], owner: n.owner}
+		node.element = n.owner.list.InsertAfter(node, n.element)
+		return node
+	})},
+	{"লিংক_আগে_যোগ", nodeBuiltin("লিংক_আগে_যোগ", 2, func(n *ListNode, args []Object) Object {
+		node := &ListNode{Value: args[1], owner: n.owner}
+		node.element = n.owner.list.InsertBefore(node, n.element)
+		return node
+	})},
+	{"লিংক_সরাও", nodeBuiltin("লিংক_সরাও", 1, func(n *ListNode, args []Object) Object {
+		n.owner.list.Remove(n.element)
+		n.element = nil
+		return n.Value
+	})},
+}
@@ -0,0 +1,232 @@
+package object
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Lock is a mutual exclusion lock created by তালা. Tasks that change the
+// same array, hash or captured variable take the lock around the change,
+// so only one of them does so at a time.
+type Lock struct {
+	// held has room for one token, which a task puts in to take the lock;
+	// unlike sync.Mutex, releasing a free lock is then an error, not a crash
+	held chan struct{}
+}
+
+func (l *Lock) Type() ObjectType { return LOCK_OBJ }
+func (l *Lock) Inspect() string {
+	if len(l.held) > 0 {
+		return "তালা(বন্ধ)"
+	}
+	return "তালা(খোলা)"
+}
+
+// Acquire waits until the lock is free and takes it
+func (l *Lock) Acquire() { l.held <- struct{}{} }
+
+// TryAcquire takes the lock if it is free, without waiting
+func (l *Lock) TryAcquire() bool {
+	select {
+	case l.held <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release frees the lock for the next task waiting for it
+func (l *Lock) Release() *Error {
+	select {
+	case <-l.held:
+		return nil
+	default:
+		return newError("release of a lock that is not held")
+	}
+}
+
+// AtomicInteger is an integer created by পারমাণবিক that tasks can read
+// and change at the same time without a lock
+type AtomicInteger struct {
+	value int64
+}
+
+func (a *AtomicInteger) Type() ObjectType { return ATOMIC_INTEGER_OBJ }
+func (a *AtomicInteger) Inspect() string {
+	return fmt.Sprintf("পারমাণবিক(%d)", a.Load())
+}
+
+// Load returns the current value
+func (a *AtomicInteger) Load() int64 { return atomic.LoadInt64(&a.value) }
+
+// lockArg checks that args[0] is a lock
+func lockArg(name string, args []Object) (*Lock, *Error) {
+	l, ok := args[0].(*Lock)
+	if !ok {
+		return nil, newError("argument to '%s' must be LOCK, got %s", name, args[0].Type())
+	}
+	return l, nil
+}
+
+// atomicArgs checks that args[0] is an atomic integer and the rest are
+// integers, returning their values
+func atomicArgs(name string, args []Object) (*AtomicInteger, []int64, *Error) {
+	a, ok := args[0].(*AtomicInteger)
+	if !ok {
+		return nil, nil, newError("first argument to '%s' must be ATOMIC_INTEGER, got %s", name, args[0].Type())
+	}
+	values := make([]int64, len(args)-1)
+	for i, arg := range args[1:] {
+		n, ok := integerValue(arg)
+		if !ok {
+			return nil, nil, newError("arguments to '%s' must be INTEGER, got %s", name, arg.Type())
+		}
+		values[i] = n
+	}
+	return a, values, nil
+}
+
+// lockBuiltins guard state shared between tasks: locks for changes that
+// take several steps, atomic integers for counters and flags
+var lockBuiltins = []BuiltinDef{
+	{
+		"তালা", // new lock, not held
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+			return &Lock{held: make(chan struct{}, 1)}
+		}},
+	},
+	{
+		"তালা_লাগাও", // acquire, waiting while another task holds it: (lock)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			l, err := lockArg("তালা_লাগাও", args)
+			if err != nil {
+				return err
+			}
+			l.Acquire()
+			return NULL
+		}},
+	},
+	{
+		"তালা_চেষ্টা", // acquire if free, without waiting: (lock) gives whether it did
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			l, err := lockArg("তালা_চেষ্টা", args)
+			if err != nil {
+				return err
+			}
+			return NativeBool(l.TryAcquire())
+		}},
+	},
+	{
+		"তালা_খোলো", // release: (lock)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			l, err := lockArg("তালা_খোলো", args)
+			if err != nil {
+				return err
+			}
+			if err := l.Release(); err != nil {
+				return err
+			}
+			return NULL
+		}},
+	},
+	{
+		"তালা_সহ", // call fn holding the lock, releasing it even if fn fails: (lock, fn)
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			l, err := lockArg("তালা_সহ", args)
+			if err != nil {
+				return err
+			}
+			if args[1].Type() != CLOSURE_OBJ && args[1].Type() != BUILTIN_OBJ {
+				return newError("second argument to 'তালা_সহ' must be FUNCTION, got %s", args[1].Type())
+			}
+			l.Acquire()
+			defer l.Release()
+			return rt.CallFunction(args[1])
+		}},
+	},
+	{
+		"পারমাণবিক", // new atomic integer: ([initial value])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+			}
+			a := &AtomicInteger{}
+			if len(args) == 1 {
+				n, ok := integerValue(args[0])
+				if !ok {
+					return newError("argument to 'পারমাণবিক' must be INTEGER, got %s", args[0].Type())
+				}
+				a.value = n
+			}
+			return a
+		}},
+	},
+	{
+		"পারমাণবিক_মান", // current value: (atomic)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			a, _, err := atomicArgs("পারমাণবিক_মান", args)
+			if err != nil {
+				return err
+			}
+			return NewInteger(a.Load())
+		}},
+	},
+	{
+		"পারমাণবিক_স্থাপন", // set the value: (atomic, n)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			a, values, err := atomicArgs("পারমাণবিক_স্থাপন", args)
+			if err != nil {
+				return err
+			}
+			atomic.StoreInt64(&a.value, values[0])
+			return NULL
+		}},
+	},
+	{
+		"পারমাণবিক_যোগ", // add and give the new value: (atomic, delta)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			a, values, err := atomicArgs("পারমাণবিক_যোগ", args)
+			if err != nil {
+				return err
+			}
+			return NewInteger(atomic.AddInt64(&a.value, values[0]))
+		}},
+	},
+	{
+		"পারমাণবিক_তুলনা_বদল", // set to new only if the value is old: (atomic, old, new) gives whether it did
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=3", len(args))
+			}
+			a, values, err := atomicArgs("পারমাণবিক_তুলনা_বদল", args)
+			if err != nil {
+				return err
+			}
+			return NativeBool(atomic.CompareAndSwapInt64(&a.value, values[0], values[1]))
+		}},
+	},
+}
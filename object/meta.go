@@ -0,0 +1,24 @@
+package object
+
+import "bhasa/version"
+
+// metaBuiltins expose information about the interpreter itself
+var metaBuiltins = []BuiltinDef{
+	{
+		"ভাষা_সংস্করণ", // version - build information as a hash
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+			info := version.Get()
+			hash := &Hash{Pairs: make(map[HashKey]HashPair)}
+			hash.Set("সংস্করণ", &String{Value: info.Version})
+			hash.Set("কমিট", &String{Value: info.Commit})
+			hash.Set("নির্মাণ_তারিখ", &String{Value: info.BuildDate})
+			hash.Set("বাইটকোড_সংস্করণ", NewInteger(int64(info.BytecodeVersion)))
+			hash.Set("গো_সংস্করণ", &String{Value: info.GoVersion})
+			hash.Set("প্ল্যাটফর্ম", &String{Value: info.Platform})
+			return hash
+		}},
+	},
+}
@@ -0,0 +1,20 @@
+package object
+
+// numberText is how DisplayText shows a number: with Bengali digits, so
+// that 15 is written ১৫, when rt's BengaliNumerals asks for them, and as
+// Inspect writes it otherwise. The mode applies wherever a number becomes
+// text for people to read: লেখ and the REPL, casts to লেখা, ফরম্যাট and
+// interpolation. Inspect itself, JSON, environment variables, command
+// arguments and HTTP bodies keep ASCII digits. Bengali digits are accepted
+// wherever numbers are read back, so output in this mode can be parsed
+// again. The second result is false when obj is not a number.
+func numberText(rt Runtime, obj Object) (string, bool) {
+	switch obj.(type) {
+	case *Integer, *Byte, *Short, *Int, *Long, *Float, *Double, *BigInteger, *Decimal:
+		if rt.BengaliNumerals() {
+			return toBengaliDigits(obj.Inspect()), true
+		}
+		return obj.Inspect(), true
+	}
+	return "", false
+}
@@ -0,0 +1,145 @@
+package object
+
+import (
+	"bhasa/token"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// integerValue extracts an int64 from any integer-like object
+func integerValue(obj Object) (int64, bool) {
+	switch v := obj.(type) {
+	case *Integer:
+		return v.Value, true
+	case *Byte:
+		return int64(uint8(v.Value)), true
+	case *Short:
+		return int64(v.Value), true
+	case *Int:
+		return int64(v.Value), true
+	case *Long:
+		return v.Value, true
+	default:
+		return 0, false
+	}
+}
+
+// floatValue extracts a float64 from any numeric object
+func floatValue(obj Object) (float64, bool) {
+	switch v := obj.(type) {
+	case *Float:
+		return float64(v.Value), true
+	case *Double:
+		return v.Value, true
+	case *BigInteger:
+		f, _ := new(big.Float).SetInt(v.Value).Float64()
+		return f, true
+	case *Decimal:
+		f, _ := v.Value.Float64()
+		return f, true
+	default:
+		if i, ok := integerValue(obj); ok {
+			return float64(i), true
+		}
+		return 0, false
+	}
+}
+
+// parseNumber parses text as written by users: surrounding whitespace and
+// a leading + or - are allowed, and digits may be Bengali. With radix 10,
+// fractions and exponents (1.5, 1e9, ১.৫ই৩) give a Double; other radixes
+// (2 to 36) accept integers only.
+func parseNumber(text string, radix int) (Object, error) {
+	if radix < 2 || radix > 36 {
+		return nil, fmt.Errorf("radix must be between 2 and 36, got %d", radix)
+	}
+	s := token.ConvertBengaliNumber(strings.TrimSpace(text))
+	if s == "" {
+		return nil, fmt.Errorf("cannot parse an empty string as a number")
+	}
+
+	n, err := strconv.ParseInt(s, radix, 64)
+	if err == nil {
+		return NewInteger(n), nil
+	}
+	if radix == 10 {
+		if f, floatErr := strconv.ParseFloat(token.NormalizeFloat(s), 64); floatErr == nil {
+			return &Double{Value: f}, nil
+		}
+	}
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		return nil, fmt.Errorf("'%s' is out of range for an integer", text)
+	}
+	if radix != 10 {
+		return nil, fmt.Errorf("cannot parse '%s' as a base %d integer", text, radix)
+	}
+	return nil, fmt.Errorf("cannot parse '%s' as a number", text)
+}
+
+// radixArg returns the optional radix argument at args[i], defaulting to 10
+func radixArg(name string, args []Object, i int) (int, *Error) {
+	if len(args) <= i {
+		return 10, nil
+	}
+	radix, ok := integerValue(args[i])
+	if !ok {
+		return 0, newError("radix argument to '%s' must be INTEGER, got %s", name, args[i].Type())
+	}
+	return int(radix), nil
+}
+
+// numericBuiltins classify floating-point values. Float division by zero
+// gives ±Inf and 0/0 gives NaN, as in IEEE 754; NaN compares unequal to
+// everything, itself included, and both become null in JSON.
+var numericBuiltins = []BuiltinDef{
+	{
+		"সসীম_কি", // isFinite - false for NaN and ±Inf
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			x, ok := floatValue(args[0])
+			if !ok {
+				return newError("argument to 'সসীম_কি' must be a number, got %s", args[0].Type())
+			}
+			return NativeBool(!math.IsNaN(x) && !math.IsInf(x, 0))
+		}},
+	},
+	{
+		"নান_কি", // isNaN
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			x, ok := floatValue(args[0])
+			if !ok {
+				return newError("argument to 'নান_কি' must be a number, got %s", args[0].Type())
+			}
+			return NativeBool(math.IsNaN(x))
+		}},
+	},
+	{
+		"সংখ্যা_চেষ্টা", // parse a number like সংখ্যা, giving null instead of an error: (str, [radix])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			str, ok := args[0].(*String)
+			if !ok {
+				return NULL
+			}
+			radix, radixErr := radixArg("সংখ্যা_চেষ্টা", args, 1)
+			if radixErr != nil {
+				return radixErr
+			}
+			result, err := parseNumber(str.Value, radix)
+			if err != nil {
+				return NULL
+			}
+			return result
+		}},
+	},
+}
@@ -9,6 +9,7 @@ import (
 	"hash/fnv"
 	"math"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -40,6 +41,22 @@ const (
 	STRUCT_OBJ            = "STRUCT"
 	ENUM_OBJ              = "ENUM"
 	ENUM_TYPE_OBJ         = "ENUM_TYPE"
+	FILE_OBJ              = "FILE"
+	HEAP_OBJ              = "HEAP"
+	SET_OBJ               = "SET"
+	BYTES_OBJ             = "BYTES"
+	DEQUE_OBJ             = "DEQUE"
+	BIG_INTEGER_OBJ       = "BIG_INTEGER"
+	LINKED_LIST_OBJ       = "LINKED_LIST"
+	LIST_NODE_OBJ         = "LIST_NODE"
+	GRAPH_OBJ             = "GRAPH"
+	DECIMAL_OBJ           = "DECIMAL"
+	TUPLE_OBJ             = "TUPLE"
+	CELL_OBJ              = "CELL"
+	TASK_OBJ              = "TASK"
+	CHANNEL_OBJ           = "CHANNEL"
+	LOCK_OBJ              = "LOCK"
+	ATOMIC_INTEGER_OBJ    = "ATOMIC_INTEGER"
 
 	// OOP object types
 	CLASS_OBJ          = "CLASS"
@@ -62,6 +79,31 @@ type Integer struct {
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 
+// Integers from SmallIntMin to SmallIntMax are made once and shared, as
+// loop counters, indexes and lengths are mostly small
+const (
+	SmallIntMin = -128
+	SmallIntMax = 1024
+)
+
+var smallInts = func() *[SmallIntMax - SmallIntMin + 1]Integer {
+	ints := &[SmallIntMax - SmallIntMin + 1]Integer{}
+	for i := range ints {
+		ints[i].Value = int64(i + SmallIntMin)
+	}
+	return ints
+}()
+
+// NewInteger returns an integer with the given value. Small values are
+// shared rather than allocated, which is safe because an Integer is never
+// changed once made; compare integers by Value, never by pointer.
+func NewInteger(value int64) *Integer {
+	if value >= SmallIntMin && value <= SmallIntMax {
+		return &smallInts[value-SmallIntMin]
+	}
+	return &Integer{Value: value}
+}
+
 // Byte represents a byte value (0-255)
 type Byte struct {
 	Value int8
@@ -126,6 +168,22 @@ type Boolean struct {
 func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
 func (b *Boolean) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
 
+// The two booleans and the null, shared by the VM, the evaluator and the
+// builtins
+var (
+	TRUE  = &Boolean{Value: true}
+	FALSE = &Boolean{Value: false}
+	NULL  = &Null{}
+)
+
+// NativeBool returns the shared boolean for a Go bool
+func NativeBool(value bool) *Boolean {
+	if value {
+		return TRUE
+	}
+	return FALSE
+}
+
 // String represents a string value
 type String struct {
 	Value string
@@ -151,6 +209,7 @@ func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 // Error represents an error
 type Error struct {
 	Message string
+	Abort   bool // stops the program when a builtin returns it, as a failed assertion does
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
@@ -159,6 +218,8 @@ func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
 // Function represents a function
 type Function struct {
 	Parameters []*ast.Identifier
+	Defaults   []ast.Expression // default values, nil for required parameters
+	Rest       bool             // the last parameter collects any extra arguments
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
@@ -167,8 +228,12 @@ func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
 func (f *Function) Inspect() string {
 	var out bytes.Buffer
 	params := []string{}
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
+	for i, p := range f.Parameters {
+		if f.Rest && i == len(f.Parameters)-1 {
+			params = append(params, "..."+p.String())
+		} else {
+			params = append(params, p.String())
+		}
 	}
 	out.WriteString("ফাংশন")
 	out.WriteString("(")
@@ -184,7 +249,8 @@ type BuiltinFunction func(args ...Object) Object
 
 // Builtin represents a built-in function
 type Builtin struct {
-	Fn BuiltinFunction
+	Fn        BuiltinFunction
+	RuntimeFn RuntimeFunction // used instead of Fn when set
 }
 
 func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
@@ -193,6 +259,7 @@ func (b *Builtin) Inspect() string  { return "builtin function" }
 // Array represents an array
 type Array struct {
 	Elements []Object
+	Mutable  bool // changed in place by index assignment and the array builtins
 }
 
 func (ao *Array) Type() ObjectType { return ARRAY_OBJ }
@@ -208,6 +275,25 @@ func (ao *Array) Inspect() string {
 	return out.String()
 }
 
+// Tuple is a fixed group of values, written (a, b) or returned by
+// ফেরত a, b. Unlike an array it can never change, and a tuple whose
+// elements can all be hash keys can be one itself.
+type Tuple struct {
+	Elements []Object
+}
+
+func (t *Tuple) Type() ObjectType { return TUPLE_OBJ }
+func (t *Tuple) Inspect() string {
+	elements := make([]string, len(t.Elements))
+	for i, e := range t.Elements {
+		elements[i] = e.Inspect()
+	}
+	if len(elements) == 1 {
+		return "(" + elements[0] + ",)"
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
 // HashKey represents a hashable key
 type HashKey struct {
 	Type  ObjectType
@@ -229,7 +315,7 @@ func (h *Hash) Type() ObjectType { return HASH_OBJ }
 func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for _, pair := range h.Pairs {
+	for _, pair := range h.SortedPairs() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s",
 			pair.Key.Inspect(), pair.Value.Inspect()))
 	}
@@ -239,6 +325,65 @@ func (h *Hash) Inspect() string {
 	return out.String()
 }
 
+// Set stores value under a string key
+func (h *Hash) Set(key string, value Object) {
+	k := &String{Value: key}
+	h.Pairs[k.HashKey()] = HashPair{Key: k, Value: value}
+}
+
+// SortedPairs returns the pairs ordered by key, so that printing or
+// iterating a hash gives the same result on every run. Booleans come
+// first, then numbers by value, then characters and strings.
+func (h *Hash) SortedPairs() []HashPair {
+	pairs := make([]HashPair, 0, len(h.Pairs))
+	for _, pair := range h.Pairs {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return keyLess(pairs[i].Key, pairs[j].Key)
+	})
+	return pairs
+}
+
+// keyRank groups hash keys of different types for keyLess
+func keyRank(key Object) int {
+	switch key.(type) {
+	case *Boolean:
+		return 0
+	case *Char:
+		return 2
+	case *String:
+		return 3
+	}
+	if _, ok := floatValue(key); ok {
+		return 1
+	}
+	return 4
+}
+
+// keyLess orders two hash keys
+func keyLess(a, b Object) bool {
+	if ra, rb := keyRank(a), keyRank(b); ra != rb {
+		return ra < rb
+	}
+	switch a := a.(type) {
+	case *Boolean:
+		return !a.Value && b.(*Boolean).Value
+	case *Char:
+		return a.Value < b.(*Char).Value
+	case *String:
+		return a.Value < b.(*String).Value
+	}
+	if fa, ok := floatValue(a); ok {
+		fb, _ := floatValue(b)
+		if fa != fb {
+			return fa < fb
+		}
+		return a.Type() < b.Type()
+	}
+	return a.Inspect() < b.Inspect()
+}
+
 // Hashable interface for objects that can be hashed
 type Hashable interface {
 	HashKey() HashKey
@@ -293,6 +438,26 @@ func (s *String) HashKey() HashKey {
 	return HashKey{Type: s.Type(), Value: h.Sum64()}
 }
 
+// HashKeyOf returns the hash key of obj, reporting whether it can be used
+// as one. A tuple can when all of its elements can.
+func HashKeyOf(obj Object) (HashKey, bool) {
+	switch obj := obj.(type) {
+	case Hashable:
+		return obj.HashKey(), true
+	case *Tuple:
+		h := fnv.New64a()
+		for _, el := range obj.Elements {
+			key, ok := HashKeyOf(el)
+			if !ok {
+				return HashKey{}, false
+			}
+			fmt.Fprintf(h, "%s:%d;", key.Type, key.Value)
+		}
+		return HashKey{Type: obj.Type(), Value: h.Sum64()}, true
+	}
+	return HashKey{}, false
+}
+
 // Environment represents a variable environment
 type Environment struct {
 	store map[string]Object
@@ -312,6 +477,10 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	return env
 }
 
+// Outer returns the environment this one is enclosed in, nil at the top
+// level
+func (e *Environment) Outer() *Environment { return e.outer }
+
 // Get retrieves a variable from the environment
 func (e *Environment) Get(name string) (Object, bool) {
 	obj, ok := e.store[name]
@@ -327,11 +496,71 @@ func (e *Environment) Set(name string, val Object) Object {
 	return val
 }
 
+// Assign updates a variable in the innermost environment that declares
+// it, and reports false when none does
+func (e *Environment) Assign(name string, val Object) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			env.store[name] = val
+			return true
+		}
+	}
+	return false
+}
+
 // CompiledFunction represents a compiled function
 type CompiledFunction struct {
 	Instructions  []byte
 	NumLocals     int
 	NumParameters int
+	NumDefaults   int        // trailing parameters with default values, which callers may leave out
+	Variadic      bool       // the last parameter collects any extra arguments into an array
+	Lines         []LineInfo // source lines of the instructions, if known
+	ClassName     string     // class whose body the function was written in, for access checks
+	LocalNames    []string   // names of the locals by index, if known, for watches
+}
+
+// LineInfo marks that the instructions from Offset onwards were compiled
+// from source line Line of File (empty when the file is unknown)
+type LineInfo struct {
+	Offset int
+	Line   int
+	File   string
+}
+
+// PositionForOffset returns the entry of lines covering the instruction at
+// offset; its Line is 0 when unknown
+func PositionForOffset(lines []LineInfo, offset int) LineInfo {
+	pos := LineInfo{}
+	for _, l := range lines {
+		if l.Offset > offset {
+			break
+		}
+		pos = l
+	}
+	return pos
+}
+
+// LineForOffset returns the source line of the instruction at offset, or 0
+func LineForOffset(lines []LineInfo, offset int) int {
+	line := 0
+	for _, l := range lines {
+		if l.Offset > offset {
+			break
+		}
+		line = l.Line
+	}
+	return line
+}
+
+// Accepts reports whether the function can be called with numArgs
+// arguments, leaving out some of those with default values
+func (cf *CompiledFunction) Accepts(numArgs int) bool {
+	required := cf.NumParameters - cf.NumDefaults
+	if cf.Variadic {
+		return numArgs >= required-1
+	}
+	return numArgs <= cf.NumParameters && numArgs >= required
 }
 
 func (cf *CompiledFunction) Type() ObjectType { return COMPILED_FUNCTION_OBJ }
@@ -350,6 +579,18 @@ func (c *Closure) Inspect() string {
 	return fmt.Sprintf("Closure[%p]", c)
 }
 
+// Cell holds a local variable that a closure assigns, so that the
+// function declaring it and every closure capturing it share one value.
+// Cells only ever live in local and free variable slots; programs never
+// see them.
+type Cell struct {
+	Value Object
+	Watch string // name of the watched local the cell holds, reported on writes
+}
+
+func (c *Cell) Type() ObjectType { return CELL_OBJ }
+func (c *Cell) Inspect() string  { return c.Value.Inspect() }
+
 // BoundMethod represents a method bound to an instance (with 'this')
 type BoundMethod struct {
 	Receiver Object   // The instance that 'this' refers to
@@ -394,9 +635,21 @@ func (et *EnumType) Inspect() string {
 	out.WriteString(et.Name)
 	out.WriteString(" { ")
 
+	// Variants are listed by value so the output is the same every run
+	names := make([]string, 0, len(et.Variants))
+	for name := range et.Variants {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		vi, vj := et.Variants[names[i]], et.Variants[names[j]]
+		if vi != vj {
+			return vi < vj
+		}
+		return names[i] < names[j]
+	})
 	variants := []string{}
-	for name, value := range et.Variants {
-		variants = append(variants, fmt.Sprintf("%s = %d", name, value))
+	for _, name := range names {
+		variants = append(variants, fmt.Sprintf("%s = %d", name, et.Variants[name]))
 	}
 	out.WriteString(strings.Join(variants, ", "))
 	out.WriteString(" }")
@@ -450,7 +703,7 @@ type Class struct {
 	Interfaces   []*Interface          // Implemented interfaces (বাস্তবায়ন)
 	Fields       map[string]string     // field name -> field type (for type checking)
 	Methods      map[string]*Method    // method name -> method
-	Constructor  *Closure              // Constructor function (নির্মাতা)
+	Constructors []*Closure            // Constructor functions (নির্মাতা), one per arity
 	StaticFields map[string]Object     // static fields (স্থির)
 	IsAbstract   bool                  // বিমূর্ত
 	IsFinal      bool                  // চূড়ান্ত
@@ -487,6 +740,87 @@ func (c *Class) GetMethod(name string) *Method {
 	return nil
 }
 
+// GetStatic retrieves a static field, or the closure of a static method,
+// from the class or its parent chain
+func (c *Class) GetStatic(name string) (Object, bool) {
+	if value, ok := c.StaticFields[name]; ok {
+		return value, true
+	}
+	if method, ok := c.Methods[name]; ok && method.IsStatic {
+		return method.Closure, true
+	}
+	if c.SuperClass != nil {
+		return c.SuperClass.GetStatic(name)
+	}
+	return nil, false
+}
+
+// SetStatic assigns a static field declared by the class or a parent,
+// reporting false when no class in the chain declares it
+func (c *Class) SetStatic(name string, value Object) bool {
+	if _, ok := c.StaticFields[name]; ok {
+		c.StaticFields[name] = value
+		return true
+	}
+	if c.SuperClass != nil {
+		return c.SuperClass.SetStatic(name, value)
+	}
+	return false
+}
+
+// MemberAccess returns the access modifier of a field or method and the
+// class in the parent chain that declares it, or nil if none does
+func (c *Class) MemberAccess(name string) (string, *Class) {
+	for class := c; class != nil; class = class.SuperClass {
+		if access, ok := class.FieldAccess[name]; ok {
+			return access, class
+		}
+		if method, ok := class.Methods[name]; ok {
+			return method.Access, class
+		}
+	}
+	return "", nil
+}
+
+// CanAccess reports whether code written in the body of the class named
+// from (empty outside any class) may use the named member. A private
+// member may only be used inside the class that declares it; a protected
+// one also inside subclasses between the instance's class and the
+// declaring class.
+func (c *Class) CanAccess(name, from string) bool {
+	access, declaring := c.MemberAccess(name)
+	switch access {
+	case "ব্যক্তিগত":
+		return from == declaring.Name
+	case "সুরক্ষিত":
+		for class := c; class != nil; class = class.SuperClass {
+			if class.Name == from {
+				return true
+			}
+			if class == declaring {
+				break
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// ConstructorFor picks the constructor to call with numArgs arguments: the
+// one taking that many, or the only one so that a wrong count is reported
+// when it is called. It returns nil when no constructor fits.
+func (c *Class) ConstructorFor(numArgs int) *Closure {
+	if len(c.Constructors) == 1 {
+		return c.Constructors[0]
+	}
+	for _, constructor := range c.Constructors {
+		if constructor.Fn.Accepts(numArgs + 1) { // +1 for এই
+			return constructor
+		}
+	}
+	return nil
+}
+
 // HasField checks if the class or its parents have a field
 func (c *Class) HasField(name string) bool {
 	if _, ok := c.Fields[name]; ok {
@@ -523,19 +857,130 @@ func (ci *ClassInstance) Inspect() string {
 	out.WriteString("{")
 
 	pairs := []string{}
+	for _, fieldName := range ci.shownFields() {
+		pairs = append(pairs, fmt.Sprintf("%s: %s", fieldName, ci.Fields[fieldName].Inspect()))
+	}
+
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+// shownFields returns the fields an instance is printed with: those that
+// are set and public or protected, in declaration order
+func (ci *ClassInstance) shownFields() []string {
+	var names []string
 	for _, fieldName := range ci.Class.FieldOrder {
-		if value, ok := ci.Fields[fieldName]; ok {
-			// Check field access - only show public and protected fields
+		if _, ok := ci.Fields[fieldName]; ok {
 			access := ci.Class.FieldAccess[fieldName]
 			if access == "সার্বজনীন" || access == "সুরক্ষিত" {
-				pairs = append(pairs, fmt.Sprintf("%s: %s", fieldName, value.Inspect()))
+				names = append(names, fieldName)
 			}
 		}
 	}
+	return names
+}
 
-	out.WriteString(strings.Join(pairs, ", "))
-	out.WriteString("}")
-	return out.String()
+// ToStringMethod is the method a class defines to choose how লেখ prints
+// its instances
+const ToStringMethod = "লেখা_রূপ"
+
+// DisplayText is how a value is turned into text by লেখ, ফরম্যাট,
+// interpolation and লেখা: the result of calling a class instance's
+// লেখা_রূপ method when its class defines one, numbers in the digits rt
+// asks for, and the value's Inspect text otherwise. Arrays, tuples,
+// hashes, structs and instances show their elements the same way.
+func DisplayText(rt Runtime, obj Object) (string, *Error) {
+	if text, ok := numberText(rt, obj); ok {
+		return text, nil
+	}
+	switch obj := obj.(type) {
+	case *ClassInstance:
+		if obj.Class.GetMethod(ToStringMethod) != nil {
+			return instanceText(rt, obj)
+		}
+		fields, err := fieldTexts(rt, obj.shownFields(), obj.Fields)
+		if err != nil {
+			return "", err
+		}
+		return obj.Class.Name + "{" + fields + "}", nil
+	case *Struct:
+		fields, err := fieldTexts(rt, obj.FieldOrder, obj.Fields)
+		if err != nil {
+			return "", err
+		}
+		return "{" + fields + "}", nil
+	case *Array:
+		elements, err := displayTexts(rt, obj.Elements)
+		if err != nil {
+			return "", err
+		}
+		return "[" + strings.Join(elements, ", ") + "]", nil
+	case *Tuple:
+		elements, err := displayTexts(rt, obj.Elements)
+		if err != nil {
+			return "", err
+		}
+		if len(elements) == 1 {
+			return "(" + elements[0] + ",)", nil
+		}
+		return "(" + strings.Join(elements, ", ") + ")", nil
+	case *Hash:
+		var pairs []string
+		for _, pair := range obj.SortedPairs() {
+			key, err := DisplayText(rt, pair.Key)
+			if err != nil {
+				return "", err
+			}
+			value, err := DisplayText(rt, pair.Value)
+			if err != nil {
+				return "", err
+			}
+			pairs = append(pairs, key+": "+value)
+		}
+		return "{" + strings.Join(pairs, ", ") + "}", nil
+	}
+	return obj.Inspect(), nil
+}
+
+// displayTexts returns the DisplayText of each of values
+func displayTexts(rt Runtime, values []Object) ([]string, *Error) {
+	texts := make([]string, len(values))
+	for i, value := range values {
+		text, err := DisplayText(rt, value)
+		if err != nil {
+			return nil, err
+		}
+		texts[i] = text
+	}
+	return texts, nil
+}
+
+// fieldTexts joins the named fields as name: value, showing each value
+// with DisplayText
+func fieldTexts(rt Runtime, names []string, fields map[string]Object) (string, *Error) {
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		text, err := DisplayText(rt, fields[name])
+		if err != nil {
+			return "", err
+		}
+		pairs[i] = name + ": " + text
+	}
+	return strings.Join(pairs, ", "), nil
+}
+
+// instanceText calls the লেখা_রূপ method of an instance's class
+func instanceText(rt Runtime, instance *ClassInstance) (string, *Error) {
+	method := instance.Class.GetMethod(ToStringMethod)
+	result := rt.CallFunction(method.Closure, instance)
+	switch result := result.(type) {
+	case *Error:
+		return "", result
+	case *String:
+		return result.Value, nil
+	}
+	return "", newError("%s of class '%s' must return STRING, got %s", ToStringMethod, instance.Class.Name, result.Type())
 }
 
 // GetField retrieves a field value from the instance or its class hierarchy
@@ -557,15 +1002,80 @@ type BuiltinDef struct {
 	Builtin *Builtin
 }
 
-// Builtins is the list of builtin functions
-var Builtins = []BuiltinDef{
+// Builtins is the list of builtin functions. Compiled bytecode refers to
+// builtins by index, so new groups must only ever be appended at the end.
+var Builtins = joinBuiltins(
+	coreBuiltins,
+	randomBuiltins,
+	timeBuiltins,
+	regexBuiltins,
+	metaBuiltins,
+	ioBuiltins,
+	envBuiltins,
+	processBuiltins,
+	selfHostBuiltins,
+	httpClientBuiltins,
+	httpServerBuiltins,
+	fsBuiltins,
+	fileBuiltins,
+	formatBuiltins,
+	numericBuiltins,
+	textBuiltins,
+	arrayBuiltins,
+	matchBuiltins,
+	searchBuiltins,
+	heapBuiltins,
+	setBuiltins,
+	bytesBuiltins,
+	dequeBuiltins,
+	bigIntegerBuiltins,
+	linkedListBuiltins,
+	graphBuiltins,
+	decimalBuiltins,
+	mutabilityBuiltins,
+	assertBuiltins,
+	taskBuiltins,
+	channelBuiltins,
+	lockBuiltins,
+)
+
+// joinBuiltins concatenates builtin groups in order
+func joinBuiltins(groups ...[]BuiltinDef) []BuiltinDef {
+	var all []BuiltinDef
+	for _, group := range groups {
+		all = append(all, group...)
+	}
+	return all
+}
+
+// newError creates an error object with a formatted message
+func newError(format string, a ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, a...)}
+}
+
+// coreBuiltins are the original builtin functions
+var coreBuiltins = []BuiltinDef{
 	{
 		"লেখ",
-		&Builtin{Fn: func(args ...Object) Object {
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			// লেখ("%d টি", n) formats when the first argument is a format
+			// whose directives match the remaining arguments
+			if len(args) > 1 {
+				if format, ok := args[0].(*String); ok && strings.Contains(format.Value, "%") {
+					if text, err := formatString(rt, format.Value, args[1:]); err == nil {
+						fmt.Fprintln(rt.Stdout(), text)
+						return NULL
+					}
+				}
+			}
 			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+				text, err := DisplayText(rt, arg)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(rt.Stdout(), text)
 			}
-			return &Null{}
+			return NULL
 		}},
 	},
 	{
@@ -576,9 +1086,13 @@ var Builtins = []BuiltinDef{
 			}
 			switch arg := args[0].(type) {
 			case *String:
-				return &Integer{Value: int64(len([]rune(arg.Value)))}
+				return NewInteger(int64(len([]rune(arg.Value))))
 			case *Array:
-				return &Integer{Value: int64(len(arg.Elements))}
+				return NewInteger(int64(len(arg.Elements)))
+			case *Tuple:
+				return NewInteger(int64(len(arg.Elements)))
+			case *Bytes:
+				return NewInteger(int64(len(arg.Value)))
 			default:
 				return &Error{Message: fmt.Sprintf("argument to 'দৈর্ঘ্য' not supported, got %s", args[0].Type())}
 			}
@@ -597,7 +1111,7 @@ var Builtins = []BuiltinDef{
 			if len(arr.Elements) > 0 {
 				return arr.Elements[0]
 			}
-			return &Null{}
+			return NULL
 		}},
 	},
 	{
@@ -614,7 +1128,7 @@ var Builtins = []BuiltinDef{
 			if length > 0 {
 				return arr.Elements[length-1]
 			}
-			return &Null{}
+			return NULL
 		}},
 	},
 	{
@@ -633,7 +1147,7 @@ var Builtins = []BuiltinDef{
 				copy(newElements, arr.Elements[1:length])
 				return &Array{Elements: newElements}
 			}
-			return &Null{}
+			return NULL
 		}},
 	},
 	{
@@ -646,6 +1160,10 @@ var Builtins = []BuiltinDef{
 				return &Error{Message: fmt.Sprintf("argument to 'যোগ' must be ARRAY, got %s", args[0].Type())}
 			}
 			arr := args[0].(*Array)
+			if arr.Mutable {
+				arr.Elements = append(arr.Elements, args[1])
+				return arr
+			}
 			length := len(arr.Elements)
 			newElements := make([]Object, length+1)
 			copy(newElements, arr.Elements)
@@ -664,22 +1182,21 @@ var Builtins = []BuiltinDef{
 	},
 	// String methods
 	{
-		"বিভক্ত", // split
+		"বিভক্ত", // split: (str, delimiter, [max parts])
 		&Builtin{Fn: func(args ...Object) Object {
-			if len(args) != 2 {
-				return &Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=2", len(args))}
+			if len(args) < 2 || len(args) > 3 {
+				return &Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=2 or 3", len(args))}
 			}
 			if args[0].Type() != STRING_OBJ || args[1].Type() != STRING_OBJ {
 				return &Error{Message: "arguments to 'বিভক্ত' must be STRING"}
 			}
+			limit, err := splitLimit("বিভক্ত", args, 2)
+			if err != nil {
+				return err
+			}
 			str := args[0].(*String).Value
 			delimiter := args[1].(*String).Value
-			parts := strings.Split(str, delimiter)
-			elements := make([]Object, len(parts))
-			for i, part := range parts {
-				elements[i] = &String{Value: part}
-			}
-			return &Array{Elements: elements}
+			return stringArray(strings.SplitN(str, delimiter, limit))
 		}},
 	},
 	{
@@ -727,15 +1244,15 @@ var Builtins = []BuiltinDef{
 		}},
 	},
 	{
-		"ছাঁটো", // trim
+		"ছাঁটো", // trim both ends: (str, [cutset])
 		&Builtin{Fn: func(args ...Object) Object {
-			if len(args) != 1 {
-				return &Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+			str, cutset, hasCutset, err := trimArgs("ছাঁটো", args)
+			if err != nil {
+				return err
 			}
-			if args[0].Type() != STRING_OBJ {
-				return &Error{Message: "argument to 'ছাঁটো' must be STRING"}
+			if hasCutset {
+				return &String{Value: strings.Trim(str, cutset)}
 			}
-			str := args[0].(*String).Value
 			return &String{Value: strings.TrimSpace(str)}
 		}},
 	},
@@ -765,7 +1282,7 @@ var Builtins = []BuiltinDef{
 			}
 			str := args[0].(*String).Value
 			substr := args[1].(*String).Value
-			return &Integer{Value: int64(strings.Index(str, substr))}
+			return NewInteger(int64(strings.Index(str, substr)))
 		}},
 	},
 	// Math functions
@@ -781,7 +1298,7 @@ var Builtins = []BuiltinDef{
 			base := float64(args[0].(*Integer).Value)
 			exp := float64(args[1].(*Integer).Value)
 			result := math.Pow(base, exp)
-			return &Integer{Value: int64(result)}
+			return NewInteger(int64(result))
 		}},
 	},
 	{
@@ -798,7 +1315,7 @@ var Builtins = []BuiltinDef{
 				return &Error{Message: "cannot take square root of negative number"}
 			}
 			result := math.Sqrt(n)
-			return &Integer{Value: int64(result)}
+			return NewInteger(int64(result))
 		}},
 	},
 	{
@@ -812,9 +1329,9 @@ var Builtins = []BuiltinDef{
 			}
 			n := args[0].(*Integer).Value
 			if n < 0 {
-				return &Integer{Value: -n}
+				return NewInteger(-n)
 			}
-			return &Integer{Value: n}
+			return NewInteger(n)
 		}},
 	},
 	{
@@ -829,9 +1346,9 @@ var Builtins = []BuiltinDef{
 			a := args[0].(*Integer).Value
 			b := args[1].(*Integer).Value
 			if a > b {
-				return &Integer{Value: a}
+				return NewInteger(a)
 			}
-			return &Integer{Value: b}
+			return NewInteger(b)
 		}},
 	},
 	{
@@ -846,9 +1363,9 @@ var Builtins = []BuiltinDef{
 			a := args[0].(*Integer).Value
 			b := args[1].(*Integer).Value
 			if a < b {
-				return &Integer{Value: a}
+				return NewInteger(a)
 			}
-			return &Integer{Value: b}
+			return NewInteger(b)
 		}},
 	},
 	{
@@ -989,7 +1506,7 @@ var Builtins = []BuiltinDef{
 				return &Error{Message: fmt.Sprintf("error writing file: %s", err)}
 			}
 
-			return &Null{}
+			return NULL
 		}},
 	},
 	{
@@ -1015,7 +1532,7 @@ var Builtins = []BuiltinDef{
 				return &Error{Message: fmt.Sprintf("error appending to file: %s", err)}
 			}
 
-			return &Null{}
+			return NULL
 		}},
 	},
 	{
@@ -1032,9 +1549,9 @@ var Builtins = []BuiltinDef{
 			_, err := os.Stat(filename)
 
 			if os.IsNotExist(err) {
-				return &Boolean{Value: false}
+				return NativeBool(false)
 			}
-			return &Boolean{Value: true}
+			return NativeBool(true)
 		}},
 	},
 	// JSON functions
@@ -1090,7 +1607,7 @@ var Builtins = []BuiltinDef{
 			}
 			hash := args[0].(*Hash)
 			keys := make([]Object, 0, len(hash.Pairs))
-			for _, pair := range hash.Pairs {
+			for _, pair := range hash.SortedPairs() {
 				keys = append(keys, pair.Key)
 			}
 			return &Array{Elements: keys}
@@ -1107,7 +1624,7 @@ var Builtins = []BuiltinDef{
 			}
 			hash := args[0].(*Hash)
 			values := make([]Object, 0, len(hash.Pairs))
-			for _, pair := range hash.Pairs {
+			for _, pair := range hash.SortedPairs() {
 				values = append(values, pair.Value)
 			}
 			return &Array{Elements: values}
@@ -1125,13 +1642,13 @@ var Builtins = []BuiltinDef{
 			hash := args[0].(*Hash)
 
 			// Check if key is hashable
-			keyObj, ok := args[1].(Hashable)
+			key, ok := HashKeyOf(args[1])
 			if !ok {
 				return &Error{Message: "second argument must be a hashable type (INTEGER, STRING, or BOOLEAN)"}
 			}
 
-			_, exists := hash.Pairs[keyObj.HashKey()]
-			return &Boolean{Value: exists}
+			_, exists := hash.Pairs[key]
+			return NativeBool(exists)
 		}},
 	},
 	{
@@ -1200,7 +1717,7 @@ var Builtins = []BuiltinDef{
 				return &Error{Message: "cannot get code of empty string"}
 			}
 
-			return &Integer{Value: int64(runes[0])}
+			return NewInteger(int64(runes[0]))
 		}},
 	},
 	{
@@ -1222,39 +1739,39 @@ var Builtins = []BuiltinDef{
 		}},
 	},
 	{
-		"সংখ্যা", // parseInt - convert string to integer
+		"সংখ্যা", // parseInt - convert string to a number: (str, [radix])
 		&Builtin{Fn: func(args ...Object) Object {
-			if len(args) != 1 {
-				return &Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+			if len(args) < 1 || len(args) > 2 {
+				return &Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1 or 2", len(args))}
 			}
 			if args[0].Type() != STRING_OBJ {
 				return &Error{Message: "argument to 'সংখ্যা' must be STRING"}
 			}
+			radix, radixErr := radixArg("সংখ্যা", args, 1)
+			if radixErr != nil {
+				return radixErr
+			}
 
-			str := args[0].(*String).Value
-			// Convert Bengali numerals to Arabic numerals
-			str = token.ConvertBengaliNumber(str)
-
-			result, err := strconv.ParseInt(strings.TrimSpace(str), 10, 64)
+			// Bengali numerals, signs, surrounding spaces and (in base 10)
+			// fractions and exponents are accepted
+			result, err := parseNumber(args[0].(*String).Value, radix)
 			if err != nil {
-				return &Error{Message: fmt.Sprintf("cannot parse '%s' as integer: %s", str, err)}
+				return &Error{Message: err.Error()}
 			}
-
-			return &Integer{Value: result}
+			return result
 		}},
 	},
 	{
-		"লেখা", // toString - convert integer to string
-		&Builtin{Fn: func(args ...Object) Object {
+		"লেখা", // toString - convert a value to the text লেখ shows for it
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
 			if len(args) != 1 {
 				return &Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
 			}
-			if args[0].Type() != INTEGER_OBJ {
-				return &Error{Message: "argument to 'লেখা' must be INTEGER"}
+			text, err := DisplayText(rt, args[0])
+			if err != nil {
+				return err
 			}
-
-			num := args[0].(*Integer).Value
-			return &String{Value: fmt.Sprintf("%d", num)}
+			return &String{Value: text}
 		}},
 	},
 	// Type casting functions
@@ -1559,15 +2076,26 @@ func GetBuiltinByName(name string) *Builtin {
 	return nil
 }
 
+// BuiltinIndex returns the position of a builtin in Builtins, which is
+// the operand OpGetBuiltin takes, or -1 if there is no builtin by that name
+func BuiltinIndex(name string) int {
+	for i, def := range Builtins {
+		if def.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
 // jsonToObject converts JSON data to Bhasa objects
 func jsonToObject(data interface{}) Object {
 	switch v := data.(type) {
 	case nil:
-		return &Null{}
+		return NULL
 	case bool:
-		return &Boolean{Value: v}
+		return NativeBool(v)
 	case float64:
-		return &Integer{Value: int64(v)}
+		return NewInteger(int64(v))
 	case string:
 		return &String{Value: v}
 	case []interface{}:
@@ -1585,7 +2113,7 @@ func jsonToObject(data interface{}) Object {
 		}
 		return &Hash{Pairs: pairs}
 	default:
-		return &Null{}
+		return NULL
 	}
 }
 
@@ -1598,6 +2126,18 @@ func objectToJSON(obj Object) interface{} {
 		return o.Value
 	case *Integer:
 		return o.Value
+	case *BigInteger:
+		// *big.Int marshals as a JSON number of any length
+		return o.Value
+	case *Decimal:
+		return json.Number(FormatDecimal(o.Value))
+	case *Float, *Double:
+		// JSON has no NaN or Infinity; write them as null
+		f, _ := floatValue(o)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil
+		}
+		return f
 	case *String:
 		return o.Value
 	case *Array:
@@ -1607,6 +2147,7 @@ func objectToJSON(obj Object) interface{} {
 		}
 		return result
 	case *Hash:
+		// json.Marshal writes map keys in sorted order
 		result := make(map[string]interface{})
 		for _, pair := range o.Pairs {
 			if key, ok := pair.Key.(*String); ok {
@@ -0,0 +1,133 @@
+package object
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// rng is the shared random source used by the এলোমেলো builtins.
+// math/rand sources are not safe for concurrent use, hence the mutex.
+var (
+	rngMu sync.Mutex
+	rng   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// randomBuiltins provide random numbers, shuffling and seeding
+var randomBuiltins = []BuiltinDef{
+	{
+		"এলোমেলো", // random double in [0, 1)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+			rngMu.Lock()
+			defer rngMu.Unlock()
+			return &Double{Value: rng.Float64()}
+		}},
+	},
+	{
+		"এলোমেলো_পূর্ণ", // random integer in [min, max]
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			min, ok1 := integerValue(args[0])
+			max, ok2 := integerValue(args[1])
+			if !ok1 || !ok2 {
+				return newError("arguments to 'এলোমেলো_পূর্ণ' must be INTEGER")
+			}
+			if min > max {
+				return newError("invalid range: %d > %d", min, max)
+			}
+			rngMu.Lock()
+			defer rngMu.Unlock()
+			span := uint64(max) - uint64(min)
+			if span < math.MaxInt64 {
+				return NewInteger(min + rng.Int63n(int64(span)+1))
+			}
+			// The range is too wide for Int63n; a draw is out of range
+			// less than half the time, so redrawing stays cheap and
+			// unbiased. The sum wraps back into [min, max].
+			for {
+				if v := rng.Uint64(); v <= span {
+					return NewInteger(int64(uint64(min) + v))
+				}
+			}
+		}},
+	},
+	{
+		"এলোমেলো_দশমিক", // random double in [min, max)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			min, ok1 := floatValue(args[0])
+			max, ok2 := floatValue(args[1])
+			if !ok1 || !ok2 {
+				return newError("arguments to 'এলোমেলো_দশমিক' must be numeric")
+			}
+			if min > max {
+				return newError("invalid range: %g > %g", min, max)
+			}
+			rngMu.Lock()
+			defer rngMu.Unlock()
+			return &Double{Value: min + rng.Float64()*(max-min)}
+		}},
+	},
+	{
+		"এলোমেলো_বাছাই", // random element of an array
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			arr, ok := args[0].(*Array)
+			if !ok {
+				return newError("argument to 'এলোমেলো_বাছাই' must be ARRAY, got %s", args[0].Type())
+			}
+			if len(arr.Elements) == 0 {
+				return NULL
+			}
+			rngMu.Lock()
+			defer rngMu.Unlock()
+			return arr.Elements[rng.Intn(len(arr.Elements))]
+		}},
+	},
+	{
+		"এলোমেলো_সাজাও", // shuffle - returns a shuffled copy of an array
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			arr, ok := args[0].(*Array)
+			if !ok {
+				return newError("argument to 'এলোমেলো_সাজাও' must be ARRAY, got %s", args[0].Type())
+			}
+			shuffled := make([]Object, len(arr.Elements))
+			copy(shuffled, arr.Elements)
+			rngMu.Lock()
+			defer rngMu.Unlock()
+			rng.Shuffle(len(shuffled), func(i, j int) {
+				shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+			})
+			return &Array{Elements: shuffled}
+		}},
+	},
+	{
+		"এলোমেলো_বীজ", // seed - makes the random sequence reproducible
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			seed, ok := integerValue(args[0])
+			if !ok {
+				return newError("argument to 'এলোমেলো_বীজ' must be INTEGER, got %s", args[0].Type())
+			}
+			rngMu.Lock()
+			defer rngMu.Unlock()
+			rng.Seed(seed)
+			return NULL
+		}},
+	},
+}
@@ -0,0 +1,181 @@
+package object
+
+import (
+	"regexp"
+	"sync"
+)
+
+// maxCachedRegexes bounds regexCache, so a program building a new pattern
+// on every iteration does not grow it without end
+const maxCachedRegexes = 256
+
+// regexCache keeps compiled patterns so loops don't recompile them
+var (
+	regexMu    sync.Mutex
+	regexCache = make(map[string]*regexp.Regexp)
+)
+
+// compileRegex returns the compiled form of pattern, using the cache
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	regexMu.Lock()
+	defer regexMu.Unlock()
+	if re, ok := regexCache[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(regexCache) >= maxCachedRegexes {
+		// Start over rather than track use; a loop over a few patterns
+		// refills the cache at once
+		clear(regexCache)
+	}
+	regexCache[pattern] = re
+	return re, nil
+}
+
+// regexArgs validates the (pattern, text, ...) arguments shared by the
+// রেজেক্স builtins and returns the compiled pattern and text
+func regexArgs(name string, args []Object, want int) (*regexp.Regexp, string, *Error) {
+	if len(args) != want {
+		return nil, "", newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+	}
+	pattern, ok := args[0].(*String)
+	if !ok {
+		return nil, "", newError("first argument to '%s' must be STRING, got %s", name, args[0].Type())
+	}
+	text, ok := args[1].(*String)
+	if !ok {
+		return nil, "", newError("second argument to '%s' must be STRING, got %s", name, args[1].Type())
+	}
+	re, err := compileRegex(pattern.Value)
+	if err != nil {
+		return nil, "", newError("invalid regular expression: %s", err)
+	}
+	return re, text.Value, nil
+}
+
+// groupsToArray converts submatch indices into an array of strings,
+// using null for groups that did not participate in the match
+func groupsToArray(text string, loc []int) *Array {
+	elements := make([]Object, len(loc)/2)
+	for i := range elements {
+		if loc[2*i] < 0 {
+			elements[i] = NULL
+			continue
+		}
+		elements[i] = &String{Value: text[loc[2*i]:loc[2*i+1]]}
+	}
+	return &Array{Elements: elements}
+}
+
+// regexBuiltins provide regular expressions backed by Go's regexp package
+var regexBuiltins = []BuiltinDef{
+	{
+		"রেজেক্স_মেলে", // match - does the pattern occur in the text?
+		&Builtin{Fn: func(args ...Object) Object {
+			re, text, err := regexArgs("রেজেক্স_মেলে", args, 2)
+			if err != nil {
+				return err
+			}
+			return NativeBool(re.MatchString(text))
+		}},
+	},
+	{
+		"রেজেক্স_খোঁজ", // find - first match, or null
+		&Builtin{Fn: func(args ...Object) Object {
+			re, text, err := regexArgs("রেজেক্স_খোঁজ", args, 2)
+			if err != nil {
+				return err
+			}
+			loc := re.FindStringIndex(text)
+			if loc == nil {
+				return NULL
+			}
+			return &String{Value: text[loc[0]:loc[1]]}
+		}},
+	},
+	{
+		"রেজেক্স_সব", // find all - array of every match
+		&Builtin{Fn: func(args ...Object) Object {
+			re, text, err := regexArgs("রেজেক্স_সব", args, 2)
+			if err != nil {
+				return err
+			}
+			matches := re.FindAllString(text, -1)
+			elements := make([]Object, len(matches))
+			for i, m := range matches {
+				elements[i] = &String{Value: m}
+			}
+			return &Array{Elements: elements}
+		}},
+	},
+	{
+		"রেজেক্স_দল", // capture groups of the first match: [whole, group1, ...]
+		&Builtin{Fn: func(args ...Object) Object {
+			re, text, err := regexArgs("রেজেক্স_দল", args, 2)
+			if err != nil {
+				return err
+			}
+			loc := re.FindStringSubmatchIndex(text)
+			if loc == nil {
+				return NULL
+			}
+			return groupsToArray(text, loc)
+		}},
+	},
+	{
+		"রেজেক্স_সব_দল", // capture groups of every match, as an array of arrays
+		&Builtin{Fn: func(args ...Object) Object {
+			re, text, err := regexArgs("রেজেক্স_সব_দল", args, 2)
+			if err != nil {
+				return err
+			}
+			locs := re.FindAllStringSubmatchIndex(text, -1)
+			elements := make([]Object, len(locs))
+			for i, loc := range locs {
+				elements[i] = groupsToArray(text, loc)
+			}
+			return &Array{Elements: elements}
+		}},
+	},
+	{
+		"রেজেক্স_নামযুক্ত_দল", // named groups (?P<name>...) of the first match as a hash
+		&Builtin{Fn: func(args ...Object) Object {
+			re, text, err := regexArgs("রেজেক্স_নামযুক্ত_দল", args, 2)
+			if err != nil {
+				return err
+			}
+			loc := re.FindStringSubmatchIndex(text)
+			if loc == nil {
+				return NULL
+			}
+			groups := groupsToArray(text, loc)
+			hash := &Hash{Pairs: make(map[HashKey]HashPair)}
+			for i, name := range re.SubexpNames() {
+				if name != "" {
+					hash.Set(name, groups.Elements[i])
+				}
+			}
+			return hash
+		}},
+	},
+	{
+		"রেজেক্স_প্রতিস্থাপন", // replace all matches; $1 / ${name} expand groups
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=3", len(args))
+			}
+			re, text, err := regexArgs("রেজেক্স_প্রতিস্থাপন", args[:2], 2)
+			if err != nil {
+				return err
+			}
+			repl, ok := args[2].(*String)
+			if !ok {
+				return newError("third argument to 'রেজেক্স_প্রতিস্থাপন' must be STRING, got %s", args[2].Type())
+			}
+			return &String{Value: re.ReplaceAllString(text, repl.Value)}
+		}},
+	},
+}
@@ -0,0 +1,73 @@
+package object
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// Runtime gives builtins access to the machine that is executing them,
+// so that things like standard input are shared with the host (e.g. the
+// REPL) instead of each builtin opening its own reader.
+type Runtime interface {
+	Stdin() *bufio.Reader
+	Stdout() io.Writer
+	Stderr() io.Writer
+	Args() []string
+	Eval(source string, bindings *Hash) Object
+	CallFunction(fn Object, args ...Object) Object
+	Spawn(fn Object, args ...Object) Object
+	// BengaliNumerals reports whether numbers are shown with Bengali
+	// digits; see DisplayText
+	BengaliNumerals() bool
+}
+
+// RuntimeFunction is a builtin that needs the executing runtime
+type RuntimeFunction func(rt Runtime, args ...Object) Object
+
+// stdRuntime is the process-wide runtime backed by the standard streams
+type stdRuntime struct{}
+
+// stdin is shared so buffered input is not lost between readers
+var stdin = bufio.NewReader(os.Stdin)
+
+func (stdRuntime) Stdin() *bufio.Reader  { return stdin }
+func (stdRuntime) Stdout() io.Writer     { return os.Stdout }
+func (stdRuntime) Stderr() io.Writer     { return os.Stderr }
+func (stdRuntime) Args() []string        { return nil }
+func (stdRuntime) BengaliNumerals() bool { return false }
+
+func (stdRuntime) Eval(source string, bindings *Hash) Object {
+	return newError("'মূল্যায়ন' is only available when running on the VM")
+}
+
+func (stdRuntime) CallFunction(fn Object, args ...Object) Object {
+	if builtin, ok := fn.(*Builtin); ok {
+		return builtin.Call(DefaultRuntime, args...)
+	}
+	return newError("calling %s from a builtin is only available when running on the VM", fn.Type())
+}
+
+func (stdRuntime) Spawn(fn Object, args ...Object) Object {
+	if builtin, ok := fn.(*Builtin); ok {
+		return StartTask(func() Object { return builtin.Call(DefaultRuntime, args...) })
+	}
+	return newError("running %s concurrently is only available when running on the VM", fn.Type())
+}
+
+// DefaultRuntime is used when a builtin is called outside of a VM
+var DefaultRuntime Runtime = stdRuntime{}
+
+// DefaultStdin returns the shared buffered reader over os.Stdin
+func DefaultStdin() *bufio.Reader { return stdin }
+
+// Call invokes the builtin, passing rt to runtime-aware builtins
+func (b *Builtin) Call(rt Runtime, args ...Object) Object {
+	if b.RuntimeFn != nil {
+		if rt == nil {
+			rt = DefaultRuntime
+		}
+		return b.RuntimeFn(rt, args...)
+	}
+	return b.Fn(args...)
+}
@@ -0,0 +1,149 @@
+package object
+
+import "sort"
+
+// compareValues orders a and b, using the comparator cmp when it is not
+// nil. A comparator returns a negative number, zero or a positive number
+// when a sorts before, equal to or after b. Without one, numbers compare
+// by value, exactly when both are integers, and strings lexically.
+func compareValues(rt Runtime, cmp Object, a, b Object) (int, *Error) {
+	if cmp != nil {
+		result := rt.CallFunction(cmp, a, b)
+		if err, ok := result.(*Error); ok {
+			return 0, err
+		}
+		n, ok := integerValue(result)
+		if !ok {
+			return 0, newError("comparator must return INTEGER, got %s", result.Type())
+		}
+		switch {
+		case n < 0:
+			return -1, nil
+		case n > 0:
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	// Two integers compare exactly; converting them to float64 would make
+	// values above 2^53 that differ compare equal
+	if x, ok := integerValue(a); ok {
+		if y, ok := integerValue(b); ok {
+			switch {
+			case x < y:
+				return -1, nil
+			case x > y:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	if x, ok := floatValue(a); ok {
+		if y, ok := floatValue(b); ok {
+			switch {
+			case x < y:
+				return -1, nil
+			case x > y:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	if x, ok := a.(*String); ok {
+		if y, ok := b.(*String); ok {
+			switch {
+			case x.Value < y.Value:
+				return -1, nil
+			case x.Value > y.Value:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	return 0, newError("cannot compare %s with %s without a comparator", a.Type(), b.Type())
+}
+
+// sortedArgs reads (arr, value, [comparator]) for the sorted array builtins
+func sortedArgs(name string, args []Object) (*Array, Object, *Error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, nil, newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+	}
+	arr, err := arrayArg(name, args, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(args) == 2 {
+		return arr, nil, nil
+	}
+	if args[2].Type() != CLOSURE_OBJ && args[2].Type() != BUILTIN_OBJ {
+		return nil, nil, newError("comparator for '%s' must be FUNCTION, got %s", name, args[2].Type())
+	}
+	return arr, args[2], nil
+}
+
+// searchSorted returns the first position in the sorted array at which
+// value could be inserted: before equal elements when after is false,
+// after them when it is true
+func searchSorted(rt Runtime, arr *Array, value, cmp Object, after bool) (int, *Error) {
+	var failure *Error
+	pos := sort.Search(len(arr.Elements), func(i int) bool {
+		if failure != nil {
+			return true
+		}
+		c, err := compareValues(rt, cmp, arr.Elements[i], value)
+		if err != nil {
+			failure = err
+			return true
+		}
+		if after {
+			return c > 0
+		}
+		return c >= 0
+	})
+	return pos, failure
+}
+
+// searchBuiltins work on arrays kept in ascending order
+var searchBuiltins = []BuiltinDef{
+	{
+		"দ্বিখণ্ড_খোঁজ", // binary search: (sorted arr, value, [comparator]) -> index or -1
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			arr, cmp, err := sortedArgs("দ্বিখণ্ড_খোঁজ", args)
+			if err != nil {
+				return err
+			}
+			pos, err := searchSorted(rt, arr, args[1], cmp, false)
+			if err != nil {
+				return err
+			}
+			if pos < len(arr.Elements) {
+				c, err := compareValues(rt, cmp, arr.Elements[pos], args[1])
+				if err != nil {
+					return err
+				}
+				if c == 0 {
+					return NewInteger(int64(pos))
+				}
+			}
+			return NewInteger(-1)
+		}},
+	},
+	{
+		"সাজানো_সন্নিবেশ", // insert keeping order: (sorted arr, value, [comparator])
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			arr, cmp, err := sortedArgs("সাজানো_সন্নিবেশ", args)
+			if err != nil {
+				return err
+			}
+			pos, err := searchSorted(rt, arr, args[1], cmp, true)
+			if err != nil {
+				return err
+			}
+			elements := make([]Object, 0, len(arr.Elements)+1)
+			elements = append(elements, arr.Elements[:pos]...)
+			elements = append(elements, args[1])
+			elements = append(elements, arr.Elements[pos:]...)
+			return &Array{Elements: elements}
+		}},
+	},
+}
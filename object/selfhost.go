@@ -0,0 +1,106 @@
+package object
+
+import (
+	"bhasa/ast"
+	"bhasa/lexer"
+	"bhasa/parser"
+	"bhasa/token"
+	"sort"
+	"strings"
+)
+
+// nativeToObject converts plain Go values (as produced by ast.ToMap) into
+// Bhasa objects
+func nativeToObject(v interface{}) Object {
+	switch v := v.(type) {
+	case int64:
+		return NewInteger(v)
+	case []interface{}:
+		elements := make([]Object, len(v))
+		for i, item := range v {
+			elements[i] = nativeToObject(item)
+		}
+		return &Array{Elements: elements}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		hash := &Hash{Pairs: make(map[HashKey]HashPair, len(v))}
+		for _, key := range keys {
+			hash.Set(key, nativeToObject(v[key]))
+		}
+		return hash
+	default:
+		return jsonToObject(v)
+	}
+}
+
+// selfHostBuiltins expose the lexer, parser and compiler to Bhasa programs
+var selfHostBuiltins = []BuiltinDef{
+	{
+		"টোকেন_করো", // tokenize - array of {type, literal, line, column}
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			src, ok := args[0].(*String)
+			if !ok {
+				return newError("argument to 'টোকেন_করো' must be STRING, got %s", args[0].Type())
+			}
+			l := lexer.New(src.Value)
+			elements := []Object{}
+			for {
+				tok := l.NextToken()
+				hash := &Hash{Pairs: make(map[HashKey]HashPair)}
+				hash.Set("type", &String{Value: string(tok.Type)})
+				hash.Set("literal", &String{Value: tok.Literal})
+				hash.Set("line", NewInteger(int64(tok.Line)))
+				hash.Set("column", NewInteger(int64(tok.Column)))
+				elements = append(elements, hash)
+				if tok.Type == token.EOF {
+					break
+				}
+			}
+			return &Array{Elements: elements}
+		}},
+	},
+	{
+		"পার্স_করো", // parse - the program's AST as nested hashes
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			src, ok := args[0].(*String)
+			if !ok {
+				return newError("argument to 'পার্স_করো' must be STRING, got %s", args[0].Type())
+			}
+			p := parser.New(lexer.New(src.Value))
+			program := p.ParseProgram()
+			if len(p.Errors()) != 0 {
+				return newError("parser errors: %s", strings.Join(p.Errors(), "; "))
+			}
+			return nativeToObject(ast.ToMap(program))
+		}},
+	},
+	{
+		"মূল্যায়ন", // eval - run source in an isolated child VM: (source, [bindings])
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			src, ok := args[0].(*String)
+			if !ok {
+				return newError("first argument to 'মূল্যায়ন' must be STRING, got %s", args[0].Type())
+			}
+			var bindings *Hash
+			if len(args) == 2 {
+				if bindings, ok = args[1].(*Hash); !ok {
+					return newError("second argument to 'মূল্যায়ন' must be HASH, got %s", args[1].Type())
+				}
+			}
+			return rt.Eval(src.Value, bindings)
+		}},
+	},
+}
@@ -0,0 +1,252 @@
+package object
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// route is one entry of the routes hash passed to সার্ভার_চালাও. Its key
+// is "METHOD /path" or just "/path" for any method; path segments written
+// as {name} match any single segment and are passed to the handler.
+type route struct {
+	method   string
+	segments []string
+	handler  Object
+	wildcard int // number of {name} segments
+}
+
+// parseRoutes converts the routes hash into routes, literal paths first
+func parseRoutes(routes *Hash) ([]*route, *Error) {
+	var result []*route
+	for _, pair := range routes.Pairs {
+		key, ok := pair.Key.(*String)
+		if !ok {
+			return nil, newError("route keys must be STRING, got %s", pair.Key.Type())
+		}
+		if pair.Value.Type() != CLOSURE_OBJ && pair.Value.Type() != BUILTIN_OBJ {
+			return nil, newError("handler for route %q must be FUNCTION, got %s", key.Value, pair.Value.Type())
+		}
+
+		r := &route{handler: pair.Value}
+		path := strings.TrimSpace(key.Value)
+		if fields := strings.Fields(path); len(fields) == 2 {
+			r.method = strings.ToUpper(fields[0])
+			path = fields[1]
+		}
+		if !strings.HasPrefix(path, "/") {
+			return nil, newError("route %q must start with a path such as \"/\"", key.Value)
+		}
+		r.segments = strings.Split(strings.Trim(path, "/"), "/")
+		for _, seg := range r.segments {
+			if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+				r.wildcard++
+			}
+		}
+		result = append(result, r)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].wildcard != result[j].wildcard {
+			return result[i].wildcard < result[j].wildcard
+		}
+		return strings.Join(result[i].segments, "/") < strings.Join(result[j].segments, "/")
+	})
+	return result, nil
+}
+
+// match reports whether the route matches path, returning its parameters
+func (r *route) match(path string) (*Hash, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) != len(r.segments) {
+		return nil, false
+	}
+	params := &Hash{Pairs: make(map[HashKey]HashPair)}
+	for i, seg := range r.segments {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			params.Set(seg[1:len(seg)-1], &String{Value: segments[i]})
+		} else if seg != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// requestObject converts a request into the hash passed to handlers:
+// {"মেথড", "পথ", "কোয়েরি", "হেডার", "প্যারামিটার", "বডি"}
+func requestObject(req *http.Request, params *Hash) (Object, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &Hash{Pairs: make(map[HashKey]HashPair)}
+	for name, values := range req.URL.Query() {
+		query.Set(name, &String{Value: values[0]})
+	}
+	headers := &Hash{Pairs: make(map[HashKey]HashPair)}
+	for name, values := range req.Header {
+		headers.Set(name, &String{Value: strings.Join(values, ", ")})
+	}
+
+	result := &Hash{Pairs: make(map[HashKey]HashPair)}
+	result.Set("মেথড", &String{Value: req.Method})
+	result.Set("পথ", &String{Value: req.URL.Path})
+	result.Set("কোয়েরি", query)
+	result.Set("হেডার", headers)
+	result.Set("প্যারামিটার", params)
+	result.Set("বডি", &String{Value: string(body)})
+	return result, nil
+}
+
+// writeResponse sends a handler's result. A hash may set "স্ট্যাটাস",
+// "হেডার" and "বডি"; anything else becomes a 200 response body.
+func writeResponse(rt Runtime, w http.ResponseWriter, result Object) {
+	status := http.StatusOK
+	var body Object = result
+
+	switch result := result.(type) {
+	case *Error:
+		fmt.Fprintf(rt.Stderr(), "handler error: %s\n", result.Message)
+		http.Error(w, result.Message, http.StatusInternalServerError)
+		return
+	case *Null:
+		body = &String{}
+	case *Hash:
+		body = &String{}
+		for _, pair := range result.Pairs {
+			switch objectText(pair.Key) {
+			case "স্ট্যাটাস":
+				if n, ok := integerValue(pair.Value); ok {
+					status = int(n)
+				}
+			case "হেডার":
+				if headers, ok := pair.Value.(*Hash); ok {
+					for _, h := range headers.Pairs {
+						w.Header().Set(objectText(h.Key), objectText(h.Value))
+					}
+				}
+			case "বডি":
+				body = pair.Value
+			}
+		}
+	}
+
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.WriteHeader(status)
+	io.WriteString(w, objectText(body))
+}
+
+// serverStops holds one channel per running server; সার্ভার_থামাও closes
+// them all
+var serverStops struct {
+	sync.Mutex
+	chans []chan struct{}
+}
+
+// serve runs an HTTP server until it fails or সার্ভার_থামাও is called.
+// Handlers share the caller's globals, so they are run one at a time.
+func serve(rt Runtime, addr string, routes []*route) Object {
+	var mu sync.Mutex
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		methodAllowed := true
+		for _, r := range routes {
+			params, ok := r.match(req.URL.Path)
+			if !ok {
+				continue
+			}
+			if r.method != "" && r.method != req.Method {
+				methodAllowed = false
+				continue
+			}
+			request, err := requestObject(req, params)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			mu.Lock()
+			result := rt.CallFunction(r.handler, request)
+			mu.Unlock()
+			writeResponse(rt, w, result)
+			return
+		}
+		if !methodAllowed {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		http.NotFound(w, req)
+	})
+
+	stop := make(chan struct{})
+	serverStops.Lock()
+	serverStops.chans = append(serverStops.chans, stop)
+	serverStops.Unlock()
+
+	srv := &http.Server{Addr: addr, Handler: handler}
+	errs := make(chan error, 1)
+	go func() { errs <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-errs:
+		return newError("HTTP server failed: %s", err)
+	case <-stop:
+		// Let the handler that asked to stop finish its response
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+		mu.Lock()
+		mu.Unlock()
+		return nil
+	}
+}
+
+// httpServerBuiltins serve HTTP requests with Bhasa handlers
+var httpServerBuiltins = []BuiltinDef{
+	{
+		"সার্ভার_চালাও", // serve HTTP: (address or port, {"GET /path": handler, ...})
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			var addr string
+			if port, ok := args[0].(*Integer); ok {
+				addr = fmt.Sprintf(":%d", port.Value)
+			} else if str, ok := args[0].(*String); ok {
+				addr = str.Value
+			} else {
+				return newError("first argument to 'সার্ভার_চালাও' must be STRING or INTEGER, got %s", args[0].Type())
+			}
+			hash, ok := args[1].(*Hash)
+			if !ok {
+				return newError("second argument to 'সার্ভার_চালাও' must be HASH, got %s", args[1].Type())
+			}
+			routes, err := parseRoutes(hash)
+			if err != nil {
+				return err
+			}
+			return serve(rt, addr, routes)
+		}},
+	},
+	{
+		"সার্ভার_থামাও", // stop every server started by সার্ভার_চালাও
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+			serverStops.Lock()
+			for _, stop := range serverStops.chans {
+				close(stop)
+			}
+			serverStops.chans = nil
+			serverStops.Unlock()
+			return nil
+		}},
+	},
+}
@@ -0,0 +1,207 @@
+package object
+
+import (
+	"sort"
+	"strings"
+)
+
+// Set is an unordered collection of distinct hashable values created by
+// সেট. Adding and removing change the set in place; union, intersection
+// and difference build new sets.
+type Set struct {
+	Elements map[HashKey]Object
+}
+
+func (s *Set) Type() ObjectType { return SET_OBJ }
+func (s *Set) Inspect() string {
+	parts := make([]string, 0, len(s.Elements))
+	for _, el := range s.Sorted() {
+		parts = append(parts, el.Inspect())
+	}
+	return "সেট{" + strings.Join(parts, ", ") + "}"
+}
+
+// NewSet returns an empty set
+func NewSet() *Set {
+	return &Set{Elements: make(map[HashKey]Object)}
+}
+
+// Add inserts value, which must be hashable
+func (s *Set) Add(value Object) *Error {
+	key, ok := HashKeyOf(value)
+	if !ok {
+		return newError("unusable as set element: %s", value.Type())
+	}
+	s.Elements[key] = value
+	return nil
+}
+
+// Contains reports whether value is in the set
+func (s *Set) Contains(value Object) bool {
+	key, ok := HashKeyOf(value)
+	if !ok {
+		return false
+	}
+	_, found := s.Elements[key]
+	return found
+}
+
+// Sorted returns the elements in the order used for hash keys
+func (s *Set) Sorted() []Object {
+	elements := make([]Object, 0, len(s.Elements))
+	for _, el := range s.Elements {
+		elements = append(elements, el)
+	}
+	sort.Slice(elements, func(i, j int) bool {
+		return keyLess(elements[i], elements[j])
+	})
+	return elements
+}
+
+// setArg returns args[i] as a set
+func setArg(name string, args []Object, i int) (*Set, *Error) {
+	s, ok := args[i].(*Set)
+	if !ok {
+		return nil, newError("argument %d to '%s' must be SET, got %s", i+1, name, args[i].Type())
+	}
+	return s, nil
+}
+
+// setOperation builds a builtin combining two sets; keep decides which
+// elements of the first set, and whether the elements of the second, go
+// into the result
+func setOperation(name string, keep func(inA, inB bool) bool) *Builtin {
+	return &Builtin{Fn: func(args ...Object) Object {
+		if len(args) != 2 {
+			return newError("wrong number of arguments. got=%d, want=2", len(args))
+		}
+		a, err := setArg(name, args, 0)
+		if err != nil {
+			return err
+		}
+		b, err := setArg(name, args, 1)
+		if err != nil {
+			return err
+		}
+		result := NewSet()
+		for key, el := range a.Elements {
+			if _, inB := b.Elements[key]; keep(true, inB) {
+				result.Elements[key] = el
+			}
+		}
+		for key, el := range b.Elements {
+			if _, inA := a.Elements[key]; !inA && keep(false, true) {
+				result.Elements[key] = el
+			}
+		}
+		return result
+	}}
+}
+
+// setBuiltins create and combine sets
+var setBuiltins = []BuiltinDef{
+	{
+		"সেট", // new set: ([array of elements])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+			}
+			s := NewSet()
+			if len(args) == 1 {
+				arr, err := arrayArg("সেট", args, 0)
+				if err != nil {
+					return err
+				}
+				for _, el := range arr.Elements {
+					if err := s.Add(el); err != nil {
+						return err
+					}
+				}
+			}
+			return s
+		}},
+	},
+	{
+		"সেট_যোগ", // add an element: (set, value)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			s, err := setArg("সেট_যোগ", args, 0)
+			if err != nil {
+				return err
+			}
+			if err := s.Add(args[1]); err != nil {
+				return err
+			}
+			return s
+		}},
+	},
+	{
+		"সেট_সরাও", // remove an element if present: (set, value)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			s, err := setArg("সেট_সরাও", args, 0)
+			if err != nil {
+				return err
+			}
+			if key, ok := HashKeyOf(args[1]); ok {
+				delete(s.Elements, key)
+			}
+			return s
+		}},
+	},
+	{
+		"সেট_আছে", // membership: (set, value)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			s, err := setArg("সেট_আছে", args, 0)
+			if err != nil {
+				return err
+			}
+			return NativeBool(s.Contains(args[1]))
+		}},
+	},
+	{
+		"সেট_আকার", // number of elements
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			s, err := setArg("সেট_আকার", args, 0)
+			if err != nil {
+				return err
+			}
+			return NewInteger(int64(len(s.Elements)))
+		}},
+	},
+	{
+		"সেট_তালিকা", // elements as a sorted array
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			s, err := setArg("সেট_তালিকা", args, 0)
+			if err != nil {
+				return err
+			}
+			return &Array{Elements: s.Sorted()}
+		}},
+	},
+	{
+		"সেট_মিলন", // union
+		setOperation("সেট_মিলন", func(inA, inB bool) bool { return true }),
+	},
+	{
+		"সেট_ছেদ", // intersection
+		setOperation("সেট_ছেদ", func(inA, inB bool) bool { return inA && inB }),
+	},
+	{
+		"সেট_পার্থক্য", // difference: elements of the first set not in the second
+		setOperation("সেট_পার্থক্য", func(inA, inB bool) bool { return inA && !inB }),
+	},
+}
@@ -0,0 +1,96 @@
+package object
+
+// ResolveIndex converts an index that may count back from the end, as -1
+// does for the last element, to a position in a sequence of the given
+// length. It reports false when the position is out of range.
+func ResolveIndex(index int64, length int) (int, bool) {
+	if index < 0 {
+		index += int64(length)
+	}
+	if index < 0 || index >= int64(length) {
+		return 0, false
+	}
+	return int(index), true
+}
+
+// IndexString returns the character of str at index as a string
+func IndexString(str *String, index int64) (Object, bool) {
+	runes := []rune(str.Value)
+	i, ok := ResolveIndex(index, len(runes))
+	if !ok {
+		return nil, false
+	}
+	return &String{Value: string(runes[i])}, true
+}
+
+// sliceBound converts an optional slice bound to an index in [0, length],
+// using def when the bound is null. Negative bounds count from the end.
+func sliceBound(bound Object, def, length int) (int, *Error) {
+	if _, ok := bound.(*Null); ok {
+		return def, nil
+	}
+	n, ok := integerValue(bound)
+	if !ok {
+		return 0, newError("slice index must be INTEGER, got %s", bound.Type())
+	}
+	if n < 0 {
+		n += int64(length)
+	}
+	if n < 0 {
+		return 0, nil
+	}
+	if n > int64(length) {
+		return length, nil
+	}
+	return int(n), nil
+}
+
+// sliceRange resolves start and end against a sequence of the given
+// length; out-of-range bounds are clamped and an empty range is returned
+// when start is past end
+func sliceRange(start, end Object, length int) (int, int, *Error) {
+	from, err := sliceBound(start, 0, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := sliceBound(end, length, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	if from > to {
+		from = to
+	}
+	return from, to, nil
+}
+
+// Slice returns the elements of an array or byte array, or the characters
+// of a string, from start up to but not including end. Null bounds mean
+// the start or end of the sequence. Slicing copies, so the result never
+// aliases left; a slice of a mutable array is itself mutable.
+func Slice(left, start, end Object) Object {
+	switch left := left.(type) {
+	case *Array:
+		from, to, err := sliceRange(start, end, len(left.Elements))
+		if err != nil {
+			return err
+		}
+		elements := make([]Object, to-from)
+		copy(elements, left.Elements[from:to])
+		return &Array{Elements: elements, Mutable: left.Mutable}
+	case *String:
+		runes := []rune(left.Value)
+		from, to, err := sliceRange(start, end, len(runes))
+		if err != nil {
+			return err
+		}
+		return &String{Value: string(runes[from:to])}
+	case *Bytes:
+		from, to, err := sliceRange(start, end, len(left.Value))
+		if err != nil {
+			return err
+		}
+		return &Bytes{Value: append([]byte(nil), left.Value[from:to]...)}
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+}
@@ -0,0 +1,137 @@
+package object
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// envBuiltins read and modify environment variables
+var envBuiltins = []BuiltinDef{
+	{
+		"পরিবেশ_পাও", // get an environment variable: (name, [default])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			name, ok := args[0].(*String)
+			if !ok {
+				return newError("first argument to 'পরিবেশ_পাও' must be STRING, got %s", args[0].Type())
+			}
+			if value, found := os.LookupEnv(name.Value); found {
+				return &String{Value: value}
+			}
+			if len(args) == 2 {
+				return args[1]
+			}
+			return NULL
+		}},
+	},
+	{
+		"পরিবেশ_সেট", // set an environment variable
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			name, ok := args[0].(*String)
+			if !ok {
+				return newError("first argument to 'পরিবেশ_সেট' must be STRING, got %s", args[0].Type())
+			}
+			value := objectText(args[1])
+			if err := os.Setenv(name.Value, value); err != nil {
+				return newError("cannot set environment variable %s: %s", name.Value, err)
+			}
+			return NULL
+		}},
+	},
+	{
+		"পরিবেশ_মুছো", // unset an environment variable
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			name, ok := args[0].(*String)
+			if !ok {
+				return newError("argument to 'পরিবেশ_মুছো' must be STRING, got %s", args[0].Type())
+			}
+			if err := os.Unsetenv(name.Value); err != nil {
+				return newError("cannot unset environment variable %s: %s", name.Value, err)
+			}
+			return NULL
+		}},
+	},
+	{
+		"পরিবেশ_তালিকা", // all environment variables as a hash
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+			hash := &Hash{Pairs: make(map[HashKey]HashPair)}
+			for _, entry := range os.Environ() {
+				name, value, _ := strings.Cut(entry, "=")
+				hash.Set(name, &String{Value: value})
+			}
+			return hash
+		}},
+	},
+}
+
+// shellCommand builds a command that runs line through the system shell
+func shellCommand(line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", line)
+	}
+	return exec.Command("sh", "-c", line)
+}
+
+// processBuiltins run external commands
+var processBuiltins = []BuiltinDef{
+	{
+		"চালাও", // run a command: (shell line) or (program, [args])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			program, ok := args[0].(*String)
+			if !ok {
+				return newError("first argument to 'চালাও' must be STRING, got %s", args[0].Type())
+			}
+
+			var cmd *exec.Cmd
+			if len(args) == 1 {
+				cmd = shellCommand(program.Value)
+			} else {
+				arr, ok := args[1].(*Array)
+				if !ok {
+					return newError("second argument to 'চালাও' must be ARRAY, got %s", args[1].Type())
+				}
+				cmdArgs := make([]string, len(arr.Elements))
+				for i, el := range arr.Elements {
+					cmdArgs[i] = objectText(el)
+				}
+				cmd = exec.Command(program.Value, cmdArgs...)
+			}
+
+			var stdout, stderr bytes.Buffer
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+			exitCode := 0
+			if err := cmd.Run(); err != nil {
+				var exitErr *exec.ExitError
+				if !errors.As(err, &exitErr) {
+					return newError("cannot run command: %s", err)
+				}
+				exitCode = exitErr.ExitCode()
+			}
+
+			result := &Hash{Pairs: make(map[HashKey]HashPair)}
+			result.Set("আউটপুট", &String{Value: stdout.String()})
+			result.Set("ত্রুটি", &String{Value: stderr.String()})
+			result.Set("কোড", NewInteger(int64(exitCode)))
+			return result
+		}},
+	},
+}
@@ -0,0 +1,115 @@
+package object
+
+// Task is a function running concurrently, started by সমান্তরাল. Joining
+// it with অপেক্ষা blocks until the function returns and gives its result;
+// a task that failed gives its error as a value.
+type Task struct {
+	done   chan struct{}
+	result Object
+}
+
+// StartTask runs fn on a new goroutine and returns the task that joins it
+func StartTask(fn func() Object) *Task {
+	t := &Task{done: make(chan struct{})}
+	go func() {
+		defer close(t.done)
+		t.result = fn()
+		if t.result == nil {
+			t.result = NULL
+		}
+	}()
+	return t
+}
+
+func (t *Task) Type() ObjectType { return TASK_OBJ }
+func (t *Task) Inspect() string {
+	if t.Finished() {
+		return "কাজ(শেষ)"
+	}
+	return "কাজ(চলছে)"
+}
+
+// Wait blocks until the task finishes and returns its result
+func (t *Task) Wait() Object {
+	<-t.done
+	return t.result
+}
+
+// Finished reports whether the task has returned, without blocking
+func (t *Task) Finished() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// taskArg checks that args[0] is a task
+func taskArg(name string, args []Object) (*Task, *Error) {
+	t, ok := args[0].(*Task)
+	if !ok {
+		return nil, newError("argument to '%s' must be TASK, got %s", name, args[0].Type())
+	}
+	return t, nil
+}
+
+// taskBuiltins start functions concurrently and join them. Each task runs
+// on its own VM with its own stack; globals, arrays, hashes and captured
+// variables reachable from both sides are shared, so a task changing one
+// another task uses should hold a lock.
+var taskBuiltins = []BuiltinDef{
+	{
+		"সমান্তরাল", // start fn concurrently: (fn, args...)
+		&Builtin{RuntimeFn: func(rt Runtime, args ...Object) Object {
+			if len(args) < 1 {
+				return newError("wrong number of arguments. got=%d, want at least 1", len(args))
+			}
+			if args[0].Type() != CLOSURE_OBJ && args[0].Type() != BUILTIN_OBJ {
+				return newError("first argument to 'সমান্তরাল' must be FUNCTION, got %s", args[0].Type())
+			}
+			// The arguments may live on the caller's stack, which it
+			// reuses as soon as this returns, so the task gets a copy
+			rest := append([]Object(nil), args[1:]...)
+			return rt.Spawn(args[0], rest...)
+		}},
+	},
+	{
+		"অপেক্ষা", // join: (task) gives its result, (array of tasks) an array of results
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			arr, ok := args[0].(*Array)
+			if !ok {
+				t, err := taskArg("অপেক্ষা", args)
+				if err != nil {
+					return err
+				}
+				return t.Wait()
+			}
+			results := make([]Object, len(arr.Elements))
+			for i, el := range arr.Elements {
+				t, ok := el.(*Task)
+				if !ok {
+					return newError("elements of the array passed to 'অপেক্ষা' must be TASK, got %s", el.Type())
+				}
+				results[i] = t.Wait()
+			}
+			return &Array{Elements: results}
+		}},
+	},
+	{
+		"কাজ_শেষ", // whether the task has finished, without waiting: (task)
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			t, err := taskArg("কাজ_শেষ", args)
+			if err != nil {
+				return err
+			}
+			return NativeBool(t.Finished())
+		}},
+	},
+}
@@ -0,0 +1,257 @@
+package object
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stringArray wraps Go strings in an Array
+func stringArray(parts []string) *Array {
+	elements := make([]Object, len(parts))
+	for i, part := range parts {
+		elements[i] = &String{Value: part}
+	}
+	return &Array{Elements: elements}
+}
+
+// splitLimit reads the optional maximum number of parts at args[i]; zero,
+// negative or missing means no limit, as -1 does for strings.SplitN
+func splitLimit(name string, args []Object, i int) (int, *Error) {
+	if len(args) <= i {
+		return -1, nil
+	}
+	n, ok := integerValue(args[i])
+	if !ok {
+		return 0, newError("limit argument to '%s' must be INTEGER, got %s", name, args[i].Type())
+	}
+	if n <= 0 {
+		return -1, nil
+	}
+	return int(n), nil
+}
+
+// trimArgs reads the arguments of the trim builtins: a string and an
+// optional cutset of characters to remove instead of white space
+func trimArgs(name string, args []Object) (string, string, bool, *Error) {
+	if len(args) < 1 || len(args) > 2 {
+		return "", "", false, newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	str, err := stringArg(name, args, 0)
+	if err != nil {
+		return "", "", false, err
+	}
+	if len(args) == 1 {
+		return str, "", false, nil
+	}
+	cutset, err := stringArg(name, args, 1)
+	if err != nil {
+		return "", "", false, err
+	}
+	return str, cutset, true, nil
+}
+
+// zeroWidthRemover strips the invisible characters web text often has
+// between the parts of a Bengali letter
+var zeroWidthRemover = strings.NewReplacer(
+	"\u200B", "", // zero width space
+	"\u200C", "", // zero width non-joiner
+	"\u200D", "", // zero width joiner
+	"\u2060", "", // word joiner
+	"\uFEFF", "", // zero width no-break space
+)
+
+// bengaliComposer spells the letters that have two encodings one way: the
+// two-part vowel signs ো and ৌ as single code points, and ড়, ঢ় and য় as a
+// consonant plus nukta, as NFC does. It is not a full NFC implementation:
+// nothing outside these letters is composed and marks are not reordered.
+var bengaliComposer = strings.NewReplacer(
+	"\u09C7\u09BE", "\u09CB", // ে + া = ো
+	"\u09C7\u09D7", "\u09CC", // ে + ৗ = ৌ
+	"\u09DC", "\u09A1\u09BC", // ড়
+	"\u09DD", "\u09A2\u09BC", // ঢ়
+	"\u09DF", "\u09AF\u09BC", // য়
+)
+
+// NormalizeBengali removes zero-width characters from str and then
+// composes the Bengali vowel signs and nukta letters bengaliComposer
+// knows, so text that looks the same compares equal. Removing first lets
+// a sign split by a joiner, like ে + ZWJ + া, compose too.
+func NormalizeBengali(str string) string {
+	return bengaliComposer.Replace(zeroWidthRemover.Replace(str))
+}
+
+// matchArgs reads the arguments of the substring predicates: a string, the
+// text to look for and an optional flag asking for both to be normalized
+func matchArgs(name string, args []Object) (string, string, *Error) {
+	if len(args) < 2 || len(args) > 3 {
+		return "", "", newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+	}
+	str, err := stringArg(name, args, 0)
+	if err != nil {
+		return "", "", err
+	}
+	sub, err := stringArg(name, args, 1)
+	if err != nil {
+		return "", "", err
+	}
+	if len(args) == 3 {
+		normalize, ok := args[2].(*Boolean)
+		if !ok {
+			return "", "", newError("third argument to '%s' must be BOOLEAN, got %s", name, args[2].Type())
+		}
+		if normalize.Value {
+			str, sub = NormalizeBengali(str), NormalizeBengali(sub)
+		}
+	}
+	return str, sub, nil
+}
+
+// splitLines splits text into lines without their \n or \r\n endings. A
+// final line ending does not start another (empty) line.
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// textBuiltins work on the characters of strings
+var textBuiltins = []BuiltinDef{
+	{
+		"উপলেখা", // substring: (str, start, [end]), like str[start:end]
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) < 2 || len(args) > 3 {
+				return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+			}
+			if _, ok := args[0].(*String); !ok {
+				return newError("first argument to 'উপলেখা' must be STRING, got %s", args[0].Type())
+			}
+			var end Object = NULL
+			if len(args) == 3 {
+				end = args[2]
+			}
+			return Slice(args[0], args[1], end)
+		}},
+	},
+	{
+		"রেজেক্স_বিভক্ত", // split around regex matches: (pattern, str, [limit])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) < 2 || len(args) > 3 {
+				return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+			}
+			re, text, err := regexArgs("রেজেক্স_বিভক্ত", args[:2], 2)
+			if err != nil {
+				return err
+			}
+			limit, err := splitLimit("রেজেক্স_বিভক্ত", args, 2)
+			if err != nil {
+				return err
+			}
+			return stringArray(re.Split(text, limit))
+		}},
+	},
+	{
+		"লাইনগুলো", // split into lines, accepting \n and \r\n endings
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			str, ok := args[0].(*String)
+			if !ok {
+				return newError("argument to 'লাইনগুলো' must be STRING, got %s", args[0].Type())
+			}
+			return stringArray(splitLines(str.Value))
+		}},
+	},
+	{
+		"বাম_ছাঁটো", // trim the start: (str, [cutset])
+		&Builtin{Fn: func(args ...Object) Object {
+			str, cutset, hasCutset, err := trimArgs("বাম_ছাঁটো", args)
+			if err != nil {
+				return err
+			}
+			if hasCutset {
+				return &String{Value: strings.TrimLeft(str, cutset)}
+			}
+			return &String{Value: strings.TrimLeftFunc(str, unicode.IsSpace)}
+		}},
+	},
+	{
+		"ডান_ছাঁটো", // trim the end: (str, [cutset])
+		&Builtin{Fn: func(args ...Object) Object {
+			str, cutset, hasCutset, err := trimArgs("ডান_ছাঁটো", args)
+			if err != nil {
+				return err
+			}
+			if hasCutset {
+				return &String{Value: strings.TrimRight(str, cutset)}
+			}
+			return &String{Value: strings.TrimRightFunc(str, unicode.IsSpace)}
+		}},
+	},
+	{
+		"ফাঁকা_সংকোচন", // trim and collapse each run of white space to one space
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			str, err := stringArg("ফাঁকা_সংকোচন", args, 0)
+			if err != nil {
+				return err
+			}
+			return &String{Value: strings.Join(strings.Fields(str), " ")}
+		}},
+	},
+}
+
+// matchBuiltins test for substrings, optionally after normalizing Bengali
+// text with NormalizeBengali
+var matchBuiltins = []BuiltinDef{
+	{
+		"ধারণ_করে", // contains: (str, sub, [normalize])
+		&Builtin{Fn: func(args ...Object) Object {
+			str, sub, err := matchArgs("ধারণ_করে", args)
+			if err != nil {
+				return err
+			}
+			return NativeBool(strings.Contains(str, sub))
+		}},
+	},
+	{
+		"দিয়ে_শুরু", // startsWith: (str, prefix, [normalize])
+		&Builtin{Fn: func(args ...Object) Object {
+			str, prefix, err := matchArgs("দিয়ে_শুরু", args)
+			if err != nil {
+				return err
+			}
+			return NativeBool(strings.HasPrefix(str, prefix))
+		}},
+	},
+	{
+		"দিয়ে_শেষ", // endsWith: (str, suffix, [normalize])
+		&Builtin{Fn: func(args ...Object) Object {
+			str, suffix, err := matchArgs("দিয়ে_শেষ", args)
+			if err != nil {
+				return err
+			}
+			return NativeBool(strings.HasSuffix(str, suffix))
+		}},
+	},
+	{
+		"বাংলা_স্বাভাবিক", // normalize Bengali text: see NormalizeBengali
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			str, err := stringArg("বাংলা_স্বাভাবিক", args, 0)
+			if err != nil {
+				return err
+			}
+			return &String{Value: NormalizeBengali(str)}
+		}},
+	},
+}
@@ -0,0 +1,363 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// processStart anchors the monotonic clock used by সময়_ন্যানো
+var processStart = time.Now()
+
+// defaultTimeLayout is used when সময়_ফরম্যাট is called without a layout
+const defaultTimeLayout = "YYYY-MM-DD HH:mm:ss"
+
+var englishMonths = []string{
+	"January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December",
+}
+
+var bengaliMonths = []string{
+	"জানুয়ারি", "ফেব্রুয়ারি", "মার্চ", "এপ্রিল", "মে", "জুন",
+	"জুলাই", "আগস্ট", "সেপ্টেম্বর", "অক্টোবর", "নভেম্বর", "ডিসেম্বর",
+}
+
+var englishWeekdays = []string{
+	"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday",
+}
+
+var bengaliWeekdays = []string{
+	"রবিবার", "সোমবার", "মঙ্গলবার", "বুধবার", "বৃহস্পতিবার", "শুক্রবার", "শনিবার",
+}
+
+// layoutTokens are the placeholders understood by সময়_ফরম্যাট and সময়_পার্স,
+// longest first so that "MMMM" wins over "MM"
+var layoutTokens = []string{"YYYY", "MMMM", "dddd", "SSS", "YY", "MM", "DD", "HH", "hh", "mm", "ss", "A"}
+
+// nextLayoutToken returns the placeholder at the start of layout, if any
+func nextLayoutToken(layout string) string {
+	for _, tok := range layoutTokens {
+		if strings.HasPrefix(layout, tok) {
+			return tok
+		}
+	}
+	return ""
+}
+
+// toBengaliDigits replaces ASCII digits with Bengali digits
+func toBengaliDigits(s string) string {
+	var sb strings.Builder
+	for _, ch := range s {
+		if ch >= '0' && ch <= '9' {
+			sb.WriteRune('০' + (ch - '0'))
+		} else {
+			sb.WriteRune(ch)
+		}
+	}
+	return sb.String()
+}
+
+// fromBengaliDigits replaces Bengali digits with ASCII digits
+func fromBengaliDigits(s string) string {
+	var sb strings.Builder
+	for _, ch := range s {
+		if ch >= '০' && ch <= '৯' {
+			sb.WriteRune('0' + (ch - '০'))
+		} else {
+			sb.WriteRune(ch)
+		}
+	}
+	return sb.String()
+}
+
+// padInt formats n with at least width digits
+func padInt(n, width int) string {
+	return fmt.Sprintf("%0*d", width, n)
+}
+
+// formatTime renders t according to a YYYY-MM-DD style layout
+func formatTime(t time.Time, layout string, bengali bool) string {
+	months, weekdays := englishMonths, englishWeekdays
+	if bengali {
+		months, weekdays = bengaliMonths, bengaliWeekdays
+	}
+
+	var sb strings.Builder
+	for len(layout) > 0 {
+		tok := nextLayoutToken(layout)
+		if tok == "" {
+			_, size := utf8.DecodeRuneInString(layout)
+			sb.WriteString(layout[:size])
+			layout = layout[size:]
+			continue
+		}
+		layout = layout[len(tok):]
+
+		var part string
+		switch tok {
+		case "YYYY":
+			part = padInt(t.Year(), 4)
+		case "YY":
+			part = padInt(t.Year()%100, 2)
+		case "MMMM":
+			sb.WriteString(months[t.Month()-1])
+			continue
+		case "MM":
+			part = padInt(int(t.Month()), 2)
+		case "DD":
+			part = padInt(t.Day(), 2)
+		case "dddd":
+			sb.WriteString(weekdays[t.Weekday()])
+			continue
+		case "HH":
+			part = padInt(t.Hour(), 2)
+		case "hh":
+			h := t.Hour() % 12
+			if h == 0 {
+				h = 12
+			}
+			part = padInt(h, 2)
+		case "mm":
+			part = padInt(t.Minute(), 2)
+		case "ss":
+			part = padInt(t.Second(), 2)
+		case "SSS":
+			part = padInt(t.Nanosecond()/int(time.Millisecond), 3)
+		case "A":
+			part = "AM"
+			if t.Hour() >= 12 {
+				part = "PM"
+			}
+			if bengali {
+				part = map[string]string{"AM": "পূর্বাহ্ণ", "PM": "অপরাহ্ণ"}[part]
+			}
+		}
+		if bengali {
+			part = toBengaliDigits(part)
+		}
+		sb.WriteString(part)
+	}
+	return sb.String()
+}
+
+// parseTime parses value according to a YYYY-MM-DD style layout. Bengali
+// digits and Bengali month names are accepted alongside English ones.
+func parseTime(value, layout string) (time.Time, bool) {
+	value = fromBengaliDigits(value)
+	year, month, day := 1970, 1, 1
+	hour, minute, second, millis := 0, 0, 0, 0
+	pm, hasAMPM := false, false
+
+	readNumber := func(maxDigits int) (int, bool) {
+		n, i := 0, 0
+		for i < len(value) && i < maxDigits && value[i] >= '0' && value[i] <= '9' {
+			n = n*10 + int(value[i]-'0')
+			i++
+		}
+		value = value[i:]
+		return n, i > 0
+	}
+	readName := func(names ...[]string) (int, bool) {
+		for _, list := range names {
+			for i, name := range list {
+				if strings.HasPrefix(strings.ToLower(value), strings.ToLower(name)) {
+					value = value[len(name):]
+					return i, true
+				}
+			}
+		}
+		return 0, false
+	}
+
+	for len(layout) > 0 {
+		tok := nextLayoutToken(layout)
+		if tok == "" {
+			_, size := utf8.DecodeRuneInString(layout)
+			if !strings.HasPrefix(value, layout[:size]) {
+				return time.Time{}, false
+			}
+			value = value[size:]
+			layout = layout[size:]
+			continue
+		}
+		layout = layout[len(tok):]
+
+		ok := true
+		switch tok {
+		case "YYYY":
+			year, ok = readNumber(4)
+		case "YY":
+			year, ok = readNumber(2)
+			year += 2000
+		case "MMMM":
+			var m int
+			m, ok = readName(englishMonths, bengaliMonths)
+			month = m + 1
+		case "MM":
+			month, ok = readNumber(2)
+		case "DD":
+			day, ok = readNumber(2)
+		case "dddd":
+			_, ok = readName(englishWeekdays, bengaliWeekdays)
+		case "HH", "hh":
+			hour, ok = readNumber(2)
+		case "mm":
+			minute, ok = readNumber(2)
+		case "ss":
+			second, ok = readNumber(2)
+		case "SSS":
+			millis, ok = readNumber(3)
+		case "A":
+			hasAMPM = true
+			switch {
+			case strings.HasPrefix(strings.ToUpper(value), "AM"):
+			case strings.HasPrefix(strings.ToUpper(value), "PM"):
+				pm = true
+			default:
+				ok = false
+			}
+			if ok {
+				value = value[2:]
+			}
+		}
+		if !ok {
+			return time.Time{}, false
+		}
+	}
+	if value != "" || month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}, false
+	}
+	if hasAMPM {
+		hour %= 12
+		if pm {
+			hour += 12
+		}
+	}
+	return time.Date(year, time.Month(month), day, hour, minute, second, millis*int(time.Millisecond), time.Local), true
+}
+
+// timeFromMillis converts a millisecond Unix timestamp to local time
+func timeFromMillis(ms int64) time.Time {
+	return time.UnixMilli(ms)
+}
+
+// timeBuiltins provide timestamps, formatting, parsing and sleeping
+var timeBuiltins = []BuiltinDef{
+	{
+		"সময়", // current Unix timestamp in milliseconds
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+			return NewInteger(time.Now().UnixMilli())
+		}},
+	},
+	{
+		"সময়_ন্যানো", // monotonic nanoseconds, for measuring elapsed time
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+			return NewInteger(int64(time.Since(processStart)))
+		}},
+	},
+	{
+		"ঘুমাও", // sleep for the given number of milliseconds
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			ms, ok := integerValue(args[0])
+			if !ok {
+				return newError("argument to 'ঘুমাও' must be INTEGER, got %s", args[0].Type())
+			}
+			if ms > 0 {
+				time.Sleep(time.Duration(ms) * time.Millisecond)
+			}
+			return NULL
+		}},
+	},
+	{
+		"সময়_ফরম্যাট", // format a timestamp: (ms, [layout], [bengali])
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) < 1 || len(args) > 3 {
+				return newError("wrong number of arguments. got=%d, want=1 to 3", len(args))
+			}
+			ms, ok := integerValue(args[0])
+			if !ok {
+				return newError("first argument to 'সময়_ফরম্যাট' must be INTEGER, got %s", args[0].Type())
+			}
+			layout := defaultTimeLayout
+			if len(args) >= 2 {
+				str, ok := args[1].(*String)
+				if !ok {
+					return newError("second argument to 'সময়_ফরম্যাট' must be STRING, got %s", args[1].Type())
+				}
+				layout = str.Value
+			}
+			bengali := false
+			if len(args) == 3 {
+				b, ok := args[2].(*Boolean)
+				if !ok {
+					return newError("third argument to 'সময়_ফরম্যাট' must be BOOLEAN, got %s", args[2].Type())
+				}
+				bengali = b.Value
+			}
+			return &String{Value: formatTime(timeFromMillis(ms), layout, bengali)}
+		}},
+	},
+	{
+		"সময়_পার্স", // parse a date string: (str, [layout]) -> ms
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			str, ok := args[0].(*String)
+			if !ok {
+				return newError("first argument to 'সময়_পার্স' must be STRING, got %s", args[0].Type())
+			}
+			layout := defaultTimeLayout
+			if len(args) == 2 {
+				l, ok := args[1].(*String)
+				if !ok {
+					return newError("second argument to 'সময়_পার্স' must be STRING, got %s", args[1].Type())
+				}
+				layout = l.Value
+			}
+			t, ok := parseTime(str.Value, layout)
+			if !ok {
+				return newError("cannot parse %q with layout %q", str.Value, layout)
+			}
+			return NewInteger(t.UnixMilli())
+		}},
+	},
+	{
+		"তারিখ", // date components of a timestamp (default: now) as a hash
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+			}
+			t := time.Now()
+			if len(args) == 1 {
+				ms, ok := integerValue(args[0])
+				if !ok {
+					return newError("argument to 'তারিখ' must be INTEGER, got %s", args[0].Type())
+				}
+				t = timeFromMillis(ms)
+			}
+			h := &Hash{Pairs: make(map[HashKey]HashPair)}
+			h.Set("বছর", NewInteger(int64(t.Year())))
+			h.Set("মাস", NewInteger(int64(t.Month())))
+			h.Set("দিন", NewInteger(int64(t.Day())))
+			h.Set("ঘণ্টা", NewInteger(int64(t.Hour())))
+			h.Set("মিনিট", NewInteger(int64(t.Minute())))
+			h.Set("সেকেন্ড", NewInteger(int64(t.Second())))
+			h.Set("মিলিসেকেন্ড", NewInteger(int64(t.Nanosecond()/int(time.Millisecond))))
+			h.Set("সপ্তাহের_দিন", NewInteger(int64(t.Weekday())))
+			h.Set("মাসের_নাম", &String{Value: bengaliMonths[t.Month()-1]})
+			h.Set("বারের_নাম", &String{Value: bengaliWeekdays[t.Weekday()]})
+			return h
+		}},
+	},
+}
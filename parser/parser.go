@@ -5,50 +5,57 @@ import (
 	"bhasa/lexer"
 	"bhasa/token"
 	"fmt"
+	"math/big"
 	"strconv"
+	"strings"
 )
 
 // Operator precedence
 const (
 	_ int = iota
 	LOWEST
-	LOGICAL_OR   // ||
-	LOGICAL_AND  // &&
-	BIT_OR       // |
-	BIT_XOR      // ^
-	BIT_AND      // &
-	EQUALS       // ==
-	LESSGREATER  // > or <
-	SHIFT        // << >>
-	SUM          // +
-	PRODUCT      // *
-	PREFIX       // -X or !X or ~X
-	CALL         // myFunction(X)
-	INDEX        // array[index]
+	LAMBDA      // x => x * ২
+	COALESCE    // ??
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
+	BIT_OR      // |
+	BIT_XOR     // ^
+	BIT_AND     // &
+	EQUALS      // ==
+	LESSGREATER // > or <
+	SHIFT       // << >>
+	SUM         // +
+	PRODUCT     // *
+	PREFIX      // -X or !X or ~X
+	CALL        // myFunction(X)
+	INDEX       // array[index]
 )
 
 var precedences = map[token.TokenType]int{
-	token.OR:       LOGICAL_OR,
-	token.AND:      LOGICAL_AND,
-	token.BIT_OR:   BIT_OR,
-	token.BIT_XOR:  BIT_XOR,
-	token.BIT_AND:  BIT_AND,
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.LTE:      LESSGREATER,
-	token.GTE:      LESSGREATER,
-	token.LSHIFT:   SHIFT,
-	token.RSHIFT:   SHIFT,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.PERCENT:  PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
-	token.DOT:      INDEX, // Member access has same precedence as index
+	token.ARROW:         LAMBDA,
+	token.NULL_COALESCE: COALESCE,
+	token.OR:            LOGICAL_OR,
+	token.AND:           LOGICAL_AND,
+	token.BIT_OR:        BIT_OR,
+	token.BIT_XOR:       BIT_XOR,
+	token.BIT_AND:       BIT_AND,
+	token.EQ:            EQUALS,
+	token.NOT_EQ:        EQUALS,
+	token.LT:            LESSGREATER,
+	token.GT:            LESSGREATER,
+	token.LTE:           LESSGREATER,
+	token.GTE:           LESSGREATER,
+	token.LSHIFT:        SHIFT,
+	token.RSHIFT:        SHIFT,
+	token.PLUS:          SUM,
+	token.MINUS:         SUM,
+	token.SLASH:         PRODUCT,
+	token.ASTERISK:      PRODUCT,
+	token.PERCENT:       PRODUCT,
+	token.LPAREN:        CALL,
+	token.LBRACKET:      INDEX,
+	token.DOT:           INDEX, // Member access has same precedence as index
+	token.QUESTION_DOT:  INDEX,
 }
 
 type (
@@ -79,7 +86,11 @@ func New(l *lexer.Lexer) *Parser {
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.DECIMAL, p.parseDecimalLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.RAW_STRING, p.parseRawStringLiteral)
+	p.registerPrefix(token.CHAR, p.parseCharLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.BIT_NOT, p.parsePrefixExpression)
@@ -121,6 +132,9 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 	p.registerInfix(token.AS, p.parseTypeCastExpression)
 	p.registerInfix(token.DOT, p.parseMemberAccess)
+	p.registerInfix(token.QUESTION_DOT, p.parseMemberAccess)
+	p.registerInfix(token.NULL_COALESCE, p.parseInfixExpression)
+	p.registerInfix(token.ARROW, p.parseBareArrowFunction)
 
 	// Read two tokens to initialize curToken and peekToken
 	p.nextToken()
@@ -137,13 +151,21 @@ func (p *Parser) Errors() []string {
 func (p *Parser) peekError(t token.TokenType) {
 	msg := fmt.Sprintf("[Line %d, Col %d] expected next token to be %s, got %s instead",
 		p.peekToken.Line, p.peekToken.Column, t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.addError(msg)
 }
 
 func (p *Parser) error(msg string) {
 	fullMsg := fmt.Sprintf("[Line %d, Col %d] %s",
 		p.curToken.Line, p.curToken.Column, msg)
-	p.errors = append(p.errors, fullMsg)
+	p.addError(fullMsg)
+}
+
+// addError records an error, naming the source file when it is known
+func (p *Parser) addError(msg string) {
+	if file := p.l.File(); file != "" {
+		msg = file + ": " + msg
+	}
+	p.errors = append(p.errors, msg)
 }
 
 func (p *Parser) nextToken() {
@@ -177,6 +199,8 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseWhileStatement()
 	case token.FOR:
 		return p.parseForStatement()
+	case token.FOREACH:
+		return p.parseForEachStatement()
 	case token.BREAK:
 		return p.parseBreakStatement()
 	case token.CONTINUE:
@@ -198,12 +222,32 @@ func (p *Parser) parseStatement() ast.Statement {
 					return p.parseMemberAssignmentStatement(memberAccess)
 				}
 			}
+			if index, ok := left.Expression.(*ast.IndexExpression); ok && p.peekTokenIs(token.ASSIGN) {
+				return p.parseIndexAssignmentStatement(index)
+			}
+			if p.peekTokenIsCompoundAssign() {
+				return p.parseCompoundAssignmentStatement(left.Expression)
+			}
+			return left
+		}
+		// Check if this is an index assignment (identifier[index] = value)
+		if p.peekTokenIs(token.LBRACKET) {
+			left := p.parseExpressionStatement()
+			if index, ok := left.Expression.(*ast.IndexExpression); ok && p.peekTokenIs(token.ASSIGN) {
+				return p.parseIndexAssignmentStatement(index)
+			}
+			if p.peekTokenIsCompoundAssign() {
+				return p.parseCompoundAssignmentStatement(left.Expression)
+			}
 			return left
 		}
 		// Check if this is an assignment (identifier followed by =)
 		if p.peekTokenIs(token.ASSIGN) {
 			return p.parseAssignmentStatement()
 		}
+		if p.peekTokenIsCompoundAssign() {
+			return p.parseCompoundAssignmentStatement(&ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		}
 		return p.parseExpressionStatement()
 	case token.THIS, token.SUPER:
 		// Check if this is a member assignment (this.member = value or super.member = value)
@@ -216,6 +260,12 @@ func (p *Parser) parseStatement() ast.Statement {
 					return p.parseMemberAssignmentStatement(memberAccess)
 				}
 			}
+			if index, ok := left.Expression.(*ast.IndexExpression); ok && p.peekTokenIs(token.ASSIGN) {
+				return p.parseIndexAssignmentStatement(index)
+			}
+			if p.peekTokenIsCompoundAssign() {
+				return p.parseCompoundAssignmentStatement(left.Expression)
+			}
 			return left
 		}
 		return p.parseExpressionStatement()
@@ -224,7 +274,7 @@ func (p *Parser) parseStatement() ast.Statement {
 	}
 }
 
-func (p *Parser) parseLetStatement() *ast.LetStatement {
+func (p *Parser) parseLetStatement() ast.Statement {
 	stmt := &ast.LetStatement{Token: p.curToken}
 
 	if !p.expectPeek(token.IDENT) {
@@ -232,6 +282,9 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	}
 
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if p.peekTokenIs(token.COMMA) {
+		return p.parseDestructuringLet(stmt.Token, stmt.Name)
+	}
 
 	// Check for optional type annotation: ধরি x: পূর্ণসংখ্যা = 10
 	if p.peekTokenIs(token.COLON) {
@@ -251,6 +304,11 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	stmt.Value = p.parseExpression(LOWEST)
 
+	// A doc comment above ধরি name = ফাংশন(...) documents the function
+	if fn, ok := stmt.Value.(*ast.FunctionLiteral); ok && fn.Doc == "" {
+		fn.Doc = stmt.Token.Doc
+	}
+
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -258,6 +316,30 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	return stmt
 }
 
+// parseDestructuringLet parses the rest of ধরি x, y = value after the
+// first name
+func (p *Parser) parseDestructuringLet(let token.Token, first *ast.Identifier) ast.Statement {
+	stmt := &ast.DestructuringLetStatement{Token: let, Names: []*ast.Identifier{first}}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	}
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
 func (p *Parser) parseAssignmentStatement() *ast.AssignmentStatement {
 	stmt := &ast.AssignmentStatement{Token: p.curToken}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
@@ -277,6 +359,48 @@ func (p *Parser) parseAssignmentStatement() *ast.AssignmentStatement {
 	return stmt
 }
 
+// compoundOperators maps each compound assignment token to the operator
+// it applies
+var compoundOperators = map[token.TokenType]string{
+	token.PLUS_ASSIGN:     "+",
+	token.MINUS_ASSIGN:    "-",
+	token.ASTERISK_ASSIGN: "*",
+	token.SLASH_ASSIGN:    "/",
+	token.PERCENT_ASSIGN:  "%",
+}
+
+func (p *Parser) peekTokenIsCompoundAssign() bool {
+	_, ok := compoundOperators[p.peekToken.Type]
+	return ok
+}
+
+// parseCompoundAssignmentStatement parses the operator and value of
+// target += value, with the current token at the end of the target
+func (p *Parser) parseCompoundAssignmentStatement(target ast.Expression) ast.Statement {
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression, *ast.MemberAccessExpression:
+	default:
+		p.error(fmt.Sprintf("cannot assign to %s", target.String()))
+		return nil
+	}
+
+	p.nextToken()
+	stmt := &ast.CompoundAssignmentStatement{
+		Token:    p.curToken,
+		Target:   target,
+		Operator: compoundOperators[p.curToken.Type],
+	}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseMemberAssignmentStatement(memberAccess *ast.MemberAccessExpression) *ast.MemberAssignmentStatement {
 	stmt := &ast.MemberAssignmentStatement{
 		Token:  memberAccess.Token,
@@ -299,13 +423,47 @@ func (p *Parser) parseMemberAssignmentStatement(memberAccess *ast.MemberAccessEx
 	return stmt
 }
 
+func (p *Parser) parseIndexAssignmentStatement(index *ast.IndexExpression) *ast.IndexAssignmentStatement {
+	stmt := &ast.IndexAssignmentStatement{
+		Token: index.Token,
+		Left:  index.Left,
+		Index: index.Index,
+	}
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
 	p.nextToken()
+	start := p.curToken
 
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
+	// ফেরত a, b returns both values as a tuple
+	if p.peekTokenIs(token.COMMA) && stmt.ReturnValue != nil {
+		tuple := &ast.TupleLiteral{Token: start, Elements: []ast.Expression{stmt.ReturnValue}}
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			p.nextToken()
+			tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
+		}
+		stmt.ReturnValue = tuple
+	}
+
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -369,6 +527,8 @@ func (p *Parser) parseForStatement() *ast.ForStatement {
 	if p.curToken.Type != token.RPAREN {
 		if p.curToken.Type == token.IDENT && p.peekTokenIs(token.ASSIGN) {
 			stmt.Increment = p.parseAssignmentStatement()
+		} else if p.curToken.Type == token.IDENT && p.peekTokenIsCompoundAssign() {
+			stmt.Increment = p.parseCompoundAssignmentStatement(&ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
 		} else {
 			// Could be an expression statement
 			exprStmt := &ast.ExpressionStatement{Token: p.curToken}
@@ -390,6 +550,36 @@ func (p *Parser) parseForStatement() *ast.ForStatement {
 	return stmt
 }
 
+// parseForEachStatement parses প্রতিটি (x মধ্যে iterable) { ... }
+func (p *Parser) parseForEachStatement() *ast.ForEachStatement {
+	stmt := &ast.ForEachStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	return stmt
+}
+
 func (p *Parser) parseBreakStatement() *ast.BreakStatement {
 	stmt := &ast.BreakStatement{Token: p.curToken}
 
@@ -474,9 +664,42 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		if bigValue, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			return &ast.BigIntegerLiteral{Token: p.curToken, Value: bigValue}
+		}
+	}
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(msg)
+		return nil
+	}
+
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseDecimalLiteral() ast.Expression {
+	lit := &ast.DecimalLiteral{Token: p.curToken}
+
+	value, ok := new(big.Rat).SetString(p.curToken.Literal)
+	if !ok {
+		msg := fmt.Sprintf("could not parse %q as decimal", p.curToken.Literal)
+		p.addError(msg)
+		return nil
+	}
+
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
+		p.addError(msg)
 		return nil
 	}
 
@@ -485,9 +708,119 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 }
 
 func (p *Parser) parseStringLiteral() ast.Expression {
+	if strings.Contains(p.curToken.Literal, "${") {
+		return p.parseInterpolatedString()
+	}
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// parseRawStringLiteral parses a `raw string`, whose text is never
+// interpolated
+func (p *Parser) parseRawStringLiteral() ast.Expression {
+	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+}
+
+// parseCharLiteral parses a 'ক' literal, which must hold exactly one
+// character. A letter with a vowel sign, such as কা, is two.
+func (p *Parser) parseCharLiteral() ast.Expression {
+	runes := []rune(p.curToken.Literal)
+	if len(runes) != 1 {
+		p.error(fmt.Sprintf("character literal '%s' must hold exactly one character; use a string for more", p.curToken.Literal))
+		return nil
+	}
+	return &ast.CharLiteral{Token: p.curToken, Value: runes[0]}
+}
+
+// parseInterpolatedString splits a string containing ${expression} holes
+// into its text and the expressions, each parsed on its own
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: p.curToken}
+	text := p.curToken.Literal
+	for {
+		start := strings.Index(text, "${")
+		if start < 0 {
+			break
+		}
+		end := matchingBrace(text, start+2)
+		if end < 0 {
+			p.error("unterminated ${ in string")
+			return nil
+		}
+		if start > 0 {
+			str.Parts = append(str.Parts, &ast.StringLiteral{Token: p.curToken, Value: text[:start]})
+		}
+		expr := p.parseHole(text[start+2 : end])
+		if expr == nil {
+			return nil
+		}
+		str.Parts = append(str.Parts, expr)
+		text = text[end+1:]
+	}
+	if text != "" {
+		str.Parts = append(str.Parts, &ast.StringLiteral{Token: p.curToken, Value: text})
+	}
+	return str
+}
+
+// matchingBrace returns the index of the } that closes a hole whose
+// expression starts at from, allowing braces and strings inside the
+// expression
+func matchingBrace(text string, from int) int {
+	depth := 0
+	for i := from; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case '"':
+			if i = closingQuote(text, i+1); i < 0 {
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+// closingQuote returns the index of the quote that ends a string nested
+// in a hole, whose text starts at from
+func closingQuote(text string, from int) int {
+	for i := from; i < len(text); i++ {
+		switch {
+		case text[i] == '"':
+			return i
+		case strings.HasPrefix(text[i:], "${"):
+			if i = matchingBrace(text, i+2); i < 0 {
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+// parseHole parses the expression inside one ${...} of a string
+func (p *Parser) parseHole(src string) ast.Expression {
+	sub := New(lexer.New(src))
+	if sub.curTokenIs(token.EOF) {
+		p.error("empty ${} in string")
+		return nil
+	}
+	expr := sub.parseExpression(LOWEST)
+	if len(sub.errors) == 0 && !sub.peekTokenIs(token.EOF) {
+		sub.peekError(token.EOF)
+	}
+	if len(sub.errors) > 0 {
+		for _, msg := range sub.errors {
+			p.error(fmt.Sprintf("in ${%s}: %s", src, msg))
+		}
+		return nil
+	}
+	return expr
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
@@ -520,17 +853,117 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	start := p.curToken
+
+	// () can only start an arrow function without parameters
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		if !p.expectPeek(token.ARROW) {
+			return nil
+		}
+		return p.parseArrowFunction(nil)
+	}
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
 
+	// (a, b) is a tuple, and so is (a,) with its one element
+	if p.peekTokenIs(token.COMMA) {
+		tuple := &ast.TupleLiteral{Token: start, Elements: []ast.Expression{exp}}
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			if p.peekTokenIs(token.RPAREN) {
+				break
+			}
+			p.nextToken()
+			tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
+		}
+		exp = tuple
+	}
+
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
 
+	// (x) and (a, b) followed by => are the parameters of an arrow function
+	if p.peekTokenIs(token.ARROW) {
+		params, ok := p.arrowParameters(exp)
+		if !ok {
+			return nil
+		}
+		p.nextToken()
+		return p.parseArrowFunction(params)
+	}
+
 	return exp
 }
 
+// arrowParameters reads the parameter names of an arrow function out of
+// the expression parsed between its parentheses
+func (p *Parser) arrowParameters(exp ast.Expression) ([]*ast.Identifier, bool) {
+	elements := []ast.Expression{exp}
+	if tuple, ok := exp.(*ast.TupleLiteral); ok {
+		elements = tuple.Elements
+	}
+	params := make([]*ast.Identifier, 0, len(elements))
+	for _, el := range elements {
+		ident, ok := el.(*ast.Identifier)
+		if !ok {
+			p.error("arrow function parameters must be plain names; use ফাংশন for types, defaults or ...rest")
+			return nil, false
+		}
+		params = append(params, ident)
+	}
+	return params, true
+}
+
+// parseBareArrowFunction parses x => ..., whose one parameter is not
+// parenthesized
+func (p *Parser) parseBareArrowFunction(left ast.Expression) ast.Expression {
+	ident, ok := left.(*ast.Identifier)
+	if left == nil {
+		return nil // the error is already reported
+	}
+	if !ok {
+		p.error(fmt.Sprintf("expected a parameter name before =>, got %s", left.String()))
+		return nil
+	}
+	return p.parseArrowFunction([]*ast.Identifier{ident})
+}
+
+// parseArrowFunction parses the body after =>, the current token. A block
+// is the function's body; any other expression is what it returns.
+func (p *Parser) parseArrowFunction(params []*ast.Identifier) ast.Expression {
+	arrow := p.curToken
+	lit := &ast.FunctionLiteral{
+		Token:          arrow,
+		Parameters:     params,
+		ParameterTypes: make([]*ast.TypeAnnotation, len(params)),
+		Defaults:       make([]ast.Expression, len(params)),
+		Arrow:          true,
+	}
+	if lit.Parameters == nil {
+		lit.Parameters = []*ast.Identifier{}
+	}
+
+	if p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		lit.Body = p.parseBlockStatement()
+		return lit
+	}
+
+	p.nextToken()
+	value := p.parseExpression(LOWEST)
+	if value == nil {
+		return nil
+	}
+	lit.Body = &ast.BlockStatement{
+		Token:      arrow,
+		Statements: []ast.Statement{&ast.ReturnStatement{Token: arrow, ReturnValue: value}},
+	}
+	return lit
+}
+
 func (p *Parser) parseIfExpression() ast.Expression {
 	expression := &ast.IfExpression{Token: p.curToken}
 
@@ -582,13 +1015,13 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 }
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
-	lit := &ast.FunctionLiteral{Token: p.curToken}
+	lit := &ast.FunctionLiteral{Token: p.curToken, Doc: p.curToken.Doc}
 
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
 
-	lit.Parameters, lit.ParameterTypes = p.parseFunctionParameters()
+	lit.Parameters, lit.ParameterTypes, lit.Defaults, lit.Rest = p.parseFunctionParameters()
 
 	// Check for optional return type annotation: ফাংশন(x: পূর্ণসংখ্যা): দশমিক { ... }
 	if p.peekTokenIs(token.COLON) {
@@ -609,35 +1042,24 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return lit
 }
 
-func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, []*ast.TypeAnnotation) {
+func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, []*ast.TypeAnnotation, []ast.Expression, bool) {
 	identifiers := []*ast.Identifier{}
 	types := []*ast.TypeAnnotation{}
+	defaults := []ast.Expression{}
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifiers, types
+		return identifiers, types, defaults, false
 	}
 
-	p.nextToken()
-
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
-
-	// Check for optional type annotation for parameter
-	var typeAnnot *ast.TypeAnnotation
-	if p.peekTokenIs(token.COLON) {
-		p.nextToken() // consume :
-		p.nextToken() // move to type token
-		typeAnnot = p.parseTypeAnnotation()
-		if typeAnnot == nil {
-			return nil, nil
+	rest := false
+	for {
+		p.nextToken()
+		if p.curTokenIs(token.ELLIPSIS) {
+			rest = true
+			p.nextToken()
 		}
-	}
-	types = append(types, typeAnnot)
 
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
-		p.nextToken()
 		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 		identifiers = append(identifiers, ident)
 
@@ -648,17 +1070,69 @@ func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, []*ast.TypeAnnota
 			p.nextToken() // move to type token
 			typeAnnot = p.parseTypeAnnotation()
 			if typeAnnot == nil {
-				return nil, nil
+				return nil, nil, nil, false
 			}
 		}
 		types = append(types, typeAnnot)
+
+		var def ast.Expression
+		if rest {
+			if !p.checkRestParameter(ident) {
+				return nil, nil, nil, false
+			}
+		} else {
+			var ok bool
+			if def, ok = p.parseParameterDefault(ident, defaults); !ok {
+				return nil, nil, nil, false
+			}
+		}
+		defaults = append(defaults, def)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
 	}
 
 	if !p.expectPeek(token.RPAREN) {
-		return nil, nil
+		return nil, nil, nil, false
+	}
+
+	return identifiers, types, defaults, rest
+}
+
+// checkRestParameter checks the parameter written after ...: it takes the
+// arguments left over, so it must come last and cannot have a default
+func (p *Parser) checkRestParameter(param *ast.Identifier) bool {
+	if p.peekTokenIs(token.ASSIGN) {
+		p.error(fmt.Sprintf("rest parameter %s cannot have a default value", param.Value))
+		return false
+	}
+	if !p.peekTokenIs(token.RPAREN) {
+		p.error(fmt.Sprintf("rest parameter %s must be the last parameter", param.Value))
+		return false
 	}
+	return true
+}
 
-	return identifiers, types
+// parseParameterDefault parses the optional `= value` after a parameter.
+// Once one parameter has a default value, all that follow it need one too,
+// so that callers can only leave out trailing arguments.
+func (p *Parser) parseParameterDefault(param *ast.Identifier, defaults []ast.Expression) (ast.Expression, bool) {
+	if !p.peekTokenIs(token.ASSIGN) {
+		if n := len(defaults); n > 0 && defaults[n-1] != nil {
+			p.error(fmt.Sprintf("parameter %s needs a default value, like the parameters before it", param.Value))
+			return nil, false
+		}
+		return nil, true
+	}
+	p.nextToken() // move to =
+	p.nextToken() // move to the value
+	value := p.parseExpression(LOWEST)
+	if value == nil {
+		return nil, false
+	}
+	return value, true
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
@@ -698,11 +1172,37 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 }
 
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+	tok := p.curToken
+	if p.peekTokenIs(token.COLON) {
+		return p.parseSliceExpression(tok, left, nil)
+	}
+
+	exp := &ast.IndexExpression{Token: tok, Left: left}
 
 	p.nextToken()
 	exp.Index = p.parseExpression(LOWEST)
 
+	if p.peekTokenIs(token.COLON) {
+		return p.parseSliceExpression(tok, left, exp.Index)
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return exp
+}
+
+// parseSliceExpression parses the rest of left[start:end] from the colon
+func (p *Parser) parseSliceExpression(tok token.Token, left, start ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+	p.nextToken() // the :
+
+	if !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.End = p.parseExpression(LOWEST)
+	}
+
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
@@ -780,24 +1280,29 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	if t == token.ILLEGAL && p.curToken.Literal == "/*" {
+		p.error("unterminated /* comment")
+		return
+	}
+	if t == token.ILLEGAL && p.curToken.Literal == "'" {
+		p.error("unterminated ' character literal")
+		return
+	}
+	if t == token.ILLEGAL && p.curToken.Literal == "`" {
+		p.error("unterminated ` string")
+		return
+	}
+	if t == token.ILLEGAL && strings.Contains(p.curToken.Literal, "_") {
+		p.error(fmt.Sprintf("malformed number %s: underscores go between digits", p.curToken.Literal))
+		return
+	}
+	p.error(fmt.Sprintf("no prefix parse function for %s found", t))
 }
 
 // Type annotation parsing functions
 
 func (p *Parser) isTypeToken(t token.TokenType) bool {
-	return t == token.TYPE_BYTE ||
-		t == token.TYPE_SHORT ||
-		t == token.TYPE_INT ||
-		t == token.TYPE_LONG ||
-		t == token.TYPE_FLOAT ||
-		t == token.TYPE_DOUBLE ||
-		t == token.TYPE_CHAR ||
-		t == token.TYPE_STRING ||
-		t == token.TYPE_BOOLEAN ||
-		t == token.TYPE_ARRAY ||
-		t == token.TYPE_HASH
+	return token.IsType(t)
 }
 
 func (p *Parser) parseTypeAnnotation() *ast.TypeAnnotation {
@@ -1037,7 +1542,7 @@ func (p *Parser) parseEnumDefinition() ast.Expression {
 		return nil
 	}
 
-	variant := &ast.EnumVariant{Name: p.curToken.Literal}
+	variant := &ast.EnumVariant{Token: p.curToken, Name: p.curToken.Literal}
 
 	// Check for explicit value: variant = 0
 	if p.peekTokenIs(token.ASSIGN) {
@@ -1070,7 +1575,7 @@ func (p *Parser) parseEnumDefinition() ast.Expression {
 			return nil
 		}
 
-		variant := &ast.EnumVariant{Name: p.curToken.Literal}
+		variant := &ast.EnumVariant{Token: p.curToken, Name: p.curToken.Literal}
 
 		// Check for explicit value
 		if p.peekTokenIs(token.ASSIGN) {
@@ -1102,8 +1607,9 @@ func (p *Parser) parseEnumDefinition() ast.Expression {
 
 func (p *Parser) parseMemberAccess(left ast.Expression) ast.Expression {
 	exp := &ast.MemberAccessExpression{
-		Token:  p.curToken, // the . token
-		Object: left,
+		Token:    p.curToken, // the . or ?. token
+		Object:   left,
+		Optional: p.curTokenIs(token.QUESTION_DOT),
 	}
 
 	if !p.expectPeek(token.IDENT) {
@@ -1199,6 +1705,7 @@ func (p *Parser) parseStructLiteral(structType *ast.Identifier) ast.Expression {
 func (p *Parser) parseClassDefinition() *ast.ClassDefinition {
 	classDef := &ast.ClassDefinition{
 		Token:        p.curToken,
+		Doc:          p.curToken.Doc,
 		Fields:       []*ast.ClassField{},
 		Methods:      []*ast.MethodDefinition{},
 		Constructors: []*ast.ConstructorDefinition{},
@@ -1271,6 +1778,7 @@ func (p *Parser) parseClassDefinition() *ast.ClassDefinition {
 		isFinal := false
 		isAbstract := false
 		isOverride := false
+		doc := p.curToken.Doc // above the first modifier, if any
 
 		// Parse modifiers
 		for p.curTokenIs(token.PUBLIC) || p.curTokenIs(token.PRIVATE) || p.curTokenIs(token.PROTECTED) ||
@@ -1302,6 +1810,7 @@ func (p *Parser) parseClassDefinition() *ast.ClassDefinition {
 			constructor := p.parseConstructorDefinition()
 			if constructor != nil {
 				constructor.Access = access
+				constructor.Doc = doc
 				classDef.Constructors = append(classDef.Constructors, constructor)
 			}
 			p.nextToken() // Move to next token after constructor
@@ -1310,6 +1819,7 @@ func (p *Parser) parseClassDefinition() *ast.ClassDefinition {
 			method := p.parseMethodDefinition()
 			if method != nil {
 				method.Access = access
+				method.Doc = doc
 				method.IsStatic = isStatic
 				method.IsFinal = isFinal
 				method.IsAbstract = isAbstract
@@ -1342,9 +1852,10 @@ func (p *Parser) parseClassDefinition() *ast.ClassDefinition {
 }
 
 // parseClassField parses a class field declaration
-// Syntax: fieldName: TypeAnnotation; OR fieldName; (type annotation optional)
+// Syntax: fieldName: TypeAnnotation; OR fieldName; (type annotation optional),
+// either followed by = value for static fields
 func (p *Parser) parseClassField() *ast.ClassField {
-	field := &ast.ClassField{}
+	field := &ast.ClassField{Token: p.curToken}
 
 	field.Name = p.curToken.Literal
 
@@ -1355,6 +1866,13 @@ func (p *Parser) parseClassField() *ast.ClassField {
 		field.TypeAnnot = p.parseTypeAnnotation()
 	}
 
+	// Optional initial value: স্থির গণনা: পূর্ণসংখ্যা = 0;
+	if p.peekTokenIs(token.ASSIGN) {
+		p.nextToken() // consume =
+		p.nextToken() // move to the value
+		field.Value = p.parseExpression(LOWEST)
+	}
+
 	// Expect semicolon
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
@@ -1387,6 +1905,10 @@ func (p *Parser) parseMethodDefinition() *ast.MethodDefinition {
 
 	// Parse parameter list
 	for !p.curTokenIs(token.RPAREN) && !p.curTokenIs(token.EOF) {
+		if p.curTokenIs(token.ELLIPSIS) {
+			method.Rest = true
+			p.nextToken()
+		}
 		if !p.curTokenIs(token.IDENT) {
 			p.error("expected parameter name")
 			return nil
@@ -1403,6 +1925,18 @@ func (p *Parser) parseMethodDefinition() *ast.MethodDefinition {
 			paramType = p.parseTypeAnnotation()
 		}
 		method.ParameterTypes = append(method.ParameterTypes, paramType)
+		var def ast.Expression
+		if method.Rest {
+			if !p.checkRestParameter(param) {
+				return nil
+			}
+		} else {
+			var ok bool
+			if def, ok = p.parseParameterDefault(param, method.Defaults); !ok {
+				return nil
+			}
+		}
+		method.Defaults = append(method.Defaults, def)
 
 		if p.peekTokenIs(token.COMMA) {
 			p.nextToken() // skip comma
@@ -1461,6 +1995,10 @@ func (p *Parser) parseConstructorDefinition() *ast.ConstructorDefinition {
 
 	// Parse parameter list
 	for !p.curTokenIs(token.RPAREN) && !p.curTokenIs(token.EOF) {
+		if p.curTokenIs(token.ELLIPSIS) {
+			constructor.Rest = true
+			p.nextToken()
+		}
 		if !p.curTokenIs(token.IDENT) {
 			p.error("expected parameter name")
 			return nil
@@ -1477,6 +2015,18 @@ func (p *Parser) parseConstructorDefinition() *ast.ConstructorDefinition {
 			paramType = p.parseTypeAnnotation()
 		}
 		constructor.ParameterTypes = append(constructor.ParameterTypes, paramType)
+		var def ast.Expression
+		if constructor.Rest {
+			if !p.checkRestParameter(param) {
+				return nil
+			}
+		} else {
+			var ok bool
+			if def, ok = p.parseParameterDefault(param, constructor.Defaults); !ok {
+				return nil
+			}
+		}
+		constructor.Defaults = append(constructor.Defaults, def)
 
 		if p.peekTokenIs(token.COMMA) {
 			p.nextToken() // skip comma
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bhasa/vm"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// startProfiling starts writing a CPU profile to cpuFile when it is set.
+// The function it returns stops that profile and writes a heap profile to
+// memFile when that is set; both are read with `go tool pprof`.
+func startProfiling(cpuFile, memFile string) (func() error, error) {
+	var cpu *os.File
+	if cpuFile != "" {
+		f, err := os.Create(cpuFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot write CPU profile: %v", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("cannot start CPU profile: %v", err)
+		}
+		cpu = f
+	}
+
+	return func() error {
+		if cpu != nil {
+			pprof.StopCPUProfile()
+			if err := cpu.Close(); err != nil {
+				return fmt.Errorf("cannot write CPU profile: %v", err)
+			}
+		}
+		if memFile == "" {
+			return nil
+		}
+		f, err := os.Create(memFile)
+		if err != nil {
+			return fmt.Errorf("cannot write memory profile: %v", err)
+		}
+		defer f.Close()
+		// Collect first so the profile's live objects are up to date
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			return fmt.Errorf("cannot write memory profile: %v", err)
+		}
+		return f.Close()
+	}, nil
+}
+
+// writeAllocations prints what a VM counted with CountAllocations, one
+// kind of value a line
+func writeAllocations(w io.Writer, allocs *vm.Allocations) {
+	total := allocs.Total()
+	fmt.Fprintf(w, "%-10s %12s %7s\n", "value", "allocated", "share")
+	for kind, n := range allocs {
+		if n == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%-10s %12d %6.2f%%\n", vm.AllocKind(kind).Name(), n, float64(n)*100/float64(total))
+	}
+	fmt.Fprintf(w, "%-10s %12d\n", "total", total)
+}
@@ -0,0 +1,333 @@
+package repl
+
+import (
+	"bhasa/object"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// historyName is the file in the home directory that keeps the lines
+// entered in earlier sessions
+const historyName = ".bhasa_history"
+
+// historySize is how many lines of history are kept
+const historySize = 1000
+
+// errInterrupt is returned by readLine when Ctrl-C abandons the line
+var errInterrupt = errors.New("interrupted")
+
+// lineEditor reads lines from a terminal with editing keys and history:
+//
+//	←/→, Ctrl-B/F     move a character     Home/End, Ctrl-A/E  line start/end
+//	↑/↓, Ctrl-P/N     older/newer history  Backspace, Delete   delete a character
+//	Ctrl-K / Ctrl-U   delete to end/start  Ctrl-W              delete a word
+//	Ctrl-L            clear the screen     Ctrl-C / Ctrl-D     abandon line / quit
+//	Tab               complete the name before the cursor, or indent
+type lineEditor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	fd       int
+	history  []string
+	file     string                     // where history is saved, "" when there is no home directory
+	complete func(word string) []string // the names that may follow word, if set
+}
+
+// newLineEditor returns an editor for the REPL when it reads the process's
+// standard input from a terminal and writes to one, or nil otherwise, in
+// which case lines are read as they come
+func newLineEditor(in *bufio.Reader, out io.Writer) *lineEditor {
+	fd := int(os.Stdin.Fd())
+	if in != object.DefaultStdin() || out != io.Writer(os.Stdout) || !isTerminal(fd) || !isTerminal(int(os.Stdout.Fd())) {
+		return nil
+	}
+	e := &lineEditor{in: in, out: out, fd: fd}
+	if home, err := os.UserHomeDir(); err == nil {
+		e.file = filepath.Join(home, historyName)
+		e.loadHistory()
+	}
+	return e
+}
+
+// loadHistory reads the saved history, trimming the file when it has grown
+// past historySize lines
+func (e *lineEditor) loadHistory() {
+	data, err := os.ReadFile(e.file)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+	if len(e.history) > historySize {
+		e.history = e.history[len(e.history)-historySize:]
+		os.WriteFile(e.file, []byte(strings.Join(e.history, "\n")+"\n"), 0o600)
+	}
+}
+
+// remember adds an entered line to the history and its file
+func (e *lineEditor) remember(line string) {
+	if strings.TrimSpace(line) == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return
+	}
+	e.history = append(e.history, line)
+	if e.file == "" {
+		return
+	}
+	f, err := os.OpenFile(e.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
+
+// ctrl is the character a control key sends
+func ctrl(key byte) rune {
+	return rune(key & 0x1f)
+}
+
+// readLine shows prompt and reads a line, letting it be edited in place.
+// The terminal is in raw mode only while the line is read, so programs run
+// in between see it as usual. It returns io.EOF when input ends or Ctrl-D
+// is pressed on an empty line, and errInterrupt for Ctrl-C.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		fmt.Fprint(e.out, prompt)
+		line, err := e.in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	defer restore()
+
+	var line []rune
+	pos := 0
+	browsing := len(e.history) // index of the history line shown
+	var draft []rune           // the line being typed, kept while browsing
+
+	for {
+		e.refresh(prompt, line, pos)
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			fmt.Fprintln(e.out)
+			return "", io.EOF
+		}
+
+		switch r {
+		case '\r', '\n':
+			fmt.Fprintln(e.out)
+			e.remember(string(line))
+			return string(line), nil
+		case ctrl('C'):
+			fmt.Fprintln(e.out, "^C")
+			return "", errInterrupt
+		case ctrl('D'):
+			if len(line) == 0 {
+				fmt.Fprintln(e.out)
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = append(line[:pos], line[pos+1:]...)
+			}
+		case ctrl('A'):
+			pos = 0
+		case ctrl('E'):
+			pos = len(line)
+		case ctrl('B'):
+			if pos > 0 {
+				pos--
+			}
+		case ctrl('F'):
+			if pos < len(line) {
+				pos++
+			}
+		case 127, ctrl('H'):
+			if pos > 0 {
+				line = append(line[:pos-1], line[pos:]...)
+				pos--
+			}
+		case ctrl('K'):
+			line = line[:pos]
+		case ctrl('U'):
+			line = append([]rune{}, line[pos:]...)
+			pos = 0
+		case ctrl('W'):
+			start := pos
+			for start > 0 && unicode.IsSpace(line[start-1]) {
+				start--
+			}
+			for start > 0 && !unicode.IsSpace(line[start-1]) {
+				start--
+			}
+			line = append(line[:start], line[pos:]...)
+			pos = start
+		case ctrl('L'):
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case ctrl('P'):
+			line, pos, browsing, draft = e.browse(-1, line, browsing, draft)
+		case ctrl('N'):
+			line, pos, browsing, draft = e.browse(+1, line, browsing, draft)
+		case '\x1b':
+			switch e.readEscape() {
+			case 'A':
+				line, pos, browsing, draft = e.browse(-1, line, browsing, draft)
+			case 'B':
+				line, pos, browsing, draft = e.browse(+1, line, browsing, draft)
+			case 'C':
+				if pos < len(line) {
+					pos++
+				}
+			case 'D':
+				if pos > 0 {
+					pos--
+				}
+			case 'H':
+				pos = 0
+			case 'F':
+				pos = len(line)
+			case 'X':
+				if pos < len(line) {
+					line = append(line[:pos], line[pos+1:]...)
+				}
+			}
+		case '\t':
+			line, pos = e.completeWord(line, pos)
+		default:
+			if r >= ' ' {
+				line = append(line[:pos], append([]rune{r}, line[pos:]...)...)
+				pos++
+			}
+		}
+	}
+}
+
+// completeWord completes the word before the cursor: a single candidate
+// is filled in, and several are filled in as far as they agree, or listed
+// below the line when they already agree no further. Without a word to
+// complete, Tab indents.
+func (e *lineEditor) completeWord(line []rune, pos int) ([]rune, int) {
+	start := pos
+	for start > 0 && isWordRune(line[start-1]) {
+		start--
+	}
+	if start == 1 && line[0] == ':' {
+		start = 0 // a REPL command
+	}
+	if start == pos || e.complete == nil {
+		return append(line[:pos], append([]rune("    "), line[pos:]...)...), pos + 4
+	}
+
+	word := string(line[start:pos])
+	candidates := e.complete(word)
+	if len(candidates) == 0 {
+		fmt.Fprint(e.out, "\a")
+		return line, pos
+	}
+	common := []rune(candidates[0])
+	for _, candidate := range candidates[1:] {
+		c := []rune(candidate)
+		n := 0
+		for n < len(common) && n < len(c) && common[n] == c[n] {
+			n++
+		}
+		common = common[:n]
+	}
+
+	if rest := common[pos-start:]; len(rest) > 0 {
+		line = append(line[:pos], append(append([]rune{}, rest...), line[pos:]...)...)
+		return line, pos + len(rest)
+	}
+	fmt.Fprint(e.out, "\n"+strings.Join(candidates, "  ")+"\n")
+	return line, pos
+}
+
+// isWordRune reports whether r can be part of a name: Bengali names are
+// letters with vowel signs and other marks joined to them
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r)
+}
+
+// readEscape reads the rest of an escape sequence and names the key it
+// stands for: A-D for the arrows, H and F for Home and End, X for Delete,
+// or 0 for a sequence the editor does not use
+func (e *lineEditor) readEscape() byte {
+	r, _, err := e.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return 0
+	}
+	var digits []rune
+	for {
+		r, _, err = e.in.ReadRune()
+		if err != nil {
+			return 0
+		}
+		if r < '0' || r > '9' {
+			break
+		}
+		digits = append(digits, r)
+	}
+	if r == '~' {
+		switch string(digits) {
+		case "1", "7":
+			return 'H'
+		case "4", "8":
+			return 'F'
+		case "3":
+			return 'X'
+		}
+		return 0
+	}
+	if r >= 'A' && r <= 'Z' {
+		return byte(r)
+	}
+	return 0
+}
+
+// browse moves through the history by step, keeping the line being typed
+// so that going past the newest entry brings it back
+func (e *lineEditor) browse(step int, line []rune, browsing int, draft []rune) ([]rune, int, int, []rune) {
+	next := browsing + step
+	if next < 0 || next > len(e.history) {
+		return line, len(line), browsing, draft
+	}
+	if browsing == len(e.history) {
+		draft = line
+	}
+	if next == len(e.history) {
+		line = draft
+	} else {
+		line = []rune(e.history[next])
+	}
+	return line, len(line), next, draft
+}
+
+// refresh redraws the prompt and line and puts the cursor at pos
+func (e *lineEditor) refresh(prompt string, line []rune, pos int) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(line))
+	if back := displayWidth(line[pos:]); back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+// displayWidth is how many columns runes take on a terminal; combining
+// marks such as the Bengali hasanta and chandrabindu join the character
+// before them
+func displayWidth(runes []rune) int {
+	width := 0
+	for _, r := range runes {
+		if !unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+			width++
+		}
+	}
+	return width
+}
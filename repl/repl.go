@@ -1,18 +1,19 @@
 package repl
 
 import (
-	"bhasa/compiler"
 	"bhasa/lexer"
-	"bhasa/object"
-	"bhasa/parser"
-	"bhasa/vm"
+	"bhasa/token"
 	"bufio"
 	"fmt"
 	"io"
+	"strings"
 )
 
 const PROMPT = ">> "
 
+// CONTINUATION_PROMPT asks for the next line of input that is not complete
+const CONTINUATION_PROMPT = ".. "
+
 const BANNER = `
 ╔═══════════════════════════════════════════════════╗
 ║   ভাষা (Bhasa) - Bengali Programming Language   ║
@@ -21,7 +22,10 @@ const BANNER = `
 
 Welcome! Type your Bengali code below.
 Commands:
-  - Type 'প্রস্থান' or 'exit' to quit
+  - Type 'প্রস্থান' or 'exit' to quit, ':help' for REPL commands
+  - Input continues on the next line while a bracket, string or comment
+    is open; an empty line runs it as it is
+  - ↑/↓ recall earlier lines, kept in ~/.bhasa_history
   - Use Bengali keywords: ধরি, ফাংশন, যদি, নাহলে, ফেরত
   - Built-in functions: লেখ(), দৈর্ঘ্য(), প্রথম(), শেষ()
 
@@ -31,70 +35,122 @@ Example:
 
 `
 
+// Options are the settings of a REPL
+type Options struct {
+	BengaliNumerals bool // show numbers with Bengali digits
+}
+
 // Start starts the REPL
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+	StartWithOptions(in, out, Options{})
+}
 
-	constants := []object.Object{}
-	globals := make([]object.Object, vm.GlobalsSize)
-	symbolTable := compiler.NewSymbolTable()
-	for i, v := range object.Builtins {
-		symbolTable.DefineBuiltin(i, v.Name)
+// StartWithOptions starts the REPL with the given settings
+func StartWithOptions(in io.Reader, out io.Writer, opts Options) {
+	// The same reader is handed to the VM so programs can read input
+	// with পড়ো without losing lines buffered here
+	reader, ok := in.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReader(in)
 	}
 
+	session := newSession(reader, out)
+	session.opts = opts
+
 	fmt.Fprint(out, BANNER)
 
+	editor := newLineEditor(reader, out)
+	if editor != nil {
+		editor.complete = session.completions
+	}
+	var pending []string
 	for {
-		fmt.Fprint(out, PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
-			return
+		prompt := PROMPT
+		if pending != nil {
+			prompt = CONTINUATION_PROMPT
 		}
-
-		line := scanner.Text()
-
-		// Exit commands
-		if line == "প্রস্থান" || line == "exit" || line == "quit" {
-			fmt.Fprintln(out, "আবার দেখা হবে! (Goodbye!)")
+		line, err := readLine(editor, reader, out, prompt)
+		if err == errInterrupt {
+			pending = nil
+			continue
+		}
+		if err != nil {
 			return
 		}
 
-		if line == "" {
-			continue
+		if pending == nil {
+			// Exit commands
+			if line == "প্রস্থান" || line == "exit" || line == "quit" {
+				fmt.Fprintln(out, "আবার দেখা হবে! (Goodbye!)")
+				return
+			}
+
+			if line == "" {
+				continue
+			}
+
+			if command := strings.TrimSpace(line); strings.HasPrefix(command, ":") {
+				session.command(command)
+				continue
+			}
 		}
 
-		l := lexer.New(line)
-		p := parser.New(l)
-
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
-			continue
+		// Keep reading while the input is unfinished; an empty line gives
+		// up and lets the parser say what is wrong
+		if line != "" || pending == nil {
+			pending = append(pending, line)
+			if incomplete(strings.Join(pending, "\n")) {
+				continue
+			}
 		}
+		line = strings.Join(pending, "\n")
+		pending = nil
 
-		comp := compiler.NewWithState(symbolTable, constants)
-		err := comp.Compile(program)
-		if err != nil {
-			fmt.Fprintf(out, "Compilation failed:\n %s\n", err)
-			continue
+		if machine := session.run(line, ""); machine != nil {
+			session.show(machine)
 		}
+	}
+}
 
-		code := comp.Bytecode()
-		constants = code.Constants
-
-		machine := vm.NewWithGlobalsStore(code, globals)
-		err = machine.Run()
-		if err != nil {
-			fmt.Fprintf(out, "Executing bytecode failed:\n %s\n", err)
-			continue
-		}
+// readLine reads a line of input with the editor, or straight from reader
+// when there is none
+func readLine(editor *lineEditor, reader *bufio.Reader, out io.Writer, prompt string) (string, error) {
+	if editor != nil {
+		return editor.readLine(prompt)
+	}
+	fmt.Fprint(out, prompt)
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
 
-		lastPopped := machine.LastPoppedStackElem()
-		if lastPopped != nil {
-			io.WriteString(out, lastPopped.Inspect())
-			io.WriteString(out, "\n")
+// incomplete reports whether src stops inside a bracket, a string or a
+// block comment, so that more lines are needed before it can be parsed
+func incomplete(src string) bool {
+	l := lexer.New(src)
+	depth := 0
+	var last token.Token
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACE, token.LBRACKET:
+			depth++
+		case token.RPAREN, token.RBRACE, token.RBRACKET:
+			depth--
+		case token.ILLEGAL:
+			// An unterminated raw string or block comment runs to the end
+			if tok.Literal == "`" || tok.Literal == "/*" {
+				return true
+			}
 		}
+		last = tok
+	}
+	// A string left open also runs to the end of the input
+	if last.Type == token.STRING && !strings.HasSuffix(strings.TrimRight(src, " \t\n"), `"`) {
+		return true
 	}
+	return depth > 0
 }
 
 func printParserErrors(out io.Writer, errors []string) {
@@ -0,0 +1,235 @@
+package repl
+
+import (
+	"bhasa/ast"
+	"bhasa/compiler"
+	"bhasa/lexer"
+	"bhasa/object"
+	"bhasa/parser"
+	"bhasa/token"
+	"bhasa/vm"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// session is the state the inputs of a REPL build up: the names they
+// define, their constants and the values of their globals
+type session struct {
+	reader *bufio.Reader
+	out    io.Writer
+	opts   Options
+
+	symbols   *compiler.SymbolTable
+	constants []object.Object
+	globals   []object.Object
+
+	last      *compiler.Bytecode // the bytecode of the last input compiled
+	lastFirst int                // the first constant the last input added
+}
+
+func newSession(reader *bufio.Reader, out io.Writer) *session {
+	s := &session{reader: reader, out: out}
+	s.reset()
+	return s
+}
+
+// reset forgets everything defined so far
+func (s *session) reset() {
+	s.constants = []object.Object{}
+	s.globals = make([]object.Object, vm.GlobalsSize)
+	s.symbols = compiler.NewSymbolTable()
+	for i, v := range object.Builtins {
+		s.symbols.DefineBuiltin(i, v.Name)
+	}
+	s.last = nil
+}
+
+// parse parses input, reporting any errors; file names where it came from
+func (s *session) parse(src, file string) *ast.Program {
+	l := lexer.New(src)
+	if file != "" {
+		l.SetFile(file)
+	}
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(s.out, p.Errors())
+		return nil
+	}
+	return program
+}
+
+// run compiles and runs input in the session, returning the VM it ran on,
+// or nil after reporting an error. Modules imported by a file are looked
+// up next to it.
+func (s *session) run(src, file string) *vm.VM {
+	program := s.parse(src, file)
+	if program == nil {
+		return nil
+	}
+
+	comp := compiler.NewWithState(s.symbols, s.constants)
+	if file != "" {
+		comp.SetFile(file)
+		comp.SetModuleLoader(compiler.DirModuleLoader(filepath.Dir(file)))
+	}
+	if err := comp.Compile(program); err != nil {
+		fmt.Fprintf(s.out, "Compilation failed:\n %s\n", err)
+		return nil
+	}
+
+	code := comp.Bytecode()
+	s.last, s.lastFirst = code, len(s.constants)
+	s.constants = code.Constants
+
+	machine := vm.NewWithGlobalsStore(code, s.globals)
+	machine.SetStdin(s.reader)
+	machine.SetStdout(s.out)
+	machine.SetBengaliNumerals(s.opts.BengaliNumerals)
+	if err := machine.Run(); err != nil {
+		fmt.Fprintf(s.out, "Executing bytecode failed:\n %s\n", err)
+		return nil
+	}
+	return machine
+}
+
+// show prints the value of the last expression an input ran, if any
+func (s *session) show(machine *vm.VM) {
+	lastPopped := machine.LastPoppedStackElem()
+	if lastPopped == nil {
+		return
+	}
+	text, errObj := object.DisplayText(machine, lastPopped)
+	if errObj != nil {
+		text = errObj.Inspect()
+	}
+	io.WriteString(s.out, text)
+	io.WriteString(s.out, "\n")
+}
+
+// commandNames are the REPL commands, for completion
+var commandNames = []string{":bytecode", ":help", ":load", ":reset", ":type"}
+
+// completions lists the keywords, builtins, names defined in the session
+// and REPL commands that start with prefix, sorted and without repeats
+func (s *session) completions(prefix string) []string {
+	var names []string
+	if strings.HasPrefix(prefix, ":") {
+		names = commandNames
+	} else {
+		names = append(names, token.Keywords()...)
+		for _, builtin := range object.Builtins {
+			names = append(names, builtin.Name)
+		}
+		names = append(names, s.symbols.GlobalNames()...)
+	}
+
+	seen := map[string]bool{}
+	var matches []string
+	for _, name := range names {
+		if name != "" && strings.HasPrefix(name, prefix) && !seen[name] {
+			seen[name] = true
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// commandHelp describes the REPL commands
+const commandHelp = `Commands:
+  :load <file>    Run a file in this session, keeping what it defines
+  :type <expr>    Show the type of an expression's value
+  :bytecode       Show the bytecode compiled for the last input
+  :reset          Forget every variable, function and class defined so far
+  :help           Show this help
+`
+
+// command runs a colon-prefixed REPL command
+func (s *session) command(line string) {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case ":help":
+		io.WriteString(s.out, commandHelp)
+
+	case ":load":
+		if arg == "" {
+			fmt.Fprintln(s.out, "usage: :load <file>")
+			return
+		}
+		src, err := os.ReadFile(arg)
+		if err != nil {
+			fmt.Fprintln(s.out, err)
+			return
+		}
+		s.run(string(src), arg)
+
+	case ":type":
+		if arg == "" {
+			fmt.Fprintln(s.out, "usage: :type <expression>")
+			return
+		}
+		s.showType(arg)
+
+	case ":bytecode":
+		if s.last == nil {
+			fmt.Fprintln(s.out, "nothing has been compiled yet")
+			return
+		}
+		io.WriteString(s.out, s.last.Disassemble(s.lastFirst))
+
+	case ":reset":
+		s.reset()
+		fmt.Fprintln(s.out, "session reset")
+
+	default:
+		fmt.Fprintf(s.out, "unknown command %s; type :help for the list\n", name)
+	}
+}
+
+// showType prints the type of an expression. Values are only typed when
+// the program runs, so the expression is evaluated; a variable declared
+// with a type also shows the type it was declared with.
+func (s *session) showType(src string) {
+	program := s.parse(src, "")
+	if program == nil {
+		return
+	}
+	if len(program.Statements) != 1 {
+		fmt.Fprintln(s.out, ":type takes a single expression")
+		return
+	}
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		fmt.Fprintln(s.out, ":type takes an expression, not a statement")
+		return
+	}
+
+	declared := ""
+	if ident, ok := stmt.Expression.(*ast.Identifier); ok {
+		if symbol, ok := s.symbols.Lookup(ident.Value); ok && symbol.TypeAnnot != nil {
+			declared = symbol.TypeAnnot.String()
+		}
+	}
+
+	machine := s.run(src, "")
+	if machine == nil {
+		return
+	}
+	value := machine.LastPoppedStackElem()
+	if value == nil {
+		value = vm.Null
+	}
+	if declared != "" {
+		fmt.Fprintf(s.out, "%s (declared %s)\n", value.Type(), declared)
+		return
+	}
+	fmt.Fprintln(s.out, value.Type())
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package repl
+
+import "errors"
+
+// isTerminal reports false: the line editor needs termios, so other
+// systems read plain lines
+func isTerminal(fd int) bool { return false }
+
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this system")
+}
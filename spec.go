@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bhasa/evaluator"
+	"bhasa/object"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// specDir holds the spec files run by the spec command
+const specDir = "tests/spec"
+
+// Comments that state what a spec file expects of a run
+const (
+	expectMarker      = "// expect:"       // the next line of output
+	expectErrorMarker = "// expect error:" // the run fails with an error containing the text
+	enginesMarker     = "// engines:"      // run only on the listed engines
+	numeralsMarker    = "// numerals:"     // run with -numerals set to the value
+)
+
+// specEngines are the engines every spec runs on unless it lists its own
+var specEngines = []string{"vm", "eval"}
+
+// spec is what a spec file expects, read from its comments
+type spec struct {
+	output  []string
+	err     string
+	engines []string
+	bengali bool // numbers are shown with Bengali digits
+}
+
+// parseSpec reads the expectations of a spec file. A file without any is
+// rejected so that a typo in a marker cannot make a spec pass vacuously.
+func parseSpec(src string) (*spec, error) {
+	s := &spec{engines: specEngines}
+	found := false
+	for _, line := range strings.Split(src, "\n") {
+		if i := strings.Index(line, expectErrorMarker); i >= 0 {
+			s.err = strings.TrimSpace(line[i+len(expectErrorMarker):])
+			found = true
+		} else if i := strings.Index(line, expectMarker); i >= 0 {
+			s.output = append(s.output, strings.TrimSpace(line[i+len(expectMarker):]))
+			found = true
+		} else if i := strings.Index(line, enginesMarker); i >= 0 {
+			s.engines = strings.Fields(strings.ReplaceAll(line[i+len(enginesMarker):], ",", " "))
+			for _, engine := range s.engines {
+				if engine != "vm" && engine != "eval" {
+					return nil, fmt.Errorf("unknown engine %q; use vm or eval", engine)
+				}
+			}
+		} else if i := strings.Index(line, numeralsMarker); i >= 0 {
+			bengali, err := bengaliNumerals(strings.TrimSpace(line[i+len(numeralsMarker):]))
+			if err != nil {
+				return nil, err
+			}
+			s.bengali = bengali
+		}
+	}
+	if s.bengali {
+		for _, engine := range s.engines {
+			if engine == "eval" {
+				return nil, fmt.Errorf("the evaluator has no numerals setting; add %q", enginesMarker+" vm")
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no %q or %q comments", expectMarker, expectErrorMarker)
+	}
+	return s, nil
+}
+
+// captureStdout runs fn with standard output redirected to a pipe and
+// returns what it printed. The evaluator prints through the process-wide
+// runtime, so this is how its output is collected.
+func captureStdout(fn func() error) (string, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return "", err
+	}
+	done := make(chan string)
+	go func() {
+		var sb strings.Builder
+		io.Copy(&sb, r)
+		r.Close()
+		done <- sb.String()
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	runErr := fn()
+	os.Stdout = stdout
+	w.Close()
+	return <-done, runErr
+}
+
+// runSpecVM compiles a spec and runs it on the VM
+func runSpecVM(file string, s *spec) (string, error) {
+	bytecode, err := compileFile(file)
+	if err != nil {
+		return "", err
+	}
+	var output strings.Builder
+	err = runProgram(bytecode, nil, runOptions{stdout: &output, bengali: s.bengali})
+	return output.String(), err
+}
+
+// runSpecEval runs a spec on the tree-walking evaluator
+func runSpecEval(file string, s *spec) (string, error) {
+	program, err := parseFile(file)
+	if err != nil {
+		return "", err
+	}
+	return captureStdout(func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("evaluator panicked: %v", r)
+			}
+		}()
+		if result, ok := evaluator.Eval(program, object.NewEnvironment()).(*object.Error); ok {
+			return errors.New(result.Message)
+		}
+		return nil
+	})
+}
+
+// checkSpec compares one run of a spec with its expectations, returning
+// a description of the first difference or "" when there is none
+func checkSpec(s *spec, output string, runErr error) string {
+	// An error the spec does not expect explains any missing output
+	if s.err == "" && runErr != nil {
+		return fmt.Sprintf("unexpected error: %s", runErr)
+	}
+
+	got := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if output == "" {
+		got = nil
+	}
+	for i := 0; i < len(s.output) || i < len(got); i++ {
+		switch {
+		case i >= len(got):
+			return fmt.Sprintf("output ends after %d lines, want %q next", len(got), s.output[i])
+		case i >= len(s.output):
+			return fmt.Sprintf("unexpected output line %d: %q", i+1, got[i])
+		case got[i] != s.output[i]:
+			return fmt.Sprintf("output line %d: got %q, want %q", i+1, got[i], s.output[i])
+		}
+	}
+
+	switch {
+	case s.err != "" && runErr == nil:
+		return fmt.Sprintf("no error, want one containing %q", s.err)
+	case s.err != "" && !strings.Contains(runErr.Error(), s.err):
+		return fmt.Sprintf("error %q does not contain %q", runErr, s.err)
+	}
+	return ""
+}
+
+func cmdSpec(args []string) int {
+	fs := newFlagSet("spec")
+	engine := fs.String("engine", "", "Run on this engine only (vm or eval)")
+	paths, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if *engine != "" && *engine != "vm" && *engine != "eval" {
+		return fail(fmt.Errorf("unknown engine %q; use vm or eval", *engine))
+	}
+	if len(paths) == 0 {
+		paths = []string{specDir}
+	}
+
+	files, err := collectSourceFiles(paths)
+	if err != nil {
+		return fail(err)
+	}
+
+	runners := map[string]func(string, *spec) (string, error){"vm": runSpecVM, "eval": runSpecEval}
+	failed, skipped := 0, 0
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL %s\n\t%s\n", file, err)
+			continue
+		}
+		s, err := parseSpec(string(src))
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL %s\n\t%s\n", file, err)
+			continue
+		}
+
+		var ran, problems []string
+		for _, name := range s.engines {
+			if *engine != "" && name != *engine {
+				continue
+			}
+			ran = append(ran, name)
+			output, runErr := runners[name](file, s)
+			if diff := checkSpec(s, output, runErr); diff != "" {
+				problems = append(problems, fmt.Sprintf("[%s] %s", name, strings.ReplaceAll(diff, "\n", "\n\t")))
+			}
+		}
+		if len(ran) == 0 {
+			skipped++
+			fmt.Printf("skip %s (%s only)\n", file, strings.Join(s.engines, ", "))
+			continue
+		}
+		if len(problems) > 0 {
+			failed++
+			fmt.Printf("FAIL %s\n\t%s\n", file, strings.Join(problems, "\n\t"))
+			continue
+		}
+		fmt.Printf("ok   %s (%s)\n", file, strings.Join(ran, ", "))
+	}
+
+	fmt.Printf("\n%d passed, %d failed, %d skipped\n", len(files)-failed-skipped, failed, skipped)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -1,5 +1,10 @@
 package token
 
+import (
+	"sort"
+	"strings"
+)
+
 // TokenType represents the type of a token
 type TokenType string
 
@@ -7,8 +12,9 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
-	Line    int // Line number where token appears
-	Column  int // Column number where token appears
+	Line    int    // Line number where token appears
+	Column  int    // Column number where token appears
+	Doc     string // Text of the /// comment lines just before the token
 }
 
 // Token types
@@ -17,9 +23,13 @@ const (
 	EOF     = "EOF"
 
 	// Identifiers and literals
-	IDENT  = "IDENT"  // variable names
-	INT    = "INT"    // integers
-	STRING = "STRING" // strings
+	IDENT      = "IDENT"      // variable names
+	INT        = "INT"        // integers
+	FLOAT      = "FLOAT"      // floating-point numbers (1.5, 1e9, ১.৫ই৩)
+	DECIMAL    = "DECIMAL"    // exact decimals (19.99দ, 0.1d)
+	STRING     = "STRING"     // strings
+	RAW_STRING = "RAW_STRING" // `raw strings`, taken exactly as written
+	CHAR       = "CHAR"       // character literals ('ক')
 
 	// Operators
 	ASSIGN   = "="
@@ -30,6 +40,13 @@ const (
 	SLASH    = "/"
 	PERCENT  = "%"
 
+	// Compound assignment, rewritten to plain assignment before compiling
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	PERCENT_ASSIGN  = "%="
+
 	LT     = "<"
 	GT     = ">"
 	EQ     = "=="
@@ -40,6 +57,10 @@ const (
 	AND = "&&" // Logical AND
 	OR  = "||" // Logical OR
 
+	// Null-safety operators
+	QUESTION_DOT  = "?." // member access that gives null on a null object
+	NULL_COALESCE = "??" // left value, or right when it is null
+
 	// Bitwise operators
 	BIT_AND   = "&"  // Bitwise AND
 	BIT_OR    = "|"  // Bitwise OR
@@ -73,6 +94,8 @@ const (
 	BREAK    = "বিরতি"       // break
 	CONTINUE = "চালিয়ে_যাও"  // continue
 	IMPORT   = "অন্তর্ভুক্ত"  // import/include
+	FOREACH  = "প্রতিটি"     // for each (প্রতিটি (x মধ্যে তালিকা))
+	IN       = "মধ্যে"       // in, inside a for-each loop
 
 	// Type keywords (Bengali)
 	TYPE_BYTE    = "বাইট"           // byte type
@@ -89,10 +112,11 @@ const (
 	AS           = "হিসাবে"        // type casting keyword (as/in the form of)
 
 	// Struct and Enum keywords
-	STRUCT = "স্ট্রাক্ট" // struct keyword
-	ENUM   = "গণনা"     // enum keyword (enumeration in Bengali)
-	DOT    = "."        // dot for field access
-	ARROW  = "=>"       // arrow for pattern matching
+	STRUCT   = "স্ট্রাক্ট" // struct keyword
+	ENUM     = "গণনা"     // enum keyword (enumeration in Bengali)
+	DOT      = "."        // dot for field access
+	ELLIPSIS = "..."      // marks a rest parameter
+	ARROW    = "=>"       // arrow functions: (x) => x * ২
 
 	// OOP keywords (Bengali - meaningful, not transliteration)
 	CLASS       = "শ্রেণী"       // class (category/class in Bengali)
@@ -126,6 +150,8 @@ var keywords = map[string]TokenType{
 	"বিরতি":       BREAK,
 	"চালিয়ে_যাও":  CONTINUE,
 	"অন্তর্ভুক্ত": IMPORT,
+	"প্রতিটি":     FOREACH,
+	"মধ্যে":       IN,
 	// Type keywords
 	"বাইট":           TYPE_BYTE,
 	"ছোট_সংখ্যা":     TYPE_SHORT,
@@ -169,6 +195,26 @@ func LookupIdent(ident string) TokenType {
 	return IDENT
 }
 
+// Keywords lists every keyword, in sorted order
+func Keywords() []string {
+	names := make([]string, 0, len(keywords))
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsType reports whether t is one of the type keywords, such as পূর্ণসংখ্যা
+func IsType(t TokenType) bool {
+	switch t {
+	case TYPE_BYTE, TYPE_SHORT, TYPE_INT, TYPE_LONG, TYPE_FLOAT, TYPE_DOUBLE,
+		TYPE_CHAR, TYPE_STRING, TYPE_BOOLEAN, TYPE_ARRAY, TYPE_HASH:
+		return true
+	}
+	return false
+}
+
 var BengaliDigits = map[rune]rune{
 	'০': '0',
 	'১': '1',
@@ -182,6 +228,20 @@ var BengaliDigits = map[rune]rune{
 	'৯': '9',
 }
 
+// ExponentMarker is the Bengali letter that may replace 'e' in scientific
+// notation, as in ১.৫ই৩
+const ExponentMarker = 'ই'
+
+// DecimalSuffix is the Bengali letter that marks an exact decimal literal,
+// as in ১৯.৯৯দ; the ASCII suffix is 'd'
+const DecimalSuffix = 'দ'
+
+// NormalizeFloat converts a floating-point literal written with Bengali
+// digits or the Bengali exponent marker into the form strconv expects
+func NormalizeFloat(s string) string {
+	return strings.ReplaceAll(ConvertBengaliNumber(s), string(ExponentMarker), "e")
+}
+
 func ConvertBengaliNumber(s string) string {
 	result := ""
 	for _, ch := range s {
@@ -0,0 +1,77 @@
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build information. Version, Commit and BuildDate are overridden at build
+// time through the linker, e.g.
+//
+//	go build -ldflags "-X bhasa/version.Commit=$(git rev-parse --short HEAD)"
+var (
+	Version   = "1.0.0"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// BytecodeVersion is the format version written into compiled files
+const BytecodeVersion uint32 = 4
+
+// Info describes the running build
+type Info struct {
+	Version         string `json:"version"`
+	Commit          string `json:"commit"`
+	BuildDate       string `json:"buildDate"`
+	BytecodeVersion uint32 `json:"bytecodeVersion"`
+	GoVersion       string `json:"goVersion"`
+	Platform        string `json:"platform"`
+}
+
+// Get returns the build information, falling back to the VCS data recorded
+// by the Go toolchain when the linker flags were not set
+func Get() Info {
+	info := Info{
+		Version:         Version,
+		Commit:          Commit,
+		BuildDate:       BuildDate,
+		BytecodeVersion: BytecodeVersion,
+		GoVersion:       runtime.Version(),
+		Platform:        runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+					if len(info.Commit) > 12 {
+						info.Commit = info.Commit[:12]
+					}
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = s.Value
+				}
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+// String formats the build information for humans
+func (i Info) String() string {
+	return fmt.Sprintf("Bhasa (ভাষা) Programming Language v%s\n"+
+		"Bytecode Compiler & VM (bytecode format v%d)\n"+
+		"commit %s, built %s, %s %s",
+		i.Version, i.BytecodeVersion, i.Commit, i.BuildDate, i.GoVersion, i.Platform)
+}
@@ -0,0 +1,61 @@
+package vm
+
+import "bhasa/object"
+
+// AllocKind is a kind of value the VM allocates as it runs
+type AllocKind int
+
+const (
+	AllocInteger AllocKind = iota
+	AllocFloat
+	AllocString
+	AllocArray
+	AllocHash
+	AllocTuple
+	AllocClosure
+	AllocCell // a variable shared between closures
+
+	numAllocKinds
+)
+
+// Name is the type of the values of a kind, as Type() reports it
+func (k AllocKind) Name() string {
+	return [...]string{
+		object.INTEGER_OBJ, object.FLOAT_OBJ, object.STRING_OBJ, object.ARRAY_OBJ,
+		object.HASH_OBJ, object.TUPLE_OBJ, object.CLOSURE_OBJ, "CELL",
+	}[k]
+}
+
+// Allocations counts the values a VM created, by kind. Values from an
+// arena are counted like the others. Builtins allocate on their own and
+// are not counted, so these are the costs of the program's own arithmetic,
+// literals and closures.
+type Allocations [numAllocKinds]uint64
+
+// Total is the number of values counted
+func (a *Allocations) Total() uint64 {
+	var total uint64
+	for _, n := range a {
+		total += n
+	}
+	return total
+}
+
+// CountAllocations makes the VM count the values it allocates, including
+// those of functions builtins call back into
+func (vm *VM) CountAllocations() {
+	vm.allocs = &Allocations{}
+}
+
+// Allocations returns the values counted since CountAllocations, or nil
+// when the VM is not counting
+func (vm *VM) Allocations() *Allocations {
+	return vm.allocs
+}
+
+// allocated counts a value of the given kind when counting is on
+func (vm *VM) allocated(kind AllocKind) {
+	if vm.allocs != nil {
+		vm.allocs[kind]++
+	}
+}
@@ -0,0 +1,68 @@
+package vm
+
+import "bhasa/object"
+
+// arenaChunk is how many objects an arena allocates at a time
+const arenaChunk = 512
+
+// arena hands out the integers and floats produced by arithmetic from
+// slabs allocated a chunk at a time, so a run of temporaries costs one
+// allocation per chunk instead of one per value. Slots are never handed
+// out twice: a value that escapes (into a global, an array, a closure)
+// stays valid and keeps its slab alive, and a slab is collected once all
+// of its values are dead. The cost is that one long-lived value can pin a
+// whole slab.
+type arena struct {
+	ints   []object.Integer
+	floats []object.Float
+}
+
+func (a *arena) newInteger(value int64) *object.Integer {
+	if len(a.ints) == 0 {
+		a.ints = make([]object.Integer, arenaChunk)
+	}
+	i := &a.ints[0]
+	a.ints = a.ints[1:]
+	i.Value = value
+	return i
+}
+
+func (a *arena) newFloat(value float32) *object.Float {
+	if len(a.floats) == 0 {
+		a.floats = make([]object.Float, arenaChunk)
+	}
+	f := &a.floats[0]
+	a.floats = a.floats[1:]
+	f.Value = value
+	return f
+}
+
+// EnableArena makes the VM allocate arithmetic results from an arena for
+// the rest of its run
+func (vm *VM) EnableArena() {
+	if vm.arena == nil {
+		vm.arena = &arena{}
+	}
+}
+
+// newInteger returns an integer result: a shared one for small values,
+// otherwise allocated from the arena when enabled
+func (vm *VM) newInteger(value int64) *object.Integer {
+	if value >= object.SmallIntMin && value <= object.SmallIntMax {
+		return object.NewInteger(value)
+	}
+	vm.allocated(AllocInteger)
+	if vm.arena != nil {
+		return vm.arena.newInteger(value)
+	}
+	return &object.Integer{Value: value}
+}
+
+// newFloat allocates a float result, from the arena when enabled
+func (vm *VM) newFloat(value float32) *object.Float {
+	vm.allocated(AllocFloat)
+	if vm.arena != nil {
+		return vm.arena.newFloat(value)
+	}
+	return &object.Float{Value: value}
+}
@@ -0,0 +1,92 @@
+package vm
+
+import (
+	"bhasa/code"
+	"bhasa/object"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// bigOperators names the operators object.BigArithmetic applies, indexed
+// by opcode like handlers since every integer operation looks one up
+var bigOperators = [256]string{
+	code.OpAdd:        "+",
+	code.OpSub:        "-",
+	code.OpMul:        "*",
+	code.OpDiv:        "/",
+	code.OpMod:        "%",
+	code.OpBitAnd:     "&",
+	code.OpBitOr:      "|",
+	code.OpBitXor:     "^",
+	code.OpLeftShift:  "<<",
+	code.OpRightShift: ">>",
+}
+
+// executeBinaryBigIntegerOperation handles arithmetic where an operand is a
+// BigInteger or where Integer arithmetic overflowed. Mixed with a float,
+// the operation is done in floating point.
+func (vm *VM) executeBinaryBigIntegerOperation(op code.Opcode, left, right object.Object) error {
+	operator := bigOperators[op]
+	if operator == "" {
+		return fmt.Errorf("unknown integer operator: %d", op)
+	}
+
+	if vm.isFloatingType(left.Type()) || vm.isFloatingType(right.Type()) {
+		switch op {
+		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpMod:
+			return vm.executeBinaryFloatOperation(op, left, right)
+		}
+		return fmt.Errorf("bitwise operations not supported for floating-point types")
+	}
+
+	leftValue, leftOK := object.BigValue(left)
+	rightValue, rightOK := object.BigValue(right)
+	if !leftOK || !rightOK {
+		return fmt.Errorf("unsupported types for binary operation: %s %s", left.Type(), right.Type())
+	}
+
+	result, errObj := object.BigArithmetic(operator, leftValue, rightValue)
+	if errObj != nil {
+		return fmt.Errorf("%s", errObj.Message)
+	}
+	return vm.push(result)
+}
+
+// executeBigIntegerComparison compares two numbers when one is a
+// BigInteger. Floats are compared exactly rather than by rounding the
+// integer to a float64.
+func (vm *VM) executeBigIntegerComparison(op code.Opcode, left, right object.Object) error {
+	l, r := vm.toBigFloat(left), vm.toBigFloat(right)
+	if l == nil || r == nil {
+		// NaN is unequal to everything
+		return vm.push(nativeBoolToBooleanObject(op == code.OpNotEqual))
+	}
+	cmp := l.Cmp(r)
+
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(cmp == 0))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(cmp != 0))
+	case code.OpGreaterThan:
+		return vm.push(nativeBoolToBooleanObject(cmp > 0))
+	case code.OpGreaterThanEqual:
+		return vm.push(nativeBoolToBooleanObject(cmp >= 0))
+	default:
+		return fmt.Errorf("unknown operator: %d", op)
+	}
+}
+
+// toBigFloat converts a numeric operand to a big.Float, or returns nil for
+// NaN; ±Inf compare beyond every integer
+func (vm *VM) toBigFloat(obj object.Object) *big.Float {
+	if value, ok := object.BigValue(obj); ok {
+		return new(big.Float).SetInt(value)
+	}
+	f := vm.toFloat64(obj)
+	if math.IsNaN(f) {
+		return nil
+	}
+	return big.NewFloat(f)
+}
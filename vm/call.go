@@ -0,0 +1,80 @@
+package vm
+
+import (
+	"bhasa/code"
+	"bhasa/compiler"
+	"bhasa/object"
+	"bufio"
+)
+
+// CallFunction calls fn with args and returns its result, letting builtins
+// call back into Bhasa code. Closures run in a child VM that shares this
+// VM's constants, globals and standard streams but has its own stack, so
+// the call may happen on another goroutine. Callers must make sure no two
+// calls (or the VM itself) run at the same time, since globals are shared.
+func (vm *VM) CallFunction(fn object.Object, args ...object.Object) object.Object {
+	if builtin, ok := fn.(*object.Builtin); ok {
+		return builtin.Call(vm, args...)
+	}
+
+	child := vm.child(len(args))
+	child.globals = vm.globals
+	child.profile = vm.profile
+	child.allocs = vm.allocs
+	child.trace, child.traceDepth = vm.trace, vm.traceDepth+vm.framesIndex
+	return child.call(fn, args)
+}
+
+// child returns a VM whose main program is a single call of a function
+// with argc arguments, sharing this VM's constants, standard streams and
+// watches. Its callers give it this VM's globals, so the watches on
+// globals apply to it too.
+func (vm *VM) child(argc int) *VM {
+	child := New(&compiler.Bytecode{
+		Instructions: code.Make(code.OpCall, argc),
+		Constants:    vm.constants,
+	})
+	vm.share(child)
+	child.watches = vm.watches
+	return child
+}
+
+// share gives a VM this one starts its standard streams, arguments,
+// numeral setting and watches on locals. The events of a host watching this VM still arrive
+// through the streams, while the result of the child's run is left for
+// the caller rather than reported as the run's.
+func (vm *VM) share(child *VM) {
+	child.stdin = vm.stdin
+	if vm.input != nil {
+		// A buffer of its own, so the child never holds input another
+		// task should read
+		child.stdin = bufio.NewReader(vm.input)
+		child.input = vm.input
+	}
+	child.stdout = vm.stdout
+	child.stderr = vm.stderr
+	child.args = vm.args
+	child.bengaliNumerals = vm.bengaliNumerals
+	child.localWatches = vm.localWatches
+}
+
+// call pushes fn and its arguments for the main program of a VM made by
+// child, runs it and returns the result left on top of the stack
+func (vm *VM) call(fn object.Object, args []object.Object) object.Object {
+	if err := vm.push(fn); err != nil {
+		return &object.Error{Message: err.Error()}
+	}
+	for _, arg := range args {
+		if err := vm.push(arg); err != nil {
+			return &object.Error{Message: err.Error()}
+		}
+	}
+	if err := vm.Run(); err != nil {
+		return &object.Error{Message: err.Error()}
+	}
+
+	if result := vm.StackTop(); result != nil {
+		return result
+	}
+	return Null
+}
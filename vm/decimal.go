@@ -0,0 +1,98 @@
+package vm
+
+import (
+	"bhasa/code"
+	"bhasa/object"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// decimalOperators names the operators object.DecimalArithmetic applies
+var decimalOperators = map[code.Opcode]string{
+	code.OpAdd: "+",
+	code.OpSub: "-",
+	code.OpMul: "*",
+	code.OpDiv: "/",
+}
+
+// executeBinaryDecimalOperation handles arithmetic with a Decimal operand.
+// The other operand may be a decimal or an integer; floats must be
+// converted with নির্ভুল first, so binary rounding errors cannot creep in.
+func (vm *VM) executeBinaryDecimalOperation(op code.Opcode, left, right object.Object) error {
+	operator, ok := decimalOperators[op]
+	if !ok {
+		return fmt.Errorf("unknown decimal operator: %d", op)
+	}
+	leftValue, leftOK := object.DecimalValue(left)
+	rightValue, rightOK := object.DecimalValue(right)
+	if !leftOK || !rightOK {
+		return fmt.Errorf("unsupported types for binary operation: %s %s (convert with নির্ভুল)", left.Type(), right.Type())
+	}
+
+	result, errObj := object.DecimalArithmetic(operator, leftValue, rightValue)
+	if errObj != nil {
+		return fmt.Errorf("%s", errObj.Message)
+	}
+	return vm.push(result)
+}
+
+// executeDecimalComparison compares two numbers when one is a Decimal.
+// Floats are compared by their exact binary value.
+func (vm *VM) executeDecimalComparison(op code.Opcode, left, right object.Object) error {
+	cmp, ok := vm.compareRat(left, right)
+	if !ok {
+		// NaN is unequal to everything
+		return vm.push(nativeBoolToBooleanObject(op == code.OpNotEqual))
+	}
+
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(cmp == 0))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(cmp != 0))
+	case code.OpGreaterThan:
+		return vm.push(nativeBoolToBooleanObject(cmp > 0))
+	case code.OpGreaterThanEqual:
+		return vm.push(nativeBoolToBooleanObject(cmp >= 0))
+	default:
+		return fmt.Errorf("unknown operator: %d", op)
+	}
+}
+
+// compareRat orders two numbers exactly, reporting false if either is NaN
+func (vm *VM) compareRat(left, right object.Object) (int, bool) {
+	l, lInf := vm.toRat(left)
+	r, rInf := vm.toRat(right)
+	switch {
+	case (l == nil && lInf == 0) || (r == nil && rInf == 0):
+		return 0, false
+	case lInf != 0 || rInf != 0:
+		switch {
+		case lInf < rInf:
+			return -1, true
+		case lInf > rInf:
+			return 1, true
+		}
+		return 0, true
+	}
+	return l.Cmp(r), true
+}
+
+// toRat converts a numeric operand to a big.Rat. For ±Inf it returns nil
+// and the sign of the infinity, for NaN nil and zero.
+func (vm *VM) toRat(obj object.Object) (*big.Rat, int) {
+	if value, ok := object.DecimalValue(obj); ok {
+		return value, 0
+	}
+	f := vm.toFloat64(obj)
+	switch {
+	case math.IsNaN(f):
+		return nil, 0
+	case math.IsInf(f, 1):
+		return nil, 1
+	case math.IsInf(f, -1):
+		return nil, -1
+	}
+	return new(big.Rat).SetFloat64(f), 0
+}
@@ -0,0 +1,125 @@
+package vm
+
+import (
+	"bhasa/code"
+	"fmt"
+)
+
+// handler runs one instruction; see ops.go
+type handler func(vm *VM, frame *Frame, ins code.Instructions, ip int) error
+
+// handlers maps each opcode to its handler. Opcodes the VM does not run
+// (OpEnum, OpInherit, OpCheckInterface, which the verifier rejects) have
+// none. It is filled in by init because the handlers that call functions
+// refer back to run.
+var handlers [256]handler
+
+func init() {
+	handlers = [256]handler{
+		code.OpConstant:          (*VM).opConstant,
+		code.OpDup:               (*VM).opDup,
+		code.OpPop:               (*VM).opPop,
+		code.OpAdd:               (*VM).opBinary,
+		code.OpSub:               (*VM).opBinary,
+		code.OpMul:               (*VM).opBinary,
+		code.OpDiv:               (*VM).opBinary,
+		code.OpMod:               (*VM).opBinary,
+		code.OpBitAnd:            (*VM).opBinary,
+		code.OpBitOr:             (*VM).opBinary,
+		code.OpBitXor:            (*VM).opBinary,
+		code.OpLeftShift:         (*VM).opBinary,
+		code.OpRightShift:        (*VM).opBinary,
+		code.OpTrue:              (*VM).opTrue,
+		code.OpFalse:             (*VM).opFalse,
+		code.OpEqual:             (*VM).opComparison,
+		code.OpNotEqual:          (*VM).opComparison,
+		code.OpGreaterThan:       (*VM).opComparison,
+		code.OpGreaterThanEqual:  (*VM).opComparison,
+		code.OpLessThan:          (*VM).opComparison,
+		code.OpLessThanEqual:     (*VM).opComparison,
+		code.OpBang:              (*VM).opBang,
+		code.OpAnd:               (*VM).opAnd,
+		code.OpOr:                (*VM).opOr,
+		code.OpMinus:             (*VM).opMinus,
+		code.OpBitNot:            (*VM).opBitNot,
+		code.OpJump:              (*VM).opJump,
+		code.OpJumpNotTruthy:     (*VM).opJumpNotTruthy,
+		code.OpNull:              (*VM).opNull,
+		code.OpSetGlobal:         (*VM).opSetGlobal,
+		code.OpGetGlobal:         (*VM).opGetGlobal,
+		code.OpArray:             (*VM).opArray,
+		code.OpTuple:             (*VM).opTuple,
+		code.OpUnpack:            (*VM).opUnpack,
+		code.OpHash:              (*VM).opHash,
+		code.OpStruct:            (*VM).opStruct,
+		code.OpGetStructField:    (*VM).opGetStructField,
+		code.OpSetStructField:    (*VM).opSetStructField,
+		code.OpIndex:             (*VM).opIndex,
+		code.OpSlice:             (*VM).opSlice,
+		code.OpSetIndex:          (*VM).opSetIndex,
+		code.OpCall:              (*VM).opCall,
+		code.OpReturnValue:       (*VM).opReturnValue,
+		code.OpReturn:            (*VM).opReturn,
+		code.OpSetLocal:          (*VM).opSetLocal,
+		code.OpGetLocal:          (*VM).opGetLocal,
+		code.OpArgMissing:        (*VM).opArgMissing,
+		code.OpGetBuiltin:        (*VM).opGetBuiltin,
+		code.OpClosure:           (*VM).opClosure,
+		code.OpGetFree:           (*VM).opGetFree,
+		code.OpNewCell:           (*VM).opNewCell,
+		code.OpGetCell:           (*VM).opGetCell,
+		code.OpSetCell:           (*VM).opSetCell,
+		code.OpCompareJump:       (*VM).opCompareJump,
+		code.OpGetLocals:         (*VM).opGetLocals,
+		code.OpAddConstant:       (*VM).opAddConstant,
+		code.OpGetLocalAdd:       (*VM).opGetLocalAdd,
+		code.OpCurrentClosure:    (*VM).opCurrentClosure,
+		code.OpAssertType:        (*VM).opAssertType,
+		code.OpTypeCast:          (*VM).opTypeCast,
+		code.OpTypeCheck:         (*VM).opTypeCheck,
+		code.OpDefineConstructor: (*VM).opDefineConstructor,
+		code.OpDefineMethod:      (*VM).opDefineMethod,
+		code.OpClass:             (*VM).opClass,
+		code.OpNewInstance:       (*VM).opNewInstance,
+		code.OpCallMethod:        (*VM).opCallMethod,
+		code.OpGetThis:           (*VM).opGetThis,
+		code.OpGetSuper:          (*VM).opGetSuper,
+		code.OpInterface:         (*VM).opInterface,
+		code.OpGetStatic:         (*VM).opGetStatic,
+		code.OpSetStatic:         (*VM).opSetStatic,
+		code.OpGetInstanceField:  (*VM).opGetInstanceField,
+		code.OpSetInstanceField:  (*VM).opSetInstanceField,
+	}
+}
+
+// run executes instructions until the main program ends or fails. Each
+// iteration looks up the frame on top once and hands it, with the opcode's
+// offset, to the opcode's handler.
+func (vm *VM) run() error {
+	for {
+		frame := vm.frames[vm.framesIndex-1]
+		ins := frame.cl.Fn.Instructions
+		if frame.ip >= len(ins)-1 {
+			return nil
+		}
+		frame.ip++
+
+		ip := frame.ip
+		op := code.Opcode(ins[ip])
+		countOpcode(op)
+		if vm.profile != nil {
+			vm.profile.enter(op)
+		}
+		if vm.trace != nil {
+			vm.traceInstruction(ins, ip)
+		}
+
+		run := handlers[op]
+		if run == nil {
+			return fmt.Errorf("opcode %d is not supported by the VM", op)
+		}
+		if err := run(vm, frame, ins, ip); err != nil {
+			return err
+		}
+	}
+}
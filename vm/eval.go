@@ -0,0 +1,66 @@
+package vm
+
+import (
+	"bhasa/compiler"
+	"bhasa/lexer"
+	"bhasa/object"
+	"bhasa/parser"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Eval compiles and runs source in a child VM with its own globals, so the
+// evaluated code cannot touch the caller's variables. The entries of
+// bindings (if any) are defined as globals in the child first. The child
+// shares this VM's standard streams, arguments, numeral setting, trace and
+// watches on locals, but not its watches on globals, which are this VM's. The value of the last
+// expression is returned.
+func (vm *VM) Eval(source string, bindings *object.Hash) object.Object {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return &object.Error{Message: fmt.Sprintf("parser errors: %s", strings.Join(p.Errors(), "; "))}
+	}
+
+	symbolTable := compiler.NewSymbolTable()
+	for i, v := range object.Builtins {
+		symbolTable.DefineBuiltin(i, v.Name)
+	}
+
+	globals := make([]object.Object, GlobalsSize)
+	if bindings != nil {
+		names := make([]string, 0, len(bindings.Pairs))
+		values := make(map[string]object.Object, len(bindings.Pairs))
+		for _, pair := range bindings.Pairs {
+			key, ok := pair.Key.(*object.String)
+			if !ok {
+				return &object.Error{Message: fmt.Sprintf("binding names must be STRING, got %s", pair.Key.Type())}
+			}
+			names = append(names, key.Value)
+			values[key.Value] = pair.Value
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			symbol := symbolTable.Define(name)
+			globals[symbol.Index] = values[name]
+		}
+	}
+
+	comp := compiler.NewWithState(symbolTable, []object.Object{})
+	if err := comp.Compile(program); err != nil {
+		return &object.Error{Message: fmt.Sprintf("compilation failed: %s", err)}
+	}
+
+	child := NewWithGlobalsStore(comp.Bytecode(), globals)
+	vm.share(child)
+	child.trace, child.traceDepth = vm.trace, vm.traceDepth+vm.framesIndex
+	if err := child.Run(); err != nil {
+		return &object.Error{Message: err.Error()}
+	}
+
+	if result := child.LastPoppedStackElem(); result != nil {
+		return result
+	}
+	return Null
+}
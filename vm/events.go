@@ -0,0 +1,112 @@
+package vm
+
+import (
+	"bhasa/object"
+	"bytes"
+)
+
+// EventKind tells apart the kinds of output a run produces
+type EventKind int
+
+const (
+	StdoutEvent EventKind = iota // a line printed with লেখ, or a prompt
+	StderrEvent                  // a line of builtin diagnostics
+	ErrorEvent                   // the error that ended the run
+	ResultEvent                  // the value the run finished with
+)
+
+var eventKindNames = [...]string{"stdout", "stderr", "error", "result"}
+
+func (k EventKind) String() string {
+	if int(k) < len(eventKindNames) {
+		return eventKindNames[k]
+	}
+	return "unknown"
+}
+
+// Event is one piece of tagged output. Text is a line without its
+// newline, the error message, or the display text of the result; Value is
+// set for results only.
+type Event struct {
+	Kind  EventKind
+	Text  string
+	Value object.Object
+}
+
+// OnEvent sends everything the VM outputs to fn as events instead of
+// writing it to streams, so a host such as a web playground can show
+// printed lines, errors and results apart. Printed output arrives a line
+// at a time; text without a final newline, such as a prompt, is sent when
+// the program next reads input or when it finishes. After a successful
+// Run, fn also receives the value of the last expression statement, and
+// after a failed one the error. A later SetStdout or SetStderr takes that
+// stream back from fn.
+func (vm *VM) OnEvent(fn func(Event)) {
+	vm.onEvent = fn
+	vm.stdout = &eventWriter{kind: StdoutEvent, emit: fn}
+	vm.stderr = &eventWriter{kind: StderrEvent, emit: fn}
+}
+
+// emitRunEvents flushes partial lines and reports how a run ended
+func (vm *VM) emitRunEvents(err error) {
+	if vm.onEvent == nil {
+		return
+	}
+	vm.flushEvents()
+	if err != nil {
+		vm.onEvent(Event{Kind: ErrorEvent, Text: err.Error()})
+		return
+	}
+	value := vm.LastPoppedStackElem()
+	if value == nil {
+		return
+	}
+	text, errObj := object.DisplayText(vm, value)
+	if errObj != nil {
+		text = errObj.Inspect()
+	}
+	vm.onEvent(Event{Kind: ResultEvent, Text: text, Value: value})
+}
+
+// flushEvents sends any partial line still held by the event writers
+func (vm *VM) flushEvents() {
+	for _, w := range []interface{}{vm.stdout, vm.stderr} {
+		if lw, ok := w.(*lockedWriter); ok {
+			lw.mu.Lock()
+			if ew, ok := lw.w.(*eventWriter); ok {
+				ew.flush()
+			}
+			lw.mu.Unlock()
+		} else if ew, ok := w.(*eventWriter); ok {
+			ew.flush()
+		}
+	}
+}
+
+// eventWriter turns what is written to it into one event per line
+type eventWriter struct {
+	kind    EventKind
+	emit    func(Event)
+	pending []byte
+}
+
+func (w *eventWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(Event{Kind: w.kind, Text: string(w.pending[:i])})
+		w.pending = w.pending[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush sends the text after the last newline, if any
+func (w *eventWriter) flush() {
+	if len(w.pending) > 0 {
+		w.emit(Event{Kind: w.kind, Text: string(w.pending)})
+		w.pending = nil
+	}
+}
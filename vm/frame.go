@@ -10,6 +10,7 @@ type Frame struct {
 	cl          *object.Closure
 	ip          int
 	basePointer int
+	numArgs     int // arguments the caller passed, fewer when defaults are used
 }
 
 // NewFrame creates a new frame
@@ -0,0 +1,107 @@
+package vm
+
+import (
+	"bhasa/code"
+	"bhasa/object"
+	"fmt"
+)
+
+// operatorMethods name the methods a class defines to overload an
+// arithmetic operator; the method is called on the left operand with the
+// right one
+var operatorMethods = map[code.Opcode]string{
+	code.OpAdd: "__যোগ__",
+	code.OpSub: "__বিয়োগ__",
+	code.OpMul: "__গুণ__",
+	code.OpDiv: "__ভাগ__",
+	code.OpMod: "__ভাগশেষ__",
+}
+
+const (
+	// equalMethod overloads == and !=; it returns a boolean
+	equalMethod = "__সমান__"
+	// compareMethod overloads the ordering operators; like a sort
+	// comparator it returns a negative number, zero or a positive number
+	compareMethod = "__তুলনা__"
+)
+
+// callOperatorMethod calls the named method of obj with arg. It reports
+// false when obj is not a class instance or its class has no such method.
+func (vm *VM) callOperatorMethod(obj object.Object, name string, arg object.Object) (object.Object, bool, error) {
+	instance, ok := obj.(*object.ClassInstance)
+	if !ok {
+		return nil, false, nil
+	}
+	method := instance.Class.GetMethod(name)
+	if method == nil {
+		return nil, false, nil
+	}
+	result := vm.CallFunction(method.Closure, instance, arg)
+	if errObj, ok := result.(*object.Error); ok {
+		return nil, true, fmt.Errorf("%s", errObj.Message)
+	}
+	return result, true, nil
+}
+
+// executeOverloadedOperation runs an arithmetic operator through the left
+// operand's operator method, reporting false for operators that cannot be
+// overloaded
+func (vm *VM) executeOverloadedOperation(op code.Opcode, left, right object.Object) (bool, error) {
+	name, ok := operatorMethods[op]
+	if !ok {
+		return false, nil
+	}
+	result, found, err := vm.callOperatorMethod(left, name, right)
+	if err != nil {
+		return true, err
+	}
+	if !found {
+		return true, fmt.Errorf("class '%s' has no method %s for this operator",
+			left.(*object.ClassInstance).Class.Name, name)
+	}
+	return true, vm.push(result)
+}
+
+// executeOverloadedComparison runs a comparison through either operand's
+// __সমান__ or __তুলনা__ method, reporting false when neither has one.
+// The compiler turns a < b into b > a, so only > and >= reach here.
+func (vm *VM) executeOverloadedComparison(op code.Opcode, left, right object.Object) (bool, error) {
+	if op == code.OpEqual || op == code.OpNotEqual {
+		result, found, err := vm.callOperatorMethod(left, equalMethod, right)
+		if !found && err == nil {
+			result, found, err = vm.callOperatorMethod(right, equalMethod, left)
+		}
+		if !found || err != nil {
+			return found, err
+		}
+		return true, vm.push(nativeBoolToBooleanObject(isTruthy(result) == (op == code.OpEqual)))
+	}
+
+	// The right operand's method compares the other way round
+	sign := 1
+	result, found, err := vm.callOperatorMethod(left, compareMethod, right)
+	if !found && err == nil {
+		sign = -1
+		result, found, err = vm.callOperatorMethod(right, compareMethod, left)
+	}
+	if !found || err != nil {
+		return found, err
+	}
+	if !vm.isNumericType(result.Type()) {
+		return true, fmt.Errorf("%s must return a number, got %s", compareMethod, result.Type())
+	}
+
+	cmp := 0
+	if n := vm.toFloat64(result) * float64(sign); n > 0 {
+		cmp = 1
+	} else if n < 0 {
+		cmp = -1
+	}
+	switch op {
+	case code.OpGreaterThan:
+		return true, vm.push(nativeBoolToBooleanObject(cmp > 0))
+	case code.OpGreaterThanEqual:
+		return true, vm.push(nativeBoolToBooleanObject(cmp >= 0))
+	}
+	return false, nil
+}
@@ -0,0 +1,905 @@
+package vm
+
+import (
+	"bhasa/code"
+	"bhasa/object"
+	"fmt"
+)
+
+// The handlers of the opcodes, which run looks up in handlers. Each one
+// runs the instruction at ip in frame, the frame on top, whose ip already
+// points at the opcode; a handler with operands reads them from ins and
+// moves frame.ip past them. Handlers that push or pop frames must not use
+// frame afterwards.
+
+func (vm *VM) opConstant(frame *Frame, ins code.Instructions, ip int) error {
+	constIndex := code.ReadUint16(ins[ip+1:])
+	frame.ip += 2
+
+	constant, err := vm.constant(int(constIndex))
+	if err != nil {
+		return err
+	}
+
+	return vm.push(constant)
+}
+
+func (vm *VM) opDup(frame *Frame, ins code.Instructions, ip int) error {
+	return vm.push(vm.stack[vm.sp-1])
+}
+
+func (vm *VM) opPop(frame *Frame, ins code.Instructions, ip int) error {
+	// Safety check: prevent stack underflow (compiler bug workaround)
+	if vm.sp > frame.basePointer {
+		vm.pop()
+	}
+	return nil
+}
+
+func (vm *VM) opBinary(frame *Frame, ins code.Instructions, ip int) error {
+	return vm.binary(code.Opcode(ins[ip]))
+}
+
+// binary runs an arithmetic or bitwise opcode on the two values on top of
+// the stack
+func (vm *VM) binary(op code.Opcode) error {
+	if a, b, ok := vm.integerOperands(); ok {
+		if result, ok := integerArithmetic(op, a, b); ok {
+			vm.sp -= 2
+			return vm.push(vm.newInteger(result))
+		}
+	}
+	return vm.executeBinaryOperation(op)
+}
+
+// integerOperands returns the two values on top of the stack, the right
+// operand on top, when both are Integers. Integer arithmetic and comparison
+// is most of what loops do, and checking for it first spares them the
+// checks for every other type.
+func (vm *VM) integerOperands() (int64, int64, bool) {
+	left, ok := vm.stack[vm.sp-2].(*object.Integer)
+	if !ok {
+		return 0, 0, false
+	}
+	right, ok := vm.stack[vm.sp-1].(*object.Integer)
+	if !ok {
+		return 0, 0, false
+	}
+	return left.Value, right.Value, true
+}
+
+// integerArithmetic is the common case of executeBinaryIntegerOperation:
+// it fails for the operators that can fail or grow into a big integer,
+// which are left to the general path
+func integerArithmetic(op code.Opcode, a, b int64) (int64, bool) {
+	switch op {
+	case code.OpAdd:
+		if r := a + b; (r > a) == (b > 0) {
+			return r, true
+		}
+	case code.OpSub:
+		if r := a - b; (r < a) == (b > 0) {
+			return r, true
+		}
+	case code.OpMod:
+		if b > 0 {
+			return a % b, true
+		}
+	}
+	return 0, false
+}
+
+func (vm *VM) opTrue(frame *Frame, ins code.Instructions, ip int) error {
+	return vm.push(True)
+}
+
+func (vm *VM) opFalse(frame *Frame, ins code.Instructions, ip int) error {
+	return vm.push(False)
+}
+
+func (vm *VM) opComparison(frame *Frame, ins code.Instructions, ip int) error {
+	return vm.compare(code.Opcode(ins[ip]))
+}
+
+// compare runs a comparison opcode on the two values on top of the stack,
+// leaving the result in their place
+func (vm *VM) compare(op code.Opcode) error {
+	if a, b, ok := vm.integerOperands(); ok {
+		vm.sp -= 2
+		return vm.push(nativeBoolToBooleanObject(compareIntegers(op, a, b)))
+	}
+	if op != code.OpLessThan && op != code.OpLessThanEqual {
+		return vm.executeComparison(op)
+	}
+	// a < b is b > a; the operands are swapped here rather than
+	// compiled in reverse so that a is still evaluated first
+	vm.stack[vm.sp-1], vm.stack[vm.sp-2] = vm.stack[vm.sp-2], vm.stack[vm.sp-1]
+	flipped := code.OpGreaterThan
+	if op == code.OpLessThanEqual {
+		flipped = code.OpGreaterThanEqual
+	}
+	return vm.executeComparison(flipped)
+}
+
+// compareIntegers is a comparison opcode applied to two Integers
+func compareIntegers(op code.Opcode, a, b int64) bool {
+	switch op {
+	case code.OpEqual:
+		return a == b
+	case code.OpNotEqual:
+		return a != b
+	case code.OpGreaterThan:
+		return a > b
+	case code.OpGreaterThanEqual:
+		return a >= b
+	case code.OpLessThan:
+		return a < b
+	default:
+		return a <= b
+	}
+}
+
+func (vm *VM) opBang(frame *Frame, ins code.Instructions, ip int) error {
+	return vm.executeBangOperator()
+}
+
+func (vm *VM) opAnd(frame *Frame, ins code.Instructions, ip int) error {
+	return vm.executeAndOperator()
+}
+
+func (vm *VM) opOr(frame *Frame, ins code.Instructions, ip int) error {
+	return vm.executeOrOperator()
+}
+
+func (vm *VM) opMinus(frame *Frame, ins code.Instructions, ip int) error {
+	return vm.executeMinusOperator()
+}
+
+func (vm *VM) opBitNot(frame *Frame, ins code.Instructions, ip int) error {
+	return vm.executeBitNotOperator()
+}
+
+func (vm *VM) opJump(frame *Frame, ins code.Instructions, ip int) error {
+	pos := int(code.ReadUint16(ins[ip+1:]))
+	frame.ip = pos - 1
+	return nil
+}
+
+func (vm *VM) opJumpNotTruthy(frame *Frame, ins code.Instructions, ip int) error {
+	pos := int(code.ReadUint16(ins[ip+1:]))
+	frame.ip += 2
+
+	condition := vm.pop()
+	if !isTruthy(condition) {
+		frame.ip = pos - 1
+	}
+	return nil
+}
+
+func (vm *VM) opNull(frame *Frame, ins code.Instructions, ip int) error {
+	return vm.push(Null)
+}
+
+func (vm *VM) opSetGlobal(frame *Frame, ins code.Instructions, ip int) error {
+	globalIndex := code.ReadUint16(ins[ip+1:])
+	frame.ip += 2
+
+	value := vm.pop()
+	if vm.watches != nil {
+		vm.reportWrite(int(globalIndex), value)
+	}
+	vm.globals[globalIndex] = value
+	return nil
+}
+
+func (vm *VM) opGetGlobal(frame *Frame, ins code.Instructions, ip int) error {
+	globalIndex := code.ReadUint16(ins[ip+1:])
+	frame.ip += 2
+
+	// A function can run before the global it reads has been defined,
+	// which the verifier cannot rule out
+	value := vm.globals[globalIndex]
+	if value == nil {
+		return fmt.Errorf("global %d is read before it is set", globalIndex)
+	}
+	return vm.push(value)
+}
+
+func (vm *VM) opArray(frame *Frame, ins code.Instructions, ip int) error {
+	numElements := int(code.ReadUint16(ins[ip+1:]))
+	frame.ip += 2
+
+	array := vm.buildArray(vm.sp-numElements, vm.sp)
+	vm.sp = vm.sp - numElements
+
+	return vm.push(array)
+}
+
+func (vm *VM) opTuple(frame *Frame, ins code.Instructions, ip int) error {
+	numElements := int(code.ReadUint16(ins[ip+1:]))
+	frame.ip += 2
+
+	elements := make([]object.Object, numElements)
+	copy(elements, vm.stack[vm.sp-numElements:vm.sp])
+	vm.sp = vm.sp - numElements
+
+	vm.allocated(AllocTuple)
+	return vm.push(&object.Tuple{Elements: elements})
+}
+
+func (vm *VM) opUnpack(frame *Frame, ins code.Instructions, ip int) error {
+	numNames := int(code.ReadUint8(ins[ip+1:]))
+	frame.ip += 1
+
+	return vm.unpack(vm.pop(), numNames)
+}
+
+func (vm *VM) opHash(frame *Frame, ins code.Instructions, ip int) error {
+	numElements := int(code.ReadUint16(ins[ip+1:]))
+	frame.ip += 2
+
+	hash, err := vm.buildHash(vm.sp-numElements, vm.sp)
+	if err != nil {
+		return err
+	}
+	vm.sp = vm.sp - numElements
+
+	return vm.push(hash)
+}
+
+func (vm *VM) opStruct(frame *Frame, ins code.Instructions, ip int) error {
+	numElements := int(code.ReadUint16(ins[ip+1:]))
+	frame.ip += 2
+
+	structObj, err := vm.buildStruct(vm.sp-numElements, vm.sp)
+	if err != nil {
+		return err
+	}
+	vm.sp = vm.sp - numElements
+
+	return vm.push(structObj)
+}
+
+func (vm *VM) opGetStructField(frame *Frame, ins code.Instructions, ip int) error {
+	fieldName := vm.pop()
+	structObj := vm.pop()
+
+	return vm.executeGetStructField(structObj, fieldName)
+}
+
+func (vm *VM) opSetStructField(frame *Frame, ins code.Instructions, ip int) error {
+	value := vm.pop()
+	fieldName := vm.pop()
+	structObj := vm.pop()
+
+	return vm.executeSetStructField(structObj, fieldName, value)
+}
+
+func (vm *VM) opIndex(frame *Frame, ins code.Instructions, ip int) error {
+	index := vm.pop()
+	left := vm.pop()
+
+	return vm.executeIndexExpression(left, index)
+}
+
+func (vm *VM) opSlice(frame *Frame, ins code.Instructions, ip int) error {
+	end := vm.pop()
+	start := vm.pop()
+	left := vm.pop()
+
+	result := object.Slice(left, start, end)
+	if errObj, ok := result.(*object.Error); ok {
+		return fmt.Errorf("%s", errObj.Message)
+	}
+	return vm.push(result)
+}
+
+func (vm *VM) opSetIndex(frame *Frame, ins code.Instructions, ip int) error {
+	value := vm.pop()
+	index := vm.pop()
+	collection := vm.pop()
+
+	if errObj := object.SetIndex(collection, index, value); errObj != nil {
+		return fmt.Errorf("%s", errObj.Message)
+	}
+	return nil
+}
+
+func (vm *VM) opCall(frame *Frame, ins code.Instructions, ip int) error {
+	numArgs := code.ReadUint8(ins[ip+1:])
+	frame.ip += 1
+
+	return vm.executeCall(int(numArgs))
+}
+
+func (vm *VM) opReturnValue(frame *Frame, ins code.Instructions, ip int) error {
+	returnValue := vm.pop()
+
+	vm.popFrame()
+	vm.sp = frame.basePointer - 1
+
+	return vm.push(returnValue)
+}
+
+func (vm *VM) opReturn(frame *Frame, ins code.Instructions, ip int) error {
+	vm.popFrame()
+	vm.sp = frame.basePointer - 1
+
+	return vm.push(Null)
+}
+
+func (vm *VM) opSetLocal(frame *Frame, ins code.Instructions, ip int) error {
+	localIndex := code.ReadUint8(ins[ip+1:])
+	frame.ip += 1
+
+	value := vm.pop()
+	if vm.localWatches != nil {
+		vm.reportLocalWrite(frame, int(localIndex), value)
+	}
+	vm.stack[frame.basePointer+int(localIndex)] = value
+	return nil
+}
+
+func (vm *VM) opGetLocal(frame *Frame, ins code.Instructions, ip int) error {
+	localIndex := code.ReadUint8(ins[ip+1:])
+	frame.ip += 1
+
+	return vm.push(vm.stack[frame.basePointer+int(localIndex)])
+}
+
+func (vm *VM) opArgMissing(frame *Frame, ins code.Instructions, ip int) error {
+	localIndex := code.ReadUint8(ins[ip+1:])
+	frame.ip += 1
+
+	// Parameters are the first locals, so a parameter was left out
+	// when its index is past the arguments passed
+	return vm.push(nativeBoolToBooleanObject(int(localIndex) >= frame.numArgs))
+}
+
+func (vm *VM) opGetBuiltin(frame *Frame, ins code.Instructions, ip int) error {
+	builtinIndex := code.ReadUint8(ins[ip+1:])
+	frame.ip += 1
+
+	definition := object.Builtins[builtinIndex]
+
+	return vm.push(definition.Builtin)
+}
+
+func (vm *VM) opClosure(frame *Frame, ins code.Instructions, ip int) error {
+	constIndex := code.ReadUint16(ins[ip+1:])
+	numFree := code.ReadUint8(ins[ip+3:])
+	frame.ip += 3
+
+	return vm.pushClosure(int(constIndex), int(numFree))
+}
+
+func (vm *VM) opGetFree(frame *Frame, ins code.Instructions, ip int) error {
+	freeIndex := code.ReadUint8(ins[ip+1:])
+	frame.ip += 1
+
+	currentClosure := frame.cl
+
+	return vm.push(currentClosure.Free[freeIndex])
+}
+
+func (vm *VM) opNewCell(frame *Frame, ins code.Instructions, ip int) error {
+	vm.allocated(AllocCell)
+	vm.stack[vm.sp-1] = &object.Cell{Value: vm.stack[vm.sp-1]}
+	return nil
+}
+
+func (vm *VM) opGetCell(frame *Frame, ins code.Instructions, ip int) error {
+	vm.stack[vm.sp-1] = vm.stack[vm.sp-1].(*object.Cell).Value
+	return nil
+}
+
+func (vm *VM) opSetCell(frame *Frame, ins code.Instructions, ip int) error {
+	value := vm.pop()
+	cell := vm.pop().(*object.Cell)
+	if cell.Watch != "" {
+		vm.report(cell.Watch, cell.Value, value)
+	}
+	cell.Value = value
+	return nil
+}
+
+func (vm *VM) opCurrentClosure(frame *Frame, ins code.Instructions, ip int) error {
+	currentClosure := frame.cl
+
+	return vm.push(currentClosure)
+}
+
+func (vm *VM) opAssertType(frame *Frame, ins code.Instructions, ip int) error {
+	constIndex := code.ReadUint16(ins[ip+1:])
+	frame.ip += 2
+
+	expectedType, err := vm.constantString(int(constIndex))
+	if err != nil {
+		return err
+	}
+	value := vm.pop()
+
+	// Check if type matches exactly
+	if vm.checkType(value, expectedType) {
+		// Type matches, push value back
+		err := vm.push(value)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Try implicit type conversion for compatible types
+		converted, err := vm.castType(value, expectedType)
+		if err != nil {
+			return fmt.Errorf("type error: expected %s, got %s (cannot convert: %v)",
+				expectedType, vm.getTypeName(value), err)
+		}
+		// Push converted value
+		err = vm.push(converted)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (vm *VM) opTypeCast(frame *Frame, ins code.Instructions, ip int) error {
+	constIndex := code.ReadUint16(ins[ip+1:])
+	frame.ip += 2
+
+	targetType, err := vm.constantString(int(constIndex))
+	if err != nil {
+		return err
+	}
+	value := vm.pop()
+
+	castedValue, err := vm.castType(value, targetType)
+	if err != nil {
+		return err
+	}
+
+	return vm.push(castedValue)
+}
+
+func (vm *VM) opTypeCheck(frame *Frame, ins code.Instructions, ip int) error {
+	constIndex := code.ReadUint16(ins[ip+1:])
+	frame.ip += 2
+
+	expectedType, err := vm.constantString(int(constIndex))
+	if err != nil {
+		return err
+	}
+	value := vm.pop()
+
+	// Push boolean result of type check
+	result := vm.checkType(value, expectedType)
+	if result {
+		err := vm.push(True)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := vm.push(False)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ========== OOP Opcodes ==========
+
+func (vm *VM) opDefineConstructor(frame *Frame, ins code.Instructions, ip int) error {
+	_ = code.ReadUint16(ins[ip+1:]) // constIndex not needed at runtime
+	frame.ip += 2
+
+	// Pop the constructor closure from the stack
+	constructorObj := vm.pop()
+	constructor, ok := constructorObj.(*object.Closure)
+	if !ok {
+		return fmt.Errorf("OpDefineConstructor: expected closure, got %T", constructorObj)
+	}
+
+	// Store it for the upcoming OpClass
+	vm.pendingConstructors = append(vm.pendingConstructors, constructor)
+	return nil
+}
+
+func (vm *VM) opDefineMethod(frame *Frame, ins code.Instructions, ip int) error {
+	methodNameIndex := code.ReadUint16(ins[ip+1:])
+	frame.ip += 2
+
+	// Get the method name from constants
+	methodNameObj, err := vm.constant(int(methodNameIndex))
+	if err != nil {
+		return err
+	}
+	methodName, ok := methodNameObj.(*object.String)
+	if !ok {
+		return fmt.Errorf("OpDefineMethod: expected string for method name, got %T", methodNameObj)
+	}
+
+	// Pop the method closure from the stack
+	methodClosureObj := vm.pop()
+	methodClosure, ok := methodClosureObj.(*object.Closure)
+	if !ok {
+		return fmt.Errorf("OpDefineMethod: expected closure, got %T", methodClosureObj)
+	}
+
+	// Store it for the upcoming OpClass
+	vm.pendingMethods[methodName.Value] = methodClosure
+	return nil
+}
+
+func (vm *VM) opClass(frame *Frame, ins code.Instructions, ip int) error {
+	constIndex := code.ReadUint16(ins[ip+1:])
+	frame.ip += 2
+
+	// Get the class template from constants
+	classTemplate, err := vm.constant(int(constIndex))
+	if err != nil {
+		return err
+	}
+	classObj, ok := classTemplate.(*object.Class)
+	if !ok {
+		return fmt.Errorf("OpClass: expected class, got %T", classTemplate)
+	}
+
+	// Create a copy of the class and attach the runtime constructor/methods
+	class := &object.Class{
+		Name:         classObj.Name,
+		SuperClass:   classObj.SuperClass,
+		Interfaces:   classObj.Interfaces,
+		Fields:       classObj.Fields,
+		Methods:      make(map[string]*object.Method),
+		Constructors: vm.pendingConstructors,
+		StaticFields: make(map[string]object.Object),
+		IsAbstract:   classObj.IsAbstract,
+		IsFinal:      classObj.IsFinal,
+		FieldAccess:  classObj.FieldAccess,
+		FieldOrder:   classObj.FieldOrder,
+	}
+
+	// Copy methods from template and attach pending runtime closures
+	for name, method := range classObj.Methods {
+		// Create a copy of the method
+		methodCopy := &object.Method{
+			Name:       method.Name,
+			Access:     method.Access,
+			IsStatic:   method.IsStatic,
+			IsFinal:    method.IsFinal,
+			IsAbstract: method.IsAbstract,
+			Closure:    method.Closure,
+		}
+		// If there's a pending runtime closure for this method, use it
+		if runtimeClosure, exists := vm.pendingMethods[name]; exists {
+			methodCopy.Closure = runtimeClosure
+		}
+		class.Methods[name] = methodCopy
+	}
+
+	// Static fields start out null in every run of the program
+	for name := range classObj.StaticFields {
+		class.StaticFields[name] = Null
+	}
+
+	// Clear pending constructor and methods for next class
+	vm.pendingConstructors = nil
+	vm.pendingMethods = make(map[string]*object.Closure)
+
+	return vm.push(class)
+}
+
+func (vm *VM) opNewInstance(frame *Frame, ins code.Instructions, ip int) error {
+	numArgs := code.ReadUint8(ins[ip+1:])
+	frame.ip += 1
+
+	// Arguments are already on the stack: [arg1, arg2, ..., argN, class]
+	// Get the class (it's at the top of the stack after the arguments)
+	classObj := vm.stack[vm.sp-1]
+	class, ok := classObj.(*object.Class)
+	if !ok {
+		return fmt.Errorf("expected class, got %T", classObj)
+	}
+
+	// Remove class from stack (arguments stay on stack)
+	vm.sp--
+
+	// Create new instance
+	instance := &object.ClassInstance{
+		Class:  class,
+		Fields: make(map[string]object.Object),
+		This:   nil,
+	}
+	instance.This = instance
+
+	// Initialize fields to null
+	for _, fieldName := range class.FieldOrder {
+		instance.Fields[fieldName] = Null
+	}
+
+	// Call constructor if exists
+	if len(class.Constructors) > 0 {
+		constructor := class.ConstructorFor(int(numArgs))
+		if constructor == nil {
+			return fmt.Errorf("class %s has no constructor taking %d arguments", class.Name, numArgs)
+		}
+
+		// To match the normal calling convention [callee, args...], we need to push
+		// a placeholder before the instance, so stack becomes [placeholder, instance, args...]
+		// When constructor returns, the return value replaces the placeholder
+
+		// Push placeholder (use the class itself)
+		err := vm.push(class)
+		if err != nil {
+			return err
+		}
+
+		// Push instance as first argument (this)
+		err = vm.push(instance)
+		if err != nil {
+			return err
+		}
+
+		// Get constructor args that are already on stack
+		// Stack is currently: [..., arg1, arg2, ..., argN, class, instance]
+		// We need: [class, instance, arg1, arg2, ..., argN]
+
+		// First pop instance and class
+		inst := vm.pop()
+		classPlaceholder := vm.pop()
+
+		// Pop all constructor arguments
+		args := make([]object.Object, numArgs)
+		for i := int(numArgs) - 1; i >= 0; i-- {
+			args[i] = vm.pop()
+		}
+
+		// Push back in correct order: class, instance, args
+		err = vm.push(classPlaceholder)
+		if err != nil {
+			return err
+		}
+		err = vm.push(inst)
+		if err != nil {
+			return err
+		}
+		for _, arg := range args {
+			err = vm.push(arg)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Call constructor using standard calling convention
+		// Stack: [class, instance, arg1, arg2, ..., argN]
+		// The constructor expects numArgs + 1 (for 'this')
+		err = vm.callClosure(constructor, int(numArgs)+1)
+		if err != nil {
+			return err
+		}
+		// After constructor returns, the return value (instance) will be on stack
+	} else {
+		// No constructor, just push the instance
+		err := vm.push(instance)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (vm *VM) opCallMethod(frame *Frame, ins code.Instructions, ip int) error {
+	numArgs := code.ReadUint8(ins[ip+1:])
+	frame.ip += 1
+
+	// Get arguments
+	args := make([]object.Object, numArgs)
+	for i := int(numArgs) - 1; i >= 0; i-- {
+		args[i] = vm.pop()
+	}
+
+	// Get method name
+	methodName := vm.pop().(*object.String).Value
+
+	// Get object
+	obj := vm.pop()
+
+	instance, ok := obj.(*object.ClassInstance)
+	if !ok {
+		return fmt.Errorf("cannot call method on non-class instance: %T", obj)
+	}
+
+	// Find method in class hierarchy
+	method := instance.Class.GetMethod(methodName)
+	if method == nil {
+		return fmt.Errorf("method '%s' not found in class '%s'", methodName, instance.Class.Name)
+	}
+
+	// Prepare arguments: [this, arg1, arg2, ...]
+	allArgs := append([]object.Object{instance}, args...)
+
+	// Create new frame for method
+	callee := NewFrame(method.Closure, vm.sp-len(allArgs))
+	callee.numArgs = len(allArgs)
+	vm.pushFrame(callee)
+
+	// Push arguments onto stack
+	for _, arg := range allArgs {
+		err := vm.push(arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	vm.sp = callee.basePointer + method.Closure.Fn.NumLocals
+	if vm.localWatches != nil {
+		vm.clearLocals(callee)
+	}
+	return nil
+}
+
+func (vm *VM) opGetThis(frame *Frame, ins code.Instructions, ip int) error {
+	// 'this' is always the first parameter (index 0)
+	basePointer := frame.basePointer
+	return vm.push(vm.stack[basePointer])
+}
+
+func (vm *VM) opGetSuper(frame *Frame, ins code.Instructions, ip int) error {
+	// Get current instance (this)
+	basePointer := frame.basePointer
+	thisObj := vm.stack[basePointer]
+
+	instance, ok := thisObj.(*object.ClassInstance)
+	if !ok {
+		return fmt.Errorf("super can only be used in class methods")
+	}
+
+	if instance.Class.SuperClass == nil {
+		return fmt.Errorf("class has no parent class")
+	}
+
+	// Push parent class
+	return vm.push(instance.Class.SuperClass)
+}
+
+func (vm *VM) opInterface(frame *Frame, ins code.Instructions, ip int) error {
+	constIndex := code.ReadUint16(ins[ip+1:])
+	frame.ip += 2
+
+	iface, err := vm.constant(int(constIndex))
+	if err != nil {
+		return err
+	}
+	return vm.push(iface)
+}
+
+func (vm *VM) opGetStatic(frame *Frame, ins code.Instructions, ip int) error {
+	name, err := vm.constantString(int(code.ReadUint16(ins[ip+1:])))
+	if err != nil {
+		return err
+	}
+	frame.ip += 2
+
+	if err := vm.executeGetStatic(vm.pop(), name); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (vm *VM) opSetStatic(frame *Frame, ins code.Instructions, ip int) error {
+	name, err := vm.constantString(int(code.ReadUint16(ins[ip+1:])))
+	if err != nil {
+		return err
+	}
+	frame.ip += 2
+
+	value := vm.pop()
+	if err := vm.executeSetStatic(vm.pop(), name, value); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (vm *VM) opGetInstanceField(frame *Frame, ins code.Instructions, ip int) error {
+	// Get field name
+	fieldName := vm.pop().(*object.String).Value
+
+	// Get instance
+	obj := vm.pop()
+	instance, ok := obj.(*object.ClassInstance)
+	if !ok {
+		return fmt.Errorf("cannot get field from non-class instance")
+	}
+	if err := vm.checkAccess(instance.Class, fieldName); err != nil {
+		return err
+	}
+
+	// Get field value
+	value, exists := instance.GetField(fieldName)
+	if !exists {
+		value = Null
+	}
+
+	return vm.push(value)
+}
+
+func (vm *VM) opSetInstanceField(frame *Frame, ins code.Instructions, ip int) error {
+	// Get value
+	value := vm.pop()
+
+	// Get field name
+	fieldName := vm.pop().(*object.String).Value
+
+	// Get instance
+	obj := vm.pop()
+	instance, ok := obj.(*object.ClassInstance)
+	if !ok {
+		return fmt.Errorf("cannot set field on non-class instance")
+	}
+	if err := vm.checkAccess(instance.Class, fieldName); err != nil {
+		return err
+	}
+
+	// Set field value
+	instance.SetField(fieldName, value)
+
+	return vm.push(value)
+}
+
+// ========== Superinstructions ==========
+
+func (vm *VM) opCompareJump(frame *Frame, ins code.Instructions, ip int) error {
+	op := code.Opcode(ins[ip+1])
+	pos := int(code.ReadUint16(ins[ip+2:]))
+	frame.ip += 3
+
+	var result bool
+	if a, b, ok := vm.integerOperands(); ok {
+		vm.sp -= 2
+		result = compareIntegers(op, a, b)
+	} else {
+		if err := vm.compare(op); err != nil {
+			return err
+		}
+		result = isTruthy(vm.pop())
+	}
+	if !result {
+		frame.ip = pos - 1
+	}
+	return nil
+}
+
+func (vm *VM) opGetLocals(frame *Frame, ins code.Instructions, ip int) error {
+	first := code.ReadUint8(ins[ip+1:])
+	second := code.ReadUint8(ins[ip+2:])
+	frame.ip += 2
+
+	if err := vm.push(vm.stack[frame.basePointer+int(first)]); err != nil {
+		return err
+	}
+	return vm.push(vm.stack[frame.basePointer+int(second)])
+}
+
+func (vm *VM) opAddConstant(frame *Frame, ins code.Instructions, ip int) error {
+	constIndex := code.ReadUint16(ins[ip+1:])
+	frame.ip += 2
+
+	constant, err := vm.constant(int(constIndex))
+	if err != nil {
+		return err
+	}
+	if err := vm.push(constant); err != nil {
+		return err
+	}
+	return vm.binary(code.OpAdd)
+}
+
+func (vm *VM) opGetLocalAdd(frame *Frame, ins code.Instructions, ip int) error {
+	localIndex := code.ReadUint8(ins[ip+1:])
+	frame.ip += 1
+
+	if err := vm.push(vm.stack[frame.basePointer+int(localIndex)]); err != nil {
+		return err
+	}
+	return vm.binary(code.OpAdd)
+}
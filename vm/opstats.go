@@ -0,0 +1,58 @@
+//go:build opstats
+
+package vm
+
+import (
+	"bhasa/code"
+	"fmt"
+	"io"
+	"sort"
+	"sync/atomic"
+)
+
+// OpcodeStatsEnabled reports whether this binary counts executed opcodes;
+// build with -tags opstats to turn counting on
+const OpcodeStatsEnabled = true
+
+// opcodeCounts holds the number of times each opcode has run, in every VM
+// of the process (child VMs started by CallFunction may run on other
+// goroutines, hence the atomic adds)
+var opcodeCounts [256]uint64
+
+func countOpcode(op code.Opcode) {
+	atomic.AddUint64(&opcodeCounts[op], 1)
+}
+
+// WriteOpcodeStats writes how often each opcode has run, most frequent
+// first, with its share of all executed instructions
+func WriteOpcodeStats(w io.Writer) {
+	type entry struct {
+		name  string
+		count uint64
+	}
+	var entries []entry
+	var total uint64
+	for op := range opcodeCounts {
+		n := atomic.LoadUint64(&opcodeCounts[op])
+		if n == 0 {
+			continue
+		}
+		name := fmt.Sprintf("opcode %d", op)
+		if def, err := code.Lookup(byte(op)); err == nil {
+			name = def.Name
+		}
+		entries = append(entries, entry{name, n})
+		total += n
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].count != entries[j].count {
+			return entries[i].count > entries[j].count
+		}
+		return entries[i].name < entries[j].name
+	})
+
+	fmt.Fprintf(w, "opcode statistics: %d instructions\n", total)
+	for _, e := range entries {
+		fmt.Fprintf(w, "%-22s %12d %6.2f%%\n", e.name, e.count, float64(e.count)*100/float64(total))
+	}
+}
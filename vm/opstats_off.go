@@ -0,0 +1,18 @@
+//go:build !opstats
+
+package vm
+
+import (
+	"bhasa/code"
+	"io"
+)
+
+// OpcodeStatsEnabled reports whether this binary counts executed opcodes;
+// build with -tags opstats to turn counting on
+const OpcodeStatsEnabled = false
+
+// countOpcode compiles away when opcode statistics are off
+func countOpcode(op code.Opcode) {}
+
+// WriteOpcodeStats does nothing when opcode statistics are off
+func WriteOpcodeStats(w io.Writer) {}
@@ -0,0 +1,130 @@
+package vm
+
+import (
+	"bhasa/compiler"
+	"bhasa/object"
+	"io"
+	"os"
+	"sync"
+)
+
+// Job is one script run by a VMPool. Every field but Bytecode is optional.
+type Job struct {
+	Bytecode *compiler.Bytecode
+	Stdin    io.Reader
+	Stdout   io.Writer
+	Stderr   io.Writer
+	OnEvent  func(Event) // takes the place of Stdout and Stderr when set
+	Args     []string
+	Arena    bool
+	// BengaliNumerals shows numbers with Bengali digits
+	BengaliNumerals bool
+}
+
+// JobResult is the outcome of a Job: the value of the script's last
+// expression statement, or the error that stopped it
+type JobResult struct {
+	Value object.Object
+	Err   error
+}
+
+// VMPool runs scripts concurrently on a fixed number of VMs. A VM is
+// reused between jobs, keeping its stack and globals allocated, but is
+// reset first so every job starts with empty globals and sees nothing
+// from the jobs before it. Scripts may share bytecode; they must not share
+// mutable values such as arrays made with পরিবর্তনীয়.
+type VMPool struct {
+	vms chan *VM
+}
+
+// NewVMPool creates a pool of size VMs, so at most size jobs run at once
+func NewVMPool(size int) *VMPool {
+	if size < 1 {
+		size = 1
+	}
+	p := &VMPool{vms: make(chan *VM, size)}
+	for i := 0; i < size; i++ {
+		p.vms <- New(&compiler.Bytecode{})
+	}
+	return p
+}
+
+// Run runs a job on the next free VM, waiting for one if all are busy
+func (p *VMPool) Run(job Job) JobResult {
+	machine := <-p.vms
+	defer func() { p.vms <- machine }()
+
+	machine.reset(job.Bytecode)
+	if job.Stdin != nil {
+		machine.SetStdin(job.Stdin)
+	}
+	if job.Stdout != nil {
+		machine.SetStdout(job.Stdout)
+	}
+	if job.Stderr != nil {
+		machine.SetStderr(job.Stderr)
+	}
+	if job.OnEvent != nil {
+		machine.OnEvent(job.OnEvent)
+	}
+	machine.SetArgs(job.Args)
+	machine.SetBengaliNumerals(job.BengaliNumerals)
+	if job.Arena {
+		machine.EnableArena()
+	}
+
+	if err := machine.Run(); err != nil {
+		return JobResult{Err: err}
+	}
+	value := machine.LastPoppedStackElem()
+	if value == nil {
+		value = Null
+	}
+	return JobResult{Value: value}
+}
+
+// RunAll runs jobs concurrently and returns their results in the same order
+func (p *VMPool) RunAll(jobs []Job) []JobResult {
+	results := make([]JobResult, len(jobs))
+	var wg sync.WaitGroup
+	for i := range jobs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = p.Run(jobs[i])
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// reset prepares a used VM to run bytecode as if it were new. Every field
+// New sets or a run may change is set back here, so that no setting of
+// one job, such as profiling, carries over to the next.
+func (vm *VM) reset(bytecode *compiler.Bytecode) {
+	vm.constants = bytecode.Constants
+	mainFn := &object.CompiledFunction{Instructions: bytecode.Instructions, Lines: bytecode.Lines}
+	vm.frames[0] = NewFrame(&object.Closure{Fn: mainFn}, 0)
+	vm.framesIndex = 1
+
+	// Drop references left by the last job so they can be collected
+	clear(vm.stack)
+	vm.sp = 0
+	clear(vm.globals)
+	clear(vm.frames[1:])
+
+	vm.pendingConstructors = nil
+	vm.pendingMethods = make(map[string]*object.Closure)
+	vm.stdin = object.DefaultStdin()
+	vm.input = nil
+	vm.stdout = os.Stdout
+	vm.stderr = os.Stderr
+	vm.onEvent = nil
+	vm.args = nil
+	vm.bengaliNumerals = false
+	vm.arena = nil
+	vm.watches, vm.localWatches = nil, nil
+	vm.trace, vm.traceDepth = nil, 0
+	vm.profile = nil
+	vm.allocs = nil
+}
@@ -0,0 +1,63 @@
+package vm
+
+import (
+	"bhasa/code"
+	"time"
+)
+
+// Profile is what a VM counted while profiling was on: how many times each
+// opcode ran and the time spent in it. The time of an instruction runs
+// until the next one starts, so a call is charged for setting up the
+// frame and running any builtin, but not for the body of a Bhasa function,
+// whose instructions are charged themselves.
+type Profile struct {
+	Counts [256]uint64        // instructions run, by opcode
+	Times  [256]time.Duration // time spent in each opcode
+
+	current code.Opcode // the instruction being timed
+	started time.Time   // when it started, zero when none is
+}
+
+// EnableProfile makes the VM count and time every instruction it runs,
+// including those of functions builtins call back into. Reading the clock
+// for each instruction slows the program down several times over, so
+// wall times are best measured with profiling off.
+func (vm *VM) EnableProfile() {
+	vm.profile = &Profile{}
+}
+
+// Profile returns what the VM has counted since EnableProfile, or nil when
+// profiling is off
+func (vm *VM) Profile() *Profile {
+	return vm.profile
+}
+
+// Total is the number of instructions run
+func (p *Profile) Total() uint64 {
+	var total uint64
+	for _, n := range p.Counts {
+		total += n
+	}
+	return total
+}
+
+// enter ends the timing of the instruction before and starts that of op
+func (p *Profile) enter(op code.Opcode) {
+	now := time.Now()
+	p.charge(now)
+	p.Counts[op]++
+	p.current, p.started = op, now
+}
+
+// stop ends the timing of the last instruction of a run
+func (p *Profile) stop() {
+	p.charge(time.Now())
+}
+
+// charge adds the time until now to the instruction being timed
+func (p *Profile) charge(now time.Time) {
+	if !p.started.IsZero() {
+		p.Times[p.current] += now.Sub(p.started)
+		p.started = time.Time{}
+	}
+}
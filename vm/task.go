@@ -0,0 +1,83 @@
+package vm
+
+import (
+	"bhasa/object"
+	"bufio"
+	"io"
+	"sync"
+)
+
+// Spawn implements object.Runtime. It starts fn on a child VM running on
+// its own goroutine and returns the task that joins it. The child has its
+// own stack and frames and shares the constants and the globals, so tasks
+// that change a global another task uses should hold a তালা. Profiling,
+// tracing and allocation counts stay with this VM.
+func (vm *VM) Spawn(fn object.Object, args ...object.Object) object.Object {
+	vm.lockStreams()
+	child := vm.child(len(args))
+	child.globals = vm.globals
+
+	if builtin, ok := fn.(*object.Builtin); ok {
+		return object.StartTask(func() object.Object { return builtin.Call(child, args...) })
+	}
+	return object.StartTask(func() object.Object { return child.call(fn, args) })
+}
+
+// lockStreams makes the standard streams take a lock, so lines printed by
+// concurrent tasks do not interleave or race on a shared buffer, and tasks
+// reading input do not race on the reader they share. Each VM then reads
+// through a buffer of its own over the locked reader. The streams are
+// wrapped once, before the first task starts.
+func (vm *VM) lockStreams() {
+	if vm.input == nil {
+		vm.input = &lockedReader{r: vm.stdin}
+		vm.stdin = bufio.NewReader(vm.input)
+	}
+	if _, ok := vm.stdout.(*lockedWriter); ok {
+		return
+	}
+	mu := &sync.Mutex{}
+	vm.stdout = &lockedWriter{mu: mu, w: vm.stdout}
+	vm.stderr = &lockedWriter{mu: mu, w: vm.stderr}
+}
+
+// lockedWriter serializes writes to w with a lock it may share with others
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// lockedReader serializes reads from r. A read stops after the first line
+// ending, so the buffer a VM reads through never takes more than the line
+// it asked for and leaves later lines to whichever task reads next.
+type lockedReader struct {
+	mu sync.Mutex
+	r  *bufio.Reader
+}
+
+func (l *lockedReader) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for n < len(p) {
+		c, err := l.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		p[n] = c
+		n++
+		if c == '\n' {
+			break
+		}
+	}
+	return n, nil
+}
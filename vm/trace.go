@@ -0,0 +1,68 @@
+package vm
+
+import (
+	"bhasa/code"
+	"bhasa/object"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	traceStackValues = 3  // values shown from the top of the stack
+	traceValueWidth  = 24 // longer values are cut to this many characters
+)
+
+// Trace makes the VM write a line to w before it runs each instruction:
+// the depth of the frame running it, its offset and disassembly, the
+// number of values on the stack and the values on top, the topmost last,
+// and the source line it was compiled from. Comparing the stack pointer
+// before and after a statement shows whether it leaves the stack as it
+// found it. Functions that builtins call back into are traced too, deeper
+// than the call. A nil w turns tracing off.
+func (vm *VM) Trace(w io.Writer) {
+	vm.trace = w
+}
+
+// traceInstruction writes the trace line of the instruction at ip
+func (vm *VM) traceInstruction(ins code.Instructions, ip int) {
+	text, _ := ins.FormatAt(ip)
+	where := ""
+	if pos := object.PositionForOffset(vm.currentFrame().cl.Fn.Lines, ip); pos.Line != 0 {
+		where = fmt.Sprintf("  %s:%d", pos.File, pos.Line)
+	}
+	fmt.Fprintf(vm.trace, "%3d %04d %-24s sp=%-4d %s%s\n",
+		vm.traceDepth+vm.framesIndex, ip, text, vm.sp, vm.traceStack(), where)
+}
+
+// traceStack shows the values on top of the stack
+func (vm *VM) traceStack() string {
+	from := vm.sp - traceStackValues
+	prefix := "[... "
+	if from <= 0 {
+		from, prefix = 0, "["
+	}
+	values := make([]string, 0, traceStackValues)
+	for _, value := range vm.stack[from:vm.sp] {
+		values = append(values, traceValue(value))
+	}
+	return prefix + strings.Join(values, " ") + "]"
+}
+
+// traceValue shows a value on one short line, quoting strings so that
+// they stand out from names and numbers
+func traceValue(value object.Object) string {
+	var text string
+	switch value := value.(type) {
+	case nil:
+		return "<nil>"
+	case *object.String:
+		text = fmt.Sprintf("%q", value.Value)
+	default:
+		text = strings.ReplaceAll(value.Inspect(), "\n", " ")
+	}
+	if runes := []rune(text); len(runes) > traceValueWidth {
+		text = string(runes[:traceValueWidth-1]) + "…"
+	}
+	return text
+}
@@ -3,19 +3,29 @@ package vm
 import (
 	"bhasa/code"
 	"bhasa/compiler"
+	"bhasa/errors"
 	"bhasa/object"
+	"bufio"
 	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"sync/atomic"
 )
 
 const StackSize = 2048
 const GlobalsSize = 65536
 const MaxFrames = 1024
 
-var True = &object.Boolean{Value: true}
-var False = &object.Boolean{Value: false}
-var Null = &object.Null{}
+var True = object.TRUE
+var False = object.FALSE
+var Null = object.NULL
 
-// VM is a virtual machine
+// VM is a virtual machine. A VM belongs to one goroutine at a time: Run
+// fails if the VM is already running, and none of its methods may be called
+// while it runs. To run scripts concurrently give each goroutine its own
+// VM, or use a VMPool.
 type VM struct {
 	constants []object.Object
 
@@ -28,13 +38,53 @@ type VM struct {
 	framesIndex int
 
 	// Temporary storage for class construction
-	pendingConstructor *object.Closure
-	pendingMethods     map[string]*object.Closure
+	pendingConstructors []*object.Closure
+	pendingMethods      map[string]*object.Closure
+
+	// Standard streams and arguments seen by runtime-aware builtins
+	stdin  *bufio.Reader
+	stdout io.Writer
+	stderr io.Writer
+	args   []string
+
+	// input is the locked stream under stdin once tasks may read it
+	// concurrently; see lockStreams
+	input *lockedReader
+
+	// bengaliNumerals shows numbers with Bengali digits; see
+	// SetBengaliNumerals
+	bengaliNumerals bool
+
+	// onEvent receives tagged output when set with OnEvent
+	onEvent func(Event)
+
+	// arena allocates arithmetic results when enabled with EnableArena
+	arena *arena
+
+	// watches names the globals whose writes are reported, by index;
+	// localWatches holds the names of the watched locals
+	watches      map[int]string
+	localWatches map[string]bool
+
+	// profile counts and times instructions when enabled with EnableProfile
+	profile *Profile
+
+	// allocs counts the values created when enabled with CountAllocations
+	allocs *Allocations
+
+	// trace receives a line for every instruction when set with Trace;
+	// traceDepth is the depth of the call that started a child VM
+	trace      io.Writer
+	traceDepth int
+
+	// running is set while Run executes, to catch a VM shared between
+	// goroutines
+	running atomic.Bool
 }
 
 // New creates a new VM
 func New(bytecode *compiler.Bytecode) *VM {
-	mainFn := &object.CompiledFunction{Instructions: bytecode.Instructions}
+	mainFn := &object.CompiledFunction{Instructions: bytecode.Instructions, Lines: bytecode.Lines}
 	mainClosure := &object.Closure{Fn: mainFn}
 	mainFrame := NewFrame(mainClosure, 0)
 
@@ -52,8 +102,12 @@ func New(bytecode *compiler.Bytecode) *VM {
 		frames:      frames,
 		framesIndex: 1,
 
-		pendingConstructor: nil,
-		pendingMethods:     make(map[string]*object.Closure),
+		pendingConstructors: nil,
+		pendingMethods:      make(map[string]*object.Closure),
+
+		stdin:  object.DefaultStdin(),
+		stdout: os.Stdout,
+		stderr: os.Stderr,
 	}
 }
 
@@ -64,671 +118,119 @@ func NewWithGlobalsStore(bytecode *compiler.Bytecode, s []object.Object) *VM {
 	return vm
 }
 
-// StackTop returns the top element of the stack
-func (vm *VM) StackTop() object.Object {
-	if vm.sp == 0 {
-		return nil
+// SetStdin sets the input read by builtins such as পড়ো. Hosts that read
+// from the same stream themselves (like the REPL) should pass their own
+// *bufio.Reader so no buffered input is lost.
+func (vm *VM) SetStdin(r io.Reader) {
+	vm.input = nil
+	if br, ok := r.(*bufio.Reader); ok {
+		vm.stdin = br
+		return
 	}
-	return vm.stack[vm.sp-1]
+	vm.stdin = bufio.NewReader(r)
 }
 
-// Run executes the bytecode
-func (vm *VM) Run() error {
-	var ip int
-	var ins code.Instructions
-	var op code.Opcode
-
-	for vm.currentFrame().ip < len(vm.currentFrame().Instructions())-1 {
-		vm.currentFrame().ip++
-
-		ip = vm.currentFrame().ip
-		ins = vm.currentFrame().Instructions()
-		op = code.Opcode(ins[ip])
-
-		switch op {
-		case code.OpConstant:
-			constIndex := code.ReadUint16(ins[ip+1:])
-			vm.currentFrame().ip += 2
-
-			if constIndex >= uint16(len(vm.constants)) {
-				return fmt.Errorf("constant index %d out of range (length %d)", constIndex, len(vm.constants))
-			}
-
-			err := vm.push(vm.constants[constIndex])
-			if err != nil {
-				return err
-			}
-
-		case code.OpPop:
-			// Safety check: prevent stack underflow (compiler bug workaround)
-			if vm.sp > vm.currentFrame().basePointer {
-				vm.pop()
-			}
-
-		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpMod,
-			code.OpBitAnd, code.OpBitOr, code.OpBitXor, code.OpLeftShift, code.OpRightShift:
-			err := vm.executeBinaryOperation(op)
-			if err != nil {
-				return err
-			}
-
-		case code.OpTrue:
-			err := vm.push(True)
-			if err != nil {
-				return err
-			}
-
-		case code.OpFalse:
-			err := vm.push(False)
-			if err != nil {
-				return err
-			}
-
-		case code.OpEqual, code.OpNotEqual, code.OpGreaterThan, code.OpGreaterThanEqual:
-			err := vm.executeComparison(op)
-			if err != nil {
-				return err
-			}
-
-		case code.OpBang:
-			err := vm.executeBangOperator()
-			if err != nil {
-				return err
-			}
-
-		case code.OpAnd:
-			err := vm.executeAndOperator()
-			if err != nil {
-				return err
-			}
-
-		case code.OpOr:
-			err := vm.executeOrOperator()
-			if err != nil {
-				return err
-			}
-
-		case code.OpMinus:
-			err := vm.executeMinusOperator()
-			if err != nil {
-				return err
-			}
-
-		case code.OpBitNot:
-			err := vm.executeBitNotOperator()
-			if err != nil {
-				return err
-			}
-
-		case code.OpJump:
-			pos := int(code.ReadUint16(ins[ip+1:]))
-			vm.currentFrame().ip = pos - 1
-
-		case code.OpJumpNotTruthy:
-			pos := int(code.ReadUint16(ins[ip+1:]))
-			vm.currentFrame().ip += 2
-
-			condition := vm.pop()
-			if !isTruthy(condition) {
-				vm.currentFrame().ip = pos - 1
-			}
-
-		case code.OpNull:
-			err := vm.push(Null)
-			if err != nil {
-				return err
-			}
-
-		case code.OpSetGlobal:
-			globalIndex := code.ReadUint16(ins[ip+1:])
-			vm.currentFrame().ip += 2
-
-			vm.globals[globalIndex] = vm.pop()
-
-		case code.OpGetGlobal:
-			globalIndex := code.ReadUint16(ins[ip+1:])
-			vm.currentFrame().ip += 2
-
-			err := vm.push(vm.globals[globalIndex])
-			if err != nil {
-				return err
-			}
-
-		case code.OpArray:
-			numElements := int(code.ReadUint16(ins[ip+1:]))
-			vm.currentFrame().ip += 2
-
-			array := vm.buildArray(vm.sp-numElements, vm.sp)
-			vm.sp = vm.sp - numElements
-
-			err := vm.push(array)
-			if err != nil {
-				return err
-			}
-
-		case code.OpHash:
-			numElements := int(code.ReadUint16(ins[ip+1:]))
-			vm.currentFrame().ip += 2
-
-			hash, err := vm.buildHash(vm.sp-numElements, vm.sp)
-			if err != nil {
-				return err
-			}
-			vm.sp = vm.sp - numElements
-
-			err = vm.push(hash)
-			if err != nil {
-				return err
-			}
-
-		case code.OpStruct:
-			numElements := int(code.ReadUint16(ins[ip+1:]))
-			vm.currentFrame().ip += 2
-
-			structObj, err := vm.buildStruct(vm.sp-numElements, vm.sp)
-			if err != nil {
-				return err
-			}
-			vm.sp = vm.sp - numElements
-
-			err = vm.push(structObj)
-			if err != nil {
-				return err
-			}
-
-		case code.OpGetStructField:
-			fieldName := vm.pop()
-			structObj := vm.pop()
-
-			err := vm.executeGetStructField(structObj, fieldName)
-			if err != nil {
-				return err
-			}
-
-		case code.OpSetStructField:
-			value := vm.pop()
-			fieldName := vm.pop()
-			structObj := vm.pop()
-
-			err := vm.executeSetStructField(structObj, fieldName, value)
-			if err != nil {
-				return err
-			}
-
-		case code.OpIndex:
-			index := vm.pop()
-			left := vm.pop()
-
-			err := vm.executeIndexExpression(left, index)
-			if err != nil {
-				return err
-			}
-
-		case code.OpCall:
-			numArgs := code.ReadUint8(ins[ip+1:])
-			vm.currentFrame().ip += 1
-
-			err := vm.executeCall(int(numArgs))
-			if err != nil {
-				return err
-			}
-
-		case code.OpReturnValue:
-			returnValue := vm.pop()
-
-			frame := vm.popFrame()
-			vm.sp = frame.basePointer - 1
-
-			err := vm.push(returnValue)
-			if err != nil {
-				return err
-			}
-
-		case code.OpReturn:
-			frame := vm.popFrame()
-			vm.sp = frame.basePointer - 1
-
-			err := vm.push(Null)
-			if err != nil {
-				return err
-			}
-
-		case code.OpSetLocal:
-			localIndex := code.ReadUint8(ins[ip+1:])
-			vm.currentFrame().ip += 1
-
-			frame := vm.currentFrame()
-
-			vm.stack[frame.basePointer+int(localIndex)] = vm.pop()
-
-		case code.OpGetLocal:
-			localIndex := code.ReadUint8(ins[ip+1:])
-			vm.currentFrame().ip += 1
-
-			frame := vm.currentFrame()
-
-			err := vm.push(vm.stack[frame.basePointer+int(localIndex)])
-			if err != nil {
-				return err
-			}
-
-		case code.OpGetBuiltin:
-			builtinIndex := code.ReadUint8(ins[ip+1:])
-			vm.currentFrame().ip += 1
-
-			definition := object.Builtins[builtinIndex]
-
-			err := vm.push(definition.Builtin)
-			if err != nil {
-				return err
-			}
-
-		case code.OpClosure:
-			constIndex := code.ReadUint16(ins[ip+1:])
-			numFree := code.ReadUint8(ins[ip+3:])
-			vm.currentFrame().ip += 3
-
-			err := vm.pushClosure(int(constIndex), int(numFree))
-			if err != nil {
-				return err
-			}
-
-		case code.OpGetFree:
-			freeIndex := code.ReadUint8(ins[ip+1:])
-			vm.currentFrame().ip += 1
-
-			currentClosure := vm.currentFrame().cl
-
-			err := vm.push(currentClosure.Free[freeIndex])
-			if err != nil {
-				return err
-			}
-
-		case code.OpCurrentClosure:
-			currentClosure := vm.currentFrame().cl
-
-			err := vm.push(currentClosure)
-			if err != nil {
-				return err
-			}
-
-		case code.OpAssertType:
-			constIndex := code.ReadUint16(ins[ip+1:])
-			vm.currentFrame().ip += 2
-
-			expectedType := vm.constants[constIndex].(*object.String).Value
-			value := vm.pop()
-
-			// Check if type matches exactly
-			if vm.checkType(value, expectedType) {
-				// Type matches, push value back
-				err := vm.push(value)
-				if err != nil {
-					return err
-				}
-			} else {
-				// Try implicit type conversion for compatible types
-				converted, err := vm.castType(value, expectedType)
-				if err != nil {
-					return fmt.Errorf("type error: expected %s, got %s (cannot convert: %v)",
-						expectedType, vm.getTypeName(value), err)
-				}
-				// Push converted value
-				err = vm.push(converted)
-				if err != nil {
-					return err
-				}
-			}
-
-		case code.OpTypeCast:
-			constIndex := code.ReadUint16(ins[ip+1:])
-			vm.currentFrame().ip += 2
-
-			targetType := vm.constants[constIndex].(*object.String).Value
-			value := vm.pop()
-
-			castedValue, err := vm.castType(value, targetType)
-			if err != nil {
-				return err
-			}
-
-			err = vm.push(castedValue)
-			if err != nil {
-				return err
-			}
-
-		case code.OpTypeCheck:
-			constIndex := code.ReadUint16(ins[ip+1:])
-			vm.currentFrame().ip += 2
-
-			expectedType := vm.constants[constIndex].(*object.String).Value
-			value := vm.pop()
-
-			// Push boolean result of type check
-			result := vm.checkType(value, expectedType)
-			if result {
-				err := vm.push(True)
-				if err != nil {
-					return err
-				}
-			} else {
-				err := vm.push(False)
-				if err != nil {
-					return err
-				}
-			}
-
-		// ========== OOP Opcodes ==========
-
-		case code.OpDefineConstructor:
-			_ = code.ReadUint16(ins[ip+1:]) // constIndex not needed at runtime
-			vm.currentFrame().ip += 2
-
-			// Pop the constructor closure from the stack
-			constructorObj := vm.pop()
-			constructor, ok := constructorObj.(*object.Closure)
-			if !ok {
-				return fmt.Errorf("OpDefineConstructor: expected closure, got %T", constructorObj)
-			}
-
-			// Store it for the upcoming OpClass
-			vm.pendingConstructor = constructor
-
-		case code.OpDefineMethod:
-			methodNameIndex := code.ReadUint16(ins[ip+1:])
-			vm.currentFrame().ip += 2
-
-			// Get the method name from constants
-			methodNameObj := vm.constants[methodNameIndex]
-			methodName, ok := methodNameObj.(*object.String)
-			if !ok {
-				return fmt.Errorf("OpDefineMethod: expected string for method name, got %T", methodNameObj)
-			}
-
-			// Pop the method closure from the stack
-			methodClosureObj := vm.pop()
-			methodClosure, ok := methodClosureObj.(*object.Closure)
-			if !ok {
-				return fmt.Errorf("OpDefineMethod: expected closure, got %T", methodClosureObj)
-			}
-
-			// Store it for the upcoming OpClass
-			vm.pendingMethods[methodName.Value] = methodClosure
-
-		case code.OpClass:
-			constIndex := code.ReadUint16(ins[ip+1:])
-			vm.currentFrame().ip += 2
-
-			// Get the class template from constants
-			classTemplate := vm.constants[constIndex]
-			classObj, ok := classTemplate.(*object.Class)
-			if !ok {
-				return fmt.Errorf("OpClass: expected class, got %T", classTemplate)
-			}
-
-			// Create a copy of the class and attach the runtime constructor/methods
-			class := &object.Class{
-				Name:         classObj.Name,
-				SuperClass:   classObj.SuperClass,
-				Interfaces:   classObj.Interfaces,
-				Fields:       classObj.Fields,
-				Methods:      make(map[string]*object.Method),
-				Constructor:  vm.pendingConstructor,
-				StaticFields: classObj.StaticFields,
-				IsAbstract:   classObj.IsAbstract,
-				IsFinal:      classObj.IsFinal,
-				FieldAccess:  classObj.FieldAccess,
-				FieldOrder:   classObj.FieldOrder,
-			}
-
-			// Copy methods from template and attach pending runtime closures
-			for name, method := range classObj.Methods {
-				// Create a copy of the method
-				methodCopy := &object.Method{
-					Name:       method.Name,
-					Access:     method.Access,
-					IsStatic:   method.IsStatic,
-					IsFinal:    method.IsFinal,
-					IsAbstract: method.IsAbstract,
-					Closure:    method.Closure,
-				}
-				// If there's a pending runtime closure for this method, use it
-				if runtimeClosure, exists := vm.pendingMethods[name]; exists {
-					methodCopy.Closure = runtimeClosure
-				}
-				class.Methods[name] = methodCopy
-			}
-
-			// Clear pending constructor and methods for next class
-			vm.pendingConstructor = nil
-			vm.pendingMethods = make(map[string]*object.Closure)
-
-			err := vm.push(class)
-			if err != nil {
-				return err
-			}
-
-		case code.OpNewInstance:
-			numArgs := code.ReadUint8(ins[ip+1:])
-			vm.currentFrame().ip += 1
-
-			// Arguments are already on the stack: [arg1, arg2, ..., argN, class]
-			// Get the class (it's at the top of the stack after the arguments)
-			classObj := vm.stack[vm.sp-1]
-			class, ok := classObj.(*object.Class)
-			if !ok {
-				return fmt.Errorf("expected class, got %T", classObj)
-			}
-
-			// Remove class from stack (arguments stay on stack)
-			vm.sp--
-
-			// Create new instance
-			instance := &object.ClassInstance{
-				Class:  class,
-				Fields: make(map[string]object.Object),
-				This:   nil,
-			}
-			instance.This = instance
-
-			// Initialize fields to null
-			for _, fieldName := range class.FieldOrder {
-				instance.Fields[fieldName] = Null
-			}
-
-			// Call constructor if exists
-			if class.Constructor != nil {
-				// To match the normal calling convention [callee, args...], we need to push
-				// a placeholder before the instance, so stack becomes [placeholder, instance, args...]
-				// When constructor returns, the return value replaces the placeholder
-
-				// Push placeholder (use the class itself)
-				err := vm.push(class)
-				if err != nil {
-					return err
-				}
-
-				// Push instance as first argument (this)
-				err = vm.push(instance)
-				if err != nil {
-					return err
-				}
-
-				// Get constructor args that are already on stack
-				// Stack is currently: [..., arg1, arg2, ..., argN, class, instance]
-				// We need: [class, instance, arg1, arg2, ..., argN]
-
-				// First pop instance and class
-				inst := vm.pop()
-				classPlaceholder := vm.pop()
-
-				// Pop all constructor arguments
-				args := make([]object.Object, numArgs)
-				for i := int(numArgs) - 1; i >= 0; i-- {
-					args[i] = vm.pop()
-				}
-
-				// Push back in correct order: class, instance, args
-				err = vm.push(classPlaceholder)
-				if err != nil {
-					return err
-				}
-				err = vm.push(inst)
-				if err != nil {
-					return err
-				}
-				for _, arg := range args {
-					err = vm.push(arg)
-					if err != nil {
-						return err
-					}
-				}
-
-				// Call constructor using standard calling convention
-				// Stack: [class, instance, arg1, arg2, ..., argN]
-				// The constructor expects numArgs + 1 (for 'this')
-				err = vm.callClosure(class.Constructor, int(numArgs)+1)
-				if err != nil {
-					return err
-				}
-				// After constructor returns, the return value (instance) will be on stack
-			} else {
-				// No constructor, just push the instance
-				err := vm.push(instance)
-				if err != nil {
-					return err
-				}
-			}
-
-		case code.OpCallMethod:
-			numArgs := code.ReadUint8(ins[ip+1:])
-			vm.currentFrame().ip += 1
-
-			// Get arguments
-			args := make([]object.Object, numArgs)
-			for i := int(numArgs) - 1; i >= 0; i-- {
-				args[i] = vm.pop()
-			}
-
-			// Get method name
-			methodName := vm.pop().(*object.String).Value
-
-			// Get object
-			obj := vm.pop()
-
-			instance, ok := obj.(*object.ClassInstance)
-			if !ok {
-				return fmt.Errorf("cannot call method on non-class instance: %T", obj)
-			}
-
-			// Find method in class hierarchy
-			method := instance.Class.GetMethod(methodName)
-			if method == nil {
-				return fmt.Errorf("method '%s' not found in class '%s'", methodName, instance.Class.Name)
-			}
-
-			// Prepare arguments: [this, arg1, arg2, ...]
-			allArgs := append([]object.Object{instance}, args...)
-
-			// Create new frame for method
-			frame := NewFrame(method.Closure, vm.sp-len(allArgs))
-			vm.pushFrame(frame)
-
-			// Push arguments onto stack
-			for _, arg := range allArgs {
-				err := vm.push(arg)
-				if err != nil {
-					return err
-				}
-			}
-
-			vm.sp = frame.basePointer + method.Closure.Fn.NumLocals
-
-		case code.OpGetThis:
-			// 'this' is always the first parameter (index 0)
-			basePointer := vm.currentFrame().basePointer
-			err := vm.push(vm.stack[basePointer])
-			if err != nil {
-				return err
-			}
-
-		case code.OpGetSuper:
-			// Get current instance (this)
-			basePointer := vm.currentFrame().basePointer
-			thisObj := vm.stack[basePointer]
-
-			instance, ok := thisObj.(*object.ClassInstance)
-			if !ok {
-				return fmt.Errorf("super can only be used in class methods")
-			}
+// SetStdout sets the writer that লেখ prints to and prompts are shown on.
+// Hosts capturing a program's output pass their own writer instead of
+// redirecting os.Stdout, which would catch every VM in the process.
+func (vm *VM) SetStdout(w io.Writer) {
+	vm.stdout = w
+}
 
-			if instance.Class.SuperClass == nil {
-				return fmt.Errorf("class has no parent class")
-			}
+// SetStderr sets the writer for diagnostics builtins print while the
+// program runs, such as the errors of সার্ভার handlers
+func (vm *VM) SetStderr(w io.Writer) {
+	vm.stderr = w
+}
 
-			// Push parent class
-			err := vm.push(instance.Class.SuperClass)
-			if err != nil {
-				return err
-			}
+// SetArgs sets the command-line arguments returned by আর্গুমেন্ট
+func (vm *VM) SetArgs(args []string) {
+	vm.args = args
+}
 
-		case code.OpInterface:
-			constIndex := code.ReadUint16(ins[ip+1:])
-			vm.currentFrame().ip += 2
+// Args implements object.Runtime
+func (vm *VM) Args() []string { return vm.args }
 
-			iface := vm.constants[constIndex]
-			err := vm.push(iface)
-			if err != nil {
-				return err
-			}
+// SetBengaliNumerals makes the program show numbers with Bengali digits,
+// so that লেখ(15) prints ১৫. Each VM has its own setting, which the tasks
+// and calls it starts inherit.
+func (vm *VM) SetBengaliNumerals(on bool) {
+	vm.bengaliNumerals = on
+}
 
-		case code.OpGetInstanceField:
-			// Get field name
-			fieldName := vm.pop().(*object.String).Value
+// BengaliNumerals implements object.Runtime
+func (vm *VM) BengaliNumerals() bool { return vm.bengaliNumerals }
 
-			// Get instance
-			obj := vm.pop()
-			instance, ok := obj.(*object.ClassInstance)
-			if !ok {
-				return fmt.Errorf("cannot get field from non-class instance")
-			}
+// Stdin implements object.Runtime. A prompt waiting in an event writer is
+// sent first, so a host sees it before the program blocks on input.
+func (vm *VM) Stdin() *bufio.Reader {
+	vm.flushEvents()
+	return vm.stdin
+}
 
-			// Get field value
-			value, exists := instance.GetField(fieldName)
-			if !exists {
-				value = Null
-			}
+// Stdout implements object.Runtime
+func (vm *VM) Stdout() io.Writer { return vm.stdout }
 
-			err := vm.push(value)
-			if err != nil {
-				return err
-			}
+// Stderr implements object.Runtime
+func (vm *VM) Stderr() io.Writer { return vm.stderr }
 
-		case code.OpSetInstanceField:
-			// Get value
-			value := vm.pop()
+// constant returns an entry of the constant pool. Verified bytecode never
+// fails this check, but bytecode built by hand or by another compiler may.
+func (vm *VM) constant(index int) (object.Object, error) {
+	if index >= len(vm.constants) {
+		return nil, fmt.Errorf("%s", errors.ConstantIndexOutOfRange(index, len(vm.constants)))
+	}
+	return vm.constants[index], nil
+}
 
-			// Get field name
-			fieldName := vm.pop().(*object.String).Value
+// constantString returns a string constant such as a type name
+func (vm *VM) constantString(index int) (string, error) {
+	constant, err := vm.constant(index)
+	if err != nil {
+		return "", err
+	}
+	str, ok := constant.(*object.String)
+	if !ok {
+		return "", fmt.Errorf("expected STRING constant at index %d, got %s", index, constant.Type())
+	}
+	return str.Value, nil
+}
 
-			// Get instance
-			obj := vm.pop()
-			instance, ok := obj.(*object.ClassInstance)
-			if !ok {
-				return fmt.Errorf("cannot set field on non-class instance")
-			}
+// StackTop returns the top element of the stack
+func (vm *VM) StackTop() object.Object {
+	if vm.sp == 0 {
+		return nil
+	}
+	return vm.stack[vm.sp-1]
+}
 
-			// Set field value
-			instance.SetField(fieldName, value)
+// Run executes the bytecode
+func (vm *VM) Run() error {
+	if !vm.running.CompareAndSwap(false, true) {
+		return fmt.Errorf("VM is already running; a VM cannot be used by two goroutines at once")
+	}
+	defer vm.running.Store(false)
 
-			err := vm.push(value)
-			if err != nil {
-				return err
-			}
-		}
+	err := vm.run()
+	if vm.profile != nil {
+		vm.profile.stop()
 	}
+	if err != nil {
+		err = vm.locate(err)
+	}
+	vm.emitRunEvents(err)
+	return err
+}
 
-	return nil
+// locate prefixes a runtime error with the file and line of the code that
+// was running, when the compiler recorded them
+func (vm *VM) locate(err error) error {
+	frame := vm.currentFrame()
+	pos := object.PositionForOffset(frame.cl.Fn.Lines, frame.ip)
+	if pos.File == "" || pos.Line == 0 {
+		return err
+	}
+	return fmt.Errorf("%s:%d: %w", pos.File, pos.Line, err)
 }
 
 func (vm *VM) push(o object.Object) error {
@@ -760,10 +262,30 @@ func (vm *VM) executeBinaryOperation(op code.Opcode) error {
 	leftType := left.Type()
 	rightType := right.Type()
 
+	// Class instances may overload operators
+	if leftType == object.CLASS_INSTANCE_OBJ {
+		if ok, err := vm.executeOverloadedOperation(op, left, right); ok || err != nil {
+			return err
+		}
+	}
+
 	// String operations
 	if leftType == object.STRING_OBJ && rightType == object.STRING_OBJ {
 		return vm.executeBinaryStringOperation(op, left, right)
 	}
+	// A character joins a string as the one-character string it is
+	if op == code.OpAdd && (leftType == object.STRING_OBJ && rightType == object.CHAR_OBJ ||
+		leftType == object.CHAR_OBJ && rightType == object.STRING_OBJ) {
+		vm.allocated(AllocString)
+		return vm.push(&object.String{Value: left.Inspect() + right.Inspect()})
+	}
+
+	if leftType == object.DECIMAL_OBJ || rightType == object.DECIMAL_OBJ {
+		return vm.executeBinaryDecimalOperation(op, left, right)
+	}
+	if leftType == object.BIG_INTEGER_OBJ || rightType == object.BIG_INTEGER_OBJ {
+		return vm.executeBinaryBigIntegerOperation(op, left, right)
+	}
 
 	// Check if both operands are numeric types
 	if vm.isNumericType(leftType) && vm.isNumericType(rightType) {
@@ -854,6 +376,9 @@ func (vm *VM) toFloat64(obj object.Object) float64 {
 		return float64(v.Value)
 	case *object.Double:
 		return v.Value
+	case *object.BigInteger:
+		f, _ := new(big.Float).SetInt(v.Value).Float64()
+		return f
 	default:
 		return 0.0
 	}
@@ -876,7 +401,7 @@ func (vm *VM) promoteIntegerResult(result int64, left, right object.Object) obje
 		return &object.Short{Value: int16(result)}
 	}
 	// Default to Integer (legacy int64) for backward compatibility
-	return &object.Integer{Value: result}
+	return vm.newInteger(result)
 }
 
 func (vm *VM) executeBinaryIntegerOperation(
@@ -886,6 +411,12 @@ func (vm *VM) executeBinaryIntegerOperation(
 	leftValue := vm.toInt64(left)
 	rightValue := vm.toInt64(right)
 
+	// Integers grow into big integers instead of wrapping around
+	if left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ &&
+		object.IntegerOverflows(bigOperators[op], leftValue, rightValue) {
+		return vm.executeBinaryBigIntegerOperation(op, left, right)
+	}
+
 	var result int64
 
 	switch op {
@@ -952,16 +483,12 @@ func (vm *VM) executeBinaryFloatOperation(
 	case code.OpMul:
 		result = leftValue * rightValue
 	case code.OpDiv:
-		if rightValue == 0 {
-			return fmt.Errorf("division by zero")
-		}
+		// Floating-point division follows IEEE 754: x/0 is ±Inf and 0/0
+		// is NaN, unlike integer division which is an error
 		result = leftValue / rightValue
 	case code.OpMod:
-		// Floating-point modulo using fmod equivalent
-		if rightValue == 0 {
-			return fmt.Errorf("modulo by zero")
-		}
-		result = leftValue - rightValue*float64(int64(leftValue/rightValue))
+		// Likewise x % 0 is NaN
+		result = math.Mod(leftValue, rightValue)
 	default:
 		return fmt.Errorf("unknown float operator: %d", op)
 	}
@@ -970,7 +497,7 @@ func (vm *VM) executeBinaryFloatOperation(
 	if left.Type() == object.DOUBLE_OBJ || right.Type() == object.DOUBLE_OBJ {
 		return vm.push(&object.Double{Value: result})
 	}
-	return vm.push(&object.Float{Value: float32(result)})
+	return vm.push(vm.newFloat(float32(result)))
 }
 
 func (vm *VM) executeBinaryStringOperation(
@@ -984,6 +511,7 @@ func (vm *VM) executeBinaryStringOperation(
 	leftValue := left.(*object.String).Value
 	rightValue := right.(*object.String).Value
 
+	vm.allocated(AllocString)
 	return vm.push(&object.String{Value: leftValue + rightValue})
 }
 
@@ -1009,6 +537,26 @@ func (vm *VM) executeComparison(op code.Opcode) error {
 		}
 	}
 
+	if left.Type() == object.CLASS_INSTANCE_OBJ || right.Type() == object.CLASS_INSTANCE_OBJ {
+		if ok, err := vm.executeOverloadedComparison(op, left, right); ok || err != nil {
+			return err
+		}
+	}
+
+	// Big integers and decimals compare with any number
+	isNumeric := func(obj object.Object) bool {
+		t := obj.Type()
+		return t == object.BIG_INTEGER_OBJ || t == object.DECIMAL_OBJ || vm.isNumericType(t)
+	}
+	if isNumeric(left) && isNumeric(right) {
+		if left.Type() == object.DECIMAL_OBJ || right.Type() == object.DECIMAL_OBJ {
+			return vm.executeDecimalComparison(op, left, right)
+		}
+		if left.Type() == object.BIG_INTEGER_OBJ || right.Type() == object.BIG_INTEGER_OBJ {
+			return vm.executeBigIntegerComparison(op, left, right)
+		}
+	}
+
 	// Handle numeric comparisons
 	if vm.isNumericType(left.Type()) && vm.isNumericType(right.Type()) {
 		return vm.executeNumericComparison(op, left, right)
@@ -1017,14 +565,60 @@ func (vm *VM) executeComparison(op code.Opcode) error {
 	// Handle equality for non-numeric types
 	switch op {
 	case code.OpEqual:
-		return vm.push(nativeBoolToBooleanObject(right == left))
+		return vm.push(nativeBoolToBooleanObject(objectsEqual(left, right)))
 	case code.OpNotEqual:
-		return vm.push(nativeBoolToBooleanObject(right != left))
+		return vm.push(nativeBoolToBooleanObject(!objectsEqual(left, right)))
 	default:
 		return fmt.Errorf("unknown operator: %d (%s %s)", op, left.Type(), right.Type())
 	}
 }
 
+// objectsEqual compares booleans, strings and characters by value (strings
+// are not interned, and embedders may make booleans of their own) and
+// everything else by identity
+func objectsEqual(left, right object.Object) bool {
+	switch left := left.(type) {
+	case *object.Boolean:
+		if right, ok := right.(*object.Boolean); ok {
+			return left.Value == right.Value
+		}
+	case *object.String:
+		if right, ok := right.(*object.String); ok {
+			return left.Value == right.Value
+		}
+	case *object.Char:
+		if right, ok := right.(*object.Char); ok {
+			return left.Value == right.Value
+		}
+	case *object.Tuple:
+		if right, ok := right.(*object.Tuple); ok {
+			return tuplesEqual(left, right)
+		}
+	}
+	return left == right
+}
+
+// tuplesEqual compares tuples element by element; elements that can be
+// hash keys are equal when their keys are
+func tuplesEqual(left, right *object.Tuple) bool {
+	if len(left.Elements) != len(right.Elements) {
+		return false
+	}
+	for i, l := range left.Elements {
+		r := right.Elements[i]
+		lk, lok := object.HashKeyOf(l)
+		rk, rok := object.HashKeyOf(r)
+		if lok && rok {
+			if lk != rk {
+				return false
+			}
+		} else if !objectsEqual(l, r) {
+			return false
+		}
+	}
+	return true
+}
+
 func (vm *VM) executeNumericComparison(
 	op code.Opcode,
 	left, right object.Object,
@@ -1077,16 +671,8 @@ func (vm *VM) executeIntegerComparison(
 func (vm *VM) executeBangOperator() error {
 	operand := vm.pop()
 
-	switch operand {
-	case True:
-		return vm.push(False)
-	case False:
-		return vm.push(True)
-	case Null:
-		return vm.push(True)
-	default:
-		return vm.push(False)
-	}
+	// Builtins may return booleans other than True and False
+	return vm.push(nativeBoolToBooleanObject(!isTruthy(operand)))
 }
 
 func (vm *VM) executeAndOperator() error {
@@ -1114,6 +700,13 @@ func (vm *VM) executeOrOperator() error {
 func (vm *VM) executeMinusOperator() error {
 	operand := vm.pop()
 
+	if b, ok := operand.(*object.BigInteger); ok {
+		return vm.push(&object.BigInteger{Value: new(big.Int).Neg(b.Value)})
+	}
+	if d, ok := operand.(*object.Decimal); ok {
+		return vm.push(&object.Decimal{Value: new(big.Rat).Neg(d.Value)})
+	}
+
 	if !vm.isNumericType(operand.Type()) {
 		return fmt.Errorf("unsupported type for negation: %s", operand.Type())
 	}
@@ -1124,7 +717,7 @@ func (vm *VM) executeMinusOperator() error {
 		if operand.Type() == object.DOUBLE_OBJ {
 			return vm.push(&object.Double{Value: -value})
 		}
-		return vm.push(&object.Float{Value: -float32(value)})
+		return vm.push(vm.newFloat(-float32(value)))
 	}
 
 	// Handle integer negation
@@ -1141,7 +734,10 @@ func (vm *VM) executeMinusOperator() error {
 	case object.CHAR_OBJ:
 		return vm.push(&object.Char{Value: -rune(value)})
 	default:
-		return vm.push(&object.Integer{Value: -value})
+		if value == math.MinInt64 {
+			return vm.push(&object.BigInteger{Value: new(big.Int).Neg(big.NewInt(value))})
+		}
+		return vm.push(vm.newInteger(-value))
 	}
 }
 
@@ -1169,7 +765,7 @@ func (vm *VM) executeBitNotOperator() error {
 	case object.CHAR_OBJ:
 		return vm.push(&object.Char{Value: rune(^value)})
 	default:
-		return vm.push(&object.Integer{Value: ^value})
+		return vm.push(vm.newInteger(^value))
 	}
 }
 
@@ -1180,9 +776,33 @@ func (vm *VM) buildArray(startIndex, endIndex int) object.Object {
 		elements[i-startIndex] = vm.stack[i]
 	}
 
+	vm.allocated(AllocArray)
 	return &object.Array{Elements: elements}
 }
 
+// unpack pushes the elements of a tuple or array, which must have exactly
+// numNames of them
+func (vm *VM) unpack(value object.Object, numNames int) error {
+	var elements []object.Object
+	switch value := value.(type) {
+	case *object.Tuple:
+		elements = value.Elements
+	case *object.Array:
+		elements = value.Elements
+	default:
+		return fmt.Errorf("cannot unpack %s into %d names", value.Type(), numNames)
+	}
+	if len(elements) != numNames {
+		return fmt.Errorf("cannot unpack %d values into %d names", len(elements), numNames)
+	}
+	for _, el := range elements {
+		if err := vm.push(el); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (vm *VM) buildHash(startIndex, endIndex int) (object.Object, error) {
 	hashedPairs := make(map[object.HashKey]object.HashPair)
 
@@ -1192,14 +812,15 @@ func (vm *VM) buildHash(startIndex, endIndex int) (object.Object, error) {
 
 		pair := object.HashPair{Key: key, Value: value}
 
-		hashKey, ok := key.(object.Hashable)
+		hashKey, ok := object.HashKeyOf(key)
 		if !ok {
 			return nil, fmt.Errorf("unusable as hash key: %s", key.Type())
 		}
 
-		hashedPairs[hashKey.HashKey()] = pair
+		hashedPairs[hashKey] = pair
 	}
 
+	vm.allocated(AllocHash)
 	return &object.Hash{Pairs: hashedPairs}, nil
 }
 
@@ -1240,6 +861,10 @@ func (vm *VM) executeGetStructField(obj, fieldName object.Object) error {
 
 	// Handle class instance field access
 	if instance, ok := obj.(*object.ClassInstance); ok {
+		if err := vm.checkAccess(instance.Class, fieldNameStr.Value); err != nil {
+			return err
+		}
+
 		// First check if it's a field
 		if value, exists := instance.Fields[fieldNameStr.Value]; exists {
 			return vm.push(value)
@@ -1247,6 +872,10 @@ func (vm *VM) executeGetStructField(obj, fieldName object.Object) error {
 
 		// If not a field, check if it's a method in the class
 		if method, exists := instance.Class.Methods[fieldNameStr.Value]; exists {
+			// Static methods take no 'this'
+			if method.IsStatic {
+				return vm.push(method.Closure)
+			}
 			// Create a bound method that wraps the closure and the instance
 			// When called, the instance will be automatically passed as 'this'
 			boundMethod := &object.BoundMethod{
@@ -1256,9 +885,19 @@ func (vm *VM) executeGetStructField(obj, fieldName object.Object) error {
 			return vm.push(boundMethod)
 		}
 
+		// Static fields can be read through an instance too
+		if value, exists := instance.Class.GetStatic(fieldNameStr.Value); exists {
+			return vm.push(value)
+		}
+
 		return fmt.Errorf("class instance has no field or method named '%s'", fieldNameStr.Value)
 	}
 
+	// A class value (e.g. one passed to a function) gives its static members
+	if _, ok := obj.(*object.Class); ok {
+		return vm.executeGetStatic(obj, fieldNameStr.Value)
+	}
+
 	// Handle enum variant access
 	if enumType, ok := obj.(*object.EnumType); ok {
 		variantValue, exists := enumType.Variants[fieldNameStr.Value]
@@ -1278,6 +917,53 @@ func (vm *VM) executeGetStructField(obj, fieldName object.Object) error {
 	return fmt.Errorf("cannot access field on type: %s", obj.Type())
 }
 
+// checkAccess fails when the running code, judged by the class whose body
+// it was written in, may not use the named member of class
+func (vm *VM) checkAccess(class *object.Class, name string) error {
+	from := vm.currentFrame().cl.Fn.ClassName
+	if class.CanAccess(name, from) {
+		return nil
+	}
+	access, declaring := class.MemberAccess(name)
+	if access == "ব্যক্তিগত" {
+		return fmt.Errorf("%s", errors.PrivateMember(name, declaring.Name))
+	}
+	return fmt.Errorf("%s", errors.ProtectedMember(name, declaring.Name))
+}
+
+// executeGetStatic pushes a static field or static method of a class
+func (vm *VM) executeGetStatic(obj object.Object, name string) error {
+	class, ok := obj.(*object.Class)
+	if !ok {
+		return fmt.Errorf("cannot get static member '%s' of %s", name, obj.Type())
+	}
+	if err := vm.checkAccess(class, name); err != nil {
+		return err
+	}
+	if value, ok := class.GetStatic(name); ok {
+		return vm.push(value)
+	}
+	if class.GetMethod(name) != nil {
+		return fmt.Errorf("method '%s' of class '%s' is not static; call it on an instance", name, class.Name)
+	}
+	return fmt.Errorf("class '%s' has no static field or method named '%s'", class.Name, name)
+}
+
+// executeSetStatic assigns a static field of a class
+func (vm *VM) executeSetStatic(obj object.Object, name string, value object.Object) error {
+	class, ok := obj.(*object.Class)
+	if !ok {
+		return fmt.Errorf("cannot set static field '%s' of %s", name, obj.Type())
+	}
+	if err := vm.checkAccess(class, name); err != nil {
+		return err
+	}
+	if !class.SetStatic(name, value) {
+		return fmt.Errorf("class '%s' has no static field named '%s'", class.Name, name)
+	}
+	return nil
+}
+
 func (vm *VM) executeSetStructField(structObj, fieldName, value object.Object) error {
 	fieldNameStr, ok := fieldName.(*object.String)
 	if !ok {
@@ -1305,7 +991,17 @@ func (vm *VM) executeSetStructField(structObj, fieldName, value object.Object) e
 		// Push the struct back (for chaining if needed)
 		return vm.push(obj)
 
+	case *object.Class:
+		if err := vm.executeSetStatic(obj, fieldNameStr.Value, value); err != nil {
+			return err
+		}
+		return vm.push(obj)
+
 	case *object.ClassInstance:
+		if err := vm.checkAccess(obj.Class, fieldNameStr.Value); err != nil {
+			return err
+		}
+
 		// Set field on class instance
 		obj.Fields[fieldNameStr.Value] = value
 
@@ -1321,6 +1017,12 @@ func (vm *VM) executeIndexExpression(left, index object.Object) error {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return vm.executeArrayIndex(left, index)
+	case left.Type() == object.TUPLE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return vm.executeTupleIndex(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return vm.executeStringIndex(left, index)
+	case left.Type() == object.BYTES_OBJ && index.Type() == object.INTEGER_OBJ:
+		return vm.executeBytesIndex(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return vm.executeHashIndex(left, index)
 	default:
@@ -1330,25 +1032,51 @@ func (vm *VM) executeIndexExpression(left, index object.Object) error {
 
 func (vm *VM) executeArrayIndex(array, index object.Object) error {
 	arrayObject := array.(*object.Array)
-	i := index.(*object.Integer).Value
-	max := int64(len(arrayObject.Elements) - 1)
-
-	if i < 0 || i > max {
+	i, ok := object.ResolveIndex(index.(*object.Integer).Value, len(arrayObject.Elements))
+	if !ok {
 		return vm.push(Null)
 	}
 
 	return vm.push(arrayObject.Elements[i])
 }
 
+func (vm *VM) executeTupleIndex(tuple, index object.Object) error {
+	elements := tuple.(*object.Tuple).Elements
+	i, ok := object.ResolveIndex(index.(*object.Integer).Value, len(elements))
+	if !ok {
+		return vm.push(Null)
+	}
+
+	return vm.push(elements[i])
+}
+
+func (vm *VM) executeStringIndex(str, index object.Object) error {
+	char, ok := object.IndexString(str.(*object.String), index.(*object.Integer).Value)
+	if !ok {
+		return vm.push(Null)
+	}
+
+	return vm.push(char)
+}
+
+func (vm *VM) executeBytesIndex(bytes, index object.Object) error {
+	b, ok := object.IndexBytes(bytes.(*object.Bytes), index.(*object.Integer).Value)
+	if !ok {
+		return vm.push(Null)
+	}
+
+	return vm.push(b)
+}
+
 func (vm *VM) executeHashIndex(hash, index object.Object) error {
 	hashObject := hash.(*object.Hash)
 
-	key, ok := index.(object.Hashable)
+	key, ok := object.HashKeyOf(index)
 	if !ok {
 		return fmt.Errorf("unusable as hash key: %s", index.Type())
 	}
 
-	pair, ok := hashObject.Pairs[key.HashKey()]
+	pair, ok := hashObject.Pairs[key]
 	if !ok {
 		return vm.push(Null)
 	}
@@ -1397,25 +1125,60 @@ func (vm *VM) executeCall(numArgs int) error {
 }
 
 func (vm *VM) callClosure(cl *object.Closure, numArgs int) error {
-	if numArgs != cl.Fn.NumParameters {
+	if !cl.Fn.Accepts(numArgs) {
+		if cl.Fn.Variadic {
+			return fmt.Errorf("wrong number of arguments: want at least %d, got=%d",
+				cl.Fn.NumParameters-cl.Fn.NumDefaults-1, numArgs)
+		}
+		if cl.Fn.NumDefaults > 0 {
+			return fmt.Errorf("wrong number of arguments: want=%d to %d, got=%d",
+				cl.Fn.NumParameters-cl.Fn.NumDefaults, cl.Fn.NumParameters, numArgs)
+		}
 		return fmt.Errorf("wrong number of arguments: want=%d, got=%d",
 			cl.Fn.NumParameters, numArgs)
 	}
 
-	frame := NewFrame(cl, vm.sp-numArgs)
+	basePointer := vm.sp - numArgs
+	if cl.Fn.Variadic {
+		numArgs = vm.collectRestArgs(cl.Fn, basePointer, numArgs)
+	}
+
+	frame := NewFrame(cl, basePointer)
+	frame.numArgs = numArgs
 	vm.pushFrame(frame)
 
 	vm.sp = frame.basePointer + cl.Fn.NumLocals
+	if vm.localWatches != nil {
+		vm.clearLocals(frame)
+	}
 
 	return nil
 }
 
+// collectRestArgs moves the arguments of a variadic call that are left
+// over after the fixed parameters into an array, which becomes the value
+// of the rest parameter. It returns the number of fixed arguments passed.
+func (vm *VM) collectRestArgs(fn *object.CompiledFunction, basePointer, numArgs int) int {
+	fixed := fn.NumParameters - 1
+	rest := []object.Object{}
+	if numArgs > fixed {
+		rest = append(rest, vm.stack[basePointer+fixed:basePointer+numArgs]...)
+		numArgs = fixed
+	}
+	vm.allocated(AllocArray)
+	vm.stack[basePointer+fixed] = &object.Array{Elements: rest}
+	return numArgs
+}
+
 func (vm *VM) callBuiltin(builtin *object.Builtin, numArgs int) error {
 	args := vm.stack[vm.sp-numArgs : vm.sp]
 
-	result := builtin.Fn(args...)
+	result := builtin.Call(vm, args...)
 	vm.sp = vm.sp - numArgs - 1
 
+	if err, ok := result.(*object.Error); ok && err.Abort {
+		return fmt.Errorf("%s", err.Message)
+	}
 	if result != nil {
 		vm.push(result)
 	} else {
@@ -1426,7 +1189,10 @@ func (vm *VM) callBuiltin(builtin *object.Builtin, numArgs int) error {
 }
 
 func (vm *VM) pushClosure(constIndex int, numFree int) error {
-	constant := vm.constants[constIndex]
+	constant, err := vm.constant(constIndex)
+	if err != nil {
+		return err
+	}
 	function, ok := constant.(*object.CompiledFunction)
 	if !ok {
 		return fmt.Errorf("not a function: %+v", constant)
@@ -1438,6 +1204,7 @@ func (vm *VM) pushClosure(constIndex int, numFree int) error {
 	}
 	vm.sp = vm.sp - numFree
 
+	vm.allocated(AllocClosure)
 	closure := &object.Closure{Fn: function, Free: free}
 	return vm.push(closure)
 }
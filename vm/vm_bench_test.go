@@ -0,0 +1,59 @@
+package vm
+
+import (
+	"bhasa/compiler"
+	"bhasa/lexer"
+	"bhasa/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// BenchmarkPrograms runs each program of the bench suite, which lives in
+// bench/ at the root of the repository, in a fresh VM per iteration. Only
+// Run is timed. Compare dispatch changes with
+//
+//	go test ./vm -run '^$' -bench Programs -count 10 | benchstat
+func BenchmarkPrograms(b *testing.B) {
+	files, err := filepath.Glob(filepath.Join("..", "bench", "*.bhasa"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	if len(files) == 0 {
+		b.Fatal("no programs in ../bench")
+	}
+	for _, file := range files {
+		bytecode := compileBenchProgram(b, file)
+		name := strings.TrimSuffix(filepath.Base(file), ".bhasa")
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				machine := New(bytecode)
+				b.StartTimer()
+				if err := machine.Run(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// compileBenchProgram compiles one program of the bench suite
+func compileBenchProgram(b *testing.B, file string) *compiler.Bytecode {
+	b.Helper()
+	src, err := os.ReadFile(file)
+	if err != nil {
+		b.Fatal(err)
+	}
+	p := parser.New(lexer.New(string(src)))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		b.Fatalf("%s: %s", file, strings.Join(errs, "\n"))
+	}
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		b.Fatalf("%s: %v", file, err)
+	}
+	return comp.Bytecode()
+}
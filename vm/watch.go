@@ -0,0 +1,72 @@
+package vm
+
+import (
+	"bhasa/object"
+	"fmt"
+)
+
+// Watch reports every write to the global with the given index on the
+// VM's stderr, showing the value it had, the value written and the line
+// that wrote it; name is how the global is shown.
+func (vm *VM) Watch(index int, name string) {
+	if vm.watches == nil {
+		vm.watches = make(map[int]string)
+	}
+	vm.watches[index] = name
+}
+
+// WatchLocal reports every write to a local with the given name, in any
+// function, the way Watch does for globals. Only functions compiled from
+// source know the names of their locals; those read from a compiled file
+// are not watched.
+func (vm *VM) WatchLocal(name string) {
+	if vm.localWatches == nil {
+		vm.localWatches = make(map[string]bool)
+	}
+	vm.localWatches[name] = true
+}
+
+// reportWrite reports a write to a global if it is watched
+func (vm *VM) reportWrite(index int, value object.Object) {
+	name, ok := vm.watches[index]
+	if !ok {
+		return
+	}
+	vm.report(name, vm.globals[index], value)
+}
+
+// reportLocalWrite reports a write to a local of the current function if
+// it is watched. A local closures share holds a cell, which is marked so
+// that writes to it through OpSetCell are reported instead.
+func (vm *VM) reportLocalWrite(frame *Frame, index int, value object.Object) {
+	names := frame.cl.Fn.LocalNames
+	if index >= len(names) || !vm.localWatches[names[index]] {
+		return
+	}
+	if cell, ok := value.(*object.Cell); ok {
+		cell.Watch = names[index]
+		return
+	}
+	vm.report(names[index], vm.stack[frame.basePointer+index], value)
+}
+
+// clearLocals empties the slots of a new frame's locals past its
+// arguments, which hold whatever an earlier call left there, so a watched
+// local's first write is shown as changing from unset
+func (vm *VM) clearLocals(frame *Frame) {
+	clear(vm.stack[frame.basePointer+frame.numArgs : vm.sp])
+}
+
+// report writes one line about a write to a watched variable
+func (vm *VM) report(name string, old, value object.Object) {
+	oldText := "(unset)"
+	if old != nil {
+		oldText = old.Inspect()
+	}
+	where := ""
+	frame := vm.currentFrame()
+	if pos := object.PositionForOffset(frame.cl.Fn.Lines, frame.ip); pos.Line != 0 {
+		where = fmt.Sprintf(" at %s:%d", pos.File, pos.Line)
+	}
+	fmt.Fprintf(vm.stderr, "watch: %s changed from %s to %s%s\n", name, oldText, value.Inspect(), where)
+}